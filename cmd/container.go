@@ -7,15 +7,21 @@ import (
 	"github.com/doug-martin/goqu/v9"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/cors"
+	goredis "github.com/go-redis/redis/v8"
 	"go.uber.org/dig"
 
 	"github.com/tusmasoma/connectHub-backend/config"
 	"github.com/tusmasoma/connectHub-backend/interfaces/handler"
 	"github.com/tusmasoma/connectHub-backend/interfaces/middleware"
 	"github.com/tusmasoma/connectHub-backend/interfaces/ws"
+	"github.com/tusmasoma/connectHub-backend/internal/auth"
 	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+	"github.com/tusmasoma/connectHub-backend/repository/local"
+	"github.com/tusmasoma/connectHub-backend/repository/memory"
 	"github.com/tusmasoma/connectHub-backend/repository/mysql"
 	"github.com/tusmasoma/connectHub-backend/repository/redis"
+	"github.com/tusmasoma/connectHub-backend/repository/s3"
 	"github.com/tusmasoma/connectHub-backend/usecase"
 )
 
@@ -33,6 +39,14 @@ func BuildContainer(ctx context.Context) (*dig.Container, error) { //nolint:funl
 		config.NewServerConfig,
 		config.NewCacheConfig,
 		config.NewDBConfig,
+		config.NewFlusherConfig,
+		config.NewMessageConfig,
+		config.NewWebSocketConfig,
+		config.NewPubSubConfig,
+		config.NewPresenceConfig,
+		config.NewAttachmentConfig,
+		config.NewAuthConfig,
+		provideKeyManager,
 		provideMySQLDialect,
 		mysql.NewMySQLDB,
 		mysql.NewTransactionRepository,
@@ -42,22 +56,47 @@ func BuildContainer(ctx context.Context) (*dig.Container, error) { //nolint:funl
 		mysql.NewMessageRepository,
 		mysql.NewChannelRepository,
 		mysql.NewMembershipChannelRepository,
+		mysql.NewThreadFollowerRepository,
+		mysql.NewReactionRepository,
+		mysql.NewNotificationRepository,
+		mysql.NewAttachmentRepository,
 		redis.NewRedisClient,
 		redis.NewUserRepository,
+		redis.NewRefreshTokenRepository,
+		redis.NewSessionRepository,
 		redis.NewMessageRepository,
-		redis.NewPubSubRepository,
+		providePubSubRepository,
+		redis.NewReactionRepository,
+		redis.NewIdempotencyRepository,
+		provideChannelEventRepository,
+		redis.NewPresenceRepository,
+		provideBlobRepository,
 		usecase.NewUserUseCase,
 		usecase.NewMembershipUseCase,
 		usecase.NewWorkspaceUseCase,
 		usecase.NewMessageUseCase,
 		usecase.NewChannelUseCase,
 		usecase.NewMembershipChannelUseCase,
+		usecase.NewThreadUseCase,
+		usecase.NewReactionUseCase,
+		usecase.NewNotificationUseCase,
+		usecase.NewPresenceUseCase,
+		usecase.NewSearchUseCase,
+		usecase.NewAttachmentUseCase,
+		usecase.NewAuthorizationUseCase,
 		usecase.NewAuthUseCase,
+		usecase.NewMessageFlusher,
 		ws.NewHubManager,
 		handler.NewWebsocketHandler,
 		handler.NewWorkspaceHandler,
 		handler.NewUserHandler,
 		handler.NewMembershipHandler,
+		handler.NewNotificationHandler,
+		handler.NewPresenceHandler,
+		handler.NewSearchHandler,
+		handler.NewAttachmentHandler,
+		handler.NewJWKSHandler,
+		handler.NewDeadLetterHandler,
 		middleware.NewAuthMiddleware,
 		func(
 			serverConfig *config.ServerConfig,
@@ -65,6 +104,11 @@ func BuildContainer(ctx context.Context) (*dig.Container, error) { //nolint:funl
 			workspaceHandler handler.WorkspaceHandler,
 			membershipHandler handler.MembershipHandler,
 			userHandler handler.UserHandler,
+			notificationHandler handler.NotificationHandler,
+			presenceHandler handler.PresenceHandler,
+			searchHandler handler.SearchHandler,
+			attachmentHandler handler.AttachmentHandler,
+			jwksHandler handler.JWKSHandler,
 			authMiddleware middleware.AuthMiddleware,
 		) *chi.Mux {
 			r := chi.NewRouter()
@@ -83,6 +127,8 @@ func BuildContainer(ctx context.Context) (*dig.Container, error) { //nolint:funl
 				r.Get("/ws/{workspace_id}", wsHandler.WebSocket)
 			})
 
+			r.Get("/.well-known/jwks.json", jwksHandler.GetJWKS)
+
 			// r.Use(middleware.Logging)
 			r.Route("/api", func(r chi.Router) {
 				r.Route("/workspace", func(r chi.Router) {
@@ -97,14 +143,41 @@ func BuildContainer(ctx context.Context) (*dig.Container, error) { //nolint:funl
 					r.Get("/get/{workspace_id}", membershipHandler.GetMembership)
 					r.Post("/create/{workspace_id}", membershipHandler.CreateMembership)
 					r.Put("/update/{workspace_id}", membershipHandler.UpdateMembership)
+					r.Put("/read/{workspace_id}", membershipHandler.MarkChannelRead)
+				})
+
+				r.Route("/notification", func(r chi.Router) {
+					r.Use(authMiddleware.Authenticate)
+					r.Get("/list/{workspace_id}", notificationHandler.ListNotifications)
+					r.Put("/read/{workspace_id}", notificationHandler.MarkNotificationsRead)
+				})
+
+				r.Route("/presence", func(r chi.Router) {
+					r.Use(authMiddleware.Authenticate)
+					r.Get("/list/{workspace_id}", presenceHandler.ListPresences)
+				})
+
+				r.Route("/search", func(r chi.Router) {
+					r.Use(authMiddleware.Authenticate)
+					r.Get("/messages/{workspace_id}", searchHandler.SearchMessages)
+				})
+
+				r.Route("/attachment", func(r chi.Router) {
+					r.Use(authMiddleware.Authenticate)
+					r.Post("/upload/{workspace_id}", attachmentHandler.UploadAttachment)
+					r.Get("/download/{workspace_id}/{attachment_id}", attachmentHandler.DownloadAttachment)
 				})
 
 				r.Route("/user", func(r chi.Router) {
 					r.Post("/signup", userHandler.SignUp)
 					r.Post("/login", userHandler.Login)
+					r.Post("/refresh", userHandler.Refresh)
 					r.Group(func(r chi.Router) {
 						r.Use(authMiddleware.Authenticate)
 						r.Get("/logout", userHandler.Logout)
+						r.Get("/session/list", userHandler.ListSessions)
+						r.Delete("/session/revoke/{session_id}", userHandler.RevokeSession)
+						r.Delete("/session/revoke-all", userHandler.RevokeAllSessions)
 					})
 				})
 			})
@@ -128,3 +201,48 @@ func provideMySQLDialect() *goqu.DialectWrapper {
 	dialect := goqu.Dialect("mysql")
 	return &dialect
 }
+
+// provideKeyManager は、KeyDirを指定した場合はそのディレクトリから、それ以外は環境変数のパスから署名鍵を読み込みます。
+func provideKeyManager(conf *config.AuthConfig) (*auth.KeyManager, error) {
+	return auth.NewKeyManager(conf.KeyDir)
+}
+
+// providePubSubRepository は、設定に応じてPubSubの実装を選びます。
+// memoryは同じプロセスにしか配信しないため、サーバーを1台で動かす場合にだけ使えます。
+func providePubSubRepository(conf *config.PubSubConfig, client *goredis.Client) (repository.PubSubRepository, error) {
+	switch conf.Backend {
+	case config.PubSubBackendRedis:
+		return redis.NewPubSubRepository(client), nil
+	case config.PubSubBackendMemory:
+		return memory.NewPubSubRepository(), nil
+	default:
+		log.Error("Unknown pubsub backend", log.Fstring("backend", conf.Backend))
+		return nil, fmt.Errorf("unknown pubsub backend: %s", conf.Backend)
+	}
+}
+
+// provideChannelEventRepository は、チャンネルのイベントをPubSubと同じ実装で配信します。
+func provideChannelEventRepository(
+	conf *config.PubSubConfig,
+	client *goredis.Client,
+	psr repository.PubSubRepository,
+) repository.ChannelEventRepository {
+	if conf.Backend == config.PubSubBackendMemory {
+		return memory.NewChannelEventRepository(psr)
+	}
+	return redis.NewChannelEventRepository(client)
+}
+
+// provideBlobRepository は、設定に応じて添付ファイルの保存先を選びます。
+// localはサーバーのファイルシステムに保存するため、複数のサーバーで動かす場合は共有のボリュームかs3を使います。
+func provideBlobRepository(conf *config.AttachmentConfig) (repository.BlobRepository, error) {
+	switch conf.Backend {
+	case config.AttachmentBackendLocal:
+		return local.NewBlobRepository(conf.LocalDir), nil
+	case config.AttachmentBackendS3:
+		return s3.NewBlobRepository(conf)
+	default:
+		log.Error("Unknown attachment backend", log.Fstring("backend", conf.Backend))
+		return nil, fmt.Errorf("unknown attachment backend: %s", conf.Backend)
+	}
+}
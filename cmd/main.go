@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"expvar"
 	"flag"
 	"net/http"
 	"os"
@@ -13,7 +14,11 @@ import (
 	"github.com/joho/godotenv"
 
 	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/interfaces/handler"
+	"github.com/tusmasoma/connectHub-backend/interfaces/ws"
+	"github.com/tusmasoma/connectHub-backend/internal/auth"
 	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/usecase"
 )
 
 func main() {
@@ -35,8 +40,36 @@ func main() {
 		return
 	}
 
+	/* ===== メッセージ書き戻しの起動 ===== */
+	err = container.Invoke(func(flusher usecase.MessageFlusher) {
+		go flusher.Run(mainCtx)
+	})
+	if err != nil {
+		log.Critical("Failed to start message flusher", log.Ferror(err))
+		return
+	}
+
+	/* ===== 署名鍵の再読み込み ===== */
+	// SIGHUPを受け取ると鍵を読み込み直し、サーバーを再起動せずに鍵をローテーションできます
+	err = container.Invoke(func(km *auth.KeyManager) {
+		go km.ReloadOnSignal(mainCtx, syscall.SIGHUP)
+	})
+	if err != nil {
+		log.Critical("Failed to start key reloader", log.Ferror(err))
+		return
+	}
+
+	/* ===== Hubの復元 ===== */
+	err = container.Invoke(func(hm *ws.HubManager) error {
+		return hm.Load(mainCtx)
+	})
+	if err != nil {
+		log.Critical("Failed to load hubs", log.Ferror(err))
+		return
+	}
+
 	/* ===== サーバの設定 ===== */
-	err = container.Invoke(func(router *chi.Mux, config *config.ServerConfig) {
+	err = container.Invoke(func(router *chi.Mux, config *config.ServerConfig, deadLetterHandler handler.DeadLetterHandler) {
 		srv := &http.Server{
 			Addr:         addr,
 			Handler:      router,
@@ -57,6 +90,24 @@ func main() {
 			}
 		}()
 
+		/* ===== 管理用サーバの起動 ===== */
+		// /debug/varsはプロセスの情報を含み、デッドレターの操作は運用者向けのため、公開するルーターとは別のアドレスで待ち受けます
+		adminRouter := chi.NewRouter()
+		adminRouter.Handle("/debug/vars", expvar.Handler())
+		adminRouter.Post("/admin/messages/dead_letters/requeue", deadLetterHandler.RequeueDeadLetters)
+		adminSrv := &http.Server{
+			Addr:         config.AdminAddr,
+			Handler:      adminRouter,
+			ReadTimeout:  config.ReadTimeout,
+			WriteTimeout: config.WriteTimeout,
+			IdleTimeout:  config.IdleTimeout,
+		}
+		go func() {
+			if aerr := adminSrv.ListenAndServe(); aerr != nil && !errors.Is(aerr, http.ErrServerClosed) {
+				log.Error("Admin server failed", log.Ferror(aerr))
+			}
+		}()
+
 		<-signalCtx.Done()
 		log.Info("Server stopping...")
 
@@ -66,6 +117,9 @@ func main() {
 		if err = srv.Shutdown(tctx); err != nil {
 			log.Error("Failed to shutdown http server", log.Ferror(err))
 		}
+		if err = adminSrv.Shutdown(tctx); err != nil {
+			log.Error("Failed to shutdown admin server", log.Ferror(err))
+		}
 		log.Info("Server exited")
 	})
 	if err != nil {
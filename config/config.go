@@ -13,6 +13,27 @@ const (
 	dbPrefix     = "MYSQL_"
 	cachePrefix  = "REDIS_"
 	serverPrefix = "SERVER_"
+	flushPrefix  = "FLUSHER_"
+	msgPrefix    = "MESSAGE_"
+	wsPrefix     = "WEBSOCKET_"
+	pubsubPrefix = "PUBSUB_"
+	presPrefix   = "PRESENCE_"
+	attachPrefix = "ATTACHMENT_"
+	authPrefix   = "AUTH_"
+)
+
+const (
+	// PubSubBackendRedis は、Redisを経由して全てのサーバーにメッセージを配信します。
+	PubSubBackendRedis = "redis"
+	// PubSubBackendMemory は、同じプロセスにだけメッセージを配信します。単一のサーバーで動かす場合に使います。
+	PubSubBackendMemory = "memory"
+)
+
+const (
+	// AttachmentBackendLocal は、添付ファイルをサーバーのファイルシステムに保存します。
+	AttachmentBackendLocal = "local"
+	// AttachmentBackendS3 は、添付ファイルをS3互換のオブジェクトストレージに保存します。
+	AttachmentBackendS3 = "s3"
 )
 
 type DBConfig struct {
@@ -35,6 +56,68 @@ type ServerConfig struct {
 	IdleTimeout               time.Duration `env:"IDLE_TIMEOUT,default=15s"`
 	GracefulShutdownTimeout   time.Duration `env:"GRACEFUL_SHUTDOWN_TIMEOUT,default=5s"`
 	PreflightCacheDurationSec int           `env:"PREFLIGHT_CACHE_DURATION_SEC,default=300"`
+	// AdminAddr は、/debug/varsなどの運用向けのエンドポイントを待ち受けるアドレスです。
+	// 認証をかけないため、既定ではループバックだけで待ち受けます。
+	AdminAddr string `env:"ADMIN_ADDR,default=127.0.0.1:8084"`
+}
+
+type FlusherConfig struct {
+	Interval     time.Duration `env:"INTERVAL,default=1s"`
+	BatchSize    int64         `env:"BATCH_SIZE,default=100"`
+	MaxRetries   int           `env:"MAX_RETRIES,default=3"`
+	RetryBackoff time.Duration `env:"RETRY_BACKOFF,default=200ms"`
+	// RetryBudget は、1回のFlushで再試行に使える時間の上限です。超えた分は再試行せず次回に持ち越します。
+	RetryBudget      time.Duration `env:"RETRY_BUDGET,default=5s"`
+	LagWarnThreshold time.Duration `env:"LAG_WARN_THRESHOLD,default=30s"`
+}
+
+type MessageConfig struct {
+	IdempotencyWindow time.Duration `env:"IDEMPOTENCY_WINDOW,default=24h"`
+}
+
+type WebSocketConfig struct {
+	// OverflowPolicy は、送信バッファが一杯のクライアントへのフレームの扱いです。drop_oldest, disconnect, coalesceのいずれかです。
+	OverflowPolicy string `env:"OVERFLOW_POLICY,default=drop_oldest"`
+}
+
+type PubSubConfig struct {
+	// Backend は、PubSubとチャンネルのイベントの配信に使う実装です。redis, memoryのいずれかです。
+	Backend string `env:"BACKEND,default=redis"`
+}
+
+type PresenceConfig struct {
+	// TTL は、ハートビートが届かなくなった接続をオフラインとして扱うまでの時間です。HeartbeatIntervalより長くしてください。
+	TTL               time.Duration `env:"TTL,default=60s"`
+	HeartbeatInterval time.Duration `env:"HEARTBEAT_INTERVAL,default=20s"`
+}
+
+type AttachmentConfig struct {
+	// Backend は、添付ファイルの内容を保存する実装です。local, s3のいずれかです。
+	Backend  string `env:"BACKEND,default=local"`
+	LocalDir string `env:"LOCAL_DIR,default=./data/attachments"`
+	// MaxSize と AllowedTypes は、ワークスペースで設定していない場合の制限です。AllowedTypesが空の場合は全てのMIMEタイプを受け付けます。
+	MaxSize      int64    `env:"MAX_SIZE,default=10485760"`
+	AllowedTypes []string `env:"ALLOWED_TYPES"`
+	// MaxUploadSize は、ワークスペースの設定にかかわらず受け付けるアップロードのリクエストの最大バイト数です。
+	MaxUploadSize int64 `env:"MAX_UPLOAD_SIZE,default=104857600"`
+	// S3Endpoint は、MinIOなどのS3互換のストレージを使う場合に変更します。
+	S3Endpoint        string `env:"S3_ENDPOINT,default=https://s3.amazonaws.com"`
+	S3Region          string `env:"S3_REGION,default=us-east-1"`
+	S3Bucket          string `env:"S3_BUCKET"`
+	S3AccessKeyID     string `env:"S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey string `env:"S3_SECRET_ACCESS_KEY"`
+	// S3UsePathStyle は、バケット名をホスト名ではなくパスに含めます。多くのS3互換のストレージで必要です。
+	S3UsePathStyle bool `env:"S3_USE_PATH_STYLE,default=false"`
+}
+
+type AuthConfig struct {
+	// AccessTokenTTL は、アクセストークンの有効期間です。漏洩した場合の影響を抑えるため短くします。
+	AccessTokenTTL time.Duration `env:"ACCESS_TOKEN_TTL,default=15m"`
+	// RefreshTokenTTL は、リフレッシュトークンの有効期間です。リフレッシュするたびに延長されます。
+	RefreshTokenTTL time.Duration `env:"REFRESH_TOKEN_TTL,default=720h"`
+	// KeyDir は、<kid>.pemという名前の署名鍵を置くディレクトリです。名前が最も後ろの鍵で署名し、他の鍵は検証にだけ使います。
+	// 空の場合はPRIVATE_KEY_PATHとPUBLIC_KEY_PATHの鍵を使います。
+	KeyDir string `env:"KEY_DIR"`
 }
 
 func NewDBConfig(ctx context.Context) (*DBConfig, error) {
@@ -66,3 +149,73 @@ func NewServerConfig(ctx context.Context) (*ServerConfig, error) {
 	}
 	return conf, nil
 }
+
+func NewFlusherConfig(ctx context.Context) (*FlusherConfig, error) {
+	conf := &FlusherConfig{}
+	pl := envconfig.PrefixLookuper(flushPrefix, envconfig.OsLookuper())
+	if err := envconfig.ProcessWith(ctx, conf, pl); err != nil {
+		log.Error("Failed to load flusher config", log.Ferror(err))
+		return nil, err
+	}
+	return conf, nil
+}
+
+func NewMessageConfig(ctx context.Context) (*MessageConfig, error) {
+	conf := &MessageConfig{}
+	pl := envconfig.PrefixLookuper(msgPrefix, envconfig.OsLookuper())
+	if err := envconfig.ProcessWith(ctx, conf, pl); err != nil {
+		log.Error("Failed to load message config", log.Ferror(err))
+		return nil, err
+	}
+	return conf, nil
+}
+
+func NewWebSocketConfig(ctx context.Context) (*WebSocketConfig, error) {
+	conf := &WebSocketConfig{}
+	pl := envconfig.PrefixLookuper(wsPrefix, envconfig.OsLookuper())
+	if err := envconfig.ProcessWith(ctx, conf, pl); err != nil {
+		log.Error("Failed to load websocket config", log.Ferror(err))
+		return nil, err
+	}
+	return conf, nil
+}
+
+func NewPubSubConfig(ctx context.Context) (*PubSubConfig, error) {
+	conf := &PubSubConfig{}
+	pl := envconfig.PrefixLookuper(pubsubPrefix, envconfig.OsLookuper())
+	if err := envconfig.ProcessWith(ctx, conf, pl); err != nil {
+		log.Error("Failed to load pubsub config", log.Ferror(err))
+		return nil, err
+	}
+	return conf, nil
+}
+
+func NewPresenceConfig(ctx context.Context) (*PresenceConfig, error) {
+	conf := &PresenceConfig{}
+	pl := envconfig.PrefixLookuper(presPrefix, envconfig.OsLookuper())
+	if err := envconfig.ProcessWith(ctx, conf, pl); err != nil {
+		log.Error("Failed to load presence config", log.Ferror(err))
+		return nil, err
+	}
+	return conf, nil
+}
+
+func NewAttachmentConfig(ctx context.Context) (*AttachmentConfig, error) {
+	conf := &AttachmentConfig{}
+	pl := envconfig.PrefixLookuper(attachPrefix, envconfig.OsLookuper())
+	if err := envconfig.ProcessWith(ctx, conf, pl); err != nil {
+		log.Error("Failed to load attachment config", log.Ferror(err))
+		return nil, err
+	}
+	return conf, nil
+}
+
+func NewAuthConfig(ctx context.Context) (*AuthConfig, error) {
+	conf := &AuthConfig{}
+	pl := envconfig.PrefixLookuper(authPrefix, envconfig.OsLookuper())
+	if err := envconfig.ProcessWith(ctx, conf, pl); err != nil {
+		log.Error("Failed to load auth config", log.Ferror(err))
+		return nil, err
+	}
+	return conf, nil
+}
@@ -127,6 +127,7 @@ func Test_NewServerConfig(t *testing.T) {
 				IdleTimeout:               15 * time.Second,
 				GracefulShutdownTimeout:   5 * time.Second,
 				PreflightCacheDurationSec: 300,
+				AdminAddr:                 "127.0.0.1:8084",
 			},
 			err: nil,
 		},
@@ -139,6 +140,7 @@ func Test_NewServerConfig(t *testing.T) {
 				t.Setenv("SERVER_IDLE_TIMEOUT", "10s")
 				t.Setenv("SERVER_GRACEFUL_SHUTDOWN_TIMEOUT", "3s")
 				t.Setenv("SERVER_PREFLIGHT_CACHE_DURATION_SEC", "150")
+				t.Setenv("SERVER_ADMIN_ADDR", "10.0.0.1:9000")
 			},
 			want: &ServerConfig{
 				ReadTimeout:               2 * time.Second,
@@ -146,6 +148,7 @@ func Test_NewServerConfig(t *testing.T) {
 				IdleTimeout:               10 * time.Second,
 				GracefulShutdownTimeout:   3 * time.Second,
 				PreflightCacheDurationSec: 150,
+				AdminAddr:                 "10.0.0.1:9000",
 			},
 		},
 	}
@@ -161,3 +164,335 @@ func Test_NewServerConfig(t *testing.T) {
 		})
 	}
 }
+
+func Test_NewFlusherConfig(t *testing.T) {
+	ctx := context.Background()
+
+	patterns := []struct {
+		name  string
+		setup func(t *testing.T)
+		want  *FlusherConfig
+	}{
+		{
+			name: "default",
+			setup: func(t *testing.T) {
+				t.Helper()
+			},
+			want: &FlusherConfig{
+				Interval:         1 * time.Second,
+				BatchSize:        100,
+				MaxRetries:       3,
+				RetryBackoff:     200 * time.Millisecond,
+				RetryBudget:      5 * time.Second,
+				LagWarnThreshold: 30 * time.Second,
+			},
+		},
+		{
+			name: "set env",
+			setup: func(t *testing.T) {
+				t.Helper()
+				t.Setenv("FLUSHER_INTERVAL", "5s")
+				t.Setenv("FLUSHER_BATCH_SIZE", "500")
+				t.Setenv("FLUSHER_MAX_RETRIES", "5")
+				t.Setenv("FLUSHER_RETRY_BACKOFF", "1s")
+				t.Setenv("FLUSHER_RETRY_BUDGET", "10s")
+				t.Setenv("FLUSHER_LAG_WARN_THRESHOLD", "1m")
+			},
+			want: &FlusherConfig{
+				Interval:         5 * time.Second,
+				BatchSize:        500,
+				MaxRetries:       5,
+				RetryBackoff:     1 * time.Second,
+				RetryBudget:      10 * time.Second,
+				LagWarnThreshold: 1 * time.Minute,
+			},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup(t)
+
+			got, err := NewFlusherConfig(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_NewMessageConfig(t *testing.T) {
+	ctx := context.Background()
+
+	patterns := []struct {
+		name  string
+		setup func(t *testing.T)
+		want  *MessageConfig
+	}{
+		{
+			name: "default",
+			setup: func(t *testing.T) {
+				t.Helper()
+			},
+			want: &MessageConfig{
+				IdempotencyWindow: 24 * time.Hour,
+			},
+		},
+		{
+			name: "set env",
+			setup: func(t *testing.T) {
+				t.Helper()
+				t.Setenv("MESSAGE_IDEMPOTENCY_WINDOW", "10m")
+			},
+			want: &MessageConfig{
+				IdempotencyWindow: 10 * time.Minute,
+			},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup(t)
+
+			got, err := NewMessageConfig(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_NewWebSocketConfig(t *testing.T) {
+	ctx := context.Background()
+
+	patterns := []struct {
+		name  string
+		setup func(t *testing.T)
+		want  *WebSocketConfig
+	}{
+		{
+			name: "default",
+			setup: func(t *testing.T) {
+				t.Helper()
+			},
+			want: &WebSocketConfig{
+				OverflowPolicy: "drop_oldest",
+			},
+		},
+		{
+			name: "set env",
+			setup: func(t *testing.T) {
+				t.Helper()
+				t.Setenv("WEBSOCKET_OVERFLOW_POLICY", "disconnect")
+			},
+			want: &WebSocketConfig{
+				OverflowPolicy: "disconnect",
+			},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup(t)
+
+			got, err := NewWebSocketConfig(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_NewPubSubConfig(t *testing.T) {
+	ctx := context.Background()
+
+	patterns := []struct {
+		name  string
+		setup func(t *testing.T)
+		want  *PubSubConfig
+	}{
+		{
+			name: "default",
+			setup: func(t *testing.T) {
+				t.Helper()
+			},
+			want: &PubSubConfig{
+				Backend: PubSubBackendRedis,
+			},
+		},
+		{
+			name: "set env",
+			setup: func(t *testing.T) {
+				t.Helper()
+				t.Setenv("PUBSUB_BACKEND", "memory")
+			},
+			want: &PubSubConfig{
+				Backend: PubSubBackendMemory,
+			},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup(t)
+
+			got, err := NewPubSubConfig(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_NewPresenceConfig(t *testing.T) {
+	ctx := context.Background()
+
+	patterns := []struct {
+		name  string
+		setup func(t *testing.T)
+		want  *PresenceConfig
+	}{
+		{
+			name: "default",
+			setup: func(t *testing.T) {
+				t.Helper()
+			},
+			want: &PresenceConfig{
+				TTL:               60 * time.Second,
+				HeartbeatInterval: 20 * time.Second,
+			},
+		},
+		{
+			name: "set env",
+			setup: func(t *testing.T) {
+				t.Helper()
+				t.Setenv("PRESENCE_TTL", "30s")
+				t.Setenv("PRESENCE_HEARTBEAT_INTERVAL", "10s")
+			},
+			want: &PresenceConfig{
+				TTL:               30 * time.Second,
+				HeartbeatInterval: 10 * time.Second,
+			},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup(t)
+
+			got, err := NewPresenceConfig(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_NewAttachmentConfig(t *testing.T) {
+	ctx := context.Background()
+
+	patterns := []struct {
+		name  string
+		setup func(t *testing.T)
+		want  *AttachmentConfig
+	}{
+		{
+			name: "default",
+			setup: func(t *testing.T) {
+				t.Helper()
+			},
+			want: &AttachmentConfig{
+				Backend:       AttachmentBackendLocal,
+				LocalDir:      "./data/attachments",
+				MaxSize:       10485760,
+				MaxUploadSize: 104857600,
+				S3Endpoint:    "https://s3.amazonaws.com",
+				S3Region:      "us-east-1",
+			},
+		},
+		{
+			name: "set env",
+			setup: func(t *testing.T) {
+				t.Helper()
+				t.Setenv("ATTACHMENT_BACKEND", "s3")
+				t.Setenv("ATTACHMENT_MAX_SIZE", "1024")
+				t.Setenv("ATTACHMENT_ALLOWED_TYPES", "image/*,application/pdf")
+				t.Setenv("ATTACHMENT_S3_ENDPOINT", "http://minio:9000")
+				t.Setenv("ATTACHMENT_S3_BUCKET", "attachments")
+				t.Setenv("ATTACHMENT_S3_ACCESS_KEY_ID", "access")
+				t.Setenv("ATTACHMENT_S3_SECRET_ACCESS_KEY", "secret")
+				t.Setenv("ATTACHMENT_S3_USE_PATH_STYLE", "true")
+			},
+			want: &AttachmentConfig{
+				Backend:           AttachmentBackendS3,
+				LocalDir:          "./data/attachments",
+				MaxSize:           1024,
+				AllowedTypes:      []string{"image/*", "application/pdf"},
+				MaxUploadSize:     104857600,
+				S3Endpoint:        "http://minio:9000",
+				S3Region:          "us-east-1",
+				S3Bucket:          "attachments",
+				S3AccessKeyID:     "access",
+				S3SecretAccessKey: "secret",
+				S3UsePathStyle:    true,
+			},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup(t)
+
+			got, err := NewAttachmentConfig(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_NewAuthConfig(t *testing.T) {
+	ctx := context.Background()
+
+	patterns := []struct {
+		name  string
+		setup func(t *testing.T)
+		want  *AuthConfig
+	}{
+		{
+			name: "default",
+			setup: func(t *testing.T) {
+				t.Helper()
+			},
+			want: &AuthConfig{
+				AccessTokenTTL:  15 * time.Minute,
+				RefreshTokenTTL: 720 * time.Hour,
+			},
+		},
+		{
+			name: "set env",
+			setup: func(t *testing.T) {
+				t.Helper()
+				t.Setenv("AUTH_ACCESS_TOKEN_TTL", "5m")
+				t.Setenv("AUTH_REFRESH_TOKEN_TTL", "168h")
+				t.Setenv("AUTH_KEY_DIR", "/etc/connecthub/keys")
+			},
+			want: &AuthConfig{
+				AccessTokenTTL:  5 * time.Minute,
+				RefreshTokenTTL: 168 * time.Hour,
+				KeyDir:          "/etc/connecthub/keys",
+			},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup(t)
+
+			got, err := NewAuthConfig(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
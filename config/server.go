@@ -2,4 +2,7 @@ package config
 
 type ContextKey string
 
-const ContextUserIDKey ContextKey = "userID"
+const (
+	ContextUserIDKey    ContextKey = "userID"
+	ContextSessionIDKey ContextKey = "sessionID"
+)
@@ -22,10 +22,36 @@ const (
 	// ChannelBufferSize is the buffer size for the channel.
 	ChannelBufferSize = 256
 
-	// PubSubGeneralChannel is the general channel for pubsub.
-	PubSubGeneralChannel = "general"
+	// DefaultMessagePageSize is the number of messages returned by LIST_MESSAGES when no limit is given.
+	DefaultMessagePageSize = 50
+
+	// MaxMessagePageSize is the maximum number of messages returned by LIST_MESSAGES.
+	MaxMessagePageSize = 100
+
+	// DefaultSearchPageSize is the number of messages returned by message search when no limit is given.
+	DefaultSearchPageSize = 20
+
+	// MaxSearchPageSize is the maximum number of messages returned by message search.
+	MaxSearchPageSize = 100
+
+	// PubSubDeliveryPrefix is the prefix for the per-workspace topic used to deliver messages to specific memberships.
+	PubSubDeliveryPrefix = "delivery:"
+
+	// PubSubWorkspacePrefix is the prefix for the per-workspace topic used to broadcast events to every client of the workspace.
+	PubSubWorkspacePrefix = "workspace:"
+
+	// PubSubResubscribeInterval is the wait time before subscribing to a topic again after the subscription ended.
+	PubSubResubscribeInterval = 1 * time.Second
+
+	// PubSubChannelPrefix is the prefix for the per-channel topic used to deliver channel events.
+	PubSubChannelPrefix = "channel:"
+
+	// TypingThrottle is the minimum interval between TYPING events relayed for a client in the same channel.
+	TypingThrottle = 3 * time.Second
+
+	// TypingTTL is how long clients show a TYPING event unless another one arrives. Must be longer than TypingThrottle.
+	TypingTTL = 5 * time.Second
 
-	// PubSubChannelPrefix is the prefix for the channel channel.
 	WelcomeMessage = "%s joined the channel"
 	GoodbyeMessage = "%s left the channel"
 )
@@ -0,0 +1,148 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+)
+
+// maxAttachmentNameLength は、添付ファイル名の最大文字数です。
+const maxAttachmentNameLength = 255
+
+// Attachment は、メンバーシップがチャンネルにアップロードしたファイルを表します。
+// アップロードした時点ではどのメッセージにも属さず、メッセージの作成時にMessageIDを設定します。
+type Attachment struct {
+	ID           string    `json:"id" db:"id"`
+	WorkspaceID  string    `json:"workspace_id" db:"workspace_id"`
+	ChannelID    string    `json:"channel_id" db:"channel_id"`
+	MembershipID string    `json:"membership_id" db:"membership_id"` // アップロードしたメンバーシップのID
+	MessageID    string    `json:"message_id,omitempty" db:"message_id"`
+	Name         string    `json:"name" db:"name"`
+	Size         int64     `json:"size" db:"size"`
+	MimeType     string    `json:"mime_type" db:"mime_type"`
+	Checksum     string    `json:"checksum" db:"checksum"` // 内容のSHA-256の16進数表記
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+func NewAttachment(id, workspaceID, channelID, membershipID, name, mimeType string, size int64, checksum string) (*Attachment, error) {
+	if id == "" || workspaceID == "" || channelID == "" || membershipID == "" || name == "" || mimeType == "" || checksum == "" {
+		log.Warn(
+			"ID, WorkspaceID, ChannelID, MembershipID, Name, MimeType and Checksum are required",
+			log.Fstring("id", id),
+			log.Fstring("workspaceID", workspaceID),
+			log.Fstring("channelID", channelID),
+			log.Fstring("membershipID", membershipID),
+			log.Fstring("name", name),
+			log.Fstring("mimeType", mimeType),
+		)
+		return nil, fmt.Errorf("id, workspaceID, channelID, membershipID, name, mimeType and checksum are required")
+	}
+	if utf8.RuneCountInString(name) > maxAttachmentNameLength || strings.ContainsAny(name, "/\\\x00") {
+		log.Warn("Invalid attachment name", log.Fstring("name", name))
+		return nil, fmt.Errorf("name must be at most %d characters without path separators", maxAttachmentNameLength)
+	}
+	if size <= 0 {
+		log.Warn("Attachment is empty", log.Fstring("name", name))
+		return nil, fmt.Errorf("size must be positive")
+	}
+	return &Attachment{
+		ID:           id,
+		WorkspaceID:  workspaceID,
+		ChannelID:    channelID,
+		MembershipID: membershipID,
+		Name:         name,
+		Size:         size,
+		MimeType:     mimeType,
+		Checksum:     checksum,
+		CreatedAt:    time.Now(),
+	}, nil
+}
+
+// AttachmentStorageKey は、添付ファイルの内容を保存するストレージ上のキーです。
+// ワークスペースとチャンネルごとにまとめ、チャンネルの削除時に一括で消せるようにします。
+func AttachmentStorageKey(workspaceID, channelID, attachmentID string) string {
+	return workspaceID + "/" + channelID + "/" + attachmentID
+}
+
+// StorageKey は、添付ファイルの内容を保存したストレージ上のキーを返します。
+func (a Attachment) StorageKey() string {
+	return AttachmentStorageKey(a.WorkspaceID, a.ChannelID, a.ID)
+}
+
+// Attachments は、メッセージに添付したファイルの一覧です。
+// メッセージと一緒に読み込めるよう、MySQLではJSONとして1つのカラムに保存します。
+type Attachments []Attachment
+
+// Value は、添付ファイルがない場合はNULLとして保存します。
+func (a Attachments) Value() (driver.Value, error) {
+	if len(a) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (a *Attachments) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	default:
+		return fmt.Errorf("cannot scan %T into attachments", src)
+	}
+}
+
+// IDs は、添付ファイルのIDを返します。
+func (a Attachments) IDs() []string {
+	ids := make([]string, len(a))
+	for i, attachment := range a {
+		ids[i] = attachment.ID
+	}
+	return ids
+}
+
+// AttachmentPolicy は、ワークスペースで受け付ける添付ファイルの制限です。
+type AttachmentPolicy struct {
+	MaxSize int64 // 1件あたりの最大バイト数
+	// AllowedTypes は、添付できるMIMEタイプです。image/*のようにサブタイプを省略できます。空の場合は全てのタイプを受け付けます。
+	AllowedTypes []string
+}
+
+// AllowsSize は、sizeバイトのファイルを添付できるかを返します。
+func (p AttachmentPolicy) AllowsSize(size int64) bool {
+	return size <= p.MaxSize
+}
+
+// AllowsType は、mimeTypeのファイルを添付できるかを返します。パラメータ(charsetなど)は無視します。
+func (p AttachmentPolicy) AllowsType(mimeType string) bool {
+	if len(p.AllowedTypes) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range p.AllowedTypes {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
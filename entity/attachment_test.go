@@ -0,0 +1,96 @@
+package entity
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestEntity_NewAttachment(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name    string
+		attName string
+		size    int64
+		wantErr error
+	}{
+		{name: "Success", attName: "report.pdf", size: 1, wantErr: nil},
+		{name: "Fail: name is required", attName: "", size: 1, wantErr: fmt.Errorf("id, workspaceID, channelID, membershipID, name, mimeType and checksum are required")},
+		{name: "Fail: name with path", attName: "../report.pdf", size: 1, wantErr: fmt.Errorf("name must be at most 255 characters without path separators")},
+		{name: "Fail: empty file", attName: "report.pdf", size: 0, wantErr: fmt.Errorf("size must be positive")},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewAttachment("1", "1", "1", "1", tt.attName, "application/pdf", tt.size, "checksum")
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("NewAttachment() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("NewAttachment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEntity_AttachmentPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy := AttachmentPolicy{MaxSize: 10, AllowedTypes: []string{"image/*", " application/pdf"}}
+	patterns := []struct {
+		mimeType string
+		want     bool
+	}{
+		{mimeType: "image/png", want: true},
+		{mimeType: "application/pdf", want: true},
+		{mimeType: "text/plain; charset=utf-8", want: false},
+		{mimeType: "imagex/png", want: false},
+	}
+	for _, tt := range patterns {
+		if got := policy.AllowsType(tt.mimeType); got != tt.want {
+			t.Errorf("AllowsType(%q) = %v, want %v", tt.mimeType, got, tt.want)
+		}
+	}
+	if !(AttachmentPolicy{MaxSize: 10}).AllowsType("text/plain") {
+		t.Errorf("Expected every type to be allowed without AllowedTypes")
+	}
+	if !policy.AllowsSize(10) || policy.AllowsSize(11) {
+		t.Errorf("Expected sizes up to %d bytes to be allowed", policy.MaxSize)
+	}
+
+	workspace := Workspace{MaxAttachmentSize: 20, AllowedAttachmentTypes: "text/plain"}
+	want := AttachmentPolicy{MaxSize: 20, AllowedTypes: []string{"text/plain"}}
+	if got := workspace.AttachmentPolicy(policy); !reflect.DeepEqual(got, want) {
+		t.Errorf("AttachmentPolicy() = %+v, want %+v", got, want)
+	}
+	if got := (Workspace{}).AttachmentPolicy(policy); !reflect.DeepEqual(got, policy) {
+		t.Errorf("AttachmentPolicy() = %+v, want defaults %+v", got, policy)
+	}
+}
+
+func TestEntity_Attachments_ValueScan(t *testing.T) {
+	t.Parallel()
+
+	attachments := Attachments{{ID: "1", Name: "report.pdf", Size: 1, MimeType: "application/pdf"}}
+	value, err := attachments.Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+	var got Attachments
+	if err = got.Scan([]byte(value.(string))); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, attachments) {
+		t.Errorf("Scan() = %+v, want %+v", got, attachments)
+	}
+
+	if value, err = (Attachments{}).Value(); value != nil || err != nil {
+		t.Errorf("Value() of no attachments = %v, %v, want nil", value, err)
+	}
+	if err = got.Scan(nil); err != nil || got != nil {
+		t.Errorf("Scan(nil) = %v, %v, want nil", got, err)
+	}
+}
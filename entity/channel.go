@@ -1,17 +1,36 @@
 package entity
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
 
 	"github.com/tusmasoma/connectHub-backend/internal/log"
 )
 
+// DMチャンネルの名前に使う接頭辞です。通常のチャンネル名には使えません。
+const directChannelNamePrefix = "dm:"
+
+// DMチャンネルに参加できるメンバーシップ数の範囲です。
+const (
+	minDirectMembers = 2
+	maxDirectMembers = 9
+)
+
+// ChannelCreatedAction は、ワークスペースに公開チャンネルが作成されたことを全てのクライアントに伝えるイベントです。
+// クライアントはチャンネルの一覧に加え、JOIN_PUBLIC_CHANNELで参加できます。
+const ChannelCreatedAction = "CHANNEL_CREATED"
+
 type Channel struct {
 	ID          string `json:"id" db:"id"`
 	WorkspaceID string `json:"workspace_id" db:"workspace_id"`
 	Name        string `json:"name" db:"name"`
 	Description string `json:"description" db:"description"`
 	Private     bool   `json:"private" db:"private"`
+	Direct      bool   `json:"direct" db:"direct"`
 }
 
 func NewChannel(id, workspaceID, name, description string, private bool) (*Channel, error) {
@@ -27,6 +46,10 @@ func NewChannel(id, workspaceID, name, description string, private bool) (*Chann
 		log.Warn("Name is required", log.Fstring("name", name))
 		return nil, fmt.Errorf("name is required")
 	}
+	if strings.HasPrefix(name, directChannelNamePrefix) {
+		log.Warn("Name is reserved", log.Fstring("name", name))
+		return nil, fmt.Errorf("name must not start with %q", directChannelNamePrefix)
+	}
 	return &Channel{
 		ID:          id,
 		WorkspaceID: workspaceID,
@@ -35,3 +58,63 @@ func NewChannel(id, workspaceID, name, description string, private bool) (*Chann
 		Private:     private,
 	}, nil
 }
+
+// NewDirectChannel は、参加者で構成されるDMチャンネルを作成します。
+// IDは参加者の組み合わせから決まるため、同じ参加者で開いたDMは常に同じチャンネルになります。
+func NewDirectChannel(workspaceID string, membershipIDs []string) (*Channel, error) {
+	if workspaceID == "" {
+		log.Warn("WorkspaceID is required", log.Fstring("workspaceID", workspaceID))
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+
+	seen := make(map[string]bool, len(membershipIDs))
+	var participants []string
+	for _, membershipID := range membershipIDs {
+		if membershipID == "" || seen[membershipID] {
+			continue
+		}
+		seen[membershipID] = true
+		participants = append(participants, membershipID)
+	}
+	if len(participants) < minDirectMembers {
+		log.Warn("Direct channel needs at least two members", log.Fint("count", len(participants)))
+		return nil, fmt.Errorf("direct channel needs at least two members")
+	}
+	if len(participants) > maxDirectMembers {
+		log.Warn("Too many direct channel members", log.Fint("count", len(participants)))
+		return nil, fmt.Errorf("direct channel can have at most %d members", maxDirectMembers)
+	}
+	sort.Strings(participants)
+
+	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte(workspaceID+"/"+strings.Join(participants, ","))).String()
+	return &Channel{
+		ID:          id,
+		WorkspaceID: workspaceID,
+		Name:        directChannelNamePrefix + id,
+		Private:     true,
+		Direct:      true,
+	}, nil
+}
+
+// WSChannelEvent は、ワークスペースのチャンネルの一覧の変化をクライアントに伝えます。
+type WSChannelEvent struct {
+	Action   string  `json:"action_tag"`
+	Channel  Channel `json:"channel"`
+	SenderID string  `json:"sender_id"` // 変化を起こしたクライアントのID
+}
+
+func NewWSChannelCreated(channel Channel, senderID string) *WSChannelEvent {
+	return &WSChannelEvent{
+		Action:   ChannelCreatedAction,
+		Channel:  channel,
+		SenderID: senderID,
+	}
+}
+
+func (event *WSChannelEvent) Encode() []byte {
+	json, err := json.Marshal(event)
+	if err != nil {
+		log.Error("Failed to encode channel event", log.Ferror(err))
+	}
+	return json
+}
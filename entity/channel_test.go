@@ -87,6 +87,23 @@ func TestEntity_NewChannel(t *testing.T) {
 			},
 			wantErr: fmt.Errorf("name is required"),
 		},
+		{
+			name: "Fail: name is reserved for direct channels",
+			arg: struct {
+				id          string
+				workspaceID string
+				name        string
+				description string
+				private     bool
+			}{
+				id:          "1",
+				workspaceID: "1",
+				name:        "dm:test",
+				description: "test",
+				private:     true,
+			},
+			wantErr: fmt.Errorf(`name must not start with "dm:"`),
+		},
 	}
 
 	for _, tt := range patterns {
@@ -104,3 +121,114 @@ func TestEntity_NewChannel(t *testing.T) {
 		})
 	}
 }
+
+func TestEntity_NewDirectChannel(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name string
+		arg  struct {
+			workspaceID   string
+			membershipIDs []string
+		}
+		wantErr error
+	}{
+		{
+			name: "success",
+			arg: struct {
+				workspaceID   string
+				membershipIDs []string
+			}{
+				workspaceID:   "1",
+				membershipIDs: []string{"a_1", "b_1"},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: workspaceID is required",
+			arg: struct {
+				workspaceID   string
+				membershipIDs []string
+			}{
+				workspaceID:   "",
+				membershipIDs: []string{"a_1", "b_1"},
+			},
+			wantErr: fmt.Errorf("workspaceID is required"),
+		},
+		{
+			name: "Fail: only one distinct member",
+			arg: struct {
+				workspaceID   string
+				membershipIDs []string
+			}{
+				workspaceID:   "1",
+				membershipIDs: []string{"a_1", "a_1", ""},
+			},
+			wantErr: fmt.Errorf("direct channel needs at least two members"),
+		},
+		{
+			name: "Fail: too many members",
+			arg: struct {
+				workspaceID   string
+				membershipIDs []string
+			}{
+				workspaceID:   "1",
+				membershipIDs: []string{"a_1", "b_1", "c_1", "d_1", "e_1", "f_1", "g_1", "h_1", "i_1", "j_1"},
+			},
+			wantErr: fmt.Errorf("direct channel can have at most 9 members"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			channel, err := NewDirectChannel(tt.arg.workspaceID, tt.arg.membershipIDs)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("NewDirectChannel() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("NewDirectChannel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (!channel.Private || !channel.Direct || channel.Name != "dm:"+channel.ID) {
+				t.Errorf("NewDirectChannel() got = %+v", channel)
+			}
+		})
+	}
+}
+
+func TestEntity_NewDirectChannel_SameParticipants(t *testing.T) {
+	t.Parallel()
+
+	first, err := NewDirectChannel("1", []string{"a_1", "b_1", "c_1"})
+	if err != nil {
+		t.Fatalf("NewDirectChannel() error = %v", err)
+	}
+	second, err := NewDirectChannel("1", []string{"c_1", "a_1", "b_1", "a_1"})
+	if err != nil {
+		t.Fatalf("NewDirectChannel() error = %v", err)
+	}
+	if first.ID != second.ID {
+		t.Errorf("NewDirectChannel() IDs differ for the same participants: %s, %s", first.ID, second.ID)
+	}
+
+	other, err := NewDirectChannel("2", []string{"a_1", "b_1", "c_1"})
+	if err != nil {
+		t.Fatalf("NewDirectChannel() error = %v", err)
+	}
+	if first.ID == other.ID {
+		t.Errorf("NewDirectChannel() IDs collide across workspaces: %s", first.ID)
+	}
+}
+
+func TestEntity_WSChannelEvent_Encode(t *testing.T) {
+	t.Parallel()
+
+	event := NewWSChannelCreated(Channel{ID: "channel", WorkspaceID: "workspace", Name: "general"}, "client")
+	want := `{"action_tag":"CHANNEL_CREATED","channel":{"id":"channel","workspace_id":"workspace","name":"general",` +
+		`"description":"","private":false,"direct":false},"sender_id":"client"}`
+	if got := string(event.Encode()); got != want {
+		t.Errorf("Encode() = %s, want %s", got, want)
+	}
+}
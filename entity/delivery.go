@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+)
+
+// Delivery は、チャンネルの購読とは関係なく特定のメンバーシップのクライアントにだけ届けるメッセージです。
+// Payloadはクライアントにそのまま送信されます。
+// Channelが指定されている場合、届け先のクライアントをPayloadの送信前にそのチャンネルへ参加させます。
+// Leaveが真の場合は、参加させる代わりにChannelから退出させます。
+type Delivery struct {
+	MembershipIDs []string        `json:"membership_ids"`
+	Payload       json.RawMessage `json:"payload,omitempty"`
+	Channel       *Channel        `json:"channel,omitempty"`
+	Leave         bool            `json:"leave,omitempty"`
+}
+
+func NewDelivery(membershipIDs []string, payload []byte) (*Delivery, error) {
+	if len(membershipIDs) == 0 {
+		log.Warn("MembershipIDs are required")
+		return nil, fmt.Errorf("membershipIDs are required")
+	}
+	if len(payload) == 0 {
+		log.Warn("Payload is required")
+		return nil, fmt.Errorf("payload is required")
+	}
+	return &Delivery{
+		MembershipIDs: membershipIDs,
+		Payload:       payload,
+	}, nil
+}
+
+// NewMembershipDelivery は、Payloadを送らずにmembershipIDのクライアントのチャンネルへの参加と退出だけを伝えるDeliveryを返します。
+func NewMembershipDelivery(membershipID string, channel Channel, leave bool) *Delivery {
+	return &Delivery{
+		MembershipIDs: []string{membershipID},
+		Channel:       &channel,
+		Leave:         leave,
+	}
+}
+
+func (delivery *Delivery) Encode() []byte {
+	json, err := json.Marshal(delivery)
+	if err != nil {
+		log.Error("Failed to encode delivery", log.Ferror(err))
+	}
+	return json
+}
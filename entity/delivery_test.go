@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEntity_NewDelivery(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name string
+		arg  struct {
+			membershipIDs []string
+			payload       []byte
+		}
+		wantErr error
+	}{
+		{
+			name: "Success",
+			arg: struct {
+				membershipIDs []string
+				payload       []byte
+			}{
+				membershipIDs: []string{"1"},
+				payload:       []byte(`{"action_tag":"CREATE_THREAD_REPLY"}`),
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: membershipIDs are required",
+			arg: struct {
+				membershipIDs []string
+				payload       []byte
+			}{
+				membershipIDs: nil,
+				payload:       []byte(`{"action_tag":"CREATE_THREAD_REPLY"}`),
+			},
+			wantErr: fmt.Errorf("membershipIDs are required"),
+		},
+		{
+			name: "Fail: payload is required",
+			arg: struct {
+				membershipIDs []string
+				payload       []byte
+			}{
+				membershipIDs: []string{"1"},
+				payload:       nil,
+			},
+			wantErr: fmt.Errorf("payload is required"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewDelivery(tt.arg.membershipIDs, tt.arg.payload)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("NewDelivery() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("NewDelivery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"encoding/json"
+
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+)
+
+// サーバーからクライアントに送る、アクションの処理結果を表すフレームのアクションです。
+const (
+	AckAction   = "ACK"
+	ErrorAction = "ERROR"
+	// ResyncAction は、RESUMEで要求された連番以降のイベントが既に残っていないことを伝えます。
+	// クライアントはTargetIDのチャンネルのメッセージを取得し直し、Seq以降の配信から受け取ります。
+	// TargetIDが空の場合は送信バッファが溢れたことを表し、参加している全てのチャンネルをRESUMEで再開します。
+	ResyncAction = "RESYNC"
+)
+
+// ERRORフレームのエラーコードです。クライアントはこの値で再送するかどうかを判断します。
+const (
+	ErrorCodeInvalidMessage   = "INVALID_MESSAGE"
+	ErrorCodeUnknownAction    = "UNKNOWN_ACTION"
+	ErrorCodePermissionDenied = "PERMISSION_DENIED"
+	ErrorCodeNotFound         = "NOT_FOUND"
+	ErrorCodeConflict         = "CONFLICT"
+	ErrorCodeInternal         = "INTERNAL_ERROR"
+)
+
+// WSAck は、WSMessageのアクションが成功したことを送信元のクライアントに伝えます。
+// RequestIDにはクライアントがWSMessageに付けた値をそのまま返します。
+type WSAck struct {
+	Action    string `json:"action_tag"`
+	RequestID string `json:"request_id,omitempty"`
+	ReplyTo   string `json:"reply_to"` // 処理したWSMessageのアクション
+	// MessageID は、CREATE_MESSAGEで作成したメッセージのIDです。
+	// 再送した場合も最初に作成したメッセージのIDを返すため、クライアントは仮のメッセージと照合できます。
+	MessageID string `json:"message_id,omitempty"`
+}
+
+func NewWSAck(requestID, replyTo string) *WSAck {
+	return &WSAck{
+		Action:    AckAction,
+		RequestID: requestID,
+		ReplyTo:   replyTo,
+	}
+}
+
+func (ack *WSAck) Encode() []byte {
+	json, err := json.Marshal(ack)
+	if err != nil {
+		log.Error("Failed to encode ack", log.Ferror(err))
+	}
+	return json
+}
+
+// WSError は、WSMessageのアクションが失敗したことを送信元のクライアントに伝えます。
+type WSError struct {
+	Action    string `json:"action_tag"`
+	RequestID string `json:"request_id,omitempty"`
+	ReplyTo   string `json:"reply_to,omitempty"` // JSONとして読めなかった場合は空になります
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+}
+
+func NewWSError(requestID, replyTo, code, message string) *WSError {
+	return &WSError{
+		Action:    ErrorAction,
+		RequestID: requestID,
+		ReplyTo:   replyTo,
+		Code:      code,
+		Message:   message,
+	}
+}
+
+func (e *WSError) Encode() []byte {
+	json, err := json.Marshal(e)
+	if err != nil {
+		log.Error("Failed to encode error", log.Ferror(err))
+	}
+	return json
+}
@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"testing"
+)
+
+func TestEntity_WSAck_Encode(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name string
+		ack  *WSAck
+		want string
+	}{
+		{
+			name: "success",
+			ack:  NewWSAck("req-1", CreateMessageAction),
+			want: `{"action_tag":"ACK","request_id":"req-1","reply_to":"CREATE_MESSAGE"}`,
+		},
+		{
+			name: "success: without request ID",
+			ack:  NewWSAck("", CreateMessageAction),
+			want: `{"action_tag":"ACK","reply_to":"CREATE_MESSAGE"}`,
+		},
+		{
+			name: "success: with message ID",
+			ack:  &WSAck{Action: AckAction, RequestID: "req-1", ReplyTo: CreateMessageAction, MessageID: "msg-1"},
+			want: `{"action_tag":"ACK","request_id":"req-1","reply_to":"CREATE_MESSAGE","message_id":"msg-1"}`,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := string(tt.ack.Encode()); got != tt.want {
+				t.Errorf("Encode() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntity_WSError_Encode(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name string
+		err  *WSError
+		want string
+	}{
+		{
+			name: "success",
+			err:  NewWSError("req-1", DeleteMessageAction, ErrorCodePermissionDenied, "permission denied"),
+			want: `{"action_tag":"ERROR","request_id":"req-1","reply_to":"DELETE_MESSAGE","code":"PERMISSION_DENIED","message":"permission denied"}`,
+		},
+		{
+			name: "success: unreadable message",
+			err:  NewWSError("", "", ErrorCodeInvalidMessage, "invalid JSON message"),
+			want: `{"action_tag":"ERROR","code":"INVALID_MESSAGE","message":"invalid JSON message"}`,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := string(tt.err.Encode()); got != tt.want {
+				t.Errorf("Encode() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntity_IsValidAction(t *testing.T) {
+	t.Parallel()
+
+	if !IsValidAction(CreateMessageAction) {
+		t.Errorf("IsValidAction(%s) = false, want true", CreateMessageAction)
+	}
+	// サーバーが送るフレームのアクションはクライアントから送信できません。
+	for _, action := range []string{AckAction, ErrorAction, "UNKNOWN"} {
+		if IsValidAction(action) {
+			t.Errorf("IsValidAction(%s) = true, want false", action)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/tusmasoma/connectHub-backend/internal/log"
 )
@@ -9,6 +10,18 @@ import (
 type MembershipChannel struct {
 	MembershipID string `json:"membership_id" db:"membership_id"`
 	ChannelID    string `json:"channel_id" db:"channel_id"`
+	// LastReadMessageID は、最後に既読にしたメッセージのIDです。既読にしていない場合は空文字です。
+	LastReadMessageID string `json:"last_read_message_id" db:"last_read_message_id"`
+	// LastReadAt は、最後に既読にしたメッセージの作成日時です。ソートセットのスコアと揃えるため秒単位で保存します。
+	LastReadAt *time.Time `json:"last_read_at" db:"last_read_at"`
+}
+
+// ChannelUnread は、メンバーシップが参加しているチャンネルの未読のメッセージとメンションの数です。
+type ChannelUnread struct {
+	ChannelID         string `json:"channel_id"`
+	LastReadMessageID string `json:"last_read_message_id"`
+	UnreadCount       int64  `json:"unread_count"`
+	MentionCount      int64  `json:"mention_count"`
 }
 
 func NewMembershipChannel(membershipID, channelID string) (*MembershipChannel, error) {
@@ -25,3 +38,23 @@ func NewMembershipChannel(membershipID, channelID string) (*MembershipChannel, e
 		ChannelID:    channelID,
 	}, nil
 }
+
+// LastReadCursor は、最後に既読にしたメッセージの位置を返します。既読にしていない場合はnilです。
+func (mc *MembershipChannel) LastReadCursor() *MessageCursor {
+	if mc.LastReadAt == nil || mc.LastReadMessageID == "" {
+		return nil
+	}
+	return &MessageCursor{Score: mc.LastReadAt.Unix(), ID: mc.LastReadMessageID}
+}
+
+// MarkRead は、messageまでを既読にします。既読の位置は戻さないため、既に既読のメッセージの場合は何もせずにfalseを返します。
+func (mc *MembershipChannel) MarkRead(message Message) bool {
+	cursor := NewMessageCursor(message)
+	if last := mc.LastReadCursor(); last != nil && !last.Less(cursor) {
+		return false
+	}
+	readAt := time.Unix(cursor.Score, 0).UTC()
+	mc.LastReadMessageID = message.ID
+	mc.LastReadAt = &readAt
+	return true
+}
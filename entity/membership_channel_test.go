@@ -3,6 +3,7 @@ package entity
 import (
 	"fmt"
 	"testing"
+	"time"
 )
 
 func TestEntity_NewMembershipChannel(t *testing.T) {
@@ -66,3 +67,44 @@ func TestEntity_NewMembershipChannel(t *testing.T) {
 		})
 	}
 }
+
+func TestEntity_MembershipChannel_MarkRead(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 500, time.UTC)
+	read := Message{ID: "b", CreatedAt: createdAt}
+
+	patterns := []struct {
+		name    string
+		message Message
+		want    bool
+		wantID  string
+	}{
+		{name: "Success: newer message", message: Message{ID: "a", CreatedAt: createdAt.Add(time.Second)}, want: true, wantID: "a"},
+		{name: "Success: same second with greater ID", message: Message{ID: "c", CreatedAt: createdAt}, want: true, wantID: "c"},
+		{name: "Ignore: same message", message: read, want: false, wantID: "b"},
+		{name: "Ignore: same second with smaller ID", message: Message{ID: "a", CreatedAt: createdAt}, want: false, wantID: "b"},
+		{name: "Ignore: older message", message: Message{ID: "z", CreatedAt: createdAt.Add(-time.Second)}, want: false, wantID: "b"},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mc := MembershipChannel{MembershipID: "1", ChannelID: "1"}
+			if !mc.MarkRead(read) {
+				t.Fatalf("MarkRead() on unread channel = false, want true")
+			}
+			if got := mc.MarkRead(tt.message); got != tt.want {
+				t.Errorf("MarkRead() = %v, want %v", got, tt.want)
+			}
+			if mc.LastReadMessageID != tt.wantID {
+				t.Errorf("LastReadMessageID = %v, want %v", mc.LastReadMessageID, tt.wantID)
+			}
+			if cursor := mc.LastReadCursor(); cursor.Score != mc.LastReadAt.Unix() || cursor.ID != tt.wantID {
+				t.Errorf("LastReadCursor() = %+v, want score %d and ID %v", cursor, mc.LastReadAt.Unix(), tt.wantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	MentionKindMembership = "membership" // @名前 または @メンバーシップID
+	MentionKindChannel    = "channel"    // @channel: チャンネルの全メンバー
+	MentionKindHere       = "here"       // @here: チャンネルの接続中のメンバー
+)
+
+// Mentions は、メッセージ本文から検出したメンションです。
+type Mentions struct {
+	MembershipIDs []string
+	Channel       bool
+	Here          bool
+}
+
+// ParseMentions は、本文中の@channel、@here、およびmembershipsの名前かIDへのメンションを検出します。
+// 名前には空白を含められるため、同じ位置で複数の候補に一致する場合は最も長いものを採用します。
+// メールアドレスのように直前が単語の一部である@はメンションとして扱いません。
+func ParseMentions(text string, memberships []Membership) Mentions {
+	var mentions Mentions
+	seen := make(map[string]bool)
+
+	for i := 0; i < len(text); i++ {
+		if text[i] != '@' || (i > 0 && isMentionRune(lastRune(text[:i]))) {
+			continue
+		}
+		rest := text[i+1:]
+
+		if hasMention(rest, MentionKindChannel) {
+			mentions.Channel = true
+			continue
+		}
+		if hasMention(rest, MentionKindHere) {
+			mentions.Here = true
+			continue
+		}
+
+		var matchedID string
+		var matchedLen int
+		for _, membership := range memberships {
+			for _, candidate := range []string{membership.Name, membership.ID} {
+				if len(candidate) > matchedLen && hasMention(rest, candidate) {
+					matchedID = membership.ID
+					matchedLen = len(candidate)
+				}
+			}
+		}
+		if matchedID != "" && !seen[matchedID] {
+			seen[matchedID] = true
+			mentions.MembershipIDs = append(mentions.MembershipIDs, matchedID)
+		}
+	}
+	return mentions
+}
+
+// hasMention は、textがnameで始まり、その直後で単語が終わっているかを判定します。
+func hasMention(text, name string) bool {
+	if name == "" || !strings.HasPrefix(text, name) {
+		return false
+	}
+	next, _ := utf8.DecodeRuneInString(text[len(name):])
+	return next == utf8.RuneError || !isMentionRune(next)
+}
+
+func isMentionRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-'
+}
+
+func lastRune(text string) rune {
+	r, _ := utf8.DecodeLastRuneInString(text)
+	return r
+}
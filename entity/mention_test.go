@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEntity_ParseMentions(t *testing.T) {
+	t.Parallel()
+
+	memberships := []Membership{
+		{ID: "user1_workspace1", Name: "John"},
+		{ID: "user2_workspace1", Name: "John Doe"},
+		{ID: "user3_workspace1", Name: "Jane"},
+	}
+
+	patterns := []struct {
+		name string
+		text string
+		want Mentions
+	}{
+		{
+			name: "no mentions",
+			text: "hello",
+			want: Mentions{},
+		},
+		{
+			name: "membership by name",
+			text: "@Jane, could you check this?",
+			want: Mentions{MembershipIDs: []string{"user3_workspace1"}},
+		},
+		{
+			name: "longest name wins",
+			text: "thanks @John Doe and @John",
+			want: Mentions{MembershipIDs: []string{"user2_workspace1", "user1_workspace1"}},
+		},
+		{
+			name: "membership by ID",
+			text: "ping @user3_workspace1",
+			want: Mentions{MembershipIDs: []string{"user3_workspace1"}},
+		},
+		{
+			name: "duplicates are ignored",
+			text: "@Jane @Jane",
+			want: Mentions{MembershipIDs: []string{"user3_workspace1"}},
+		},
+		{
+			name: "channel and here",
+			text: "@channel meeting in 5 minutes, @here",
+			want: Mentions{Channel: true, Here: true},
+		},
+		{
+			name: "not a mention",
+			text: "mail jane@example.com or @Janet or @channels",
+			want: Mentions{},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := ParseMentions(tt.text, memberships)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMentions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -11,31 +11,70 @@ import (
 )
 
 const (
-	ListMessagesAction        = "LIST_MESSAGES"
-	CreateMessageAction       = "CREATE_MESSAGE"
-	DeleteMessageAction       = "DELETE_MESSAGE"
-	UpdateMessageAction       = "UPDATE_MESSAGE"
-	CreatePublicChannelAction = "CREATE_PUBLIC_CHANNEL"
-	JoinPublicChannelAction   = "JOIN_PUBLIC_CHANNEL"
-	LeavePublicChannelAction  = "LEAVE_PUBLIC_CHANNEL"
+	ListMessagesAction         = "LIST_MESSAGES"
+	CreateMessageAction        = "CREATE_MESSAGE"
+	DeleteMessageAction        = "DELETE_MESSAGE"
+	UpdateMessageAction        = "UPDATE_MESSAGE"
+	CreatePublicChannelAction  = "CREATE_PUBLIC_CHANNEL"
+	CreatePrivateChannelAction = "CREATE_PRIVATE_CHANNEL"
+	InviteToChannelAction      = "INVITE_TO_CHANNEL"
+	OpenDirectMessageAction    = "OPEN_DM"
+	JoinPublicChannelAction    = "JOIN_PUBLIC_CHANNEL"
+	LeavePublicChannelAction   = "LEAVE_PUBLIC_CHANNEL"
+	CreateThreadReplyAction    = "CREATE_THREAD_REPLY"
+	ListThreadAction           = "LIST_THREAD"
+	FollowThreadAction         = "FOLLOW_THREAD"
+	UnfollowThreadAction       = "UNFOLLOW_THREAD"
+	AddReactionAction          = "ADD_REACTION"
+	RemoveReactionAction       = "REMOVE_REACTION"
+	ResumeAction               = "RESUME"
+	SetPresenceAction          = "SET_PRESENCE"
+	TypingAction               = "TYPING"
+	MarkReadAction             = "MARK_READ"
 )
 
 var validActions = map[string]bool{
-	ListMessagesAction:        true,
-	CreateMessageAction:       true,
-	DeleteMessageAction:       true,
-	UpdateMessageAction:       true,
-	CreatePublicChannelAction: true,
-	JoinPublicChannelAction:   true,
-	LeavePublicChannelAction:  true,
+	ListMessagesAction:         true,
+	CreateMessageAction:        true,
+	DeleteMessageAction:        true,
+	UpdateMessageAction:        true,
+	CreatePublicChannelAction:  true,
+	CreatePrivateChannelAction: true,
+	InviteToChannelAction:      true,
+	OpenDirectMessageAction:    true,
+	JoinPublicChannelAction:    true,
+	LeavePublicChannelAction:   true,
+	CreateThreadReplyAction:    true,
+	ListThreadAction:           true,
+	FollowThreadAction:         true,
+	UnfollowThreadAction:       true,
+	AddReactionAction:          true,
+	RemoveReactionAction:       true,
+	ResumeAction:               true,
+	SetPresenceAction:          true,
+	TypingAction:               true,
+	MarkReadAction:             true,
+}
+
+// IsValidAction は、クライアントが送信できるアクションかどうかを返します。
+func IsValidAction(action string) bool {
+	return validActions[action]
 }
 
 type Message struct {
 	ID           string     `json:"id" db:"id"`
 	MembershipID string     `json:"membership_id" db:"membership_id"`
+	ChannelID    string     `json:"channel_id" db:"channel_id"`
+	ParentID     string     `json:"parent_id,omitempty" db:"parent_id"` // スレッドの返信の場合、返信先のメッセージID
 	Text         string     `json:"text" db:"text"`
 	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt    *time.Time `json:"updated_at" db:"updated_at"`
+	ReplyCount   int        `json:"reply_count" db:"reply_count"`
+	LastReplyAt  *time.Time `json:"last_reply_at" db:"last_reply_at"`
+	// Attachments は、メッセージの作成時に添付したファイルです。クライアントはアップロードで受け取ったIDだけを指定します。
+	Attachments Attachments `json:"attachments,omitempty" db:"attachments"`
+	// Reactions は、一覧の取得時にリアクションを集計して設定します。メッセージとは別に保存します。
+	Reactions []ReactionCount `json:"reactions,omitempty" db:"-"`
 }
 
 type WSMessage struct {
@@ -43,6 +82,17 @@ type WSMessage struct {
 	Content  Message `json:"content"`
 	TargetID string  `json:"target_id"` // TargetID is the ID of the channel or user the message is intended for
 	SenderID string  `json:"sender_id"` // SenderID is the ID of the user who sent the message
+	Page     *Page   `json:"page,omitempty"`
+	// RequestID は、クライアントが付けるリクエストの識別子です。ACKとERRORのフレームでそのまま返します。
+	RequestID string `json:"request_id,omitempty"`
+	// IdempotencyKey は、CREATE_MESSAGEの再送で同じメッセージを重複して作成しないよう、クライアントが付けるキーです。
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	// Seq は、チャンネルに配信したイベントのチャンネルごとの連番です。配信時にサーバーが付けます。
+	Seq int64 `json:"seq,omitempty"`
+	// Sequences は、RESUMEでクライアントが最後に受け取ったチャンネルごとの連番です。
+	Sequences map[string]int64 `json:"sequences,omitempty"`
+	// MembershipIDs は、INVITE_TO_CHANNELで招待する、またはOPEN_DMで参加させるメンバーシップのIDです。
+	MembershipIDs []string `json:"membership_ids,omitempty"`
 }
 
 func (message *WSMessage) Encode() []byte {
@@ -54,10 +104,12 @@ func (message *WSMessage) Encode() []byte {
 }
 
 type WSMessages struct {
-	Action   string    `json:"action_tag"`
-	Contents []Message `json:"contents"`
-	TargetID string    `json:"target_id"` // TargetID is the ID of the channel or user the message is intended for
-	SenderID string    `json:"sender_id"` // SenderID is the ID of the user who sent the message
+	Action     string    `json:"action_tag"`
+	Contents   []Message `json:"contents"`
+	TargetID   string    `json:"target_id"`             // TargetID is the ID of the channel or user the message is intended for
+	SenderID   string    `json:"sender_id"`             // SenderID is the ID of the user who sent the message
+	NextCursor string    `json:"next_cursor,omitempty"` // NextCursor fetches newer messages with direction "after"
+	PrevCursor string    `json:"prev_cursor,omitempty"` // PrevCursor fetches older messages with direction "before"
 }
 
 func (messages *WSMessages) Encode() []byte {
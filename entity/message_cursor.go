@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+)
+
+const (
+	PageDirectionBefore = "before" // カーソルより古いメッセージを取得します
+	PageDirectionAfter  = "after"  // カーソルより新しいメッセージを取得します
+)
+
+// Page は、LIST_MESSAGESで取得するメッセージの範囲を表します。
+type Page struct {
+	Limit     int64  `json:"limit,omitempty"`
+	Direction string `json:"direction,omitempty"` // "before" or "after". 省略時は"before"
+	Cursor    string `json:"cursor,omitempty"`    // 省略時はDirectionに応じて最新または最古から取得します
+}
+
+// MessageCursor は、チャンネル内でのメッセージの位置を表します。
+// ScoreはソートセットのスコアであるCreatedAtのUnix秒で、同じ秒のメッセージはIDの昇順に並びます。
+type MessageCursor struct {
+	Score int64
+	ID    string
+}
+
+func NewMessageCursor(message Message) MessageCursor {
+	return MessageCursor{
+		Score: message.CreatedAt.Unix(),
+		ID:    message.ID,
+	}
+}
+
+// Less は、cがoよりも前(古い)位置にある場合にtrueを返します。
+func (c MessageCursor) Less(o MessageCursor) bool {
+	if c.Score != o.Score {
+		return c.Score < o.Score
+	}
+	return c.ID < o.ID
+}
+
+// Encode は、クライアントに返す不透明なカーソル文字列を生成します。
+func (c MessageCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(c.Score, 10) + ":" + c.ID))
+}
+
+func DecodeMessageCursor(cursor string) (*MessageCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		log.Warn("Invalid cursor", log.Fstring("cursor", cursor))
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	score, id, found := strings.Cut(string(decoded), ":")
+	if !found || id == "" {
+		log.Warn("Invalid cursor", log.Fstring("cursor", cursor))
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	s, err := strconv.ParseInt(score, 10, 64)
+	if err != nil {
+		log.Warn("Invalid cursor", log.Fstring("cursor", cursor))
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &MessageCursor{Score: s, ID: id}, nil
+}
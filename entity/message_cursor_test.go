@@ -0,0 +1,72 @@
+package entity
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestEntity_MessageCursor(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cursor := NewMessageCursor(Message{ID: "31894386-3e60-45a8-bc67-f46b72b42554", CreatedAt: createdAt})
+
+	patterns := []struct {
+		name    string
+		arg     string
+		want    *MessageCursor
+		wantErr error
+	}{
+		{
+			name:    "Success",
+			arg:     cursor.Encode(),
+			want:    &MessageCursor{Score: createdAt.Unix(), ID: "31894386-3e60-45a8-bc67-f46b72b42554"},
+			wantErr: nil,
+		},
+		{
+			name:    "Fail: not base64",
+			arg:     "not a cursor!",
+			want:    nil,
+			wantErr: fmt.Errorf("invalid cursor"),
+		},
+		{
+			name:    "Fail: missing id",
+			arg:     MessageCursor{Score: createdAt.Unix()}.Encode(),
+			want:    nil,
+			wantErr: fmt.Errorf("invalid cursor"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := DecodeMessageCursor(tt.arg)
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("DecodeMessageCursor() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("DecodeMessageCursor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("DecodeMessageCursor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntity_MessageCursorLess(t *testing.T) {
+	t.Parallel()
+
+	a := MessageCursor{Score: 1, ID: "b"}
+	if !a.Less(MessageCursor{Score: 2, ID: "a"}) {
+		t.Errorf("Expected lower score to come first")
+	}
+	if !a.Less(MessageCursor{Score: 1, ID: "c"}) {
+		t.Errorf("Expected lower ID to come first when scores are equal")
+	}
+	if a.Less(a) {
+		t.Errorf("Expected cursor not to be less than itself")
+	}
+}
@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+)
+
+const NotificationAction = "NOTIFICATION"
+
+// Notification は、メンションされたメンバーシップ(MembershipID)への通知です。
+type Notification struct {
+	ID           string     `json:"id" db:"id"`
+	MembershipID string     `json:"membership_id" db:"membership_id"`
+	MessageID    string     `json:"message_id" db:"message_id"`
+	ChannelID    string     `json:"channel_id" db:"channel_id"`
+	SenderID     string     `json:"sender_id" db:"sender_id"` // メンションしたメンバーシップのID
+	Kind         string     `json:"kind" db:"kind"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	ReadAt       *time.Time `json:"read_at" db:"read_at"` // 未読の場合はnil
+}
+
+var validMentionKinds = map[string]bool{
+	MentionKindMembership: true,
+	MentionKindChannel:    true,
+	MentionKindHere:       true,
+}
+
+func NewNotification(membershipID string, message Message, kind string) (*Notification, error) {
+	if membershipID == "" || message.ID == "" {
+		log.Warn(
+			"MembershipID and MessageID are required",
+			log.Fstring("membershipID", membershipID),
+			log.Fstring("messageID", message.ID),
+		)
+		return nil, fmt.Errorf("membershipID and messageID are required")
+	}
+	if !validMentionKinds[kind] {
+		log.Warn("Invalid mention kind", log.Fstring("kind", kind))
+		return nil, fmt.Errorf("invalid mention kind: %s", kind)
+	}
+	return &Notification{
+		ID:           uuid.New().String(),
+		MembershipID: membershipID,
+		MessageID:    message.ID,
+		ChannelID:    message.ChannelID,
+		SenderID:     message.MembershipID,
+		Kind:         kind,
+		CreatedAt:    time.Now(),
+		ReadAt:       nil,
+	}, nil
+}
+
+// WSNotification は、通知をメンションされたメンバーシップのクライアントに届けるイベントです。
+type WSNotification struct {
+	Action  string       `json:"action_tag"`
+	Content Notification `json:"content"`
+	Message Message      `json:"message"`
+}
+
+func NewWSNotification(notification Notification, message Message) *WSNotification {
+	return &WSNotification{
+		Action:  NotificationAction,
+		Content: notification,
+		Message: message,
+	}
+}
+
+func (notification *WSNotification) Encode() []byte {
+	json, err := json.Marshal(notification)
+	if err != nil {
+		log.Error("Failed to encode notification", log.Ferror(err))
+	}
+	return json
+}
@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEntity_NewNotification(t *testing.T) {
+	t.Parallel()
+
+	message := Message{ID: "1", MembershipID: "2", ChannelID: "3", Text: "@channel"}
+
+	patterns := []struct {
+		name string
+		arg  struct {
+			membershipID string
+			kind         string
+		}
+		wantErr error
+	}{
+		{
+			name: "Success",
+			arg: struct {
+				membershipID string
+				kind         string
+			}{
+				membershipID: "4",
+				kind:         MentionKindChannel,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: membershipID is required",
+			arg: struct {
+				membershipID string
+				kind         string
+			}{
+				membershipID: "",
+				kind:         MentionKindChannel,
+			},
+			wantErr: fmt.Errorf("membershipID and messageID are required"),
+		},
+		{
+			name: "Fail: invalid kind",
+			arg: struct {
+				membershipID string
+				kind         string
+			}{
+				membershipID: "4",
+				kind:         "everyone",
+			},
+			wantErr: fmt.Errorf("invalid mention kind: everyone"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			notification, err := NewNotification(tt.arg.membershipID, message, tt.arg.kind)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("NewNotification() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("NewNotification() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (notification.SenderID != message.MembershipID || notification.ChannelID != message.ChannelID) {
+				t.Errorf("NewNotification() = %+v, want sender %s in channel %s", notification, message.MembershipID, message.ChannelID)
+			}
+		})
+	}
+}
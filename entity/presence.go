@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+)
+
+// メンバーシップのプレゼンスの状態です。
+// オンラインのメンバーはaway、dndを自分で設定でき、全ての接続が切れるとofflineになります。
+const (
+	PresenceOnline       = "online"
+	PresenceAway         = "away"
+	PresenceDoNotDisturb = "dnd"
+	PresenceOffline      = "offline"
+)
+
+// PresenceChangedAction は、メンバーシップのプレゼンスが変わったことをワークスペースの全てのクライアントに伝えるイベントです。
+const PresenceChangedAction = "PRESENCE_CHANGED"
+
+var settablePresenceStatuses = map[string]bool{
+	PresenceOnline:       true,
+	PresenceAway:         true,
+	PresenceDoNotDisturb: true,
+}
+
+// IsSettablePresenceStatus は、メンバーが自分で設定できるプレゼンスの状態かどうかを返します。
+func IsSettablePresenceStatus(status string) bool {
+	return settablePresenceStatuses[status]
+}
+
+type Presence struct {
+	MembershipID string `json:"membership_id"`
+	Status       string `json:"status"`
+	// LastSeenAt は、最後に接続が確認された時刻です。一度も接続していない場合はnilです。
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+}
+
+// WSPresenceEvent は、メンバーシップのプレゼンスの変化をクライアントに伝えます。
+type WSPresenceEvent struct {
+	Action   string   `json:"action_tag"`
+	Presence Presence `json:"presence"`
+}
+
+func NewWSPresenceChanged(presence Presence) *WSPresenceEvent {
+	return &WSPresenceEvent{
+		Action:   PresenceChangedAction,
+		Presence: presence,
+	}
+}
+
+func (event *WSPresenceEvent) Encode() []byte {
+	json, err := json.Marshal(event)
+	if err != nil {
+		log.Error("Failed to encode presence event", log.Ferror(err))
+	}
+	return json
+}
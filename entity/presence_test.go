@@ -0,0 +1,29 @@
+package entity
+
+import "testing"
+
+func TestEntity_IsSettablePresenceStatus(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		status string
+		want   bool
+	}{
+		{status: PresenceOnline, want: true},
+		{status: PresenceAway, want: true},
+		{status: PresenceDoNotDisturb, want: true},
+		{status: PresenceOffline, want: false},
+		{status: "", want: false},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.status, func(t *testing.T) {
+			t.Parallel()
+
+			if got := IsSettablePresenceStatus(tt.status); got != tt.want {
+				t.Errorf("IsSettablePresenceStatus(%q) = %v, want %v", tt.status, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"fmt"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+)
+
+// maxEmojiLength は、リアクションに使える絵文字(ショートコードを含む)の最大文字数です。
+const maxEmojiLength = 64
+
+// Reaction は、メンバーシップがメッセージに付けた絵文字を表します。
+// 同じメンバーシップが同じ絵文字を同じメッセージに付けられるのは1回だけです。
+type Reaction struct {
+	MessageID    string    `json:"message_id" db:"message_id"`
+	MembershipID string    `json:"membership_id" db:"membership_id"`
+	Emoji        string    `json:"emoji" db:"emoji"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// ReactionCount は、メッセージに付けられたリアクションを絵文字ごとに集計したものです。
+type ReactionCount struct {
+	Emoji         string   `json:"emoji"`
+	Count         int      `json:"count"`
+	MembershipIDs []string `json:"membership_ids"`
+}
+
+func NewReaction(messageID, membershipID, emoji string) (*Reaction, error) {
+	if messageID == "" || membershipID == "" || emoji == "" {
+		log.Warn(
+			"MessageID, MembershipID and Emoji are required",
+			log.Fstring("messageID", messageID),
+			log.Fstring("membershipID", membershipID),
+			log.Fstring("emoji", emoji),
+		)
+		return nil, fmt.Errorf("messageID, membershipID and emoji are required")
+	}
+	if utf8.RuneCountInString(emoji) > maxEmojiLength {
+		log.Warn("Emoji is too long", log.Fstring("emoji", emoji))
+		return nil, fmt.Errorf("emoji must be at most %d characters", maxEmojiLength)
+	}
+	return &Reaction{
+		MessageID:    messageID,
+		MembershipID: membershipID,
+		Emoji:        emoji,
+		CreatedAt:    time.Now(),
+	}, nil
+}
+
+// CountReactions は、リアクションを絵文字ごとに集計します。
+// 絵文字は最初に付けられた順に、メンバーシップはリアクションを付けた順に並びます。
+func CountReactions(reactions []Reaction) []ReactionCount {
+	if len(reactions) == 0 {
+		return nil
+	}
+
+	sorted := make([]Reaction, len(reactions))
+	copy(sorted, reactions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	var counts []ReactionCount
+	index := make(map[string]int)
+	for _, reaction := range sorted {
+		i, ok := index[reaction.Emoji]
+		if !ok {
+			i = len(counts)
+			index[reaction.Emoji] = i
+			counts = append(counts, ReactionCount{Emoji: reaction.Emoji})
+		}
+		counts[i].Count++
+		counts[i].MembershipIDs = append(counts[i].MembershipIDs, reaction.MembershipID)
+	}
+	return counts
+}
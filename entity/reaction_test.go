@@ -0,0 +1,100 @@
+package entity
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEntity_NewReaction(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name string
+		arg  struct {
+			messageID    string
+			membershipID string
+			emoji        string
+		}
+		wantErr error
+	}{
+		{
+			name: "Success",
+			arg: struct {
+				messageID    string
+				membershipID string
+				emoji        string
+			}{
+				messageID:    "1",
+				membershipID: "1",
+				emoji:        "👍",
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: emoji is required",
+			arg: struct {
+				messageID    string
+				membershipID string
+				emoji        string
+			}{
+				messageID:    "1",
+				membershipID: "1",
+				emoji:        "",
+			},
+			wantErr: fmt.Errorf("messageID, membershipID and emoji are required"),
+		},
+		{
+			name: "Fail: emoji is too long",
+			arg: struct {
+				messageID    string
+				membershipID string
+				emoji        string
+			}{
+				messageID:    "1",
+				membershipID: "1",
+				emoji:        strings.Repeat("a", maxEmojiLength+1),
+			},
+			wantErr: fmt.Errorf("emoji must be at most 64 characters"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewReaction(tt.arg.messageID, tt.arg.membershipID, tt.arg.emoji)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("NewReaction() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("NewReaction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEntity_CountReactions(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	reactions := []Reaction{
+		{MessageID: "1", MembershipID: "b", Emoji: "🎉", CreatedAt: now.Add(2 * time.Second)},
+		{MessageID: "1", MembershipID: "a", Emoji: "👍", CreatedAt: now},
+		{MessageID: "1", MembershipID: "b", Emoji: "👍", CreatedAt: now.Add(time.Second)},
+	}
+
+	want := []ReactionCount{
+		{Emoji: "👍", Count: 2, MembershipIDs: []string{"a", "b"}},
+		{Emoji: "🎉", Count: 1, MembershipIDs: []string{"b"}},
+	}
+	if got := CountReactions(reactions); !reflect.DeepEqual(got, want) {
+		t.Errorf("CountReactions() = %v, want %v", got, want)
+	}
+	if got := CountReactions(nil); got != nil {
+		t.Errorf("CountReactions() = %v, want nil", got)
+	}
+}
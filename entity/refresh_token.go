@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+)
+
+// RefreshToken は、アクセストークンを再発行するために使うリフレッシュトークンを表します。
+// トークンは使うたびに新しいものへ交換し、同じログインから発行されたトークンはそのセッションのIDをFamilyIDに持ちます。
+type RefreshToken struct {
+	ID        string    `json:"id"` // トークンのSHA-256のハッシュ値
+	UserID    string    `json:"user_id"`
+	FamilyID  string    `json:"family_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func NewRefreshToken(id, userID, familyID string, ttl time.Duration) (*RefreshToken, error) {
+	if id == "" || userID == "" || familyID == "" {
+		log.Warn(
+			"ID, UserID and FamilyID are required",
+			log.Fstring("userID", userID),
+			log.Fstring("familyID", familyID),
+		)
+		return nil, fmt.Errorf("id, userID and familyID are required")
+	}
+	if ttl <= 0 {
+		log.Warn("Refresh token TTL must be positive", log.Fstring("userID", userID))
+		return nil, fmt.Errorf("ttl must be positive")
+	}
+	return &RefreshToken{
+		ID:        id,
+		UserID:    userID,
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(ttl),
+	}, nil
+}
+
+// Expired は、nowの時点でリフレッシュトークンの有効期限が切れているかを返します。
+func (t RefreshToken) Expired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
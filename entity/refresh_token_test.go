@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEntity_NewRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name     string
+		id       string
+		userID   string
+		familyID string
+		ttl      time.Duration
+		wantErr  bool
+	}{
+		{name: "success", id: "hash", userID: "user", familyID: "family", ttl: time.Hour, wantErr: false},
+		{name: "Fail: missing family", id: "hash", userID: "user", familyID: "", ttl: time.Hour, wantErr: true},
+		{name: "Fail: non-positive ttl", id: "hash", userID: "user", familyID: "family", ttl: 0, wantErr: true},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewRefreshToken(tt.id, tt.userID, tt.familyID, tt.ttl)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRefreshToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Expired(time.Now()) {
+				t.Error("NewRefreshToken() returned an expired token")
+			}
+			if !got.Expired(got.ExpiresAt) {
+				t.Error("Expired() must be true at ExpiresAt")
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+)
+
+const (
+	// maxSessionDeviceLength と maxSessionUserAgentLength は、セッションに保存する端末情報の最大文字数です。
+	maxSessionDeviceLength    = 100
+	maxSessionUserAgentLength = 512
+)
+
+// Session は、ユーザーの端末ごとのログインを表します。
+// ログインするたびに作成し、リフレッシュトークンのファミリーとして同じIDを使います。
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	JTI        string    `json:"jti"` // 最後に発行したアクセストークンのjti
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+func NewSession(userID, device, userAgent, ip string) (*Session, error) {
+	if userID == "" {
+		log.Warn("UserID is required")
+		return nil, fmt.Errorf("userID is required")
+	}
+	now := time.Now()
+	return &Session{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		Device:     truncate(device, maxSessionDeviceLength),
+		UserAgent:  truncate(userAgent, maxSessionUserAgentLength),
+		IP:         ip,
+		CreatedAt:  now,
+		LastUsedAt: now,
+	}, nil
+}
+
+// Renew は、新しく発行したアクセストークンのjtiを記録し、セッションの有効期限をttl後に延ばします。
+func (s *Session) Renew(jti string, ttl time.Duration, now time.Time) {
+	s.JTI = jti
+	s.LastUsedAt = now
+	s.ExpiresAt = now.Add(ttl)
+}
+
+// Expired は、nowの時点でセッションの有効期限が切れているかを返します。
+func (s Session) Expired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// truncate は、sをlimit文字までに切り詰めます。
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit])
+}
@@ -0,0 +1,72 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEntity_NewSession(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name          string
+		userID        string
+		userAgent     string
+		wantUserAgent string
+		wantErr       bool
+	}{
+		{
+			name:          "success",
+			userID:        "user",
+			userAgent:     "Mozilla/5.0",
+			wantUserAgent: "Mozilla/5.0",
+			wantErr:       false,
+		},
+		{
+			name:          "success: long user agent is truncated",
+			userID:        "user",
+			userAgent:     strings.Repeat("あ", maxSessionUserAgentLength+1),
+			wantUserAgent: strings.Repeat("あ", maxSessionUserAgentLength),
+			wantErr:       false,
+		},
+		{
+			name:    "Fail: missing user",
+			userID:  "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewSession(tt.userID, "laptop", tt.userAgent, "192.0.2.1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.ID == "" || got.UserAgent != tt.wantUserAgent {
+				t.Errorf("NewSession() = %v", got)
+			}
+		})
+	}
+}
+
+func TestEntity_Session_Renew(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	session := Session{ID: "session", UserID: "user", JTI: "old"}
+	session.Renew("new", time.Hour, now)
+
+	if session.JTI != "new" || !session.LastUsedAt.Equal(now) {
+		t.Errorf("Renew() = %v", session)
+	}
+	if session.Expired(now.Add(59*time.Minute)) || !session.Expired(now.Add(time.Hour)) {
+		t.Errorf("Renew() set ExpiresAt = %v", session.ExpiresAt)
+	}
+}
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"fmt"
+
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+)
+
+// ThreadFollower は、スレッドの返信を通知するメンバーシップを表します。
+type ThreadFollower struct {
+	MessageID    string `json:"message_id" db:"message_id"`
+	MembershipID string `json:"membership_id" db:"membership_id"`
+}
+
+func NewThreadFollower(messageID, membershipID string) (*ThreadFollower, error) {
+	if messageID == "" {
+		log.Warn("MessageID is required", log.Fstring("messageID", messageID))
+		return nil, fmt.Errorf("messageID is required")
+	}
+	if membershipID == "" {
+		log.Warn("MembershipID is required", log.Fstring("membershipID", membershipID))
+		return nil, fmt.Errorf("membershipID is required")
+	}
+	return &ThreadFollower{
+		MessageID:    messageID,
+		MembershipID: membershipID,
+	}, nil
+}
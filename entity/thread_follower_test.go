@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEntity_NewThreadFollower(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name string
+		arg  struct {
+			messageID    string
+			membershipID string
+		}
+		wantErr error
+	}{
+		{
+			name: "Success",
+			arg: struct {
+				messageID    string
+				membershipID string
+			}{
+				messageID:    "1",
+				membershipID: "1",
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: messageID is required",
+			arg: struct {
+				messageID    string
+				membershipID string
+			}{
+				messageID:    "",
+				membershipID: "1",
+			},
+			wantErr: fmt.Errorf("messageID is required"),
+		},
+		{
+			name: "Fail: membershipID is required",
+			arg: struct {
+				messageID    string
+				membershipID string
+			}{
+				messageID:    "1",
+				membershipID: "",
+			},
+			wantErr: fmt.Errorf("membershipID is required"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewThreadFollower(tt.arg.messageID, tt.arg.membershipID)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("NewThreadFollower() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("NewThreadFollower() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
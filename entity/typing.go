@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+)
+
+// WSTypingEvent は、メンバーがチャンネルでメッセージを入力中であることをチャンネルのクライアントに伝えます。
+// 保存せずに配信するため、再接続したクライアントには再送しません。
+type WSTypingEvent struct {
+	Action       string `json:"action_tag"`
+	ChannelID    string `json:"channel_id"`
+	MembershipID string `json:"membership_id"`
+	SenderID     string `json:"sender_id"`
+	// ExpiresAt は、入力中の表示を消す時刻です。それまでに次のTYPINGが届かなければ入力をやめたものとして扱います。
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func NewWSTyping(channelID, membershipID, senderID string, expiresAt time.Time) *WSTypingEvent {
+	return &WSTypingEvent{
+		Action:       TypingAction,
+		ChannelID:    channelID,
+		MembershipID: membershipID,
+		SenderID:     senderID,
+		ExpiresAt:    expiresAt,
+	}
+}
+
+func (event *WSTypingEvent) Encode() []byte {
+	json, err := json.Marshal(event)
+	if err != nil {
+		log.Error("Failed to encode typing event", log.Ferror(err))
+	}
+	return json
+}
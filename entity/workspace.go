@@ -2,13 +2,19 @@ package entity
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/tusmasoma/connectHub-backend/internal/log"
 )
 
 type Workspace struct {
-	ID   string `json:"workspace_id" db:"id"`
-	Name string `json:"name" db:"name"`
+	ID          string `json:"workspace_id" db:"id"`
+	Name        string `json:"name" db:"name"`
+	Description string `json:"description" db:"description"`
+	// MaxAttachmentSize は、添付ファイル1件の最大バイト数です。0の場合はサーバーの設定値を使います。
+	MaxAttachmentSize int64 `json:"max_attachment_size" db:"max_attachment_size"`
+	// AllowedAttachmentTypes は、添付できるMIMEタイプのカンマ区切りの一覧です。空の場合はサーバーの設定値を使います。
+	AllowedAttachmentTypes string `json:"allowed_attachment_types" db:"allowed_attachment_types"`
 }
 
 func NewWorkspace(id, name string) (*Workspace, error) {
@@ -25,3 +31,15 @@ func NewWorkspace(id, name string) (*Workspace, error) {
 		Name: name,
 	}, nil
 }
+
+// AttachmentPolicy は、ワークスペースの設定がない項目をdefaultsで補った添付ファイルの制限を返します。
+func (w Workspace) AttachmentPolicy(defaults AttachmentPolicy) AttachmentPolicy {
+	policy := defaults
+	if w.MaxAttachmentSize > 0 {
+		policy.MaxSize = w.MaxAttachmentSize
+	}
+	if w.AllowedAttachmentTypes != "" {
+		policy.AllowedTypes = strings.Split(w.AllowedAttachmentTypes, ",")
+	}
+	return policy
+}
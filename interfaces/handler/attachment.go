@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+
+	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/usecase"
+)
+
+// maxUploadMemory は、アップロードされたファイルをメモリに保持する最大バイト数です。超えた分は一時ファイルに書き出します。
+const maxUploadMemory = 8 << 20
+
+type AttachmentHandler interface {
+	UploadAttachment(w http.ResponseWriter, r *http.Request)
+	DownloadAttachment(w http.ResponseWriter, r *http.Request)
+}
+
+type attachmentHandler struct {
+	atuc usecase.AttachmentUseCase
+	auc  usecase.AuthUseCase
+	conf *config.AttachmentConfig
+}
+
+func NewAttachmentHandler(atuc usecase.AttachmentUseCase, auc usecase.AuthUseCase, conf *config.AttachmentConfig) AttachmentHandler {
+	return &attachmentHandler{
+		atuc: atuc,
+		auc:  auc,
+		conf: conf,
+	}
+}
+
+type UploadAttachmentResponse struct {
+	Attachment entity.Attachment `json:"attachment"`
+}
+
+// UploadAttachment は、multipart/form-dataのfileのファイルをchannel_idのチャンネルにアップロードします。
+// 返したIDをCREATE_MESSAGEのContent.Attachmentsに指定すると、メッセージに添付できます。
+func (ah *attachmentHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspaceID := chi.URLParam(r, "workspace_id")
+	user, err := ah.auc.GetUserFromContext(ctx)
+	if err != nil {
+		log.Error("Failed to get UserInfo from context", log.Ferror(err))
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, ah.conf.MaxUploadSize)
+	if err = r.ParseMultipartForm(maxUploadMemory); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			log.Info("Upload request is too large", log.Fint64("limit", maxBytesErr.Limit))
+			http.Error(w, "Attachment too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		log.Info("Invalid attachment upload request", log.Fstring("method", r.Method), log.Fstring("url", r.URL.String()))
+		http.Error(w, "Invalid attachment upload request", http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll() //nolint:errcheck // 一時ファイルの削除に失敗しても応答には影響しません
+
+	channelID := r.FormValue("channel_id")
+	file, header, err := r.FormFile("file")
+	if err != nil || channelID == "" || header.Size <= 0 {
+		log.Info("Invalid attachment upload request", log.Fstring("method", r.Method), log.Fstring("url", r.URL.String()))
+		http.Error(w, "Invalid attachment upload request", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	membershipID := user.ID + "_" + workspaceID
+	attachment, err := ah.atuc.UploadAttachment(ctx, membershipID, workspaceID, usecase.AttachmentUpload{
+		ChannelID: channelID,
+		Name:      header.Filename,
+		Size:      header.Size,
+		Body:      file,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrPermissionDenied), errors.Is(err, usecase.ErrChannelAccessDenied):
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		case errors.Is(err, usecase.ErrAttachmentTooLarge):
+			http.Error(w, "Attachment too large", http.StatusRequestEntityTooLarge)
+		case errors.Is(err, usecase.ErrAttachmentTypeNotAllowed):
+			http.Error(w, "Attachment type not allowed", http.StatusUnsupportedMediaType)
+		default:
+			log.Error("Failed to upload attachment", log.Fstring("membershipID", membershipID), log.Ferror(err))
+			http.Error(w, "Failed to upload attachment", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(UploadAttachmentResponse{Attachment: *attachment}); err != nil {
+		log.Error("Failed to encode attachment to JSON", log.Ferror(err))
+		http.Error(w, "Failed to encode attachment to JSON", http.StatusInternalServerError)
+		return
+	}
+	log.Info("Successfully uploaded attachment", log.Fstring("attachmentID", attachment.ID))
+}
+
+// DownloadAttachment は、添付ファイルの内容を返します。
+// ブラウザが内容を表示して実行しないよう、常にダウンロードとして扱わせます。
+func (ah *attachmentHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspaceID := chi.URLParam(r, "workspace_id")
+	attachmentID := chi.URLParam(r, "attachment_id")
+	user, err := ah.auc.GetUserFromContext(ctx)
+	if err != nil {
+		log.Error("Failed to get UserInfo from context", log.Ferror(err))
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	membershipID := user.ID + "_" + workspaceID
+	attachment, body, err := ah.atuc.OpenAttachment(ctx, membershipID, workspaceID, attachmentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrPermissionDenied), errors.Is(err, usecase.ErrChannelAccessDenied):
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		case errors.Is(err, usecase.ErrAttachmentNotFound):
+			http.Error(w, "Attachment not found", http.StatusNotFound)
+		default:
+			log.Error("Failed to open attachment", log.Fstring("attachmentID", attachmentID), log.Ferror(err))
+			http.Error(w, "Failed to open attachment", http.StatusInternalServerError)
+		}
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", attachment.MimeType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("ETag", strconv.Quote(attachment.Checksum))
+	if _, err = io.Copy(w, body); err != nil {
+		log.Warn("Failed to write attachment", log.Fstring("attachmentID", attachmentID), log.Ferror(err))
+		return
+	}
+	log.Info("Successfully downloaded attachment", log.Fstring("attachmentID", attachmentID))
+}
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/usecase"
+	"github.com/tusmasoma/connectHub-backend/usecase/mock"
+)
+
+func newUploadRequest(t *testing.T, workspaceID, channelID, filename, content string) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("channel_id", channelID); err != nil {
+		t.Fatal(err)
+	}
+	if filename != "" {
+		part, err := writer.CreateFormFile("file", filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = io.WriteString(part, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	url := fmt.Sprintf("/api/attachment/upload/%s", workspaceID)
+	req, _ := http.NewRequest(http.MethodPost, url, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestAttachmentHandler_UploadAttachment(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+	membershipID := userID + "_" + workspaceID
+	channelID := uuid.New().String()
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockAttachmentUseCase,
+			m1 *mock.MockAuthUseCase,
+		)
+		in         func(t *testing.T) *http.Request
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockAttachmentUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+				m.EXPECT().UploadAttachment(gomock.Any(), membershipID, workspaceID, gomock.Any()).DoAndReturn(
+					func(_ any, _, _ string, upload usecase.AttachmentUpload) (*entity.Attachment, error) {
+						content, _ := io.ReadAll(upload.Body)
+						if upload.ChannelID != channelID || upload.Name != "notes.txt" || upload.Size != 5 || string(content) != "hello" {
+							t.Errorf("UploadAttachment() upload = %+v with %q", upload, content)
+						}
+						return &entity.Attachment{ID: uuid.New().String(), Name: upload.Name}, nil
+					},
+				)
+			},
+			in: func(t *testing.T) *http.Request {
+				return newUploadRequest(t, workspaceID, channelID, "notes.txt", "hello")
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: file is required",
+			setup: func(m *mock.MockAttachmentUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+			},
+			in: func(t *testing.T) *http.Request {
+				return newUploadRequest(t, workspaceID, channelID, "", "")
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: request larger than the upload limit",
+			setup: func(m *mock.MockAttachmentUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+			},
+			in: func(t *testing.T) *http.Request {
+				return newUploadRequest(t, workspaceID, channelID, "large.bin", strings.Repeat("a", 2048))
+			},
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "Fail: type not allowed",
+			setup: func(m *mock.MockAttachmentUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+				m.EXPECT().UploadAttachment(gomock.Any(), membershipID, workspaceID, gomock.Any()).Return(nil, usecase.ErrAttachmentTypeNotAllowed)
+			},
+			in: func(t *testing.T) *http.Request {
+				return newUploadRequest(t, workspaceID, channelID, "notes.txt", "hello")
+			},
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "Fail: channel not joined",
+			setup: func(m *mock.MockAttachmentUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+				m.EXPECT().UploadAttachment(gomock.Any(), membershipID, workspaceID, gomock.Any()).Return(nil, usecase.ErrChannelAccessDenied)
+			},
+			in: func(t *testing.T) *http.Request {
+				return newUploadRequest(t, workspaceID, channelID, "notes.txt", "hello")
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			atuc := mock.NewMockAttachmentUseCase(ctrl)
+			auc := mock.NewMockAuthUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(atuc, auc)
+			}
+
+			handler := NewAttachmentHandler(atuc, auc, &config.AttachmentConfig{MaxUploadSize: 1024})
+			recorder := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/api/attachment/upload/{workspace_id}", handler.UploadAttachment)
+			r.ServeHTTP(recorder, tt.in(t))
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAttachmentHandler_DownloadAttachment(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+	membershipID := userID + "_" + workspaceID
+	attachment := entity.Attachment{
+		ID:       uuid.New().String(),
+		Name:     "レポート.pdf",
+		Size:     3,
+		MimeType: "application/pdf",
+		Checksum: "checksum",
+	}
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockAttachmentUseCase,
+			m1 *mock.MockAuthUseCase,
+		)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockAttachmentUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+				m.EXPECT().OpenAttachment(gomock.Any(), membershipID, workspaceID, attachment.ID).Return(
+					&attachment, io.NopCloser(strings.NewReader("pdf")), nil,
+				)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "pdf",
+		},
+		{
+			name: "Fail: channel not joined",
+			setup: func(m *mock.MockAttachmentUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+				m.EXPECT().OpenAttachment(gomock.Any(), membershipID, workspaceID, attachment.ID).Return(nil, nil, usecase.ErrChannelAccessDenied)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "Fail: attachment not found",
+			setup: func(m *mock.MockAttachmentUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+				m.EXPECT().OpenAttachment(gomock.Any(), membershipID, workspaceID, attachment.ID).Return(nil, nil, usecase.ErrAttachmentNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			atuc := mock.NewMockAttachmentUseCase(ctrl)
+			auc := mock.NewMockAuthUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(atuc, auc)
+			}
+
+			handler := NewAttachmentHandler(atuc, auc, &config.AttachmentConfig{MaxUploadSize: 1024})
+			recorder := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Get("/api/attachment/download/{workspace_id}/{attachment_id}", handler.DownloadAttachment)
+			url := fmt.Sprintf("/api/attachment/download/%s/%s", workspaceID, attachment.ID)
+			req, _ := http.NewRequest(http.MethodGet, url, nil)
+			r.ServeHTTP(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantBody == "" {
+				return
+			}
+			if got := recorder.Body.String(); got != tt.wantBody {
+				t.Errorf("handler returned body %q, want %q", got, tt.wantBody)
+			}
+			if got := recorder.Header().Get("Content-Disposition"); !strings.HasPrefix(got, "attachment; filename*=utf-8''") {
+				t.Errorf("handler returned Content-Disposition %q, want attachment with encoded filename", got)
+			}
+		})
+	}
+}
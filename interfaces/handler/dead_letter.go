@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/usecase"
+)
+
+// DeadLetterHandler は、書き戻しを諦めたメッセージを運用者が扱うための管理用のハンドラーです。
+// 公開するルーターではなく、管理用のアドレスでのみ待ち受けます。
+type DeadLetterHandler interface {
+	RequeueDeadLetters(w http.ResponseWriter, r *http.Request)
+}
+
+type deadLetterHandler struct {
+	mf usecase.MessageFlusher
+}
+
+func NewDeadLetterHandler(mf usecase.MessageFlusher) DeadLetterHandler {
+	return &deadLetterHandler{
+		mf: mf,
+	}
+}
+
+type RequeueDeadLettersRequest struct {
+	IDs []string `json:"ids"` // 空の場合は全てのメッセージを戻します
+}
+
+type RequeueDeadLettersResponse struct {
+	Requeued int `json:"requeued"`
+}
+
+// RequeueDeadLetters は、デッドレターのメッセージを書き戻し待ちに戻します。
+// 原因となったチャンネルの復旧などを済ませてから呼び出します。
+func (dh *deadLetterHandler) RequeueDeadLetters(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var requestBody RequeueDeadLettersRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil && !errors.Is(err, io.EOF) {
+		log.Info("Invalid dead letter requeue request", log.Ferror(err))
+		http.Error(w, "Invalid dead letter requeue request", http.StatusBadRequest)
+		return
+	}
+
+	requeued, err := dh.mf.RequeueDeadLetters(ctx, requestBody.IDs)
+	if err != nil {
+		log.Error("Failed to requeue dead letters", log.Ferror(err))
+		http.Error(w, "Failed to requeue dead letters", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(RequeueDeadLettersResponse{Requeued: requeued}); err != nil {
+		log.Error("Failed to encode requeue result to JSON", log.Ferror(err))
+		http.Error(w, "Failed to encode requeue result to JSON", http.StatusInternalServerError)
+		return
+	}
+	log.Info("Successfully requeued dead letters", log.Fint("count", requeued))
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/usecase/mock"
+)
+
+func TestDeadLetterHandler_RequeueDeadLetters(t *testing.T) {
+	t.Parallel()
+
+	messageID := uuid.New().String()
+	patterns := []struct {
+		name         string
+		setup        func(m *mock.MockMessageFlusher)
+		in           func() *http.Request
+		wantStatus   int
+		wantRequeued int
+	}{
+		{
+			name: "success: selected messages",
+			setup: func(m *mock.MockMessageFlusher) {
+				m.EXPECT().RequeueDeadLetters(gomock.Any(), []string{messageID}).Return(1, nil)
+			},
+			in: func() *http.Request {
+				body := fmt.Sprintf(`{"ids":["%s"]}`, messageID)
+				req, _ := http.NewRequest(http.MethodPost, "/admin/messages/dead_letters/requeue", strings.NewReader(body))
+				return req
+			},
+			wantStatus:   http.StatusOK,
+			wantRequeued: 1,
+		},
+		{
+			name: "success: all messages without body",
+			setup: func(m *mock.MockMessageFlusher) {
+				m.EXPECT().RequeueDeadLetters(gomock.Any(), nil).Return(3, nil)
+			},
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodPost, "/admin/messages/dead_letters/requeue", http.NoBody)
+				return req
+			},
+			wantStatus:   http.StatusOK,
+			wantRequeued: 3,
+		},
+		{
+			name: "Fail: invalid body",
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodPost, "/admin/messages/dead_letters/requeue", strings.NewReader(`{"ids":`))
+				return req
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: cache unavailable",
+			setup: func(m *mock.MockMessageFlusher) {
+				m.EXPECT().RequeueDeadLetters(gomock.Any(), nil).Return(0, fmt.Errorf("connection refused"))
+			},
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodPost, "/admin/messages/dead_letters/requeue", strings.NewReader(`{}`))
+				return req
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			mf := mock.NewMockMessageFlusher(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(mf)
+			}
+
+			handler := NewDeadLetterHandler(mf)
+			recorder := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/admin/messages/dead_letters/requeue", handler.RequeueDeadLetters)
+			r.ServeHTTP(recorder, tt.in())
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got RequeueDeadLettersResponse
+			if err := json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if got.Requeued != tt.wantRequeued {
+				t.Errorf("RequeueDeadLetters() requeued = %d, want %d", got.Requeued, tt.wantRequeued)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tusmasoma/connectHub-backend/internal/auth"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+)
+
+// jwksMaxAge は、公開鍵の一覧をキャッシュしてよい秒数です。
+// ローテーションした鍵が他のサービスに早く届くよう、短くします。
+const jwksMaxAge = "300"
+
+type JWKSHandler interface {
+	GetJWKS(w http.ResponseWriter, r *http.Request)
+}
+
+type jwksHandler struct {
+	km *auth.KeyManager
+}
+
+func NewJWKSHandler(km *auth.KeyManager) JWKSHandler {
+	return &jwksHandler{
+		km: km,
+	}
+}
+
+// GetJWKS は、アクセストークンの検証に使う公開鍵をJWK Setの形式で返します。
+func (jh *jwksHandler) GetJWKS(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age="+jwksMaxAge)
+	if err := json.NewEncoder(w).Encode(jh.km.JWKS()); err != nil {
+		log.Error("Failed to encode JWKS to JSON", log.Ferror(err))
+		http.Error(w, "Failed to encode JWKS to JSON", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tusmasoma/connectHub-backend/internal/auth"
+)
+
+func TestJWKSHandler_GetJWKS(t *testing.T) {
+	t.Setenv("PRIVATE_KEY_PATH", "../../.certificate/private_key.pem")
+	t.Setenv("PUBLIC_KEY_PATH", "../../.certificate/public_key.pem")
+
+	km, err := auth.NewKeyManager("")
+	if err != nil {
+		t.Fatalf("Failed to NewKeyManager: %v", err)
+	}
+
+	handler := NewJWKSHandler(km)
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	handler.GetJWKS(recorder, req)
+
+	if status := recorder.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	var got auth.JWKS
+	if err = json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	// 秘密鍵と公開鍵は同じ鍵の組なので、公開される鍵は1つだけ
+	if len(got.Keys) != 1 || got.Keys[0].Kty != "RSA" || got.Keys[0].Alg != "RS256" || got.Keys[0].Kid == "" {
+		t.Errorf("GetJWKS() = %v", got)
+	}
+}
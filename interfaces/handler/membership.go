@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -19,19 +20,27 @@ type MembershipHandler interface {
 	ListMemberships(w http.ResponseWriter, r *http.Request)
 	ListChannelMemberships(w http.ResponseWriter, r *http.Request)
 	UpdateMembership(w http.ResponseWriter, r *http.Request)
+	MarkChannelRead(w http.ResponseWriter, r *http.Request)
 }
 
 type membershipHandler struct {
-	muc usecase.MembershipUseCase
-	cuc usecase.ChannelUseCase
-	auc usecase.AuthUseCase
+	muc  usecase.MembershipUseCase
+	cuc  usecase.ChannelUseCase
+	mcuc usecase.MembershipChannelUseCase
+	auc  usecase.AuthUseCase
 }
 
-func NewMembershipHandler(muc usecase.MembershipUseCase, cuc usecase.ChannelUseCase, auc usecase.AuthUseCase) MembershipHandler {
+func NewMembershipHandler(
+	muc usecase.MembershipUseCase,
+	cuc usecase.ChannelUseCase,
+	mcuc usecase.MembershipChannelUseCase,
+	auc usecase.AuthUseCase,
+) MembershipHandler {
 	return &membershipHandler{
-		muc: muc,
-		cuc: cuc,
-		auc: auc,
+		muc:  muc,
+		cuc:  cuc,
+		mcuc: mcuc,
+		auc:  auc,
 	}
 }
 
@@ -40,6 +49,8 @@ type GetMembershipResponse struct {
 	Email           string           `json:"email"`
 	ProfileImageURL string           `json:"profile_image_url"`
 	Channels        []entity.Channel `json:"channels"`
+	// Unreads は、Channelsのチャンネルごとの未読のメッセージとメンションの数です。
+	Unreads []entity.ChannelUnread `json:"unreads"`
 }
 
 func (mh *membershipHandler) GetMembership(w http.ResponseWriter, r *http.Request) {
@@ -65,12 +76,19 @@ func (mh *membershipHandler) GetMembership(w http.ResponseWriter, r *http.Reques
 		http.Error(w, "Failed to list membership channels", http.StatusInternalServerError)
 		return
 	}
+	unreads, err := mh.mcuc.ListUnreads(ctx, membershipID)
+	if err != nil {
+		log.Error("Failed to list unreads", log.Fstring("membershipID", membershipID))
+		http.Error(w, "Failed to list unreads", http.StatusInternalServerError)
+		return
+	}
 
 	response := GetMembershipResponse{
 		Name:            membership.Name,
 		Email:           user.Email,
 		ProfileImageURL: membership.ProfileImageURL,
 		Channels:        channels,
+		Unreads:         unreads,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -116,6 +134,27 @@ func (mh *membershipHandler) ListChannelMemberships(w http.ResponseWriter, r *ht
 	ctx := r.Context()
 
 	channelID := chi.URLParam(r, "channel_id")
+	user, err := mh.auc.GetUserFromContext(ctx)
+	if err != nil {
+		log.Error("Failed to get UserInfo from context", log.Ferror(err))
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// プライベートチャンネルとDMのメンバーは、チャンネルのメンバーにだけ公開します。
+	if _, err = mh.cuc.AuthorizeChannel(ctx, user.ID, channelID); err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrChannelNotFound):
+			http.Error(w, "Channel not found", http.StatusNotFound)
+		case errors.Is(err, usecase.ErrChannelAccessDenied):
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		default:
+			log.Error("Failed to authorize channel", log.Fstring("channelID", channelID), log.Ferror(err))
+			http.Error(w, "Failed to authorize channel", http.StatusInternalServerError)
+		}
+		return
+	}
+
 	memberships, err := mh.muc.ListChannelMemberships(ctx, channelID)
 	if err != nil {
 		log.Error("Failed to list channel memberships", log.Fstring("channelID", channelID), log.Ferror(err))
@@ -264,3 +303,57 @@ func convertUpdateMembershipReqeuestToParams(req UpdateMembershipRequest) *useca
 		ProfileImageURL: req.ProfileImageURL,
 	}
 }
+
+type MarkChannelReadRequest struct {
+	ChannelID string `json:"channel_id"`
+	MessageID string `json:"message_id"`
+}
+
+// MarkChannelRead は、チャンネルのMessageIDのメッセージまでを既読にします。
+func (mh *membershipHandler) MarkChannelRead(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspaceID := chi.URLParam(r, "workspace_id")
+	user, err := mh.auc.GetUserFromContext(ctx)
+	if err != nil {
+		log.Error("Failed to get UserInfo from context", log.Ferror(err))
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	var requestBody MarkChannelReadRequest
+	if ok := isValidMarkChannelReadRequest(r.Body, &requestBody); !ok {
+		log.Info("Invalid channel read request", log.Fstring("method", r.Method), log.Fstring("url", r.URL.String()))
+		http.Error(w, "Invalid channel read request", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	membershipID := user.ID + "_" + workspaceID
+	if err = mh.mcuc.MarkRead(ctx, membershipID, requestBody.ChannelID, requestBody.MessageID); err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrChannelAccessDenied):
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		case errors.Is(err, usecase.ErrMessageNotFound):
+			http.Error(w, "Message not found", http.StatusNotFound)
+		default:
+			log.Error("Failed to mark channel as read", log.Fstring("membershipID", membershipID), log.Ferror(err))
+			http.Error(w, "Failed to mark channel as read", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	log.Info("Successfully marked channel as read", log.Fstring("membershipID", membershipID), log.Fstring("channelID", requestBody.ChannelID))
+	w.WriteHeader(http.StatusOK)
+}
+
+func isValidMarkChannelReadRequest(body io.ReadCloser, requestBody *MarkChannelReadRequest) bool {
+	if err := json.NewDecoder(body).Decode(requestBody); err != nil {
+		log.Error("Invalid request body", log.Ferror(err))
+		return false
+	}
+	if requestBody.ChannelID == "" || requestBody.MessageID == "" {
+		log.Info("Missing required fields", log.Fstring("channelID", requestBody.ChannelID), log.Fstring("messageID", requestBody.MessageID))
+		return false
+	}
+	return true
+}
@@ -29,13 +29,19 @@ func TestMembershipHandler_GetMembership(t *testing.T) {
 			m *mock.MockMembershipUseCase,
 			m1 *mock.MockChannelUseCase,
 			m2 *mock.MockAuthUseCase,
+			m3 *mock.MockMembershipChannelUseCase,
 		)
 		in         func() *http.Request
 		wantStatus int
 	}{
 		{
 			name: "success",
-			setup: func(m *mock.MockMembershipUseCase, m1 *mock.MockChannelUseCase, m2 *mock.MockAuthUseCase) {
+			setup: func(
+				m *mock.MockMembershipUseCase,
+				m1 *mock.MockChannelUseCase,
+				m2 *mock.MockAuthUseCase,
+				m3 *mock.MockMembershipChannelUseCase,
+			) {
 				m2.EXPECT().GetUserFromContext(gomock.Any()).Return(
 					&entity.User{
 						ID:       userID,
@@ -61,6 +67,14 @@ func TestMembershipHandler_GetMembership(t *testing.T) {
 							Private:     false,
 						},
 					}, nil)
+				m3.EXPECT().ListUnreads(gomock.Any(), membershipID).Return(
+					[]entity.ChannelUnread{
+						{
+							ChannelID:    "channelID",
+							UnreadCount:  3,
+							MentionCount: 1,
+						},
+					}, nil)
 			},
 			in: func() *http.Request {
 				url := fmt.Sprintf("/api/membership/get/%s", workspaceID)
@@ -78,12 +92,13 @@ func TestMembershipHandler_GetMembership(t *testing.T) {
 			muc := mock.NewMockMembershipUseCase(ctrl)
 			ruc := mock.NewMockChannelUseCase(ctrl)
 			auc := mock.NewMockAuthUseCase(ctrl)
+			mcuc := mock.NewMockMembershipChannelUseCase(ctrl)
 
 			if tt.setup != nil {
-				tt.setup(muc, ruc, auc)
+				tt.setup(muc, ruc, auc, mcuc)
 			}
 
-			handler := NewMembershipHandler(muc, ruc, auc)
+			handler := NewMembershipHandler(muc, ruc, mcuc, auc)
 			recorder := httptest.NewRecorder()
 
 			r := chi.NewRouter()
@@ -149,7 +164,7 @@ func TestMembershipHandler_ListMemberships(t *testing.T) {
 				tt.setup(muc)
 			}
 
-			handler := NewMembershipHandler(muc, ruc, auc)
+			handler := NewMembershipHandler(muc, ruc, nil, auc)
 			recorder := httptest.NewRecorder()
 
 			r := chi.NewRouter()
@@ -168,18 +183,29 @@ func TestMembershipHandler_ListChannelMemberships(t *testing.T) {
 
 	workspaceID := uuid.New().String()
 	channelID := uuid.New().String()
+	user := &entity.User{
+		ID:       uuid.New().String(),
+		Email:    "test@gmail.com",
+		Password: "password123",
+	}
 	patterns := []struct {
 		name  string
 		setup func(
 			m *mock.MockMembershipUseCase,
 			m1 *mock.MockAuthUseCase,
+			m2 *mock.MockChannelUseCase,
 		)
 		in         func() *http.Request
 		wantStatus int
 	}{
 		{
 			name: "success",
-			setup: func(m *mock.MockMembershipUseCase, m1 *mock.MockAuthUseCase) {
+			setup: func(m *mock.MockMembershipUseCase, m1 *mock.MockAuthUseCase, m2 *mock.MockChannelUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(user, nil)
+				m2.EXPECT().AuthorizeChannel(gomock.Any(), user.ID, channelID).Return(
+					&entity.Channel{ID: channelID, WorkspaceID: workspaceID, Name: "test"},
+					nil,
+				)
 				m.EXPECT().ListChannelMemberships(
 					gomock.Any(),
 					channelID,
@@ -204,6 +230,32 @@ func TestMembershipHandler_ListChannelMemberships(t *testing.T) {
 			},
 			wantStatus: http.StatusOK,
 		},
+		{
+			name: "Fail: not a member of the private channel",
+			setup: func(m *mock.MockMembershipUseCase, m1 *mock.MockAuthUseCase, m2 *mock.MockChannelUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(user, nil)
+				m2.EXPECT().AuthorizeChannel(gomock.Any(), user.ID, channelID).Return(nil, usecase.ErrChannelAccessDenied)
+			},
+			in: func() *http.Request {
+				url := fmt.Sprintf("/api/membership/list-channel/%s", channelID)
+				req, _ := http.NewRequest(http.MethodGet, url, nil)
+				return req
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "Fail: channel not found",
+			setup: func(m *mock.MockMembershipUseCase, m1 *mock.MockAuthUseCase, m2 *mock.MockChannelUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(user, nil)
+				m2.EXPECT().AuthorizeChannel(gomock.Any(), user.ID, channelID).Return(nil, usecase.ErrChannelNotFound)
+			},
+			in: func() *http.Request {
+				url := fmt.Sprintf("/api/membership/list-channel/%s", channelID)
+				req, _ := http.NewRequest(http.MethodGet, url, nil)
+				return req
+			},
+			wantStatus: http.StatusNotFound,
+		},
 	}
 
 	for _, tt := range patterns {
@@ -216,10 +268,10 @@ func TestMembershipHandler_ListChannelMemberships(t *testing.T) {
 			auc := mock.NewMockAuthUseCase(ctrl)
 
 			if tt.setup != nil {
-				tt.setup(muc, auc)
+				tt.setup(muc, auc, ruc)
 			}
 
-			handler := NewMembershipHandler(muc, ruc, auc)
+			handler := NewMembershipHandler(muc, ruc, nil, auc)
 			recorder := httptest.NewRecorder()
 
 			r := chi.NewRouter()
@@ -319,7 +371,7 @@ func TestMembershipHandler_CreateMembership(t *testing.T) {
 				tt.setup(muc, auc)
 			}
 
-			handler := NewMembershipHandler(muc, ruc, auc)
+			handler := NewMembershipHandler(muc, ruc, nil, auc)
 			recorder := httptest.NewRecorder()
 
 			r := chi.NewRouter()
@@ -430,7 +482,7 @@ func TestMembershipHandler_UpdateMembership(t *testing.T) {
 				tt.setup(muc, auc)
 			}
 
-			handler := NewMembershipHandler(muc, ruc, auc)
+			handler := NewMembershipHandler(muc, ruc, nil, auc)
 			recorder := httptest.NewRecorder()
 
 			r := chi.NewRouter()
@@ -443,3 +495,102 @@ func TestMembershipHandler_UpdateMembership(t *testing.T) {
 		})
 	}
 }
+
+func TestMembershipHandler_MarkChannelRead(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+	membershipID := userID + "_" + workspaceID
+	channelID := uuid.New().String()
+	messageID := uuid.New().String()
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockMembershipChannelUseCase,
+			m1 *mock.MockAuthUseCase,
+		)
+		in         func() *http.Request
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockMembershipChannelUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+				m.EXPECT().MarkRead(gomock.Any(), membershipID, channelID, messageID).Return(nil)
+			},
+			in: func() *http.Request {
+				body, _ := json.Marshal(MarkChannelReadRequest{ChannelID: channelID, MessageID: messageID})
+				url := fmt.Sprintf("/api/membership/read/%s", workspaceID)
+				req, _ := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(body))
+				return req
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: message ID is required",
+			setup: func(m *mock.MockMembershipChannelUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+			},
+			in: func() *http.Request {
+				body, _ := json.Marshal(MarkChannelReadRequest{ChannelID: channelID})
+				url := fmt.Sprintf("/api/membership/read/%s", workspaceID)
+				req, _ := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(body))
+				return req
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: not a member of the channel",
+			setup: func(m *mock.MockMembershipChannelUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+				m.EXPECT().MarkRead(gomock.Any(), membershipID, channelID, messageID).Return(usecase.ErrChannelAccessDenied)
+			},
+			in: func() *http.Request {
+				body, _ := json.Marshal(MarkChannelReadRequest{ChannelID: channelID, MessageID: messageID})
+				url := fmt.Sprintf("/api/membership/read/%s", workspaceID)
+				req, _ := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(body))
+				return req
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "Fail: message not found",
+			setup: func(m *mock.MockMembershipChannelUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+				m.EXPECT().MarkRead(gomock.Any(), membershipID, channelID, messageID).Return(usecase.ErrMessageNotFound)
+			},
+			in: func() *http.Request {
+				body, _ := json.Marshal(MarkChannelReadRequest{ChannelID: channelID, MessageID: messageID})
+				url := fmt.Sprintf("/api/membership/read/%s", workspaceID)
+				req, _ := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(body))
+				return req
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			mcuc := mock.NewMockMembershipChannelUseCase(ctrl)
+			auc := mock.NewMockAuthUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(mcuc, auc)
+			}
+
+			handler := NewMembershipHandler(nil, nil, mcuc, auc)
+			recorder := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Put("/api/membership/read/{workspace_id}", handler.MarkChannelRead)
+			r.ServeHTTP(recorder, tt.in())
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
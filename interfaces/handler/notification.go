@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/usecase"
+)
+
+type NotificationHandler interface {
+	ListNotifications(w http.ResponseWriter, r *http.Request)
+	MarkNotificationsRead(w http.ResponseWriter, r *http.Request)
+}
+
+type notificationHandler struct {
+	nuc usecase.NotificationUseCase
+	auc usecase.AuthUseCase
+}
+
+func NewNotificationHandler(nuc usecase.NotificationUseCase, auc usecase.AuthUseCase) NotificationHandler {
+	return &notificationHandler{
+		nuc: nuc,
+		auc: auc,
+	}
+}
+
+type ListNotificationsResponse struct {
+	Notifications []entity.Notification `json:"notifications"`
+}
+
+// ListNotifications は、ワークスペースでの自分宛ての通知を新しい順に返します。
+// クエリパラメータunread=trueを指定すると未読の通知のみ返します。
+func (nh *notificationHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspaceID := chi.URLParam(r, "workspace_id")
+	user, err := nh.auc.GetUserFromContext(ctx)
+	if err != nil {
+		log.Error("Failed to get UserInfo from context", log.Ferror(err))
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	membershipID := user.ID + "_" + workspaceID
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+	notifications, err := nh.nuc.ListNotifications(ctx, membershipID, unreadOnly)
+	if err != nil {
+		log.Error("Failed to list notifications", log.Fstring("membershipID", membershipID), log.Ferror(err))
+		http.Error(w, "Failed to list notifications", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(ListNotificationsResponse{Notifications: notifications}); err != nil {
+		log.Error("Failed to encode notifications to JSON", log.Ferror(err))
+		http.Error(w, "Failed to encode notifications to JSON", http.StatusInternalServerError)
+		return
+	}
+	log.Info("Successfully retrieved notifications", log.Fstring("membershipID", membershipID))
+}
+
+type MarkNotificationsReadRequest struct {
+	IDs []string `json:"ids"` // 空の場合は全ての通知を既読にします
+}
+
+func (nh *notificationHandler) MarkNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspaceID := chi.URLParam(r, "workspace_id")
+	user, err := nh.auc.GetUserFromContext(ctx)
+	if err != nil {
+		log.Error("Failed to get UserInfo from context", log.Ferror(err))
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	var requestBody MarkNotificationsReadRequest
+	if ok := isValidMarkNotificationsReadRequest(r.Body, &requestBody); !ok {
+		log.Info("Invalid notification read request", log.Fstring("method", r.Method), log.Fstring("url", r.URL.String()))
+		http.Error(w, "Invalid notification read request", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	membershipID := user.ID + "_" + workspaceID
+	if err = nh.nuc.MarkNotificationsRead(ctx, membershipID, requestBody.IDs); err != nil {
+		log.Error("Failed to mark notifications as read", log.Fstring("membershipID", membershipID), log.Ferror(err))
+		http.Error(w, "Failed to mark notifications as read", http.StatusInternalServerError)
+		return
+	}
+
+	log.Info("Successfully marked notifications as read", log.Fstring("membershipID", membershipID))
+	w.WriteHeader(http.StatusOK)
+}
+
+func isValidMarkNotificationsReadRequest(body io.ReadCloser, requestBody *MarkNotificationsReadRequest) bool {
+	if err := json.NewDecoder(body).Decode(requestBody); err != nil {
+		log.Error("Invalid request body", log.Ferror(err))
+		return false
+	}
+	for _, id := range requestBody.IDs {
+		if id == "" {
+			log.Info("Notification ID must not be empty")
+			return false
+		}
+	}
+	return true
+}
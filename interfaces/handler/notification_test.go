@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/usecase/mock"
+)
+
+func TestNotificationHandler_ListNotifications(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+	membershipID := userID + "_" + workspaceID
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockNotificationUseCase,
+			m1 *mock.MockAuthUseCase,
+		)
+		in         func() *http.Request
+		wantStatus int
+	}{
+		{
+			name: "success: unread only",
+			setup: func(m *mock.MockNotificationUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+				m.EXPECT().ListNotifications(gomock.Any(), membershipID, true).Return(
+					[]entity.Notification{
+						{
+							ID:           uuid.New().String(),
+							MembershipID: membershipID,
+							MessageID:    uuid.New().String(),
+							Kind:         entity.MentionKindMembership,
+						},
+					},
+					nil,
+				)
+			},
+			in: func() *http.Request {
+				url := fmt.Sprintf("/api/notification/list/%s?unread=true", workspaceID)
+				req, _ := http.NewRequest(http.MethodGet, url, nil)
+				return req
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: failed to list notifications",
+			setup: func(m *mock.MockNotificationUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+				m.EXPECT().ListNotifications(gomock.Any(), membershipID, false).Return(nil, fmt.Errorf("database error"))
+			},
+			in: func() *http.Request {
+				url := fmt.Sprintf("/api/notification/list/%s", workspaceID)
+				req, _ := http.NewRequest(http.MethodGet, url, nil)
+				return req
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			nuc := mock.NewMockNotificationUseCase(ctrl)
+			auc := mock.NewMockAuthUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(nuc, auc)
+			}
+
+			handler := NewNotificationHandler(nuc, auc)
+			recorder := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Get("/api/notification/list/{workspace_id}", handler.ListNotifications)
+			r.ServeHTTP(recorder, tt.in())
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestNotificationHandler_MarkNotificationsRead(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+	membershipID := userID + "_" + workspaceID
+	notificationID := uuid.New().String()
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockNotificationUseCase,
+			m1 *mock.MockAuthUseCase,
+		)
+		in         func() *http.Request
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockNotificationUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+				m.EXPECT().MarkNotificationsRead(gomock.Any(), membershipID, []string{notificationID}).Return(nil)
+			},
+			in: func() *http.Request {
+				body, _ := json.Marshal(MarkNotificationsReadRequest{IDs: []string{notificationID}})
+				url := fmt.Sprintf("/api/notification/read/%s", workspaceID)
+				req, _ := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(body))
+				req.Header.Set("Content-Type", "application/json")
+				return req
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: invalid request",
+			setup: func(m *mock.MockNotificationUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+			},
+			in: func() *http.Request {
+				body, _ := json.Marshal(MarkNotificationsReadRequest{IDs: []string{""}})
+				url := fmt.Sprintf("/api/notification/read/%s", workspaceID)
+				req, _ := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(body))
+				req.Header.Set("Content-Type", "application/json")
+				return req
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			nuc := mock.NewMockNotificationUseCase(ctrl)
+			auc := mock.NewMockAuthUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(nuc, auc)
+			}
+
+			handler := NewNotificationHandler(nuc, auc)
+			recorder := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Put("/api/notification/read/{workspace_id}", handler.MarkNotificationsRead)
+			r.ServeHTTP(recorder, tt.in())
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/usecase"
+)
+
+// maxPresenceMembershipIDs は、一度に取得できるプレゼンスの数の上限です。
+const maxPresenceMembershipIDs = 100
+
+type PresenceHandler interface {
+	ListPresences(w http.ResponseWriter, r *http.Request)
+}
+
+type presenceHandler struct {
+	puc usecase.PresenceUseCase
+	auc usecase.AuthUseCase
+}
+
+func NewPresenceHandler(puc usecase.PresenceUseCase, auc usecase.AuthUseCase) PresenceHandler {
+	return &presenceHandler{
+		puc: puc,
+		auc: auc,
+	}
+}
+
+type ListPresencesResponse struct {
+	Presences []entity.Presence `json:"presences"`
+}
+
+// ListPresences は、クエリパラメータmembership_idsにカンマ区切りで指定したメンバーシップのプレゼンスを返します。
+func (ph *presenceHandler) ListPresences(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspaceID := chi.URLParam(r, "workspace_id")
+	user, err := ph.auc.GetUserFromContext(ctx)
+	if err != nil {
+		log.Error("Failed to get UserInfo from context", log.Ferror(err))
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	membershipIDs, ok := parseMembershipIDs(r.URL.Query().Get("membership_ids"))
+	if !ok {
+		log.Info("Invalid presence list request", log.Fstring("method", r.Method), log.Fstring("url", r.URL.String()))
+		http.Error(w, "Invalid presence list request", http.StatusBadRequest)
+		return
+	}
+
+	membershipID := user.ID + "_" + workspaceID
+	presences, err := ph.puc.ListPresences(ctx, membershipID, workspaceID, membershipIDs)
+	if errors.Is(err, usecase.ErrPermissionDenied) {
+		http.Error(w, "Permission denied", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		log.Error("Failed to list presences", log.Fstring("membershipID", membershipID), log.Ferror(err))
+		http.Error(w, "Failed to list presences", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(ListPresencesResponse{Presences: presences}); err != nil {
+		log.Error("Failed to encode presences to JSON", log.Ferror(err))
+		http.Error(w, "Failed to encode presences to JSON", http.StatusInternalServerError)
+		return
+	}
+	log.Info("Successfully retrieved presences", log.Fstring("membershipID", membershipID))
+}
+
+func parseMembershipIDs(param string) ([]string, bool) {
+	if param == "" {
+		log.Info("Membership IDs are required")
+		return nil, false
+	}
+	membershipIDs := strings.Split(param, ",")
+	if len(membershipIDs) > maxPresenceMembershipIDs {
+		log.Info("Too many membership IDs", log.Fint("count", len(membershipIDs)))
+		return nil, false
+	}
+	for _, id := range membershipIDs {
+		if id == "" {
+			log.Info("Membership ID must not be empty")
+			return nil, false
+		}
+	}
+	return membershipIDs, true
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/usecase"
+	"github.com/tusmasoma/connectHub-backend/usecase/mock"
+)
+
+func TestPresenceHandler_ListPresences(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+	membershipID := userID + "_" + workspaceID
+	otherMembershipID := uuid.New().String() + "_" + workspaceID
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockPresenceUseCase,
+			m1 *mock.MockAuthUseCase,
+		)
+		in         func() *http.Request
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockPresenceUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+				m.EXPECT().ListPresences(gomock.Any(), membershipID, workspaceID, []string{membershipID, otherMembershipID}).Return(
+					[]entity.Presence{
+						{MembershipID: membershipID, Status: entity.PresenceOnline},
+						{MembershipID: otherMembershipID, Status: entity.PresenceOffline},
+					},
+					nil,
+				)
+			},
+			in: func() *http.Request {
+				url := fmt.Sprintf("/api/presence/list/%s?membership_ids=%s,%s", workspaceID, membershipID, otherMembershipID)
+				req, _ := http.NewRequest(http.MethodGet, url, nil)
+				return req
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: membership IDs are required",
+			setup: func(m *mock.MockPresenceUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+			},
+			in: func() *http.Request {
+				url := fmt.Sprintf("/api/presence/list/%s", workspaceID)
+				req, _ := http.NewRequest(http.MethodGet, url, nil)
+				return req
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: too many membership IDs",
+			setup: func(m *mock.MockPresenceUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+			},
+			in: func() *http.Request {
+				ids := strings.Repeat(otherMembershipID+",", maxPresenceMembershipIDs) + otherMembershipID
+				url := fmt.Sprintf("/api/presence/list/%s?membership_ids=%s", workspaceID, ids)
+				req, _ := http.NewRequest(http.MethodGet, url, nil)
+				return req
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: permission denied",
+			setup: func(m *mock.MockPresenceUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+				m.EXPECT().ListPresences(gomock.Any(), membershipID, workspaceID, []string{otherMembershipID}).Return(
+					nil,
+					usecase.ErrPermissionDenied,
+				)
+			},
+			in: func() *http.Request {
+				url := fmt.Sprintf("/api/presence/list/%s?membership_ids=%s", workspaceID, otherMembershipID)
+				req, _ := http.NewRequest(http.MethodGet, url, nil)
+				return req
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			puc := mock.NewMockPresenceUseCase(ctrl)
+			auc := mock.NewMockAuthUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(puc, auc)
+			}
+
+			handler := NewPresenceHandler(puc, auc)
+			recorder := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Get("/api/presence/list/{workspace_id}", handler.ListPresences)
+			r.ServeHTTP(recorder, tt.in())
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/usecase"
+)
+
+// searchHasLink は、URLを含むメッセージに絞るhasクエリパラメータの値です。
+const searchHasLink = "link"
+
+type SearchHandler interface {
+	SearchMessages(w http.ResponseWriter, r *http.Request)
+}
+
+type searchHandler struct {
+	suc usecase.SearchUseCase
+	auc usecase.AuthUseCase
+}
+
+func NewSearchHandler(suc usecase.SearchUseCase, auc usecase.AuthUseCase) SearchHandler {
+	return &searchHandler{
+		suc: suc,
+		auc: auc,
+	}
+}
+
+type SearchMessagesResponse struct {
+	Messages   []entity.Message `json:"messages"`
+	NextOffset int64            `json:"next_offset,omitempty"` // 次のページがない場合は省略します
+}
+
+// SearchMessages は、ワークスペースで自分が参加しているチャンネルのメッセージを関連度の高い順に返します。
+// クエリパラメータは、q(本文), channel_id, membership_id(作成者), since, until(RFC3339), has=link, offset, limitです。
+func (sh *searchHandler) SearchMessages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspaceID := chi.URLParam(r, "workspace_id")
+	user, err := sh.auc.GetUserFromContext(ctx)
+	if err != nil {
+		log.Error("Failed to get UserInfo from context", log.Ferror(err))
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	params, ok := parseSearchMessagesParams(r.URL.Query())
+	if !ok {
+		log.Info("Invalid message search request", log.Fstring("method", r.Method), log.Fstring("url", r.URL.String()))
+		http.Error(w, "Invalid message search request", http.StatusBadRequest)
+		return
+	}
+
+	membershipID := user.ID + "_" + workspaceID
+	result, err := sh.suc.SearchMessages(ctx, membershipID, workspaceID, params)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrPermissionDenied), errors.Is(err, usecase.ErrChannelAccessDenied):
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		default:
+			log.Error("Failed to search messages", log.Fstring("membershipID", membershipID), log.Ferror(err))
+			http.Error(w, "Failed to search messages", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	response := SearchMessagesResponse{Messages: result.Messages, NextOffset: result.NextOffset}
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("Failed to encode messages to JSON", log.Ferror(err))
+		http.Error(w, "Failed to encode messages to JSON", http.StatusInternalServerError)
+		return
+	}
+	log.Info("Successfully searched messages", log.Fstring("membershipID", membershipID))
+}
+
+func parseSearchMessagesParams(query url.Values) (usecase.SearchMessagesParams, bool) {
+	params := usecase.SearchMessagesParams{
+		Text:         query.Get("q"),
+		ChannelID:    query.Get("channel_id"),
+		MembershipID: query.Get("membership_id"),
+	}
+	if params.Text == "" && params.ChannelID == "" && params.MembershipID == "" && query.Get("has") == "" {
+		log.Info("Search query or filter is required")
+		return params, false
+	}
+
+	switch has := query.Get("has"); has {
+	case "":
+	case searchHasLink:
+		params.HasLink = true
+	default:
+		log.Info("Invalid has filter", log.Fstring("has", has))
+		return params, false
+	}
+
+	for name, dst := range map[string]**time.Time{"since": &params.Since, "until": &params.Until} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			log.Info("Invalid time filter", log.Fstring(name, value))
+			return params, false
+		}
+		*dst = &t
+	}
+
+	for name, dst := range map[string]*int64{"offset": &params.Offset, "limit": &params.Limit} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 0 {
+			log.Info("Invalid page parameter", log.Fstring(name, value))
+			return params, false
+		}
+		*dst = n
+	}
+	return params, true
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/usecase"
+	"github.com/tusmasoma/connectHub-backend/usecase/mock"
+)
+
+func TestSearchHandler_SearchMessages(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+	membershipID := userID + "_" + workspaceID
+	channelID := uuid.New().String()
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockSearchUseCase,
+			m1 *mock.MockAuthUseCase,
+		)
+		in         func() *http.Request
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockSearchUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+				m.EXPECT().SearchMessages(gomock.Any(), membershipID, workspaceID, usecase.SearchMessagesParams{
+					Text:      "hello",
+					ChannelID: channelID,
+					Since:     &since,
+					HasLink:   true,
+					Offset:    20,
+					Limit:     20,
+				}).Return(&usecase.SearchMessagesResult{
+					Messages: []entity.Message{{ID: uuid.New().String(), ChannelID: channelID, Text: "hello https://example.com"}},
+				}, nil)
+			},
+			in: func() *http.Request {
+				url := fmt.Sprintf(
+					"/api/search/messages/%s?q=hello&channel_id=%s&since=%s&has=link&offset=20&limit=20",
+					workspaceID, channelID, since.Format(time.RFC3339),
+				)
+				req, _ := http.NewRequest(http.MethodGet, url, nil)
+				return req
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: query or filter is required",
+			setup: func(m *mock.MockSearchUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+			},
+			in: func() *http.Request {
+				url := fmt.Sprintf("/api/search/messages/%s", workspaceID)
+				req, _ := http.NewRequest(http.MethodGet, url, nil)
+				return req
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: invalid since",
+			setup: func(m *mock.MockSearchUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+			},
+			in: func() *http.Request {
+				url := fmt.Sprintf("/api/search/messages/%s?q=hello&since=yesterday", workspaceID)
+				req, _ := http.NewRequest(http.MethodGet, url, nil)
+				return req
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: channel not joined",
+			setup: func(m *mock.MockSearchUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+				m.EXPECT().SearchMessages(gomock.Any(), membershipID, workspaceID, usecase.SearchMessagesParams{
+					Text:      "hello",
+					ChannelID: channelID,
+				}).Return(nil, usecase.ErrChannelAccessDenied)
+			},
+			in: func() *http.Request {
+				url := fmt.Sprintf("/api/search/messages/%s?q=hello&channel_id=%s", workspaceID, channelID)
+				req, _ := http.NewRequest(http.MethodGet, url, nil)
+				return req
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			suc := mock.NewMockSearchUseCase(ctrl)
+			auc := mock.NewMockAuthUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(suc, auc)
+			}
+
+			handler := NewSearchHandler(suc, auc)
+			recorder := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Get("/api/search/messages/{workspace_id}", handler.SearchMessages)
+			r.ServeHTTP(recorder, tt.in())
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
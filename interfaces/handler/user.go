@@ -2,9 +2,14 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
 
 	"github.com/tusmasoma/connectHub-backend/internal/log"
 	"github.com/tusmasoma/connectHub-backend/usecase"
@@ -13,7 +18,11 @@ import (
 type UserHandler interface {
 	SignUp(w http.ResponseWriter, r *http.Request)
 	Login(w http.ResponseWriter, r *http.Request)
+	Refresh(w http.ResponseWriter, r *http.Request)
 	Logout(w http.ResponseWriter, r *http.Request)
+	ListSessions(w http.ResponseWriter, r *http.Request)
+	RevokeSession(w http.ResponseWriter, r *http.Request)
+	RevokeAllSessions(w http.ResponseWriter, r *http.Request)
 }
 
 type userHandler struct {
@@ -31,11 +40,38 @@ func NewUserHandler(uuc usecase.UserUseCase, auc usecase.AuthUseCase) UserHandle
 type SignUpRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	Device   string `json:"device"` // セッションの一覧で表示する端末名(任意)
 }
 
 type LoginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	Device   string `json:"device"` // セッションの一覧で表示する端末名(任意)
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenResponse は、発行したトークンの組です。アクセストークンはAuthorizationヘッダにも設定します。
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // アクセストークンの有効期間(秒)
+}
+
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Current    bool      `json:"current"` // リクエストの認証に使ったセッションかどうか
+}
+
+type ListSessionsResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
 }
 
 func (uh *userHandler) SignUp(w http.ResponseWriter, r *http.Request) {
@@ -49,7 +85,7 @@ func (uh *userHandler) SignUp(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
-	jwt, err := uh.uuc.SignUpAndGenerateToken(ctx, requestBody.Email, requestBody.Password)
+	tokens, err := uh.uuc.SignUpAndGenerateToken(ctx, requestBody.Email, requestBody.Password, sessionMetadata(r, requestBody.Device))
 	if err != nil {
 		log.Error("Failed to create user and generate token", log.Fstring("email", requestBody.Email), log.Ferror(err))
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -57,8 +93,7 @@ func (uh *userHandler) SignUp(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Info("User sign up successfully", log.Fstring("email", requestBody.Email))
-	w.Header().Set("Authorization", "Bearer "+jwt)
-	w.WriteHeader(http.StatusOK)
+	writeTokens(w, tokens)
 }
 
 func isValidSignUpRequest(body io.ReadCloser, requestBody *SignUpRequest) bool {
@@ -84,7 +119,7 @@ func (uh *userHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
-	jwt, err := uh.uuc.LoginAndGenerateToken(ctx, requestBody.Email, requestBody.Password)
+	tokens, err := uh.uuc.LoginAndGenerateToken(ctx, requestBody.Email, requestBody.Password, sessionMetadata(r, requestBody.Device))
 	if err != nil {
 		log.Error("Failed to login or generate token", log.Fstring("email", requestBody.Email), log.Ferror(err))
 		http.Error(w, "Failed to Login or generate token", http.StatusInternalServerError)
@@ -92,8 +127,7 @@ func (uh *userHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Info("User login successfully", log.Fstring("email", requestBody.Email))
-	w.Header().Set("Authorization", "Bearer "+jwt)
-	w.WriteHeader(http.StatusOK)
+	writeTokens(w, tokens)
 }
 
 func isValidLoginRequest(body io.ReadCloser, requestBody *LoginRequest) bool {
@@ -109,6 +143,62 @@ func isValidLoginRequest(body io.ReadCloser, requestBody *LoginRequest) bool {
 	return true
 }
 
+func (uh *userHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var requestBody RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil || requestBody.RefreshToken == "" {
+		log.Info("Invalid refresh request", log.Fstring("method", r.Method), log.Fstring("url", r.URL.String()))
+		http.Error(w, "Invalid refresh request", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	tokens, err := uh.uuc.RefreshToken(ctx, requestBody.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidRefreshToken), errors.Is(err, usecase.ErrRefreshTokenReused):
+			http.Error(w, "Invalid refresh token", http.StatusUnauthorized)
+		default:
+			log.Error("Failed to refresh token", log.Ferror(err))
+			http.Error(w, "Failed to refresh token", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	log.Info("Token refreshed successfully")
+	writeTokens(w, tokens)
+}
+
+// sessionMetadata は、リクエストからログインした端末の情報を取り出します。
+func sessionMetadata(r *http.Request, device string) usecase.SessionMetadata {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	return usecase.SessionMetadata{
+		Device:    device,
+		UserAgent: r.UserAgent(),
+		IP:        ip,
+	}
+}
+
+// writeTokens は、アクセストークンをAuthorizationヘッダに、トークンの組をレスポンスボディに書き込みます。
+func writeTokens(w http.ResponseWriter, tokens *usecase.AuthTokens) {
+	response := TokenResponse{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    int64(tokens.ExpiresIn.Seconds()),
+	}
+	w.Header().Set("Authorization", "Bearer "+tokens.AccessToken)
+	w.Header().Set("Content-Type", "application/json")
+	// トークンをプロキシやブラウザにキャッシュさせません
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("Failed to encode tokens to JSON", log.Ferror(err))
+		http.Error(w, "Failed to encode tokens to JSON", http.StatusInternalServerError)
+	}
+}
+
 func (uh *userHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	user, err := uh.auc.GetUserFromContext(ctx)
@@ -117,8 +207,14 @@ func (uh *userHandler) Logout(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
 		return
 	}
+	sessionID, err := uh.auc.GetSessionIDFromContext(ctx)
+	if err != nil {
+		log.Error("Failed to get sessionID from context", log.Ferror(err))
+		http.Error(w, fmt.Sprintf("Failed to get sessionID from context: %v", err), http.StatusInternalServerError)
+		return
+	}
 
-	if err = uh.uuc.LogoutUser(ctx, user.ID); err != nil {
+	if err = uh.uuc.LogoutUser(ctx, user.ID, sessionID); err != nil {
 		log.Error("Failed to logout", log.Ferror(err))
 		http.Error(w, "Failed to logout", http.StatusInternalServerError)
 		return
@@ -127,3 +223,94 @@ func (uh *userHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	log.Info("User logout successfully", log.Fstring("userID", user.ID))
 	w.WriteHeader(http.StatusOK)
 }
+
+func (uh *userHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := uh.auc.GetUserFromContext(ctx)
+	if err != nil {
+		log.Error("Failed to get UserInfo from context", log.Ferror(err))
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+	currentSessionID, err := uh.auc.GetSessionIDFromContext(ctx)
+	if err != nil {
+		log.Error("Failed to get sessionID from context", log.Ferror(err))
+		http.Error(w, fmt.Sprintf("Failed to get sessionID from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	sessions, err := uh.uuc.ListSessions(ctx, user.ID)
+	if err != nil {
+		log.Error("Failed to list sessions", log.Fstring("userID", user.ID), log.Ferror(err))
+		http.Error(w, "Failed to list sessions", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListSessionsResponse{Sessions: make([]SessionResponse, 0, len(sessions))}
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, SessionResponse{
+			ID:         session.ID,
+			Device:     session.Device,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("Failed to encode sessions to JSON", log.Ferror(err))
+		http.Error(w, "Failed to encode sessions to JSON", http.StatusInternalServerError)
+		return
+	}
+	log.Info("Successfully listed sessions", log.Fstring("userID", user.ID))
+}
+
+func (uh *userHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	sessionID := chi.URLParam(r, "session_id")
+	if sessionID == "" {
+		log.Warn("Session ID is required", log.Fstring("method", r.Method), log.Fstring("url", r.URL.String()))
+		http.Error(w, "Session ID is required", http.StatusBadRequest)
+		return
+	}
+	user, err := uh.auc.GetUserFromContext(ctx)
+	if err != nil {
+		log.Error("Failed to get UserInfo from context", log.Ferror(err))
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err = uh.uuc.RevokeSession(ctx, user.ID, sessionID); err != nil {
+		if errors.Is(err, usecase.ErrSessionNotFound) {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+		log.Error("Failed to revoke session", log.Fstring("userID", user.ID), log.Ferror(err))
+		http.Error(w, "Failed to revoke session", http.StatusInternalServerError)
+		return
+	}
+
+	log.Info("Session revoked successfully", log.Fstring("userID", user.ID), log.Fstring("sessionID", sessionID))
+	w.WriteHeader(http.StatusOK)
+}
+
+func (uh *userHandler) RevokeAllSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := uh.auc.GetUserFromContext(ctx)
+	if err != nil {
+		log.Error("Failed to get UserInfo from context", log.Ferror(err))
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err = uh.uuc.RevokeAllSessions(ctx, user.ID); err != nil {
+		log.Error("Failed to revoke sessions", log.Fstring("userID", user.ID), log.Ferror(err))
+		http.Error(w, "Failed to revoke sessions", http.StatusInternalServerError)
+		return
+	}
+
+	log.Info("All sessions revoked successfully", log.Fstring("userID", user.ID))
+	w.WriteHeader(http.StatusOK)
+}
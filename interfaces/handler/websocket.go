@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi"
@@ -24,6 +25,10 @@ type WebsocketHandler struct {
 	psr  repository.PubSubRepository
 	muc  usecase.MessageUseCase
 	mcuc usecase.MembershipChannelUseCase
+	tuc  usecase.ThreadUseCase
+	ruc  usecase.ReactionUseCase
+	azuc usecase.AuthorizationUseCase
+	conf *config.WebSocketConfig
 }
 
 func NewWebsocketHandler(
@@ -32,6 +37,10 @@ func NewWebsocketHandler(
 	psr repository.PubSubRepository,
 	muc usecase.MessageUseCase,
 	mcuc usecase.MembershipChannelUseCase,
+	tuc usecase.ThreadUseCase,
+	ruc usecase.ReactionUseCase,
+	azuc usecase.AuthorizationUseCase,
+	conf *config.WebSocketConfig,
 ) *WebsocketHandler {
 	return &WebsocketHandler{
 		hm:   hm,
@@ -39,6 +48,10 @@ func NewWebsocketHandler(
 		psr:  psr,
 		muc:  muc,
 		mcuc: mcuc,
+		tuc:  tuc,
+		ruc:  ruc,
+		azuc: azuc,
+		conf: conf,
 	}
 }
 
@@ -51,11 +64,39 @@ func (wsh *WebsocketHandler) WebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 
 	workspaceID := chi.URLParam(r, "workspace_id")
-	hub, exists := wsh.hm.Get(workspaceID)
-	if !exists {
+	// Hubに参加するとプレゼンスやチャンネルの作成も届くため、接続の前にワークスペースのメンバーであることを確認します。
+	if err = wsh.azuc.AuthorizeWorkspace(ctx, user.ID+"_"+workspaceID); err != nil {
+		if errors.Is(err, usecase.ErrPermissionDenied) {
+			http.Error(w, "Not a member of the workspace", http.StatusForbidden)
+			return
+		}
+		log.Error("Failed to authorize workspace", log.Fstring("workspaceID", workspaceID), log.Ferror(err))
+		http.Error(w, "Failed to authorize workspace", http.StatusInternalServerError)
+		return
+	}
+
+	hub, err := wsh.hm.GetOrLoad(ctx, workspaceID)
+	if errors.Is(err, usecase.ErrWorkspaceNotFound) {
 		http.Error(w, "Workspace not found", http.StatusNotFound)
 		return
 	}
+	if err != nil {
+		log.Error("Failed to load workspace", log.Fstring("workspaceID", workspaceID), log.Ferror(err))
+		http.Error(w, "Failed to load workspace", http.StatusInternalServerError)
+		return
+	}
+
+	// 送信バッファが一杯のときの扱いは、クライアントごとにクエリパラメータで選べます。
+	policy := wsh.conf.OverflowPolicy
+	if overflow := r.URL.Query().Get("overflow"); overflow != "" {
+		policy = overflow
+	}
+	overflow, err := ws.ParseOverflowPolicy(policy)
+	if err != nil {
+		log.Info("Invalid overflow policy", log.Fstring("overflow", policy))
+		http.Error(w, "Invalid overflow policy", http.StatusBadRequest)
+		return
+	}
 
 	conn, err := upgrader.Upgrade(w, r, nil) // conn is *websocket.Conn
 	if err != nil {
@@ -63,13 +104,17 @@ func (wsh *WebsocketHandler) WebSocket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := ws.NewClient(user.ID, conn, hub, wsh.psr, wsh.muc, wsh.mcuc)
+	// 再接続したクライアントは、取りこぼしたイベントを受け取るまで新しいイベントの配信を止めるよう要求できます。
+	resuming := r.URL.Query().Get("resume") == "true"
+	client := ws.NewClient(user.ID, conn, hub, resuming, overflow, wsh.psr, wsh.muc, wsh.mcuc, wsh.tuc, wsh.ruc, wsh.azuc)
+
+	// ReadPumpは切断時にHubから登録を解除するため、登録を終えてから起動します。
+	// 先に起動すると登録の解除が登録より先に処理され、切断したクライアントがオンラインのまま残ることがあります。
+	hub.Register <- client
 
 	go client.WritePump()
 	go client.ReadPump()
 
-	hub.Register <- client
-
 	log.Info(
 		"Successfully Client connected",
 		log.Fstring("userID", user.ID),
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/usecase"
+	"github.com/tusmasoma/connectHub-backend/usecase/mock"
+)
+
+func TestWebsocketHandler_WebSocket(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+	membershipID := userID + "_" + workspaceID
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockAuthorizationUseCase,
+			m1 *mock.MockAuthUseCase,
+		)
+		wantStatus int
+	}{
+		{
+			name: "Fail: not a member of the workspace",
+			setup: func(m *mock.MockAuthorizationUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+				m.EXPECT().AuthorizeWorkspace(gomock.Any(), membershipID).Return(usecase.ErrPermissionDenied)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "Fail: internal error",
+			setup: func(m *mock.MockAuthorizationUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&entity.User{ID: userID}, nil)
+				m.EXPECT().AuthorizeWorkspace(gomock.Any(), membershipID).Return(fmt.Errorf("connection refused"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			azuc := mock.NewMockAuthorizationUseCase(ctrl)
+			auc := mock.NewMockAuthUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(azuc, auc)
+			}
+
+			// 認可に失敗した場合はHubを読み込まないため、HubManagerは渡しません。
+			handler := NewWebsocketHandler(nil, auc, nil, nil, nil, nil, nil, azuc, nil)
+			recorder := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Get("/ws/{workspace_id}", handler.WebSocket)
+			req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/ws/%s", workspaceID), nil)
+			r.ServeHTTP(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -6,9 +6,10 @@ import (
 	"io"
 	"net/http"
 
+	"github.com/google/uuid"
+
 	"github.com/tusmasoma/connectHub-backend/interfaces/ws"
 	"github.com/tusmasoma/connectHub-backend/internal/log"
-	"github.com/tusmasoma/connectHub-backend/repository"
 	"github.com/tusmasoma/connectHub-backend/usecase"
 )
 
@@ -20,26 +21,17 @@ type workspaceHandler struct {
 	hm  *ws.HubManager
 	auc usecase.AuthUseCase
 	wuc usecase.WorkspaceUseCase
-	cuc usecase.ChannelUseCase
-	psr repository.PubSubRepository
-	mcr repository.MessageCacheRepository
 }
 
 func NewWorkspaceHandler(
 	hm *ws.HubManager,
 	auc usecase.AuthUseCase,
 	wuc usecase.WorkspaceUseCase,
-	cuc usecase.ChannelUseCase,
-	psr repository.PubSubRepository,
-	mcr repository.MessageCacheRepository,
 ) WorkspaceHandler {
 	return &workspaceHandler{
 		hm:  hm,
 		auc: auc,
 		wuc: wuc,
-		cuc: cuc,
-		psr: psr,
-		mcr: mcr,
 	}
 }
 
@@ -69,23 +61,22 @@ func (wh *workspaceHandler) CreateWorkspace(w http.ResponseWriter, r *http.Reque
 	}
 	defer r.Body.Close()
 
+	workspaceID := uuid.New().String()
 	workspaceName := requestBody.Name
-	hub := ws.NewHub(
-		workspaceName,
-		wh.cuc,
-		wh.psr,
-		wh.mcr,
-	)
-
-	go hub.Run()
-	wh.hm.Add(hub.ID, hub)
-
-	if err = wh.wuc.CreateWorkspace(ctx, hub.ID, workspaceName); err != nil {
+	if err = wh.wuc.CreateWorkspace(ctx, workspaceID, workspaceName); err != nil {
 		log.Error("Failed to create workspace", log.Ferror(err))
 		http.Error(w, fmt.Sprintf("Failed to create workspace: %v", err), http.StatusInternalServerError)
 		return
 	}
 
+	// 作成したワークスペースのHubは、他のHubと同じく永続化された内容から組み立てて起動します。
+	hub, err := wh.hm.GetOrLoad(ctx, workspaceID)
+	if err != nil {
+		log.Error("Failed to load hub", log.Fstring("workspaceID", workspaceID), log.Ferror(err))
+		http.Error(w, "Failed to load hub", http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	if err = json.NewEncoder(w).Encode(CreateWorkspaceResponse{ID: hub.ID, Name: workspaceName}); err != nil {
 		log.Error("Failed to encode workspace to JSON", log.Ferror(err))
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/tusmasoma/connectHub-backend/config"
 	"github.com/tusmasoma/connectHub-backend/internal/auth"
@@ -13,19 +14,23 @@ import (
 	"github.com/tusmasoma/connectHub-backend/repository"
 )
 
-var ErrCacheMiss = errors.New("cache: key not found")
+// sessionTouchInterval は、セッションの最終利用日時を更新する間隔です。
+// リクエストのたびにRedisへ書き込まないよう、前回の更新から一定時間が経過した場合だけ更新します。
+const sessionTouchInterval = time.Minute
 
 type AuthMiddleware interface {
 	Authenticate(nextFunc http.Handler) http.Handler
 }
 
 type authMiddleware struct {
-	rr repository.UserCacheRepository
+	sr repository.SessionRepository
+	km *auth.KeyManager
 }
 
-func NewAuthMiddleware(rr repository.UserCacheRepository) AuthMiddleware {
+func NewAuthMiddleware(sr repository.SessionRepository, km *auth.KeyManager) AuthMiddleware {
 	return &authMiddleware{
-		rr: rr,
+		sr: sr,
+		km: km,
 	}
 }
 
@@ -51,8 +56,8 @@ func (am *authMiddleware) Authenticate(next http.Handler) http.Handler {
 		}
 		jwt := parts[1]
 
-		// アクセストークンの検証
-		err := auth.ValidateAccessToken(jwt)
+		// アクセストークンの検証(署名と有効期限)
+		err := am.km.ValidateAccessToken(jwt)
 		if err != nil {
 			log.Warn("Authentication failed: invalid access token", log.Ferror(err))
 			http.Error(w, fmt.Sprintf("Authentication failed: %v", err), http.StatusUnauthorized)
@@ -68,37 +73,47 @@ func (am *authMiddleware) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
-		// 該当のuserIdが存在するかキャッシュに問い合わせ
-		jti, err := am.rr.GetUserSession(ctx, payload.UserID)
-		if errors.Is(err, ErrCacheMiss) {
-			log.Warn("Authentication failed: userId does not exist in cache", log.Fstring("userId", payload.UserID))
-			http.Error(w, "Authentication failed: userId does not exist in cache", http.StatusUnauthorized)
+		// トークンを発行したセッションが有効かキャッシュに問い合わせ
+		session, err := am.sr.Get(ctx, payload.UserID, payload.SessionID)
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			log.Warn(
+				"Authentication failed: session does not exist in cache",
+				log.Fstring("userId", payload.UserID),
+				log.Fstring("sessionId", payload.SessionID),
+			)
+			http.Error(w, "Authentication failed: session does not exist in cache", http.StatusUnauthorized)
 			return
 		} else if err != nil {
 			log.Error(
-				"Authentication failed: failed to get userId from cache",
+				"Authentication failed: failed to get session from cache",
 				log.Fstring("userId", payload.UserID),
 				log.Ferror(err),
 			)
-			http.Error(w, "Authentication failed: failed to get userId from cache", http.StatusUnauthorized)
+			http.Error(w, "Authentication failed: failed to get session from cache", http.StatusUnauthorized)
 			return
 		}
 
-		// Redisから取得したjtiとJWTのjtiを比較
-		if payload.JTI != jti {
+		// セッションの最新のjtiとJWTのjtiを比較
+		if payload.JTI != session.JTI {
 			log.Warn(
 				"Authentication failed: jwt does not match",
 				log.Fstring("jwtJTI", payload.JTI),
-				log.Fstring("cacheJTI", jti),
+				log.Fstring("cacheJTI", session.JTI),
 			)
 			http.Error(w, "Authentication failed: jwt does not match", http.StatusUnauthorized)
 			return
 		}
 
-		// TODO:今後有効期限の確認も行う
+		if now := time.Now(); now.Sub(session.LastUsedAt) >= sessionTouchInterval {
+			// 最終利用日時は表示のためだけに使うため、更新に失敗しても認証は続けます
+			if err = am.sr.Touch(ctx, session.UserID, session.ID, now); err != nil {
+				log.Warn("Failed to touch session", log.Fstring("sessionId", session.ID), log.Ferror(err))
+			}
+		}
 
-		// コンテキストに userID を保存
+		// コンテキストに userID と sessionID を保存
 		ctx = context.WithValue(ctx, config.ContextUserIDKey, payload.UserID)
+		ctx = context.WithValue(ctx, config.ContextSessionIDKey, payload.SessionID)
 
 		log.Info("Successfully Authentication", log.Fstring("userID", payload.UserID))
 		next.ServeHTTP(w, r.WithContext(ctx))
@@ -4,12 +4,15 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 
 	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/entity"
 	"github.com/tusmasoma/connectHub-backend/internal/auth"
+	"github.com/tusmasoma/connectHub-backend/repository"
 	"github.com/tusmasoma/connectHub-backend/repository/mock"
 )
 
@@ -18,6 +21,9 @@ func dummyTestHandler(w http.ResponseWriter, r *http.Request) {
 	if userID, _ := userIDValue.(string); userID == "" {
 		w.WriteHeader(http.StatusInternalServerError)
 	}
+	if sessionID, _ := r.Context().Value(config.ContextSessionIDKey).(string); sessionID == "" {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -28,26 +34,60 @@ func TestAuthMiddleware_Authenticate(t *testing.T) {
 	userID := uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")
 	email := "test@gmail.com"
 
-	jwt, jti := auth.GenerateToken(userID.String(), email)
+	sessionID := "2b1c8d6e-3f4a-4b5c-8d9e-0f1a2b3c4d5e"
+
+	km, err := auth.NewKeyManager("")
+	if err != nil {
+		t.Fatalf("Failed to NewKeyManager: %v", err)
+	}
+	jwt, jti, err := km.GenerateToken(userID.String(), email, sessionID, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to GenerateToken: %v", err)
+	}
+	expired, _, err := km.GenerateToken(userID.String(), email, sessionID, -time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to GenerateToken: %v", err)
+	}
 
 	patterns := []struct {
 		name  string
 		setup func(
-			m *mock.MockUserCacheRepository,
+			m *mock.MockSessionRepository,
 		)
 		in         func() *http.Request
 		wantStatus int
 	}{
 		{
 			name: "success",
-			setup: func(m *mock.MockUserCacheRepository) {
-				m.EXPECT().GetUserSession(
+			setup: func(m *mock.MockSessionRepository) {
+				m.EXPECT().Get(
+					gomock.Any(),
+					"f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
+					sessionID,
+				).Return(
+					&entity.Session{ID: sessionID, UserID: userID.String(), JTI: jti, LastUsedAt: time.Now()},
+					nil,
+				)
+			},
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set("Authorization", "Bearer "+jwt)
+				return req
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "success: stale session is touched",
+			setup: func(m *mock.MockSessionRepository) {
+				m.EXPECT().Get(
 					gomock.Any(),
 					"f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
+					sessionID,
 				).Return(
-					jti,
+					&entity.Session{ID: sessionID, UserID: userID.String(), JTI: jti, LastUsedAt: time.Now().Add(-time.Hour)},
 					nil,
 				)
+				m.EXPECT().Touch(gomock.Any(), userID.String(), sessionID, gomock.Any()).Return(nil)
 			},
 			in: func() *http.Request {
 				req, _ := http.NewRequest(http.MethodGet, "/", nil)
@@ -83,14 +123,24 @@ func TestAuthMiddleware_Authenticate(t *testing.T) {
 			wantStatus: http.StatusUnauthorized,
 		},
 		{
-			name: "Fail: User ID Not In Cache",
-			setup: func(m *mock.MockUserCacheRepository) {
-				m.EXPECT().GetUserSession(
+			name: "Fail: Expired Token",
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set("Authorization", "Bearer "+expired)
+				return req
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "Fail: Session Not In Cache",
+			setup: func(m *mock.MockSessionRepository) {
+				m.EXPECT().Get(
 					gomock.Any(),
 					"f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
+					sessionID,
 				).Return(
-					"",
-					ErrCacheMiss,
+					nil,
+					repository.ErrSessionNotFound,
 				)
 			},
 			in: func() *http.Request {
@@ -102,12 +152,13 @@ func TestAuthMiddleware_Authenticate(t *testing.T) {
 		},
 		{
 			name: "Fail: jti in Cache != jti in Payload",
-			setup: func(m *mock.MockUserCacheRepository) {
-				m.EXPECT().GetUserSession(
+			setup: func(m *mock.MockSessionRepository) {
+				m.EXPECT().Get(
 					gomock.Any(),
 					"f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
+					sessionID,
 				).Return(
-					"invalid jti",
+					&entity.Session{ID: sessionID, UserID: userID.String(), JTI: "invalid jti", LastUsedAt: time.Now()},
 					nil,
 				)
 			},
@@ -125,13 +176,13 @@ func TestAuthMiddleware_Authenticate(t *testing.T) {
 			tt := tt
 
 			ctrl := gomock.NewController(t)
-			repo := mock.NewMockUserCacheRepository(ctrl)
+			repo := mock.NewMockSessionRepository(ctrl)
 
 			if tt.setup != nil {
 				tt.setup(repo)
 			}
 
-			am := NewAuthMiddleware(repo)
+			am := NewAuthMiddleware(repo, km)
 
 			handler := am.Authenticate(http.HandlerFunc(dummyTestHandler))
 
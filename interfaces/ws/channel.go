@@ -2,10 +2,10 @@ package ws
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 
-	"github.com/google/uuid"
-
 	"github.com/tusmasoma/connectHub-backend/config"
 	"github.com/tusmasoma/connectHub-backend/entity"
 	"github.com/tusmasoma/connectHub-backend/internal/log"
@@ -17,28 +17,57 @@ type Channel struct {
 	Name         string `json:"name"`
 	Private      bool   `json:"private"`
 	clients      map[*Client]bool
-	register     chan *Client
+	held         map[*Client]bool  // RESUMEを待っている間、配信を止めているクライアント
+	resumedSeq   map[*Client]int64 // RESUMEで再送した最後の連番。これ以下の配信は重複のため送りません
+	register     chan *registration
 	unregister   chan *Client
+	resume       chan *resumeRequest
 	broadcast    chan *entity.WSMessage
+	events       chan []byte
 	pubsubRepo   repository.PubSubRepository
+	eventRepo    repository.ChannelEventRepository
 	msgCacheRepo repository.MessageCacheRepository
 }
 
+// registration は、クライアントのチャンネルへの登録の依頼です。
+// holdが真の場合は、RESUMEを受け取るまでイベントの配信を止めます。
+type registration struct {
+	client *Client
+	hold   bool
+	done   chan struct{}
+}
+
+// resumeRequest は、クライアントが取りこぼしたイベントの再送を要求します。
+// replayがfalseの場合は再送せず、止めていた配信を再開するだけです。
+type resumeRequest struct {
+	client *Client
+	seq    int64
+	replay bool
+	done   chan struct{}
+}
+
 func NewChannel(
+	id string,
 	name string,
 	private bool,
 	pubsubRepo repository.PubSubRepository,
+	eventRepo repository.ChannelEventRepository,
 	msgCacheRepo repository.MessageCacheRepository,
 ) *Channel {
 	return &Channel{
-		ID:           uuid.New().String(),
+		ID:           id,
 		Name:         name,
 		Private:      private,
 		clients:      make(map[*Client]bool),
-		register:     make(chan *Client),
+		held:         make(map[*Client]bool),
+		resumedSeq:   make(map[*Client]int64),
+		register:     make(chan *registration),
 		unregister:   make(chan *Client),
+		resume:       make(chan *resumeRequest),
 		broadcast:    make(chan *entity.WSMessage),
+		events:       make(chan []byte),
 		pubsubRepo:   pubsubRepo,
+		eventRepo:    eventRepo,
 		msgCacheRepo: msgCacheRepo,
 	}
 }
@@ -48,18 +77,36 @@ func (channel *Channel) Run(ctx context.Context) {
 
 	for {
 		select {
-		case client := <-channel.register:
-			channel.registerClientInChannel(client)
+		case r := <-channel.register:
+			if r.hold {
+				channel.held[r.client] = true
+			}
+			channel.registerClientInChannel(r.client)
+			close(r.done)
 
 		case client := <-channel.unregister:
 			channel.unregisterClientInChannel(client)
 
+		case request := <-channel.resume:
+			channel.resumeClient(ctx, request)
+
 		case message := <-channel.broadcast:
 			channel.publishChannelMessage(ctx, message)
+
+		case payload := <-channel.events:
+			channel.deliverEvent(payload)
 		}
 	}
 }
 
+// join は、クライアントの登録をRunのゴルーチンに依頼します。
+// 返したチャンネルは登録を終えると閉じられ、以降にチャンネルへ配信したイベントはそのクライアントに届きます。
+func (channel *Channel) join(client *Client, hold bool) <-chan struct{} {
+	r := &registration{client: client, hold: hold, done: make(chan struct{})}
+	channel.register <- r
+	return r.done
+}
+
 func (channel *Channel) registerClientInChannel(client *Client) {
 	if !channel.Private {
 		channel.notifyClientJoined(client)
@@ -69,6 +116,51 @@ func (channel *Channel) registerClientInChannel(client *Client) {
 
 func (channel *Channel) unregisterClientInChannel(client *Client) {
 	delete(channel.clients, client)
+	delete(channel.held, client)
+	delete(channel.resumedSeq, client)
+}
+
+// Resume は、seqより後のイベントをclientに再送し、止めていた配信を再開します。再送を終えてから戻ります。
+// replayがfalseの場合は再送せずに配信を再開します。
+// 既にイベントが残っていない場合は、再送の代わりにRESYNCを送ります。
+func (channel *Channel) Resume(client *Client, seq int64, replay bool) {
+	request := &resumeRequest{
+		client: client,
+		seq:    seq,
+		replay: replay,
+		done:   make(chan struct{}),
+	}
+	channel.resume <- request
+	<-request.done
+}
+
+// resumeClient は、Runのゴルーチンで再送を行うことで、再送したイベントより前に新しいイベントが届かないようにします。
+func (channel *Channel) resumeClient(ctx context.Context, request *resumeRequest) {
+	defer close(request.done)
+
+	client := request.client
+	if !channel.clients[client] {
+		return
+	}
+	delete(channel.held, client)
+	if !request.replay {
+		return
+	}
+
+	events, latest, err := channel.eventRepo.ListSince(ctx, channel.ID, request.seq)
+	if err != nil {
+		if !errors.Is(err, repository.ErrChannelEventsExpired) {
+			log.Error("Failed to list channel events", log.Fstring("channelID", channel.ID), log.Ferror(err))
+		}
+		resync := &entity.WSMessage{Action: entity.ResyncAction, TargetID: channel.ID, Seq: latest}
+		client.enqueue(resync.Encode())
+		channel.resumedSeq[client] = latest
+		return
+	}
+	for _, event := range events {
+		client.enqueue(event)
+	}
+	channel.resumedSeq[client] = latest
 }
 
 func (channel *Channel) notifyClientJoined(client *Client) {
@@ -98,23 +190,64 @@ func (channel *Channel) notifyClientJoined(client *Client) {
 
 func (channel *Channel) broadcastToClientsInChannel(message []byte) {
 	for client := range channel.clients {
-		client.send <- message
+		client.enqueue(message)
 	}
 }
 
 func (channel *Channel) publishChannelMessage(ctx context.Context, message *entity.WSMessage) {
-	if err := channel.pubsubRepo.Publish(ctx, channel.ID, message.Encode()); err != nil {
+	// 連番はクライアントの値を使わず、配信時に付けます。
+	message.Seq = 0
+	if _, err := channel.eventRepo.Publish(ctx, channel.ID, message.Encode()); err != nil {
 		log.Error("Failed to publish message", log.Ferror(err))
 	}
 }
 
-func (channel *Channel) subscribeToChannelMessages(ctx context.Context) {
-	pubsub := channel.pubsubRepo.Subscribe(ctx, channel.ID)
-	defer pubsub.Close()
+// publishTyping は、入力中のイベントを保存せず、連番も付けずにチャンネルのトピックに配信します。
+// Runのゴルーチンを経由しないため、TYPINGが増えてもメッセージの配信を妨げません。
+func (channel *Channel) publishTyping(ctx context.Context, event *entity.WSTypingEvent) error {
+	if err := channel.pubsubRepo.Publish(ctx, config.PubSubChannelPrefix+channel.ID, event.Encode()); err != nil {
+		log.Error("Failed to publish typing event", log.Fstring("channelID", channel.ID), log.Ferror(err))
+		return err
+	}
+	return nil
+}
 
-	ch := pubsub.Channel()
+// deliverEvent は、配信を止めているクライアントと、RESUMEで既に再送したクライアントを除いてイベントを送ります。
+// 連番のないイベントは再送の対象ではないため、配信を止めているクライアント以外に送ります。
+func (channel *Channel) deliverEvent(payload []byte) {
+	if len(channel.held) == 0 && len(channel.resumedSeq) == 0 {
+		channel.broadcastToClientsInChannel(payload)
+		return
+	}
 
-	for msg := range ch {
-		channel.broadcastToClientsInChannel([]byte(msg.Payload))
+	var event struct {
+		Seq int64 `json:"seq"`
 	}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		log.Warn("Failed to unmarshal channel event", log.Fstring("channelID", channel.ID), log.Ferror(err))
+	}
+	for client := range channel.clients {
+		if channel.held[client] {
+			continue
+		}
+		if event.Seq == 0 {
+			client.enqueue(payload)
+			continue
+		}
+		if seq, ok := channel.resumedSeq[client]; ok {
+			if event.Seq <= seq {
+				continue
+			}
+			delete(channel.resumedSeq, client)
+		}
+		client.enqueue(payload)
+	}
+}
+
+// subscribeToChannelMessages は、他のサーバーを含めて配信されたイベントをRunのゴルーチンに渡します。
+// イベントはChannelEventRepositoryがチャンネルIDから決まるトピックに配信するため、全てのサーバーの同じチャンネルに届きます。
+func (channel *Channel) subscribeToChannelMessages(ctx context.Context) {
+	subscribe(ctx, channel.pubsubRepo, config.PubSubChannelPrefix+channel.ID, func(payload []byte) {
+		channel.events <- payload
+	})
 }
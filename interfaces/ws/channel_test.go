@@ -0,0 +1,159 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository/memory"
+)
+
+func frameSeq(t *testing.T, payload []byte) int64 {
+	t.Helper()
+
+	var message entity.WSMessage
+	if err := json.Unmarshal(payload, &message); err != nil {
+		t.Fatalf("Failed to unmarshal frame: %v", err)
+	}
+	return message.Seq
+}
+
+func receiveSeq(t *testing.T, client *Client) int64 {
+	t.Helper()
+
+	select {
+	case payload := <-client.send:
+		return frameSeq(t, payload)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("client %s received no frame", client.ID)
+		return 0
+	}
+}
+
+// Test_Channel_PublishAndResume は、Redisを使わずにチャンネルのイベントの配信とRESUMEの再送を確認します。
+func Test_Channel_PublishAndResume(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	psr := memory.NewPubSubRepository()
+	channel := NewChannel("channel", "general", true, psr, memory.NewChannelEventRepository(psr), nil)
+	go channel.Run(ctx)
+
+	newClient := func(id string) *Client {
+		return &Client{
+			ID:       id,
+			channels: make(map[*Channel]bool),
+			send:     make(chan []byte, config.ChannelBufferSize),
+			overflow: OverflowDropOldest,
+		}
+	}
+	publish := func(n int) {
+		for i := 0; i < n; i++ {
+			channel.broadcast <- &entity.WSMessage{
+				Action:   entity.CreateMessageAction,
+				Content:  entity.Message{Text: fmt.Sprintf("message %d", i)},
+				TargetID: channel.ID,
+			}
+		}
+	}
+
+	// Runが購読を始めるまでに配信したイベントは届かないため、イベントが届くまで配信を繰り返します。
+	online := newClient("online")
+	<-channel.join(online, false)
+	var seq int64
+	for seq == 0 {
+		publish(1)
+		select {
+		case payload := <-online.send:
+			seq = frameSeq(t, payload)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	_, base, err := channel.eventRepo.ListSince(ctx, channel.ID, 0)
+	if err != nil {
+		t.Fatalf("ListSince() error = %v", err)
+	}
+	for seq != base {
+		seq = receiveSeq(t, online)
+	}
+
+	resuming := newClient("resuming")
+	<-channel.join(resuming, true)
+	publish(3)
+	for want := base + 1; want <= base+3; want++ {
+		if got := receiveSeq(t, online); got != want {
+			t.Errorf("online client received seq %d, want %d", got, want)
+		}
+	}
+	if len(resuming.send) != 0 {
+		t.Errorf("held client received %d frames before RESUME, want 0", len(resuming.send))
+	}
+
+	channel.Resume(resuming, base+1, true)
+	publish(1)
+	for want := base + 2; want <= base+4; want++ {
+		if got := receiveSeq(t, resuming); got != want {
+			t.Errorf("resumed client received seq %d, want %d", got, want)
+		}
+	}
+	if got := receiveSeq(t, online); got != base+4 {
+		t.Errorf("online client received seq %d, want %d", got, base+4)
+	}
+	if len(resuming.send) != 0 {
+		t.Errorf("resumed client received %d duplicate frames, want 0", len(resuming.send))
+	}
+}
+
+// Test_Channel_Typing は、入力中のイベントが保存されずに、配信を止めていないクライアントにだけ届くことを確認します。
+func Test_Channel_Typing(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	psr := memory.NewPubSubRepository()
+	channel := NewChannel("channel", "general", true, psr, memory.NewChannelEventRepository(psr), nil)
+	go channel.Run(ctx)
+
+	online := &Client{ID: "online", send: make(chan []byte, config.ChannelBufferSize), overflow: OverflowDropOldest}
+	held := &Client{ID: "held", send: make(chan []byte, config.ChannelBufferSize), overflow: OverflowDropOldest}
+	<-channel.join(online, false)
+	<-channel.join(held, true)
+
+	// Runが購読を始めるまでに配信したイベントは届かないため、イベントが届くまで配信を繰り返します。
+	event := entity.NewWSTyping(channel.ID, "user_workspace", "sender", time.Now().Add(config.TypingTTL))
+	var received entity.WSTypingEvent
+	for received.Action == "" {
+		if err := channel.publishTyping(ctx, event); err != nil {
+			t.Fatalf("publishTyping() error = %v", err)
+		}
+		select {
+		case payload := <-online.send:
+			if err := json.Unmarshal(payload, &received); err != nil {
+				t.Fatalf("Failed to unmarshal frame: %v", err)
+			}
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	if received.Action != entity.TypingAction || received.MembershipID != "user_workspace" || received.ChannelID != channel.ID {
+		t.Errorf("online client received %+v, want typing event of user_workspace", received)
+	}
+
+	if len(held.send) != 0 {
+		t.Errorf("held client received %d typing events, want 0", len(held.send))
+	}
+
+	events, latest, err := channel.eventRepo.ListSince(ctx, channel.ID, 0)
+	if err != nil {
+		t.Fatalf("ListSince() error = %v", err)
+	}
+	if len(events) != 0 || latest != 0 {
+		t.Errorf("typing events were stored: %d events up to seq %d", len(events), latest)
+	}
+}
@@ -3,6 +3,9 @@ package ws
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,6 +20,7 @@ import (
 
 var newline = []byte{'\n'}
 
+// Client は、WebSocketの接続です。channelsはHub.Runのゴルーチンだけが操作します。
 type Client struct {
 	ID       string
 	UserID   string // TODO: membershipIDに変更すべきか？
@@ -24,29 +28,55 @@ type Client struct {
 	hub      *Hub
 	channels map[*Channel]bool
 	send     chan []byte
-	psr      repository.PubSubRepository
-	muc      usecase.MessageUseCase
-	mcuc     usecase.MembershipChannelUseCase
+	sendMu   sync.Mutex // sendへの追加とcloseを排他します
+	closed   bool
+	overflow OverflowPolicy
+	// overflowOnce は、OverflowDisconnectで接続を一度だけ閉じるために使います。
+	overflowOnce sync.Once
+	// resuming が真のクライアントは、RESUMEを送るまでチャンネルのイベントを受け取りません。
+	resuming bool
+	// registered は、Hubが参加しているチャンネルへの登録を終えると閉じられます。
+	registered chan struct{}
+	// typedAt は、チャンネルごとに最後にTYPINGを配信した時刻です。ReadPumpのゴルーチンだけが操作します。
+	typedAt map[string]time.Time
+	psr     repository.PubSubRepository
+	muc     usecase.MessageUseCase
+	mcuc    usecase.MembershipChannelUseCase
+	tuc     usecase.ThreadUseCase
+	ruc     usecase.ReactionUseCase
+	azuc    usecase.AuthorizationUseCase
 }
 
 func NewClient(
 	userID string,
 	conn *websocket.Conn,
 	hub *Hub,
+	resuming bool,
+	overflow OverflowPolicy,
 	psr repository.PubSubRepository,
 	muc usecase.MessageUseCase,
 	mcuc usecase.MembershipChannelUseCase,
+	tuc usecase.ThreadUseCase,
+	ruc usecase.ReactionUseCase,
+	azuc usecase.AuthorizationUseCase,
 ) *Client {
 	return &Client{
-		ID:       uuid.New().String(),
-		UserID:   userID,
-		conn:     conn,
-		hub:      hub,
-		channels: make(map[*Channel]bool),
-		send:     make(chan []byte, config.ChannelBufferSize),
-		psr:      psr,
-		muc:      muc,
-		mcuc:     mcuc,
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		conn:       conn,
+		hub:        hub,
+		channels:   make(map[*Channel]bool),
+		send:       make(chan []byte, config.ChannelBufferSize),
+		resuming:   resuming,
+		overflow:   overflow,
+		registered: make(chan struct{}),
+		typedAt:    make(map[string]time.Time),
+		psr:        psr,
+		muc:        muc,
+		mcuc:       mcuc,
+		tuc:        tuc,
+		ruc:        ruc,
+		azuc:       azuc,
 	}
 }
 
@@ -148,7 +178,10 @@ func (client *Client) WritePump() { //nolint: gocognit
 
 func (client *Client) disconnect() {
 	client.hub.unregister <- client
+	client.sendMu.Lock()
+	client.closed = true
 	close(client.send)
+	client.sendMu.Unlock()
 	if err := client.conn.Close(); err != nil {
 		log.Warn("Failed to close connection", log.Ferror(err))
 	} else {
@@ -156,218 +189,537 @@ func (client *Client) disconnect() {
 	}
 }
 
+var errChannelExists = errors.New("channel already exists")
+
+// handleNewMessage は、クライアントから受け取ったWSMessageを処理し、結果をACKかERRORのフレームで送信元に返します。
 func (client *Client) handleNewMessage(jsonMessage []byte) {
 	ctx := context.Background()
 
 	var message entity.WSMessage
 	if err := json.Unmarshal(jsonMessage, &message); err != nil {
 		log.Error("Error unmarshalling JSON message", log.Ferror(err))
+		client.enqueue(entity.NewWSError("", "", entity.ErrorCodeInvalidMessage, "invalid JSON message").Encode())
 		return
 	}
 
 	message.SenderID = client.ID
 
+	if !entity.IsValidAction(message.Action) {
+		log.Warn("Unknown message action", log.Fstring("action", message.Action))
+		client.enqueue(entity.NewWSError(
+			message.RequestID,
+			message.Action,
+			entity.ErrorCodeUnknownAction,
+			"unknown action: "+message.Action,
+		).Encode())
+		return
+	}
+
+	ack, err := client.dispatch(ctx, message)
+	if err != nil {
+		client.enqueue(newWSError(message, err).Encode())
+		return
+	}
+	client.enqueue(ack.Encode())
+}
+
+func (client *Client) dispatch(ctx context.Context, message entity.WSMessage) (*entity.WSAck, error) {
+	// クライアントが指定したチャンネルやメッセージのIDは、全てのアクションで処理する前に権限を確認します。
+	membershipID := client.UserID + "_" + client.hub.ID
+	if err := client.azuc.AuthorizeAction(ctx, membershipID, message); err != nil {
+		log.Warn(
+			"Action not authorized",
+			log.Fstring("action", message.Action),
+			log.Fstring("membershipID", membershipID),
+			log.Ferror(err),
+		)
+		return nil, err
+	}
+
+	ack := entity.NewWSAck(message.RequestID, message.Action)
+	var err error
 	switch message.Action {
 	case entity.ListMessagesAction:
-		client.handleListMessages(ctx, message)
+		err = client.handleListMessages(ctx, message)
 	case entity.CreateMessageAction:
-		client.handleCreateMessage(ctx, message)
+		ack.MessageID, err = client.handleCreateMessage(ctx, message)
 	case entity.DeleteMessageAction:
-		client.handleDeleteMessage(ctx, message)
+		err = client.handleDeleteMessage(ctx, message)
 	case entity.UpdateMessageAction:
-		client.handleUpdateMessage(ctx, message)
+		err = client.handleUpdateMessage(ctx, message)
 	case entity.CreatePublicChannelAction:
-		client.handleCreatePublicChannel(ctx, message)
+		err = client.handleCreateChannel(ctx, message, false)
+	case entity.CreatePrivateChannelAction:
+		err = client.handleCreateChannel(ctx, message, true)
+	case entity.InviteToChannelAction:
+		err = client.handleInviteToChannel(ctx, message)
+	case entity.OpenDirectMessageAction:
+		err = client.handleOpenDirectMessage(ctx, message)
 	case entity.JoinPublicChannelAction:
-		client.handleJoinPublicChannel(ctx, message)
+		err = client.handleJoinPublicChannel(ctx, message)
 	case entity.LeavePublicChannelAction:
-		client.handleLeavePublicChannel(ctx, message)
+		err = client.handleLeavePublicChannel(ctx, message)
+	case entity.CreateThreadReplyAction:
+		err = client.handleCreateThreadReply(ctx, message)
+	case entity.ListThreadAction:
+		err = client.handleListThread(ctx, message)
+	case entity.FollowThreadAction:
+		err = client.handleFollowThread(ctx, message)
+	case entity.UnfollowThreadAction:
+		err = client.handleUnfollowThread(ctx, message)
+	case entity.AddReactionAction:
+		err = client.handleAddReaction(ctx, message)
+	case entity.RemoveReactionAction:
+		err = client.handleRemoveReaction(ctx, message)
+	case entity.ResumeAction:
+		client.handleResume(message)
+	case entity.SetPresenceAction:
+		err = client.hub.SetPresence(ctx, membershipID, message.Content.Text)
+	case entity.TypingAction:
+		err = client.handleTyping(ctx, message)
+	case entity.MarkReadAction:
+		err = client.mcuc.MarkRead(ctx, membershipID, message.TargetID, message.Content.ID)
 	default:
-		log.Warn("Unknown message action", log.Fstring("action", message.Action))
+		err = fmt.Errorf("unhandled action: %s", message.Action)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ack, nil
+}
+
+// newWSError は、アクションの処理中に発生したエラーをERRORフレームに変換します。
+// 想定していないエラーは内部の情報を含む可能性があるため、メッセージをクライアントに返しません。
+func newWSError(message entity.WSMessage, err error) *entity.WSError {
+	code := entity.ErrorCodeInternal
+	text := "internal error"
+	switch {
+	case errors.Is(err, usecase.ErrPermissionDenied), errors.Is(err, usecase.ErrChannelAccessDenied):
+		code, text = entity.ErrorCodePermissionDenied, err.Error()
+	case errors.Is(err, usecase.ErrChannelNotFound), errors.Is(err, usecase.ErrMessageNotFound):
+		code, text = entity.ErrorCodeNotFound, err.Error()
+	case errors.Is(err, errChannelExists):
+		code, text = entity.ErrorCodeConflict, err.Error()
+	case errors.Is(err, usecase.ErrInvalidPresenceStatus), errors.Is(err, usecase.ErrAttachmentUnavailable):
+		code, text = entity.ErrorCodeInvalidMessage, err.Error()
+	}
+	return entity.NewWSError(message.RequestID, message.Action, code, text)
+}
+
+// handleResume は、参加しているチャンネルごとに、Sequencesの連番より後のイベントを再送して配信を再開します。
+// Sequencesにないチャンネルは再送せずに配信を再開します。全てのチャンネルの再送を終えてから戻ります。
+func (client *Client) handleResume(message entity.WSMessage) {
+	<-client.registered
+	for _, channel := range client.hub.clientChannels(client) {
+		seq, ok := message.Sequences[channel.ID]
+		channel.Resume(client, seq, ok)
 	}
 }
 
-func (client *Client) handleListMessages(ctx context.Context, message entity.WSMessage) {
+// handleTyping は、TargetIDのチャンネルのクライアントに入力中であることを伝えます。
+// 同じチャンネルのTYPINGはconfig.TypingThrottleに一度だけ配信し、それより短い間隔のTYPINGは配信せずにACKを返します。
+func (client *Client) handleTyping(ctx context.Context, message entity.WSMessage) error {
 	channelID := message.TargetID
-	start := time.Unix(0, 0)                       // Unixエポックの開始
-	end := time.Unix(1<<63-62135596801, 999999999) //nolint:gomnd // Unixエポックの終了
-	msgs, err := client.muc.ListMessages(ctx, channelID, start, end)
+	now := time.Now()
+	if !client.allowTyping(channelID, now) {
+		return nil
+	}
+
+	channel := client.hub.FindChannelByID(channelID)
+	if channel == nil {
+		log.Warn("Channel not found", log.Fstring("channelID", channelID))
+		return usecase.ErrChannelNotFound
+	}
+
+	membershipID := client.UserID + "_" + client.hub.ID
+	return channel.publishTyping(ctx, entity.NewWSTyping(channelID, membershipID, client.ID, now.Add(config.TypingTTL)))
+}
+
+// allowTyping は、チャンネルで前回TYPINGを配信してからconfig.TypingThrottle以上経っていれば、配信した時刻を記録して真を返します。
+func (client *Client) allowTyping(channelID string, now time.Time) bool {
+	if last, ok := client.typedAt[channelID]; ok && now.Sub(last) < config.TypingThrottle {
+		return false
+	}
+	client.typedAt[channelID] = now
+	return true
+}
+
+func (client *Client) handleListMessages(ctx context.Context, message entity.WSMessage) error {
+	channelID := message.TargetID
+	var page entity.Page
+	if message.Page != nil {
+		page = *message.Page
+	}
+
+	result, err := client.muc.ListMessagesPage(ctx, channelID, page)
 	if err != nil {
 		log.Error("Failed to list messages", log.Ferror(err))
-		return
+		return err
 	}
 
 	response := entity.WSMessages{
-		Action:   entity.ListMessagesAction,
-		TargetID: channelID,
-		Contents: msgs,
+		Action:     entity.ListMessagesAction,
+		TargetID:   channelID,
+		Contents:   result.Messages,
+		NextCursor: result.NextCursor,
+		PrevCursor: result.PrevCursor,
 	}
-	client.send <- response.Encode()
+	client.enqueue(response.Encode())
+	return nil
 }
 
-func (client *Client) handleCreateMessage(ctx context.Context, message entity.WSMessage) {
+// handleCreateMessage は、メッセージを作成し、作成したメッセージのIDを返します。
+// IdempotencyKeyが一致する再送の場合は、配信せずに最初に作成したメッセージのIDを返します。
+func (client *Client) handleCreateMessage(ctx context.Context, message entity.WSMessage) (string, error) {
 	channelID := message.TargetID
 	message.Content.ID = uuid.New().String()
 	message.Content.MembershipID = client.UserID + "_" + client.hub.ID
-
-	if err := client.muc.CreateMessage(ctx, channelID, message.Content); err != nil {
+	message.Content.ChannelID = channelID
+	// スレッドやリアクションの情報はサーバーが管理するため、クライアントが送った値は使いません。
+	// parent_idを受け付けると、チャンネルのメッセージが無関係なスレッドの返信として扱われてしまいます。
+	message.Content.ParentID = ""
+	message.Content.ReplyCount = 0
+	message.Content.LastReplyAt = nil
+	message.Content.Reactions = nil
+	message.Content.CreatedAt = time.Now()
+	message.Content.UpdatedAt = nil
+
+	created, err := client.muc.CreateMessage(
+		ctx,
+		channelID,
+		message.Content,
+		message.IdempotencyKey,
+		client.hub.OnlineMembershipIDs(),
+	)
+	if err != nil {
 		log.Error("Failed to create message", log.Ferror(err))
-		return
+		return "", err
+	}
+	if created.Duplicate {
+		return created.MessageID, nil
 	}
 
-	if channel := client.hub.FindChannelByID(channelID); channel != nil {
-		log.Info("Broadcasting message", log.Fstring("channelID", channelID), log.Fstring("messageID", message.Content.ID))
-		channel.broadcast <- &message
-	} else {
-		log.Warn("Channel not found", log.Fstring("channelID", channelID))
+	message.Content = created.Message
+	client.broadcastToChannel(&message)
+
+	// 通知はメンションされたメンバーごとに異なるため、個別に届けます。
+	for _, notification := range created.Notifications {
+		payload := entity.NewWSNotification(notification, message.Content).Encode()
+		client.hub.Deliver(ctx, []string{notification.MembershipID}, payload)
 	}
+	return created.MessageID, nil
 }
 
-func (client *Client) handleDeleteMessage(ctx context.Context, message entity.WSMessage) {
-	channelID := message.TargetID
+// handleDeleteMessage は、Content.IDのメッセージを削除します。
+// 配信先のチャンネルはTargetIDではなく、保存済みのメッセージが属するチャンネルを使います。
+func (client *Client) handleDeleteMessage(ctx context.Context, message entity.WSMessage) error {
 	membershipID := client.UserID + "_" + client.hub.ID
 
-	if err := client.muc.DeleteMessage(ctx, message.Content, membershipID, channelID); err != nil {
+	deleted, err := client.muc.DeleteMessage(ctx, message.Content.ID, membershipID)
+	if err != nil {
 		log.Error("Failed to delete message", log.Ferror(err))
-		return
+		return err
 	}
 
-	if channel := client.hub.FindChannelByID(channelID); channel != nil {
-		log.Info("Broadcasting message", log.Fstring("channelID", channelID), log.Fstring("messageID", message.Content.ID))
-		channel.broadcast <- &message
-	} else {
-		log.Warn("Channel not found", log.Fstring("channelID", channelID))
-	}
+	message.Content = *deleted
+	message.TargetID = deleted.ChannelID
+	client.broadcastToChannel(&message)
+	return nil
 }
 
-func (client *Client) handleUpdateMessage(ctx context.Context, message entity.WSMessage) {
+// handleUpdateMessage は、Content.IDのメッセージの本文をContent.Textに更新します。
+func (client *Client) handleUpdateMessage(ctx context.Context, message entity.WSMessage) error {
 	membershipID := client.UserID + "_" + client.hub.ID
-	if err := client.muc.UpdateMessage(ctx, message.Content, membershipID); err != nil {
+
+	updated, err := client.muc.UpdateMessage(ctx, message.Content, membershipID)
+	if err != nil {
 		log.Error("Failed to update message", log.Ferror(err))
-		return
+		return err
 	}
 
+	message.Content = *updated
+	message.TargetID = updated.ChannelID
+	client.broadcastToChannel(&message)
+	return nil
+}
+
+func (client *Client) broadcastToChannel(message *entity.WSMessage) {
 	channelID := message.TargetID
 	if channel := client.hub.FindChannelByID(channelID); channel != nil {
 		log.Info("Broadcasting message", log.Fstring("channelID", channelID), log.Fstring("messageID", message.Content.ID))
-		channel.broadcast <- &message
+		channel.broadcast <- message
 	} else {
 		log.Warn("Channel not found", log.Fstring("channelID", channelID))
 	}
 }
 
-func (client *Client) handleCreatePublicChannel(ctx context.Context, message entity.WSMessage) {
+// handleCreateChannel は、Content.Textを名前とするチャンネルを作成し、作成者の全てのクライアントを登録してから作成を配信します。
+// プライベートチャンネルは作成者だけが参加した状態で作成され、INVITE_TO_CHANNELでメンバーを招待します。
+func (client *Client) handleCreateChannel(ctx context.Context, message entity.WSMessage, private bool) error {
 	channelName := message.Content.Text
 	membershipID := client.UserID + "_" + client.hub.ID
 	channel := client.hub.FindChannelByName(channelName)
 	if channel != nil {
 		log.Warn("Channel already exists", log.Fstring("channelName", channelName))
-		return
+		return errChannelExists
 	}
 
-	channel = client.hub.CreateChannel(ctx, membershipID, channelName, false)
-	if channel == nil {
+	channel, err := client.hub.CreateChannel(ctx, membershipID, channelName, private)
+	if err != nil {
 		log.Error("Failed to create channel", log.Fstring("channelName", channelName))
-		return
-	}
-
-	for c := range client.hub.clients {
-		if c.UserID == client.UserID {
-			if !c.isInChannel(channel) {
-				c.channels[channel] = true
-				channel.register <- c
-				log.Info("Client registered to channel", log.Fstring("clientID", c.ID), log.Fstring("channelID", channel.ID))
-			}
-		}
+		return err
 	}
 
-	time.Sleep(5 * time.Second) //nolint:gomnd // TODO: time.Sleepを使うのは避ける
+	// 作成者のクライアントが全て登録を終えてから配信するため、作成の通知は全てのクライアントに届きます。
+	client.hub.Join(ctx, client.UserID, channel)
 
 	content, err := entity.NewMessage(membershipID, channel.Name)
 	if err != nil {
 		log.Error("Failed to create message", log.Ferror(err))
-		return
+		return err
 	}
-	msg, err := entity.NewWSMessage(entity.CreatePublicChannelAction, *content, channel.ID, client.ID)
+	msg, err := entity.NewWSMessage(message.Action, *content, channel.ID, client.ID)
 	if err != nil {
 		log.Error("Failed to create message", log.Ferror(err))
-		return
+		return err
 	}
 	channel.broadcast <- msg
+
+	// 公開チャンネルは、ワークスペースの他のメンバーが見つけて参加できるよう全てのクライアントに知らせます。
+	if !private {
+		created := entity.Channel{ID: channel.ID, WorkspaceID: client.hub.ID, Name: channel.Name}
+		client.hub.Announce(ctx, entity.NewWSChannelCreated(created, client.ID).Encode())
+	}
+	return nil
 }
 
-func (client *Client) handleJoinPublicChannel(ctx context.Context, message entity.WSMessage) {
+func (client *Client) handleJoinPublicChannel(ctx context.Context, message entity.WSMessage) error {
 	channelID := message.TargetID
 	membershipID := client.UserID + "_" + client.hub.ID
 	channel := client.hub.FindChannelByID(channelID)
 	if channel == nil {
 		log.Warn("Channel not found", log.Fstring("channelID", channelID))
-		return
+		return usecase.ErrChannelNotFound
 	}
 
 	if err := client.mcuc.CreateMembershipChannel(ctx, membershipID, channelID); err != nil {
 		log.Error("Failed to create membership channel", log.Fstring("membershipID", membershipID), log.Fstring("channelID", channelID))
-		return
+		return err
 	}
 
-	for c := range client.hub.clients {
-		if c.UserID == client.UserID {
-			if !c.isInChannel(channel) {
-				c.channels[channel] = true
-				channel.register <- c
-				log.Info("Client registered to channel", log.Fstring("clientID", c.ID), log.Fstring("channelID", channel.ID))
-			}
-		}
-	}
+	client.hub.Join(ctx, client.UserID, channel)
 
 	content, err := entity.NewMessage(membershipID, channel.Name)
 	if err != nil {
 		log.Error("Failed to create message", log.Ferror(err))
-		return
+		return err
 	}
 	msg, err := entity.NewWSMessage(entity.JoinPublicChannelAction, *content, channel.ID, client.ID)
 	if err != nil {
 		log.Error("Failed to create message", log.Ferror(err))
-		return
+		return err
 	}
 	channel.broadcast <- msg
+	return nil
+}
+
+// handleInviteToChannel は、TargetIDのチャンネルにMembershipIDsのメンバーを招待します。
+// 招待されたメンバーのクライアントはチャンネルに参加させ、チャンネルの全メンバーに招待を通知します。
+func (client *Client) handleInviteToChannel(ctx context.Context, message entity.WSMessage) error {
+	channelID := message.TargetID
+	membershipID := client.UserID + "_" + client.hub.ID
+
+	invitation, err := client.hub.channelUseCase.InviteToChannel(ctx, membershipID, channelID, message.MembershipIDs)
+	if err != nil {
+		log.Error("Failed to invite to channel", log.Fstring("channelID", channelID), log.Ferror(err))
+		return err
+	}
+	if len(invitation.Invited) == 0 {
+		return nil
+	}
+
+	content, err := entity.NewMessage(membershipID, invitation.Channel.Name)
+	if err != nil {
+		log.Error("Failed to create message", log.Ferror(err))
+		return err
+	}
+	msg, err := entity.NewWSMessage(entity.InviteToChannelAction, *content, channelID, client.ID)
+	if err != nil {
+		log.Error("Failed to create message", log.Ferror(err))
+		return err
+	}
+	msg.MembershipIDs = invitation.Invited
+	client.hub.Invite(ctx, invitation.Channel, invitation.Members, msg.Encode())
+	return nil
+}
+
+// handleOpenDirectMessage は、自分とMembershipIDsのメンバーのDMを開きます。
+// 新しく作成した場合は参加者全員のクライアントをチャンネルに参加させ、既に存在する場合は自分のクライアントにだけ返します。
+func (client *Client) handleOpenDirectMessage(ctx context.Context, message entity.WSMessage) error {
+	membershipID := client.UserID + "_" + client.hub.ID
+
+	channel, created, err := client.hub.channelUseCase.OpenDirectMessage(ctx, membershipID, client.hub.ID, message.MembershipIDs)
+	if err != nil {
+		log.Error("Failed to open direct message", log.Ferror(err))
+		return err
+	}
+
+	content, err := entity.NewMessage(membershipID, channel.Name)
+	if err != nil {
+		log.Error("Failed to create message", log.Ferror(err))
+		return err
+	}
+	msg, err := entity.NewWSMessage(entity.OpenDirectMessageAction, *content, channel.ID, client.ID)
+	if err != nil {
+		log.Error("Failed to create message", log.Ferror(err))
+		return err
+	}
+	msg.MembershipIDs = message.MembershipIDs
+
+	recipients := []string{membershipID}
+	if created {
+		recipients = append(recipients, message.MembershipIDs...)
+	}
+	client.hub.Invite(ctx, *channel, recipients, msg.Encode())
+	return nil
 }
 
-func (client *Client) handleLeavePublicChannel(ctx context.Context, message entity.WSMessage) {
+func (client *Client) handleLeavePublicChannel(ctx context.Context, message entity.WSMessage) error {
 	channelID := message.TargetID
 	membershipID := client.UserID + "_" + client.hub.ID
 	channel := client.hub.FindChannelByID(channelID)
 	if channel == nil {
 		log.Warn("Channel not found", log.Fstring("channelID", channelID))
-		return
+		return usecase.ErrChannelNotFound
 	}
 
 	if err := client.mcuc.DeleteMembershipChannel(ctx, membershipID, channelID); err != nil {
 		log.Error("Failed to delete membership channel", log.Fstring("membershipID", membershipID), log.Fstring("channelID", channelID))
-		return
+		return err
 	}
 
-	for c := range client.hub.clients {
-		if c.UserID == client.UserID {
-			if !c.isInChannel(channel) {
-				delete(c.channels, channel)
-				channel.unregister <- c
-				log.Info("Client unregistered from channel", log.Fstring("clientID", client.ID), log.Fstring("channelID", channel.ID))
-			}
-		}
-	}
+	client.hub.Leave(ctx, client.UserID, channel)
 
 	content, err := entity.NewMessage(membershipID, channel.Name)
 	if err != nil {
 		log.Error("Failed to create message", log.Ferror(err))
-		return
+		return err
 	}
 	msg, err := entity.NewWSMessage(entity.LeavePublicChannelAction, *content, channel.ID, client.ID)
 	if err != nil {
 		log.Error("Failed to create message", log.Ferror(err))
-		return
+		return err
 	}
 	channel.broadcast <- msg
+	return nil
+}
+
+// handleCreateThreadReply は、TargetIDのメッセージへの返信を作成します。
+// 返信はチャンネルの購読に関係なくスレッドのフォロワーに届け、チャンネルには返信数を更新した親メッセージを配信します。
+func (client *Client) handleCreateThreadReply(ctx context.Context, message entity.WSMessage) error {
+	parentID := message.TargetID
+	message.Content.ID = uuid.New().String()
+	message.Content.MembershipID = client.UserID + "_" + client.hub.ID
+	message.Content.CreatedAt = time.Now()
+	message.Content.UpdatedAt = nil
+
+	result, err := client.tuc.CreateReply(ctx, parentID, message.Content)
+	if err != nil {
+		log.Error("Failed to create thread reply", log.Fstring("parentID", parentID), log.Ferror(err))
+		return err
+	}
+
+	message.Content = result.Reply
+	client.hub.Deliver(ctx, result.Followers, message.Encode())
+
+	channelID := result.Parent.ChannelID
+	if channel := client.hub.FindChannelByID(channelID); channel != nil {
+		channel.broadcast <- &entity.WSMessage{
+			Action:   entity.UpdateMessageAction,
+			Content:  result.Parent,
+			TargetID: channelID,
+			SenderID: client.ID,
+		}
+	} else {
+		log.Warn("Channel not found", log.Fstring("channelID", channelID))
+	}
+	return nil
+}
+
+func (client *Client) handleListThread(ctx context.Context, message entity.WSMessage) error {
+	parentID := message.TargetID
+	replies, err := client.tuc.ListThread(ctx, parentID)
+	if err != nil {
+		log.Error("Failed to list thread", log.Fstring("parentID", parentID), log.Ferror(err))
+		return err
+	}
+
+	response := entity.WSMessages{
+		Action:   entity.ListThreadAction,
+		TargetID: parentID,
+		Contents: replies,
+	}
+	client.enqueue(response.Encode())
+	return nil
+}
+
+func (client *Client) handleFollowThread(ctx context.Context, message entity.WSMessage) error {
+	parentID := message.TargetID
+	membershipID := client.UserID + "_" + client.hub.ID
+	if err := client.tuc.FollowThread(ctx, parentID, membershipID); err != nil {
+		log.Error("Failed to follow thread", log.Fstring("parentID", parentID), log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+func (client *Client) handleUnfollowThread(ctx context.Context, message entity.WSMessage) error {
+	parentID := message.TargetID
+	membershipID := client.UserID + "_" + client.hub.ID
+	if err := client.tuc.UnfollowThread(ctx, parentID, membershipID); err != nil {
+		log.Error("Failed to unfollow thread", log.Fstring("parentID", parentID), log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+// handleAddReaction は、Content.IDのメッセージにContent.Textの絵文字でリアクションします。
+// チャンネルには集計し直したリアクションを含むメッセージを配信します。
+func (client *Client) handleAddReaction(ctx context.Context, message entity.WSMessage) error {
+	membershipID := client.UserID + "_" + client.hub.ID
+	updated, err := client.ruc.AddReaction(ctx, message.Content.ID, membershipID, message.Content.Text)
+	if err != nil {
+		log.Error("Failed to add reaction", log.Fstring("msgID", message.Content.ID), log.Ferror(err))
+		return err
+	}
+	client.broadcastReaction(entity.AddReactionAction, *updated)
+	return nil
+}
+
+func (client *Client) handleRemoveReaction(ctx context.Context, message entity.WSMessage) error {
+	membershipID := client.UserID + "_" + client.hub.ID
+	updated, err := client.ruc.RemoveReaction(ctx, message.Content.ID, membershipID, message.Content.Text)
+	if err != nil {
+		log.Error("Failed to remove reaction", log.Fstring("msgID", message.Content.ID), log.Ferror(err))
+		return err
+	}
+	client.broadcastReaction(entity.RemoveReactionAction, *updated)
+	return nil
+}
+
+func (client *Client) broadcastReaction(action string, content entity.Message) {
+	channelID := content.ChannelID
+	if channel := client.hub.FindChannelByID(channelID); channel != nil {
+		log.Info("Broadcasting reaction", log.Fstring("channelID", channelID), log.Fstring("messageID", content.ID))
+		channel.broadcast <- &entity.WSMessage{
+			Action:   action,
+			Content:  content,
+			TargetID: channelID,
+			SenderID: client.ID,
+		}
+	} else {
+		log.Warn("Channel not found", log.Fstring("channelID", channelID))
+	}
 }
 
 func (client *Client) isInChannel(channel *Channel) bool {
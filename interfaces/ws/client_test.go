@@ -0,0 +1,86 @@
+package ws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/usecase"
+	ucmock "github.com/tusmasoma/connectHub-backend/usecase/mock"
+)
+
+func Test_Client_AllowTyping(t *testing.T) {
+	t.Parallel()
+
+	client := &Client{typedAt: make(map[string]time.Time)}
+	now := time.Now()
+
+	patterns := []struct {
+		name      string
+		channelID string
+		at        time.Time
+		want      bool
+	}{
+		{name: "first typing", channelID: "channel", at: now, want: true},
+		{name: "within throttle", channelID: "channel", at: now.Add(config.TypingThrottle - time.Millisecond), want: false},
+		{name: "other channel", channelID: "other", at: now.Add(time.Millisecond), want: true},
+		{name: "after throttle", channelID: "channel", at: now.Add(config.TypingThrottle), want: true},
+		{name: "throttled from last relay", channelID: "channel", at: now.Add(config.TypingThrottle + time.Second), want: false},
+	}
+
+	// 前のケースで記録した時刻に依存するため、順番に実行します。
+	for _, tt := range patterns {
+		if got := client.allowTyping(tt.channelID, tt.at); got != tt.want {
+			t.Errorf("%s: allowTyping() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func Test_Client_HandleCreateMessage_IgnoresServerFields(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	muc := ucmock.NewMockMessageUseCase(ctrl)
+	hub := NewHub("workspace", "workspace", nil, nil, nil, nil, nil, &config.PresenceConfig{})
+	client := NewClient("user", nil, hub, false, OverflowDropOldest, nil, muc, nil, nil, nil, nil)
+
+	forgedAt := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	message := entity.WSMessage{
+		Action:   entity.CreateMessageAction,
+		TargetID: "channel",
+		Content: entity.Message{
+			ID:          "forged-id",
+			ParentID:    "other-thread",
+			Text:        "hello",
+			CreatedAt:   forgedAt,
+			UpdatedAt:   &forgedAt,
+			ReplyCount:  10,
+			LastReplyAt: &forgedAt,
+			Reactions:   []entity.ReactionCount{{Emoji: "👍", Count: 100}},
+		},
+	}
+
+	muc.EXPECT().CreateMessage(gomock.Any(), "channel", gomock.Any(), "", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, got entity.Message, _ string, _ []string) (*usecase.CreatedMessage, error) {
+			if got.ID == "forged-id" || got.MembershipID != "user_workspace" || got.ChannelID != "channel" || got.Text != "hello" {
+				t.Errorf("CreateMessage() got unexpected message %v", got)
+			}
+			if got.ParentID != "" || got.ReplyCount != 0 || got.LastReplyAt != nil || got.Reactions != nil {
+				t.Errorf("CreateMessage() got client supplied thread fields %v", got)
+			}
+			if got.CreatedAt.Equal(forgedAt) || got.UpdatedAt != nil {
+				t.Errorf("CreateMessage() got client supplied timestamps %v", got)
+			}
+			// 配信を行わないよう、再送として扱います。
+			return &usecase.CreatedMessage{MessageID: got.ID, Duplicate: true}, nil
+		},
+	)
+
+	if _, err := client.handleCreateMessage(context.Background(), message); err != nil {
+		t.Fatalf("handleCreateMessage() error = %v", err)
+	}
+}
@@ -2,31 +2,63 @@ package ws
 
 import (
 	"context"
+	"encoding/json"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/entity"
 	"github.com/tusmasoma/connectHub-backend/internal/log"
 	"github.com/tusmasoma/connectHub-backend/repository"
 	"github.com/tusmasoma/connectHub-backend/usecase"
 )
 
 type HubManager struct {
-	hubs map[string]*Hub
-	mu   sync.RWMutex
+	hubs             map[string]*Hub
+	mu               sync.RWMutex
+	workspaceUseCase usecase.WorkspaceUseCase
+	channelUseCase   usecase.ChannelUseCase
+	presenceUseCase  usecase.PresenceUseCase
+	pubsubRepo       repository.PubSubRepository
+	channelEventRepo repository.ChannelEventRepository
+	messageCacheRepo repository.MessageCacheRepository
+	presenceConf     *config.PresenceConfig
 }
 
-func NewHubManager() *HubManager {
+func NewHubManager(
+	workspaceUseCase usecase.WorkspaceUseCase,
+	channelUseCase usecase.ChannelUseCase,
+	presenceUseCase usecase.PresenceUseCase,
+	pubsubRepo repository.PubSubRepository,
+	channelEventRepo repository.ChannelEventRepository,
+	messageCacheRepo repository.MessageCacheRepository,
+	presenceConf *config.PresenceConfig,
+) *HubManager {
 	return &HubManager{
-		hubs: make(map[string]*Hub),
+		hubs:             make(map[string]*Hub),
+		workspaceUseCase: workspaceUseCase,
+		channelUseCase:   channelUseCase,
+		presenceUseCase:  presenceUseCase,
+		pubsubRepo:       pubsubRepo,
+		channelEventRepo: channelEventRepo,
+		messageCacheRepo: messageCacheRepo,
+		presenceConf:     presenceConf,
 	}
 }
 
-func (hm *HubManager) Add(workspaceID string, hub *Hub) {
+// Add は、workspaceIDのHubがまだ登録されていなければhubを登録し、登録されているHubを返します。
+// GetOrLoadと同時に呼び出されても同じワークスペースのHubが2つ使われないよう、既に登録されているHubは置き換えません。
+// 戻り値がhubと異なる場合、hubは使われないため起動しないでください。
+func (hm *HubManager) Add(workspaceID string, hub *Hub) *Hub {
 	hm.mu.Lock()
 	defer hm.mu.Unlock()
+	if existing, exists := hm.hubs[workspaceID]; exists {
+		return existing
+	}
 	hm.hubs[workspaceID] = hub
+	return hub
 }
 
 func (hm *HubManager) Get(workspaceID string) (*Hub, bool) {
@@ -36,39 +68,147 @@ func (hm *HubManager) Get(workspaceID string) (*Hub, bool) {
 	return hub, exists
 }
 
+// Load は、永続化されている全てのワークスペースとそのチャンネルからHubを組み立てて起動します。
+func (hm *HubManager) Load(ctx context.Context) error {
+	workspaces, err := hm.workspaceUseCase.ListWorkspaces(ctx)
+	if err != nil {
+		log.Error("Failed to list workspaces", log.Ferror(err))
+		return err
+	}
+
+	for _, workspace := range workspaces {
+		if _, err = hm.load(ctx, workspace); err != nil {
+			return err
+		}
+	}
+	log.Info("Successfully hubs loaded", log.Fint("count", len(workspaces)))
+	return nil
+}
+
+// GetOrLoad は、まだ読み込まれていないワークスペースであれば永続化された内容からHubを組み立てて起動します。
+// ワークスペースが存在しない場合はusecase.ErrWorkspaceNotFoundを返します。
+func (hm *HubManager) GetOrLoad(ctx context.Context, workspaceID string) (*Hub, error) {
+	if hub, exists := hm.Get(workspaceID); exists {
+		return hub, nil
+	}
+
+	workspace, err := hm.workspaceUseCase.GetWorkspace(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return hm.load(ctx, *workspace)
+}
+
+func (hm *HubManager) load(ctx context.Context, workspace entity.Workspace) (*Hub, error) {
+	channels, err := hm.channelUseCase.ListWorkspaceChannels(ctx, workspace.ID)
+	if err != nil {
+		log.Error("Failed to list workspace channels", log.Fstring("workspaceID", workspace.ID))
+		return nil, err
+	}
+
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+
+	// 同じワークスペースへの接続が同時に来た場合、先に読み込まれたHubを使います。
+	if hub, exists := hm.hubs[workspace.ID]; exists {
+		return hub, nil
+	}
+
+	hub := NewHub(
+		workspace.ID,
+		workspace.Name,
+		hm.channelUseCase,
+		hm.presenceUseCase,
+		hm.pubsubRepo,
+		hm.channelEventRepo,
+		hm.messageCacheRepo,
+		hm.presenceConf,
+	)
+	hub.loadChannels(channels)
+	go hub.Run()
+
+	hm.hubs[workspace.ID] = hub
+	return hub, nil
+}
+
+// Hub は、ワークスペースに接続しているクライアントとチャンネルを管理します。
+// clients、channelsと各クライアントのchannelsはRunのゴルーチンだけが操作します。
 type Hub struct {
 	ID               string
 	Name             string
 	clients          map[*Client]bool
-	channels         map[*Channel]bool
+	channels         map[string]*Channel // 永続化されたチャンネルのIDをキーにします
 	Register         chan *Client
 	unregister       chan *Client
 	broadcast        chan []byte
+	deliver          chan *entity.Delivery
+	commands         chan hubCommand
+	online           map[string]int // membershipIDごとの接続数
+	onlineMu         sync.RWMutex
 	channelUseCase   usecase.ChannelUseCase
+	presenceUseCase  usecase.PresenceUseCase
 	pubsubRepo       repository.PubSubRepository
+	channelEventRepo repository.ChannelEventRepository
 	messageCacheRepo repository.MessageCacheRepository
+	presenceConf     *config.PresenceConfig
 }
 
 // NewWebsocketServer creates a new Hub type
-func NewHub(name string, channelUseCase usecase.ChannelUseCase, pubsubRepo repository.PubSubRepository, messageCacheRepo repository.MessageCacheRepository) *Hub {
+func NewHub(
+	id string,
+	name string,
+	channelUseCase usecase.ChannelUseCase,
+	presenceUseCase usecase.PresenceUseCase,
+	pubsubRepo repository.PubSubRepository,
+	channelEventRepo repository.ChannelEventRepository,
+	messageCacheRepo repository.MessageCacheRepository,
+	presenceConf *config.PresenceConfig,
+) *Hub {
 	return &Hub{
-		ID:               uuid.New().String(),
+		ID:               id,
 		Name:             name,
 		clients:          make(map[*Client]bool),
-		channels:         make(map[*Channel]bool),
+		channels:         make(map[string]*Channel),
 		Register:         make(chan *Client),
 		unregister:       make(chan *Client),
 		broadcast:        make(chan []byte),
+		deliver:          make(chan *entity.Delivery),
+		commands:         make(chan hubCommand),
+		online:           make(map[string]int),
 		channelUseCase:   channelUseCase,
+		presenceUseCase:  presenceUseCase,
 		pubsubRepo:       pubsubRepo,
+		channelEventRepo: channelEventRepo,
 		messageCacheRepo: messageCacheRepo,
+		presenceConf:     presenceConf,
 	}
 }
 
+// loadChannels は、永続化されているチャンネルを起動してHubに登録します。Runの前に呼び出してください。
+func (h *Hub) loadChannels(channels []entity.Channel) {
+	for _, ch := range channels {
+		h.startChannel(ch)
+	}
+}
+
+// startChannel は、永続化済みのチャンネルを起動してHubに登録します。既に起動している場合はそのチャンネルを返します。
+func (h *Hub) startChannel(ch entity.Channel) *Channel {
+	if channel, exists := h.channels[ch.ID]; exists {
+		return channel
+	}
+	channel := NewChannel(ch.ID, ch.Name, ch.Private, h.pubsubRepo, h.channelEventRepo, h.messageCacheRepo)
+	// チャンネルはHubと同じくプロセスが終了するまで動き続けるため、リクエストのコンテキストは使いません。
+	go channel.Run(context.Background())
+	h.channels[channel.ID] = channel
+	return channel
+}
+
 // Run starts the server and listens for incoming messages
 func (h *Hub) Run() {
 	ctx := context.Background()
-	go h.listenPubSubChannel(ctx)
+	go h.listenDeliveries(ctx)
+	go h.listenWorkspaceEvents(ctx)
+	go h.heartbeat(ctx)
 
 	for {
 		select {
@@ -80,30 +220,66 @@ func (h *Hub) Run() {
 
 		case message := <-h.broadcast:
 			h.broadcastToClients(message)
+
+		case delivery := <-h.deliver:
+			h.deliverToClients(delivery)
+
+		case cmd := <-h.commands:
+			cmd.execute(h)
 		}
 	}
 }
 
+// registerClient は、クライアントをHubに登録します。
+// プレゼンスの更新と参加しているチャンネルの取得はHub.Runを止めないよう別のゴルーチンで行い、
+// チャンネルへの登録はjoinClientChannelsCommandでHub.Runのゴルーチンに戻して行います。
 func (h *Hub) registerClient(client *Client) {
-	ctx := context.Background()
-
 	h.clients[client] = true
 
 	membershipID := client.UserID + "_" + h.ID
+	h.onlineMu.Lock()
+	h.online[membershipID]++
+	h.onlineMu.Unlock()
+
+	go h.connectClient(client, membershipID)
+}
+
+// connectClient は、プレゼンスを更新し、クライアントが参加しているチャンネルを取得してHubに登録を依頼します。
+// 取得に失敗した場合も、client.registeredを閉じるために空のチャンネルで依頼します。
+func (h *Hub) connectClient(client *Client, membershipID string) {
+	ctx := context.Background()
+	presence, err := h.presenceUseCase.Connect(ctx, membershipID, client.ID)
+	if err != nil {
+		log.Error("Failed to connect presence", log.Fstring("membershipID", membershipID), log.Ferror(err))
+	}
+	h.announcePresence(ctx, presence)
+
 	channels, err := h.channelUseCase.ListMembershipChannels(ctx, membershipID)
 	if err != nil {
 		log.Error("Failed to list membership channels", log.Fstring("membershipID", membershipID))
-		return
+		channels = nil
 	}
+	h.commands <- &joinClientChannelsCommand{client: client, channels: channels}
+}
 
+// joinClientChannels は、クライアントを参加しているチャンネルに登録します。
+// 再開するクライアントは、RESUMEを受け取るまでチャンネルのイベントの配信を止めておきます。
+// チャンネルの取得中に切断したクライアントは登録しません。
+func (h *Hub) joinClientChannels(client *Client, channels []entity.Channel) {
+	defer close(client.registered)
+
+	if !h.clients[client] {
+		return
+	}
 	for _, ch := range channels {
-		channel := h.FindChannelByID(ch.ID)
-		if channel == nil {
-			log.Warn("Channel not found", log.Fstring("channelID", ch.ID))
+		// 他のサーバーで作成され、このサーバーではまだ起動していないチャンネルもここで起動します。
+		channel := h.startChannel(ch)
+		// チャンネルの取得中にJoinやDeliverで登録されたチャンネルには、重ねて登録しません。
+		if client.isInChannel(channel) {
 			continue
 		}
 		client.channels[channel] = true
-		channel.register <- client
+		channel.join(client, client.resuming)
 	}
 }
 
@@ -112,35 +288,221 @@ func (h *Hub) unregisterClient(client *Client) {
 		channel.unregister <- client
 	}
 	delete(h.clients, client)
+
+	membershipID := client.UserID + "_" + h.ID
+	h.onlineMu.Lock()
+	if h.online[membershipID]--; h.online[membershipID] <= 0 {
+		delete(h.online, membershipID)
+	}
+	h.onlineMu.Unlock()
+
+	go h.disconnectClient(client, membershipID)
+}
+
+// disconnectClient は、接続時のプレゼンスの更新を終えてから、切断をプレゼンスに反映します。
+// 接続時の更新より先に反映すると、切断したクライアントがオンラインのまま残ってしまいます。
+func (h *Hub) disconnectClient(client *Client, membershipID string) {
+	<-client.registered
+
+	ctx := context.Background()
+	presence, err := h.presenceUseCase.Disconnect(ctx, membershipID, client.ID)
+	if err != nil {
+		log.Error("Failed to disconnect presence", log.Fstring("membershipID", membershipID), log.Ferror(err))
+	}
+	h.announcePresence(ctx, presence)
+}
+
+// heartbeat は、このサーバーに接続しているクライアントの接続の期限を定期的に延ばします。
+// サーバーが停止するとハートビートが止まり、その接続は期限が切れてオフラインになります。
+func (h *Hub) heartbeat(ctx context.Context) {
+	ticker := time.NewTicker(h.presenceConf.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Hub.Runを止めないよう、接続の一覧だけを受け取ってこのゴルーチンで更新します。
+			for clientID, membershipID := range h.connections() {
+				presence, err := h.presenceUseCase.Connect(ctx, membershipID, clientID)
+				if err != nil {
+					log.Error("Failed to refresh presence", log.Fstring("membershipID", membershipID), log.Ferror(err))
+					continue
+				}
+				h.announcePresence(ctx, presence)
+			}
+		}
+	}
+}
+
+// SetPresence は、メンバーが設定したプレゼンスを保存し、ワークスペースの全てのクライアントに伝えます。
+func (h *Hub) SetPresence(ctx context.Context, membershipID, status string) error {
+	presence, err := h.presenceUseCase.SetStatus(ctx, membershipID, status)
+	if err != nil {
+		return err
+	}
+	h.announcePresence(ctx, presence)
+	return nil
+}
+
+// announcePresence は、プレゼンスが変わった場合に、他のサーバーを含めてワークスペースの全てのクライアントにPRESENCE_CHANGEDを送ります。
+func (h *Hub) announcePresence(ctx context.Context, presence *entity.Presence) {
+	if presence == nil {
+		return
+	}
+	h.Announce(ctx, entity.NewWSPresenceChanged(*presence).Encode())
+}
+
+// OnlineMembershipIDs は、このサーバーのHubに接続しているメンバーシップのIDを返します。
+func (h *Hub) OnlineMembershipIDs() []string {
+	h.onlineMu.RLock()
+	defer h.onlineMu.RUnlock()
+	membershipIDs := make([]string, 0, len(h.online))
+	for membershipID := range h.online {
+		membershipIDs = append(membershipIDs, membershipID)
+	}
+	return membershipIDs
 }
 
 func (h *Hub) broadcastToClients(message []byte) {
 	for client := range h.clients {
-		client.send <- message
+		client.enqueue(message)
 	}
 }
 
-func (h *Hub) listenPubSubChannel(ctx context.Context) {
-	pubsub := h.pubsubRepo.Subscribe(ctx, config.PubSubGeneralChannel)
-	defer pubsub.Close()
+// Deliver は、チャンネルの購読に関係なく指定したメンバーシップのクライアントにpayloadを送信します。
+// 他のサーバーに接続しているクライアントにも届くよう、PubSubを経由します。
+func (h *Hub) Deliver(ctx context.Context, membershipIDs []string, payload []byte) {
+	delivery, err := entity.NewDelivery(membershipIDs, payload)
+	if err != nil {
+		log.Error("Failed to create delivery", log.Ferror(err))
+		return
+	}
+	h.publishDelivery(ctx, delivery)
+}
 
-	ch := pubsub.Channel()
-	for msg := range ch {
-		h.broadcastToClients([]byte(msg.Payload))
+// Invite は、指定したメンバーシップのクライアントをチャンネルに参加させてからpayloadを送信します。
+// 他のサーバーではチャンネルがまだ起動していない場合があるため、チャンネルの情報も合わせて配信します。
+func (h *Hub) Invite(ctx context.Context, channel entity.Channel, membershipIDs []string, payload []byte) {
+	delivery, err := entity.NewDelivery(membershipIDs, payload)
+	if err != nil {
+		log.Error("Failed to create delivery", log.Ferror(err))
+		return
 	}
+	delivery.Channel = &channel
+	h.publishDelivery(ctx, delivery)
 }
 
-func (h *Hub) FindChannelByID(id string) *Channel {
-	for channel := range h.channels {
-		if channel.ID == id {
-			return channel
+// Announce は、チャンネルの購読に関係なく、他のサーバーを含めてワークスペースの全てのクライアントにpayloadを送信します。
+func (h *Hub) Announce(ctx context.Context, payload []byte) {
+	if err := h.pubsubRepo.Publish(ctx, h.workspaceTopic(), payload); err != nil {
+		log.Error("Failed to publish workspace event", log.Ferror(err))
+	}
+}
+
+func (h *Hub) workspaceTopic() string {
+	return config.PubSubWorkspacePrefix + h.ID
+}
+
+// listenWorkspaceEvents は、ワークスペースのイベントを全てのクライアントに送ります。
+// 他のサーバーで作成されたチャンネルは、クライアントが参加する前に起動しておきます。
+func (h *Hub) listenWorkspaceEvents(ctx context.Context) {
+	subscribe(ctx, h.pubsubRepo, h.workspaceTopic(), func(payload []byte) {
+		var event entity.WSChannelEvent
+		if err := json.Unmarshal(payload, &event); err == nil && event.Action == entity.ChannelCreatedAction {
+			h.startChannelByCommand(event.Channel)
 		}
+		h.broadcast <- payload
+	})
+}
+
+func (h *Hub) publishDelivery(ctx context.Context, delivery *entity.Delivery) {
+	if err := h.pubsubRepo.Publish(ctx, h.deliveryTopic(), delivery.Encode()); err != nil {
+		log.Error("Failed to publish delivery", log.Ferror(err))
 	}
-	return nil
 }
 
+func (h *Hub) deliveryTopic() string {
+	return config.PubSubDeliveryPrefix + h.ID
+}
+
+func (h *Hub) listenDeliveries(ctx context.Context) {
+	subscribe(ctx, h.pubsubRepo, h.deliveryTopic(), func(payload []byte) {
+		var delivery entity.Delivery
+		if err := json.Unmarshal(payload, &delivery); err != nil {
+			log.Error("Failed to unmarshal delivery", log.Ferror(err))
+			return
+		}
+		h.deliver <- &delivery
+	})
+}
+
+func (h *Hub) deliverToClients(delivery *entity.Delivery) {
+	membershipIDs := make(map[string]bool, len(delivery.MembershipIDs))
+	for _, membershipID := range delivery.MembershipIDs {
+		membershipIDs[membershipID] = true
+	}
+	var channel *Channel
+	switch {
+	case delivery.Channel != nil && delivery.Leave:
+		channel = h.findChannelByID(delivery.Channel.ID)
+	case delivery.Channel != nil:
+		channel = h.startChannel(*delivery.Channel)
+	}
+	for client := range h.clients {
+		if !membershipIDs[client.UserID+"_"+h.ID] {
+			continue
+		}
+		switch {
+		case channel == nil:
+		case delivery.Leave && client.isInChannel(channel):
+			delete(client.channels, channel)
+			channel.unregister <- client
+		case !delivery.Leave && !client.isInChannel(channel):
+			client.channels[channel] = true
+			channel.join(client, false)
+		}
+		if len(delivery.Payload) > 0 {
+			client.enqueue(delivery.Payload)
+		}
+	}
+}
+
+// FindChannelByID は、IDのチャンネルを返します。Hub.Runのゴルーチンの外から呼び出してください。
+// 他のサーバーで作成されてまだ起動していないチャンネルは、永続化された内容から起動します。
+func (h *Hub) FindChannelByID(id string) *Channel {
+	cmd := &findChannelCommand{id: id, reply: make(chan *Channel, 1)}
+	h.commands <- cmd
+	if channel := <-cmd.reply; channel != nil {
+		return channel
+	}
+
+	ch, err := h.channelUseCase.GetChannel(context.Background(), id)
+	if err != nil {
+		return nil
+	}
+	// 他のワークスペースのチャンネルは、このHubでは起動しません。
+	if ch.WorkspaceID != h.ID {
+		log.Warn("Channel belongs to another workspace", log.Fstring("channelID", id), log.Fstring("workspaceID", h.ID))
+		return nil
+	}
+	return h.startChannelByCommand(*ch)
+}
+
+// FindChannelByName は、名前が一致するチャンネルを返します。Hub.Runのゴルーチンの外から呼び出してください。
 func (h *Hub) FindChannelByName(name string) *Channel {
-	for channel := range h.channels {
+	cmd := &findChannelCommand{name: name, reply: make(chan *Channel, 1)}
+	h.commands <- cmd
+	return <-cmd.reply
+}
+
+func (h *Hub) findChannelByID(id string) *Channel {
+	return h.channels[id]
+}
+
+func (h *Hub) findChannelByName(name string) *Channel {
+	for _, channel := range h.channels {
 		if channel.Name == name {
 			return channel
 		}
@@ -148,21 +510,83 @@ func (h *Hub) FindChannelByName(name string) *Channel {
 	return nil
 }
 
-func (h *Hub) CreateChannel(ctx context.Context, membershipID, channelName string, channelPrivate bool) *Channel {
-	channel := NewChannel(channelName, channelPrivate, h.pubsubRepo, h.messageCacheRepo)
+// CreateChannel は、チャンネルを永続化してから起動します。
+// 永続化はHub.Runのゴルーチンを止めないよう呼び出し元のゴルーチンで行い、起動だけをコマンドで依頼します。
+func (h *Hub) CreateChannel(ctx context.Context, membershipID, channelName string, channelPrivate bool) (*Channel, error) {
+	ch := entity.Channel{
+		ID:          uuid.New().String(),
+		WorkspaceID: h.ID,
+		Name:        channelName,
+		Private:     channelPrivate,
+	}
 
 	if err := h.channelUseCase.CreateChannel(ctx, usecase.CreateChannelParams{
-		ID:           channel.ID,
+		ID:           ch.ID,
 		MembershipID: membershipID,
 		WorkspaceID:  h.ID,
-		Name:         channel.Name,
-		Private:      channel.Private,
+		Name:         ch.Name,
+		Private:      ch.Private,
 	}); err != nil {
 		log.Error("Failed to create channel", log.Fstring("name", channelName))
-		return nil
+		return nil, err
 	}
 
-	go channel.Run(ctx)
-	h.channels[channel] = true
-	return channel
+	return h.startChannelByCommand(ch), nil
+}
+
+// startChannelByCommand は、永続化済みのチャンネルの起動をHub.Runのゴルーチンに依頼します。
+func (h *Hub) startChannelByCommand(ch entity.Channel) *Channel {
+	cmd := &startChannelCommand{channel: ch, reply: make(chan *Channel, 1)}
+	h.commands <- cmd
+	return <-cmd.reply
+}
+
+// Join は、userIDのこのサーバーの全てのクライアントをチャンネルに登録してから戻り、他のサーバーにも同じ登録を依頼します。
+// 戻った後にチャンネルへ配信したイベントは、このサーバーのuserIDのクライアントには必ず届きます。
+func (h *Hub) Join(ctx context.Context, userID string, channel *Channel) {
+	h.joinChannel(userID, channel)
+	h.publishMembership(ctx, userID, channel, false)
+}
+
+// Leave は、userIDのこのサーバーの全てのクライアントの登録をチャンネルから解除してから戻り、他のサーバーにも同じ解除を依頼します。
+func (h *Hub) Leave(ctx context.Context, userID string, channel *Channel) {
+	h.leaveChannel(userID, channel)
+	h.publishMembership(ctx, userID, channel, true)
+}
+
+func (h *Hub) publishMembership(ctx context.Context, userID string, channel *Channel, leave bool) {
+	ch := entity.Channel{ID: channel.ID, WorkspaceID: h.ID, Name: channel.Name, Private: channel.Private}
+	h.publishDelivery(ctx, entity.NewMembershipDelivery(userID+"_"+h.ID, ch, leave))
+}
+
+// joinChannel は、userIDの全てのクライアントをチャンネルに登録し、Channel.Runが登録を終えてから戻ります。
+// 戻った後にチャンネルへ配信したイベントは、userIDのこのサーバーの全てのクライアントに届きます。
+func (h *Hub) joinChannel(userID string, channel *Channel) {
+	cmd := &joinChannelCommand{userID: userID, channel: channel, reply: make(chan []<-chan struct{}, 1)}
+	h.commands <- cmd
+	// Hub.Runを止めないよう、登録の完了は呼び出し元のゴルーチンで待ちます。
+	for _, done := range <-cmd.reply {
+		<-done
+	}
+}
+
+// leaveChannel は、userIDの全てのクライアントの登録をチャンネルから解除し、解除を終えてから戻ります。
+func (h *Hub) leaveChannel(userID string, channel *Channel) {
+	cmd := &leaveChannelCommand{userID: userID, channel: channel, done: make(chan struct{})}
+	h.commands <- cmd
+	<-cmd.done
+}
+
+// connections は、このサーバーに接続しているクライアントのIDとメンバーシップのIDを返します。
+func (h *Hub) connections() map[string]string {
+	cmd := &connectionsCommand{reply: make(chan map[string]string, 1)}
+	h.commands <- cmd
+	return <-cmd.reply
+}
+
+// clientChannels は、クライアントが参加しているチャンネルを返します。
+func (h *Hub) clientChannels(client *Client) []*Channel {
+	cmd := &clientChannelsCommand{client: client, reply: make(chan []*Channel, 1)}
+	h.commands <- cmd
+	return <-cmd.reply
 }
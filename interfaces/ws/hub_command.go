@@ -0,0 +1,112 @@
+package ws
+
+import (
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+)
+
+// hubCommand は、Hubの状態を操作するコマンドです。
+// Hubのクライアントとチャンネル、各クライアントが参加しているチャンネルはHub.Runのゴルーチンだけが読み書きし、
+// 他のゴルーチンはコマンドを送って操作します。結果はコマンドごとのチャンネルで受け取ります。
+type hubCommand interface {
+	execute(h *Hub)
+}
+
+// findChannelCommand は、IDか名前でチャンネルを探します。見つからない場合はnilを返します。
+type findChannelCommand struct {
+	id    string
+	name  string
+	reply chan *Channel
+}
+
+func (cmd *findChannelCommand) execute(h *Hub) {
+	if cmd.id != "" {
+		cmd.reply <- h.findChannelByID(cmd.id)
+		return
+	}
+	cmd.reply <- h.findChannelByName(cmd.name)
+}
+
+// startChannelCommand は、永続化済みのチャンネルを起動してHubに登録します。
+type startChannelCommand struct {
+	channel entity.Channel
+	reply   chan *Channel
+}
+
+func (cmd *startChannelCommand) execute(h *Hub) {
+	cmd.reply <- h.startChannel(cmd.channel)
+}
+
+// joinClientChannelsCommand は、Hub.Runの外で取得したチャンネルに、登録したばかりのクライアントを登録します。
+type joinClientChannelsCommand struct {
+	client   *Client
+	channels []entity.Channel
+}
+
+func (cmd *joinClientChannelsCommand) execute(h *Hub) {
+	h.joinClientChannels(cmd.client, cmd.channels)
+}
+
+// joinChannelCommand は、userIDの全てのクライアントをチャンネルに登録し、それぞれの登録の完了を通知するチャンネルを返します。
+type joinChannelCommand struct {
+	userID  string
+	channel *Channel
+	reply   chan []<-chan struct{}
+}
+
+func (cmd *joinChannelCommand) execute(h *Hub) {
+	var registrations []<-chan struct{}
+	for client := range h.clients {
+		if client.UserID == cmd.userID && !client.isInChannel(cmd.channel) {
+			client.channels[cmd.channel] = true
+			registrations = append(registrations, cmd.channel.join(client, false))
+			log.Info("Client registered to channel", log.Fstring("clientID", client.ID), log.Fstring("channelID", cmd.channel.ID))
+		}
+	}
+	cmd.reply <- registrations
+}
+
+// leaveChannelCommand は、userIDの全てのクライアントの登録をチャンネルから解除します。
+type leaveChannelCommand struct {
+	userID  string
+	channel *Channel
+	done    chan struct{}
+}
+
+func (cmd *leaveChannelCommand) execute(h *Hub) {
+	defer close(cmd.done)
+	for client := range h.clients {
+		if client.UserID == cmd.userID && client.isInChannel(cmd.channel) {
+			delete(client.channels, cmd.channel)
+			cmd.channel.unregister <- client
+			log.Info("Client unregistered from channel", log.Fstring("clientID", client.ID), log.Fstring("channelID", cmd.channel.ID))
+		}
+	}
+}
+
+// clientChannelsCommand は、クライアントが参加しているチャンネルを返します。
+type clientChannelsCommand struct {
+	client *Client
+	reply  chan []*Channel
+}
+
+func (cmd *clientChannelsCommand) execute(h *Hub) {
+	channels := make([]*Channel, 0, len(cmd.client.channels))
+	for channel := range cmd.client.channels {
+		channels = append(channels, channel)
+	}
+	cmd.reply <- channels
+}
+
+// connectionsCommand は、Hubに接続しているクライアントのIDとメンバーシップのIDを返します。
+type connectionsCommand struct {
+	reply chan map[string]string
+}
+
+func (cmd *connectionsCommand) execute(h *Hub) {
+	connections := make(map[string]string, len(h.clients))
+	for client := range h.clients {
+		connections[client.ID] = client.UserID + "_" + h.ID
+	}
+	cmd.reply <- connections
+}
@@ -0,0 +1,212 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/ory/dockertest"
+
+	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository/redis"
+	"github.com/tusmasoma/connectHub-backend/usecase"
+	ucmock "github.com/tusmasoma/connectHub-backend/usecase/mock"
+)
+
+// startRedis は、Dockerを使用してRedisコンテナを起動し、接続したクライアントを返します。
+// Dockerを使えない環境ではテストをスキップします。
+func startRedis(t *testing.T) *goredis.Client {
+	t.Helper()
+
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		t.Skipf("Could not construct pool: %s", err)
+	}
+	if err = pool.Client.Ping(); err != nil {
+		t.Skipf("Could not connect to Docker: %s", err)
+	}
+
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{Repository: "redis", Tag: "5.0"})
+	if err != nil {
+		t.Fatalf("Could not start Redis resource: %s", err)
+	}
+	t.Cleanup(func() {
+		if err := pool.Purge(resource); err != nil {
+			t.Errorf("Failed to purge resource: %s", err)
+		}
+	})
+
+	var client *goredis.Client
+	err = pool.Retry(func() error {
+		client = goredis.NewClient(&goredis.Options{Addr: fmt.Sprintf("localhost:%s", resource.GetPort("6379/tcp"))})
+		return client.Ping(context.Background()).Err()
+	})
+	if err != nil {
+		t.Fatalf("Could not connect to Redis container: %s", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// waitSubscribers は、topicを購読しているサーバーがn台になるまで待ちます。
+func waitSubscribers(t *testing.T, client *goredis.Client, topic string, n int64) {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		counts, err := client.PubSubNumSub(context.Background(), topic).Result()
+		if err != nil {
+			t.Fatalf("PubSubNumSub() error = %v", err)
+		}
+		if counts[topic] >= n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("topic %s has fewer than %d subscribers", topic, n)
+}
+
+// waitFrame は、matchに一致するフレームが届くまで、クライアントに届いたフレームを読み進めます。
+func waitFrame(t *testing.T, client *Client, match func(payload []byte) bool) {
+	t.Helper()
+
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case payload := <-client.send:
+			if match(payload) {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("client %s of user %s did not receive the expected frame", client.ID, client.UserID)
+		}
+	}
+}
+
+// waitChannels は、クライアントが参加しているチャンネルの数がnになるまで待ちます。
+func waitChannels(t *testing.T, client *Client, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if len(client.hub.clientChannels(client)) == n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("client of user %s is not in %d channels", client.UserID, n)
+}
+
+// Test_HubManager_MultiNode は、同じRedisを使う2台のサーバーの間で、チャンネルの作成、参加と退出、
+// チャンネルへの配信とメンバーシップへの配信が届くことを確認します。
+func Test_HubManager_MultiNode(t *testing.T) {
+	client := startRedis(t)
+	ctx := context.Background()
+
+	workspace := entity.Workspace{ID: "workspace", Name: "workspace"}
+	var created entity.Channel
+
+	ctrl := gomock.NewController(t)
+	wuc := ucmock.NewMockWorkspaceUseCase(ctrl)
+	wuc.EXPECT().GetWorkspace(gomock.Any(), workspace.ID).Return(&workspace, nil).AnyTimes()
+	cuc := ucmock.NewMockChannelUseCase(ctrl)
+	cuc.EXPECT().ListWorkspaceChannels(gomock.Any(), workspace.ID).Return(nil, nil).AnyTimes()
+	cuc.EXPECT().ListMembershipChannels(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	cuc.EXPECT().CreateChannel(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, params usecase.CreateChannelParams) error {
+			created = entity.Channel{ID: params.ID, WorkspaceID: params.WorkspaceID, Name: params.Name, Private: params.Private}
+			return nil
+		},
+	)
+	cuc.EXPECT().GetChannel(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string) (*entity.Channel, error) {
+			return &created, nil
+		},
+	).AnyTimes()
+
+	psr := redis.NewPubSubRepository(client)
+	cer := redis.NewChannelEventRepository(client)
+	conf := &config.PresenceConfig{TTL: time.Minute, HeartbeatInterval: time.Second}
+	puc := usecase.NewPresenceUseCase(redis.NewPresenceRepository(client), nil, conf)
+	var hubs [2]*Hub
+	for i := range hubs {
+		hub, err := NewHubManager(wuc, cuc, puc, psr, cer, nil, conf).GetOrLoad(ctx, workspace.ID)
+		if err != nil {
+			t.Fatalf("GetOrLoad() error = %v", err)
+		}
+		hubs[i] = hub
+	}
+	waitSubscribers(t, client, config.PubSubWorkspacePrefix+workspace.ID, 2)
+	waitSubscribers(t, client, config.PubSubDeliveryPrefix+workspace.ID, 2)
+
+	connect := func(hub *Hub, userID string) *Client {
+		c := NewClient(userID, nil, hub, false, OverflowDropOldest, psr, nil, nil, nil, nil, nil)
+		hub.Register <- c
+		<-c.registered
+		return c
+	}
+	aliceA := connect(hubs[0], "alice")
+	aliceB := connect(hubs[1], "alice")
+	bobB := connect(hubs[1], "bob")
+
+	// サーバーBでの接続は、サーバーAのクライアントにもプレゼンスの変化として届きます。
+	waitFrame(t, aliceA, func(payload []byte) bool {
+		var event entity.WSPresenceEvent
+		return json.Unmarshal(payload, &event) == nil && event.Action == entity.PresenceChangedAction &&
+			event.Presence.MembershipID == "bob_"+workspace.ID && event.Presence.Status == entity.PresenceOnline
+	})
+
+	// サーバーAで作成したチャンネルは、作成の通知でサーバーBでも起動します。
+	channelA, err := hubs[0].CreateChannel(ctx, "alice_"+workspace.ID, "general", false)
+	if err != nil {
+		t.Fatalf("CreateChannel() error = %v", err)
+	}
+	hubs[0].Join(ctx, "alice", channelA)
+	hubs[0].Announce(ctx, entity.NewWSChannelCreated(created, aliceA.ID).Encode())
+
+	isChannelCreated := func(payload []byte) bool {
+		var event entity.WSChannelEvent
+		return json.Unmarshal(payload, &event) == nil && event.Action == entity.ChannelCreatedAction && event.Channel.ID == channelA.ID
+	}
+	waitFrame(t, bobB, isChannelCreated)
+
+	channelB := hubs[1].FindChannelByID(channelA.ID)
+	if channelB == nil {
+		t.Fatalf("channel %s is not running on the other node", channelA.ID)
+	}
+	hubs[1].Join(ctx, "bob", channelB)
+	// サーバーAでの参加は、サーバーBのaliceのクライアントにも反映されます。
+	waitChannels(t, aliceB, 1)
+	waitSubscribers(t, client, config.PubSubChannelPrefix+channelA.ID, 2)
+
+	channelA.broadcast <- &entity.WSMessage{
+		Action:   entity.CreateMessageAction,
+		Content:  entity.Message{ID: "message", Text: "hello"},
+		TargetID: channelA.ID,
+		SenderID: aliceA.ID,
+	}
+	isHello := func(payload []byte) bool {
+		var message entity.WSMessage
+		return json.Unmarshal(payload, &message) == nil && message.Content.ID == "message" && message.Seq == 1
+	}
+	for _, c := range []*Client{aliceA, aliceB, bobB} {
+		waitFrame(t, c, isHello)
+	}
+
+	hubs[0].Deliver(ctx, []string{"bob_" + workspace.ID}, []byte(`{"action_tag":"NOTIFICATION"}`))
+	waitFrame(t, bobB, func(payload []byte) bool {
+		return string(payload) == `{"action_tag":"NOTIFICATION"}`
+	})
+
+	// サーバーAでの退出は、サーバーBのaliceのクライアントにも反映されます。
+	hubs[0].Leave(ctx, "alice", channelA)
+	waitChannels(t, aliceB, 0)
+	if channels := hubs[1].clientChannels(bobB); len(channels) != 1 {
+		t.Errorf("bob is in %d channels after alice left, want 1", len(channels))
+	}
+}
@@ -0,0 +1,301 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository/memory"
+	ucmock "github.com/tusmasoma/connectHub-backend/usecase/mock"
+)
+
+// countClientsCommand は、Hubに登録されているクライアントと、それらが参加しているチャンネルの数を返します。
+type countClientsCommand struct {
+	reply chan [2]int
+}
+
+func (cmd *countClientsCommand) execute(h *Hub) {
+	var channels int
+	for client := range h.clients {
+		channels += len(client.channels)
+	}
+	cmd.reply <- [2]int{len(h.clients), channels}
+}
+
+func countClients(h *Hub) (int, int) {
+	cmd := &countClientsCommand{reply: make(chan [2]int, 1)}
+	h.commands <- cmd
+	counts := <-cmd.reply
+	return counts[0], counts[1]
+}
+
+// Test_Hub_ConcurrentClients は、多数のクライアントが同時に接続、チャンネルへの参加と退出、切断を行っても
+// Hubの状態が壊れないことを確認します。go test -raceで実行してください。
+func Test_Hub_ConcurrentClients(t *testing.T) {
+	t.Parallel()
+
+	const (
+		users          = 20
+		clientsPerUser = 3
+		iterations     = 20
+	)
+
+	ctrl := gomock.NewController(t)
+	psr := memory.NewPubSubRepository()
+	cer := memory.NewChannelEventRepository(psr)
+	cuc := ucmock.NewMockChannelUseCase(ctrl)
+
+	var channels []entity.Channel
+	for i := 0; i < 5; i++ {
+		channels = append(channels, entity.Channel{ID: fmt.Sprintf("channel-%d", i), Name: fmt.Sprintf("channel %d", i)})
+	}
+	cuc.EXPECT().ListMembershipChannels(gomock.Any(), gomock.Any()).Return(channels[:2], nil).AnyTimes()
+	cuc.EXPECT().CreateChannel(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	puc := ucmock.NewMockPresenceUseCase(ctrl)
+	puc.EXPECT().Connect(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	puc.EXPECT().Disconnect(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	conf := &config.PresenceConfig{TTL: time.Minute, HeartbeatInterval: time.Second}
+
+	hub := NewHub("workspace", "workspace", cuc, puc, psr, cer, nil, conf)
+	hub.loadChannels(channels)
+	go hub.Run()
+
+	var wg sync.WaitGroup
+	for u := 0; u < users; u++ {
+		userID := fmt.Sprintf("user-%d", u)
+		for c := 0; c < clientsPerUser; c++ {
+			wg.Add(1)
+			go func(c int) {
+				defer wg.Done()
+
+				client := NewClient(userID, nil, hub, c%2 == 0, OverflowDropOldest, psr, nil, nil, nil, nil, nil)
+				hub.Register <- client
+				<-client.registered
+				if client.resuming {
+					client.handleResume(entity.WSMessage{Action: entity.ResumeAction})
+				}
+
+				for i := 0; i < iterations; i++ {
+					channel := hub.FindChannelByID(channels[(c+i)%len(channels)].ID)
+					if channel == nil {
+						t.Errorf("channel %s not found", channels[(c+i)%len(channels)].ID)
+						return
+					}
+					hub.joinChannel(userID, channel)
+					channel.broadcast <- &entity.WSMessage{Action: entity.CreateMessageAction, TargetID: channel.ID}
+					_ = hub.clientChannels(client)
+					_ = hub.OnlineMembershipIDs()
+					hub.deliver <- &entity.Delivery{
+						MembershipIDs: []string{userID + "_" + hub.ID},
+						Payload:       []byte(`{"action_tag":"NOTIFICATION"}`),
+						Channel:       &entity.Channel{ID: fmt.Sprintf("dm-%s-%d", userID, i), Private: true, Direct: true},
+					}
+					if i%5 == 0 {
+						if _, err := hub.CreateChannel(context.Background(), userID+"_"+hub.ID, fmt.Sprintf("%s-%d-%d", userID, c, i), false); err != nil {
+							t.Errorf("CreateChannel() error = %v", err)
+						}
+					}
+					hub.leaveChannel(userID, channel)
+				}
+
+				hub.unregister <- client
+			}(c)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatalf("clients did not finish")
+	}
+
+	if clients, joined := countClients(hub); clients != 0 || joined != 0 {
+		t.Errorf("hub has %d clients in %d channels after all clients left, want 0", clients, joined)
+	}
+	if online := hub.OnlineMembershipIDs(); len(online) != 0 {
+		t.Errorf("OnlineMembershipIDs() = %v, want empty", online)
+	}
+}
+
+// Test_Hub_RegisterDoesNotBlockRun は、プレゼンスの更新やチャンネルの取得が遅くても、
+// Hub.Runが他のコマンドを処理し続け、切断が接続の後にプレゼンスへ反映されることを確認します。
+func Test_Hub_RegisterDoesNotBlockRun(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	psr := memory.NewPubSubRepository()
+	cer := memory.NewChannelEventRepository(psr)
+	channels := []entity.Channel{{ID: "channel-0", Name: "channel 0"}, {ID: "channel-1", Name: "channel 1"}}
+	cuc := ucmock.NewMockChannelUseCase(ctrl)
+	cuc.EXPECT().ListMembershipChannels(gomock.Any(), "user_workspace").Return(channels, nil)
+	puc := ucmock.NewMockPresenceUseCase(ctrl)
+	unblock := make(chan struct{})
+	connected := make(chan struct{})
+	disconnected := make(chan struct{})
+	gomock.InOrder(
+		puc.EXPECT().Connect(gomock.Any(), "user_workspace", gomock.Any()).DoAndReturn(
+			func(context.Context, string, string) (*entity.Presence, error) {
+				<-unblock
+				close(connected)
+				return nil, nil
+			},
+		),
+		puc.EXPECT().Disconnect(gomock.Any(), "user_workspace", gomock.Any()).DoAndReturn(
+			func(context.Context, string, string) (*entity.Presence, error) {
+				select {
+				case <-connected:
+				default:
+					t.Errorf("Disconnect() was called before Connect() returned")
+				}
+				close(disconnected)
+				return nil, nil
+			},
+		),
+	)
+	conf := &config.PresenceConfig{TTL: time.Minute, HeartbeatInterval: time.Hour}
+
+	hub := NewHub("workspace", "workspace", cuc, puc, psr, cer, nil, conf)
+	go hub.Run()
+
+	client := NewClient("user", nil, hub, false, OverflowDropOldest, psr, nil, nil, nil, nil, nil)
+	hub.Register <- client
+
+	// Connectが戻らない間も、Hub.Runはコマンドを処理します。
+	counted := make(chan [2]int, 1)
+	go func() {
+		clients, joined := countClients(hub)
+		counted <- [2]int{clients, joined}
+	}()
+	select {
+	case got := <-counted:
+		if got != [2]int{1, 0} {
+			t.Errorf("countClients() = %v, want [1 0] while presence is being updated", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Hub.Run is blocked by presence update")
+	}
+
+	close(unblock)
+	<-client.registered
+	if clients, joined := countClients(hub); clients != 1 || joined != len(channels) {
+		t.Errorf("countClients() = %d, %d, want 1, %d", clients, joined, len(channels))
+	}
+
+	hub.unregister <- client
+	if clients, joined := countClients(hub); clients != 0 || joined != 0 {
+		t.Errorf("countClients() = %d, %d, want 0, 0", clients, joined)
+	}
+	select {
+	case <-disconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("presence was not disconnected")
+	}
+}
+
+// Test_Hub_UnregisterBeforeChannelsLoaded は、チャンネルの取得中に切断したクライアントが
+// チャンネルに登録されず、プレゼンスも接続の後に切断されることを確認します。
+func Test_Hub_UnregisterBeforeChannelsLoaded(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	psr := memory.NewPubSubRepository()
+	cer := memory.NewChannelEventRepository(psr)
+	channels := []entity.Channel{{ID: "channel-0", Name: "channel 0"}}
+	unblock := make(chan struct{})
+	cuc := ucmock.NewMockChannelUseCase(ctrl)
+	cuc.EXPECT().ListMembershipChannels(gomock.Any(), "user_workspace").DoAndReturn(
+		func(context.Context, string) ([]entity.Channel, error) {
+			<-unblock
+			return channels, nil
+		},
+	)
+	puc := ucmock.NewMockPresenceUseCase(ctrl)
+	disconnected := make(chan struct{})
+	gomock.InOrder(
+		puc.EXPECT().Connect(gomock.Any(), "user_workspace", gomock.Any()).Return(nil, nil),
+		puc.EXPECT().Disconnect(gomock.Any(), "user_workspace", gomock.Any()).DoAndReturn(
+			func(context.Context, string, string) (*entity.Presence, error) {
+				close(disconnected)
+				return nil, nil
+			},
+		),
+	)
+	conf := &config.PresenceConfig{TTL: time.Minute, HeartbeatInterval: time.Hour}
+
+	hub := NewHub("workspace", "workspace", cuc, puc, psr, cer, nil, conf)
+	go hub.Run()
+
+	client := NewClient("user", nil, hub, false, OverflowDropOldest, psr, nil, nil, nil, nil, nil)
+	hub.Register <- client
+	hub.unregister <- client
+	close(unblock)
+
+	select {
+	case <-disconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("presence was not disconnected")
+	}
+	<-client.registered
+	if clients, joined := countClients(hub); clients != 0 || joined != 0 {
+		t.Errorf("countClients() = %d, %d, want 0, 0", clients, joined)
+	}
+}
+
+// Test_HubManager_AddDoesNotReplace は、AddとGetOrLoadが同時に呼び出されても、
+// 同じワークスペースのHubが1つだけ使われることを確認します。
+func Test_HubManager_AddDoesNotReplace(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	psr := memory.NewPubSubRepository()
+	cer := memory.NewChannelEventRepository(psr)
+	wuc := ucmock.NewMockWorkspaceUseCase(ctrl)
+	wuc.EXPECT().GetWorkspace(gomock.Any(), "workspace").Return(&entity.Workspace{ID: "workspace", Name: "workspace"}, nil).AnyTimes()
+	cuc := ucmock.NewMockChannelUseCase(ctrl)
+	cuc.EXPECT().ListWorkspaceChannels(gomock.Any(), "workspace").Return(nil, nil).AnyTimes()
+	puc := ucmock.NewMockPresenceUseCase(ctrl)
+	conf := &config.PresenceConfig{TTL: time.Minute, HeartbeatInterval: time.Hour}
+
+	hm := NewHubManager(wuc, cuc, puc, psr, cer, nil, conf)
+
+	const callers = 20
+	hubs := make([]*Hub, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				hubs[i] = hm.Add("workspace", NewHub("workspace", "workspace", cuc, puc, psr, cer, nil, conf))
+				return
+			}
+			hub, err := hm.GetOrLoad(context.Background(), "workspace")
+			if err != nil {
+				t.Errorf("GetOrLoad() error = %v", err)
+			}
+			hubs[i] = hub
+		}(i)
+	}
+	wg.Wait()
+
+	registered, ok := hm.Get("workspace")
+	if !ok {
+		t.Fatalf("hub is not registered")
+	}
+	for i, hub := range hubs {
+		if hub != registered {
+			t.Errorf("caller %d got hub %p, want registered hub %p", i, hub, registered)
+		}
+	}
+}
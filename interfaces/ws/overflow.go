@@ -0,0 +1,170 @@
+package ws
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+)
+
+// OverflowPolicy は、クライアントの送信バッファが一杯のときにフレームをどう扱うかを表します。
+// 送信はブロックしないため、読み込みの遅いクライアントがいてもHubやChannelのゴルーチンは止まりません。
+type OverflowPolicy string
+
+const (
+	// OverflowDropOldest は、最も古いフレームを捨てて新しいフレームを追加します。
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+	// OverflowDisconnect は、接続を切断します。クライアントは再接続してRESUMEで取りこぼしを受け取ります。
+	OverflowDisconnect OverflowPolicy = "disconnect"
+	// OverflowCoalesce は、溜まっているチャンネルのイベントを捨てて1つのRESYNCにまとめます。
+	// クライアントはRESUMEで参加している全てのチャンネルの取りこぼしを受け取ります。
+	// ACKやERROR、通知などRESUMEで受け取り直せないフレームは捨てずに順序を保って送ります。
+	OverflowCoalesce OverflowPolicy = "coalesce"
+)
+
+// ParseOverflowPolicy は、設定やクエリパラメータの値をOverflowPolicyに変換します。
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch policy := OverflowPolicy(s); policy {
+	case OverflowDropOldest, OverflowDisconnect, OverflowCoalesce:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid overflow policy: %s", s)
+	}
+}
+
+// metrics は、送信バッファが一杯で失われたフレームを数えます。管理用のポートの/debug/varsの"websocket"で参照できます。
+var metrics = expvar.NewMap("websocket")
+
+const (
+	metricDroppedFrames           = "dropped_frames"
+	metricCoalescedFrames         = "coalesced_frames"
+	metricSlowConsumerDisconnects = "slow_consumer_disconnects"
+)
+
+// enqueue は、payloadを送信バッファに追加します。バッファが一杯の場合はOverflowPolicyに従い、ブロックしません。
+// 切断済みのクライアントへのフレームは捨てます。
+func (client *Client) enqueue(payload []byte) {
+	client.sendMu.Lock()
+	defer client.sendMu.Unlock()
+
+	if client.closed {
+		return
+	}
+	select {
+	case client.send <- payload:
+		return
+	default:
+	}
+
+	switch client.overflow {
+	case OverflowDisconnect:
+		metrics.Add(metricDroppedFrames, 1)
+		client.disconnectSlow()
+
+	case OverflowCoalesce:
+		client.coalesce(payload)
+
+	default:
+		select {
+		case <-client.send:
+		default:
+		}
+		metrics.Add(metricDroppedFrames, 1)
+		select {
+		case client.send <- payload:
+		default:
+			metrics.Add(metricDroppedFrames, 1)
+		}
+	}
+}
+
+// disconnectSlow は、送信バッファが溢れたクライアントの接続を一度だけ閉じます。
+func (client *Client) disconnectSlow() {
+	client.overflowOnce.Do(func() {
+		metrics.Add(metricSlowConsumerDisconnects, 1)
+		log.Warn("Disconnecting slow client", log.Fstring("clientID", client.ID))
+		// 接続を閉じるとReadPumpが終了し、通常の切断と同じくHubから登録が解除されます。
+		if err := client.conn.Close(); err != nil {
+			log.Warn("Failed to close connection", log.Ferror(err))
+		}
+	})
+}
+
+// coalesce は、送信バッファとpayloadのうちRESUMEで受け取り直せるチャンネルのイベントを捨て、1つのRESYNCにまとめます。
+// それ以外のフレームは順序を保って送信バッファに戻し、それでも収まらない場合は接続を閉じます。
+// sendMuを取得した状態で呼び出してください。
+func (client *Client) coalesce(payload []byte) {
+	backlog := make([][]byte, 0, cap(client.send)+1)
+	for drained := false; !drained; {
+		select {
+		case frame := <-client.send:
+			backlog = append(backlog, frame)
+		default:
+			drained = true
+		}
+	}
+	backlog = append(backlog, payload)
+
+	var coalesced int64
+	var resyncing bool
+	kept := make([][]byte, 0, len(backlog)+1)
+	for _, frame := range backlog {
+		switch classifyFrame(frame) {
+		case frameChannelEvent:
+			coalesced++
+			continue
+		case frameResync:
+			resyncing = true
+		}
+		kept = append(kept, frame)
+	}
+	// 既にRESYNCを送る予定であれば、そのRESUMEで捨てたイベントも受け取れます。
+	if coalesced > 0 && !resyncing {
+		resync := &entity.WSMessage{Action: entity.ResyncAction}
+		kept = append(kept, resync.Encode())
+	}
+
+	if len(kept) > cap(client.send) {
+		// RESUMEで受け取り直せないフレームだけで溢れた場合は、再接続に任せます。
+		metrics.Add(metricDroppedFrames, int64(len(kept)))
+		client.disconnectSlow()
+		return
+	}
+	metrics.Add(metricCoalescedFrames, coalesced)
+	for _, frame := range kept {
+		client.send <- frame
+	}
+}
+
+type frameKind int
+
+const (
+	frameOther        frameKind = iota
+	frameChannelEvent           // 連番が付いたチャンネルのイベント。RESUMEで受け取り直せます
+	frameResync                 // 送信バッファが溢れたことを伝える、全てのチャンネルのRESYNC
+)
+
+// classifyFrame は、送信バッファが溢れたときに捨ててよいフレームかどうかを判定します。
+func classifyFrame(frame []byte) frameKind {
+	var header struct {
+		Action   string `json:"action_tag"`
+		TargetID string `json:"target_id"`
+		Seq      int64  `json:"seq"`
+	}
+	if err := json.Unmarshal(frame, &header); err != nil {
+		return frameOther
+	}
+	switch {
+	case header.Action == entity.ResyncAction && header.TargetID == "":
+		return frameResync
+	case header.Action == entity.ResyncAction:
+		// チャンネルごとのRESYNCは連番を含みますが、RESUMEの結果なので捨てません。
+		return frameOther
+	case header.Seq > 0:
+		return frameChannelEvent
+	default:
+		return frameOther
+	}
+}
@@ -0,0 +1,300 @@
+package ws
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/entity"
+)
+
+// newTestConn は、サーバー側の接続と、それに繋がったクライアント側の接続を返します。
+func newTestConn(t *testing.T) (*websocket.Conn, *websocket.Conn) {
+	t.Helper()
+
+	conns := make(chan *websocket.Conn, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Failed to upgrade connection: %v", err)
+			return
+		}
+		conns <- conn
+	}))
+	t.Cleanup(server.Close)
+
+	peer, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { peer.Close() })
+
+	conn := <-conns
+	t.Cleanup(func() { conn.Close() })
+	return conn, peer
+}
+
+func newTestClient(t *testing.T, overflow OverflowPolicy) (*Client, *websocket.Conn) {
+	t.Helper()
+
+	conn, peer := newTestConn(t)
+	return &Client{
+		ID:       fmt.Sprintf("client-%p", conn),
+		conn:     conn,
+		channels: make(map[*Channel]bool),
+		send:     make(chan []byte, config.ChannelBufferSize),
+		overflow: overflow,
+	}, peer
+}
+
+func metricValue(key string) int64 {
+	if v, ok := metrics.Get(key).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+// frame は、i番目に配信したチャンネルのイベントです。連番は1から始まります。
+func frame(i int) []byte {
+	return []byte(fmt.Sprintf(`{"action_tag":"CREATE_MESSAGE","seq":%d}`, i+1))
+}
+
+// メトリクスの増分を比較するため、並列には実行しません。
+func Test_Client_enqueue(t *testing.T) {
+	patterns := []struct {
+		name     string
+		overflow OverflowPolicy
+		frames   int
+		check    func(t *testing.T, client *Client, peer *websocket.Conn)
+		metrics  map[string]int64
+	}{
+		{
+			name:     "drop oldest keeps the newest frames",
+			overflow: OverflowDropOldest,
+			frames:   config.ChannelBufferSize + 10,
+			check: func(t *testing.T, client *Client, _ *websocket.Conn) {
+				t.Helper()
+				if len(client.send) != config.ChannelBufferSize {
+					t.Fatalf("len(send) = %d, want %d", len(client.send), config.ChannelBufferSize)
+				}
+				if got := string(<-client.send); got != string(frame(10)) {
+					t.Errorf("oldest frame = %s, want %s", got, frame(10))
+				}
+			},
+			metrics: map[string]int64{metricDroppedFrames: 10},
+		},
+		{
+			name:     "coalesce replaces the backlog with a resync",
+			overflow: OverflowCoalesce,
+			frames:   config.ChannelBufferSize + 1,
+			check: func(t *testing.T, client *Client, _ *websocket.Conn) {
+				t.Helper()
+				if len(client.send) != 1 {
+					t.Fatalf("len(send) = %d, want 1", len(client.send))
+				}
+				var message entity.WSMessage
+				if err := json.Unmarshal(<-client.send, &message); err != nil {
+					t.Fatalf("Failed to unmarshal frame: %v", err)
+				}
+				if message.Action != entity.ResyncAction || message.TargetID != "" {
+					t.Errorf("frame = %+v, want RESYNC for all channels", message)
+				}
+			},
+			metrics: map[string]int64{metricCoalescedFrames: int64(config.ChannelBufferSize) + 1},
+		},
+		{
+			name:     "coalesce keeps acks and notifications in order",
+			overflow: OverflowCoalesce,
+			frames:   0,
+			check: func(t *testing.T, client *Client, _ *websocket.Conn) {
+				t.Helper()
+				ack := entity.NewWSAck("request-1", entity.CreateMessageAction).Encode()
+				notification := []byte(`{"action_tag":"NOTIFICATION"}`)
+				channelResync := (&entity.WSMessage{Action: entity.ResyncAction, TargetID: "channel", Seq: 3}).Encode()
+				client.enqueue(frame(0))
+				client.enqueue(ack)
+				client.enqueue(channelResync)
+				for i := 1; len(client.send) < config.ChannelBufferSize-1; i++ {
+					client.enqueue(frame(i))
+				}
+				client.enqueue(notification)
+				// 送信バッファが一杯の状態で、さらにエラーを送ります。
+				errFrame := entity.NewWSError("request-2", entity.CreateMessageAction, entity.ErrorCodeInternal, "failed").Encode()
+				client.enqueue(errFrame)
+
+				var got []string
+				for len(client.send) > 0 {
+					got = append(got, string(<-client.send))
+				}
+				resync := (&entity.WSMessage{Action: entity.ResyncAction}).Encode()
+				want := []string{string(ack), string(channelResync), string(notification), string(errFrame), string(resync)}
+				if strings.Join(got, "\n") != strings.Join(want, "\n") {
+					t.Errorf("frames = %v, want %v", got, want)
+				}
+
+				// RESYNCが残っている間は、重ねてRESYNCを送りません。
+				for overflows := 0; overflows < 2; overflows++ {
+					for i := 0; len(client.send) < config.ChannelBufferSize; i++ {
+						client.enqueue(frame(i))
+					}
+					client.enqueue(frame(0))
+				}
+				var resyncs int
+				for len(client.send) > 0 {
+					var message entity.WSMessage
+					if err := json.Unmarshal(<-client.send, &message); err != nil {
+						t.Fatalf("Failed to unmarshal frame: %v", err)
+					}
+					if message.Action == entity.ResyncAction {
+						resyncs++
+					}
+				}
+				if resyncs != 1 {
+					t.Errorf("RESYNC frames = %d, want 1", resyncs)
+				}
+			},
+			// 1回目はイベント以外の4つを残し、2回目は全てを、3回目はRESYNC以外を捨てます。
+			metrics: map[string]int64{metricCoalescedFrames: int64(config.ChannelBufferSize)*3 - 2},
+		},
+		{
+			name:     "coalesce disconnects when frames which cannot be resumed overflow",
+			overflow: OverflowCoalesce,
+			frames:   0,
+			check: func(t *testing.T, client *Client, peer *websocket.Conn) {
+				t.Helper()
+				for i := 0; i <= config.ChannelBufferSize; i++ {
+					client.enqueue(entity.NewWSAck(fmt.Sprintf("request-%d", i), entity.CreateMessageAction).Encode())
+				}
+				if err := peer.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+					t.Fatalf("Failed to set read deadline: %v", err)
+				}
+				if _, _, err := peer.ReadMessage(); err == nil {
+					t.Errorf("ReadMessage() error = nil, want connection closed")
+				}
+			},
+			metrics: map[string]int64{metricSlowConsumerDisconnects: 1},
+		},
+		{
+			name:     "disconnect closes the connection",
+			overflow: OverflowDisconnect,
+			frames:   config.ChannelBufferSize + 2,
+			check: func(t *testing.T, _ *Client, peer *websocket.Conn) {
+				t.Helper()
+				if err := peer.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+					t.Fatalf("Failed to set read deadline: %v", err)
+				}
+				if _, _, err := peer.ReadMessage(); err == nil {
+					t.Errorf("ReadMessage() error = nil, want connection closed")
+				}
+			},
+			metrics: map[string]int64{metricDroppedFrames: 2, metricSlowConsumerDisconnects: 1},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client, peer := newTestClient(t, tt.overflow)
+
+			before := make(map[string]int64, len(tt.metrics))
+			for key := range tt.metrics {
+				before[key] = metricValue(key)
+			}
+
+			for i := 0; i < tt.frames; i++ {
+				client.enqueue(frame(i))
+			}
+
+			tt.check(t, client, peer)
+			for key, want := range tt.metrics {
+				if got := metricValue(key) - before[key]; got != want {
+					t.Errorf("metric %s increased by %d, want %d", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_Client_enqueue_AfterDisconnect(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newTestClient(t, OverflowDropOldest)
+	client.closed = true
+	close(client.send)
+
+	// 切断済みのクライアントへの送信はパニックせずに捨てられます。
+	client.enqueue(frame(0))
+}
+
+// Test_Channel_SlowConsumer は、読み込みの止まったクライアントがいても、他のクライアントへの配信が止まらないことを確認します。
+func Test_Channel_SlowConsumer(t *testing.T) {
+	t.Parallel()
+
+	const (
+		fastClients = 50
+		frames      = 10 * config.ChannelBufferSize
+	)
+
+	for _, overflow := range []OverflowPolicy{OverflowDropOldest, OverflowDisconnect, OverflowCoalesce} {
+		overflow := overflow
+		t.Run(string(overflow), func(t *testing.T) {
+			t.Parallel()
+
+			channel := NewChannel("channel", "general", true, nil, nil, nil)
+			slow, _ := newTestClient(t, overflow)
+			channel.clients[slow] = true
+
+			var wg sync.WaitGroup
+			received := make([]int, fastClients)
+			for i := 0; i < fastClients; i++ {
+				// 読み込みの速いクライアントは、送信バッファが溢れないものとして扱います。
+				fast := &Client{
+					ID:       fmt.Sprintf("fast-%d", i),
+					channels: make(map[*Channel]bool),
+					send:     make(chan []byte, frames),
+					overflow: overflow,
+				}
+				channel.clients[fast] = true
+
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					for received[i] < frames {
+						<-fast.send
+						received[i]++
+					}
+				}(i)
+			}
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for i := 0; i < frames; i++ {
+					channel.deliverEvent(frame(i))
+				}
+				wg.Wait()
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(10 * time.Second):
+				t.Fatalf("channel stalled by a slow client")
+			}
+			for i, n := range received {
+				if n != frames {
+					t.Errorf("fast client %d received %d frames, want %d", i, n, frames)
+				}
+			}
+		})
+	}
+}
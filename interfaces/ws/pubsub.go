@@ -0,0 +1,35 @@
+package ws
+
+import (
+	"context"
+	"time"
+
+	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+// subscribe は、ctxが終了するまでtopicを購読し、届いたメッセージを順にhandleに渡します。
+// 購読に失敗した場合や購読が途中で終了した場合は、PubSubResubscribeIntervalの後に購読し直します。
+func subscribe(ctx context.Context, psr repository.PubSubRepository, topic string, handle func(payload []byte)) {
+	for {
+		sub, err := psr.Subscribe(ctx, topic)
+		if err == nil {
+			for payload := range sub.Messages() {
+				handle(payload)
+			}
+			err = sub.Err()
+			sub.Close()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Warn("Subscription ended, resubscribing", log.Fstring("topic", topic), log.Ferror(err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(config.PubSubResubscribeInterval):
+		}
+	}
+}
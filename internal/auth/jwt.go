@@ -9,21 +9,34 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"fmt"
-	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	ErrTokenExpired     = errors.New("token is expired")
+	ErrTokenNotValidYet = errors.New("token is not valid yet")
+)
+
 type Payload struct {
-	JTI    string `json:"jti"`
-	UserID string `json:"userId"`
+	JTI       string `json:"jti"`
+	UserID    string `json:"userId"`
+	SessionID string `json:"sid"`
+	IssuedAt  int64  `json:"iat"`
+	NotBefore int64  `json:"nbf"`
+	ExpiresAt int64  `json:"exp"`
 }
 
 const expectedTokenParts = 3
 
+// clockSkewLeeway は、トークンを発行したサーバーと検証するサーバーの時計のずれとして許容する時間です。
+const clockSkewLeeway = 30 * time.Second
+
 func loadPrivateKeyFromFile(filename string) (*rsa.PrivateKey, error) {
 	// ファイルから秘密鍵をバイトスライスとして読み込む
 	keyBytes, err := os.ReadFile(filename)
@@ -88,24 +101,44 @@ func base64UrlDecode(s string) ([]byte, error) {
 	return base64.RawURLEncoding.DecodeString(s)
 }
 
-// アクセストークン(JWT形式)の生成
-func GenerateToken(userID, email string) (string, string) {
+// tokenHeader は、JWTのヘッダです。
+type tokenHeader struct {
+	Typ string `json:"typ"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// GenerateToken は、アクセストークン(JWT形式)を生成し、トークンとjtiを返します。
+// sessionIDはトークンを発行したログインセッションで、ttlが経過したトークンはValidateAccessTokenで拒否されます。
+func (km *KeyManager) GenerateToken(userID, email, sessionID string, ttl time.Duration) (string, string, error) {
+	keys := km.current()
+	if keys.signingKey == nil {
+		return "", "", ErrNoSigningKey
+	}
+
 	// ヘッダの作成
-	header := map[string]string{
-		"typ": "JWT",
-		"alg": "RS256",
+	headerBytes, err := json.Marshal(tokenHeader{Typ: "JWT", Alg: "RS256", Kid: keys.signingKID})
+	if err != nil {
+		return "", "", err
 	}
-	headerBytes, _ := json.Marshal(header)
 	encodedHeader := base64UrlEncode(headerBytes)
 
 	// ペイロードの作成
 	jti := uuid.New().String()
-	payload := map[string]string{
+	now := time.Now()
+	payload := map[string]interface{}{
 		"jti":    jti,
 		"userId": userID,
 		"Email":  email,
+		"sid":    sessionID,
+		"iat":    now.Unix(),
+		"nbf":    now.Unix(),
+		"exp":    now.Add(ttl).Unix(),
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", "", err
 	}
-	payloadBytes, _ := json.Marshal(payload)
 	encodedPayload := base64UrlEncode(payloadBytes)
 
 	// エンコードされたヘッダとペイロードを結合
@@ -115,29 +148,40 @@ func GenerateToken(userID, email string) (string, string) {
 	hashed := sha256.Sum256([]byte(jwtWithoutSignature))
 
 	// 署名作成
-	privateKeyPath := os.Getenv("PRIVATE_KEY_PATH")
-	privKey, err := loadPrivateKeyFromFile(privateKeyPath)
-	if err != nil {
-		panic(err)
-	}
-	signature, err := rsa.SignPKCS1v15(rand.Reader, privKey, crypto.SHA256, hashed[:])
+	signature, err := rsa.SignPKCS1v15(rand.Reader, keys.signingKey, crypto.SHA256, hashed[:])
 	if err != nil {
-		panic(err)
+		return "", "", fmt.Errorf("failed to sign token: %w", err)
 	}
 	encodedSignature := base64UrlEncode(signature)
 
 	// JWTを完成
 	jwt := fmt.Sprintf("%s.%s", jwtWithoutSignature, encodedSignature)
 
-	return jwt, jti
+	return jwt, jti, nil
 }
 
-func ValidateAccessToken(jwt string) error {
+// ValidateAccessToken は、アクセストークンの署名と有効期間を検証します。
+// 署名はヘッダのkidの鍵で検証するため、ローテーション前の鍵で署名したトークンも鍵が残っている間は有効です。
+func (km *KeyManager) ValidateAccessToken(jwt string) error {
 	//　アクセストークンの検証
 	parts := strings.Split(jwt, ".")
 	if len(parts) != expectedTokenParts {
 		return fmt.Errorf("invalid token")
 	}
+
+	// ヘッダの検証
+	headerBytes, err := base64UrlDecode(parts[0])
+	if err != nil {
+		return fmt.Errorf("decoding failed: %w", err)
+	}
+	var header tokenHeader
+	if err = json.Unmarshal(headerBytes, &header); err != nil {
+		return fmt.Errorf("JSON unmarshalling failed")
+	}
+	if header.Alg != "RS256" {
+		return fmt.Errorf("unsupported algorithm: %s", header.Alg)
+	}
+
 	// エンコードされたヘッダとペイロードを結合
 	jwtWithoutSignature := fmt.Sprintf("%s.%s", parts[0], parts[1])
 	// SHA-256ハッシュを計算
@@ -150,18 +194,54 @@ func ValidateAccessToken(jwt string) error {
 	}
 
 	// 検証
-	publicKeyPath := os.Getenv("PUBLIC_KEY_PATH")
-	pubKey, err := loadPublicKeyFromFile(publicKeyPath)
+	if err = km.verify(header.Kid, hashed[:], signature); err != nil {
+		return fmt.Errorf("signature verification failed: %w", err)
+	}
+
+	// 有効期間の検証
+	payload, err := GetPayloadFromToken(jwt)
 	if err != nil {
 		return err
 	}
+	return validateTimeClaims(payload, time.Now())
+}
 
-	err = rsa.VerifyPKCS1v15(pubKey, crypto.SHA256, hashed[:], signature)
-	log.Print(err)
-	if err != nil {
-		return fmt.Errorf("signature verification failed: %w", err)
+// verify は、kidの公開鍵で署名を検証します。
+// kidの無いトークンはkidを付ける前に発行されたものなので、全ての鍵で検証します。
+func (km *KeyManager) verify(kid string, hashed, signature []byte) error {
+	keys := km.current()
+	if kid != "" {
+		pubKey, ok := keys.publicKeys[kid]
+		if !ok {
+			return ErrUnknownKeyID
+		}
+		return rsa.VerifyPKCS1v15(pubKey, crypto.SHA256, hashed, signature)
+	}
+
+	err := ErrUnknownKeyID
+	for _, kid := range keys.kids {
+		if err = rsa.VerifyPKCS1v15(keys.publicKeys[kid], crypto.SHA256, hashed, signature); err == nil {
+			return nil
+		}
 	}
+	return err
+}
 
+// validateTimeClaims は、exp, nbf, iatがnowの時点で有効かを検証します。
+// expのないトークンは無期限に使えてしまうため拒否します。
+func validateTimeClaims(payload Payload, now time.Time) error {
+	if payload.ExpiresAt == 0 {
+		return fmt.Errorf("missing exp claim")
+	}
+	if now.Add(-clockSkewLeeway).Unix() >= payload.ExpiresAt {
+		return ErrTokenExpired
+	}
+	if now.Add(clockSkewLeeway).Unix() < payload.NotBefore {
+		return ErrTokenNotValidYet
+	}
+	if now.Add(clockSkewLeeway).Unix() < payload.IssuedAt {
+		return fmt.Errorf("token is issued in the future")
+	}
 	return nil
 }
 
@@ -1,8 +1,10 @@
 package auth
 
 import (
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	jwtgo "github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
@@ -18,7 +20,16 @@ func Test_JWTToken(t *testing.T) {
 	email := "test@gmail.com"
 
 	// GenerateToken test
-	jwt, jti := GenerateToken(userID.String(), email)
+	km, err := NewKeyManager("")
+	if err != nil {
+		t.Fatalf("Failed to NewKeyManager: %s", err)
+	}
+
+	sessionID := uuid.New().String()
+	jwt, jti, err := km.GenerateToken(userID.String(), email, sessionID, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to GenerateToken: %s", err)
+	}
 
 	// JWTのフォーマットが正しいことを確認
 	token, err := jwtgo.Parse(jwt, func(token *jwtgo.Token) (interface{}, error) {
@@ -28,6 +39,9 @@ func Test_JWTToken(t *testing.T) {
 	if err != nil {
 		t.Errorf("Failed to parse JWT: %s", err)
 	}
+	if token.Header["kid"] != km.JWKS().Keys[0].Kid {
+		t.Errorf("Expected kid %s, got %s", km.JWKS().Keys[0].Kid, token.Header["kid"])
+	}
 
 	// クレームを検証
 	claims, ok := token.Claims.(jwtgo.MapClaims)
@@ -44,7 +58,7 @@ func Test_JWTToken(t *testing.T) {
 	}
 
 	// ValidateAccessToken test
-	err = ValidateAccessToken(jwt)
+	err = km.ValidateAccessToken(jwt)
 	if err != nil {
 		t.Errorf("Failed to ValidateAccessToken: %s", err)
 	}
@@ -54,8 +68,83 @@ func Test_JWTToken(t *testing.T) {
 	if err != nil {
 		t.Errorf("Failed to GetPayloadFromToken: %s", err)
 	}
-	wantPayload := Payload{JTI: jti, UserID: userID.String()}
+	wantPayload := Payload{
+		JTI:       jti,
+		UserID:    userID.String(),
+		SessionID: sessionID,
+		IssuedAt:  payload.IssuedAt,
+		NotBefore: payload.IssuedAt,
+		ExpiresAt: payload.IssuedAt + int64((15 * time.Minute).Seconds()),
+	}
 	if !reflect.DeepEqual(payload, wantPayload) {
 		t.Errorf("GetPayloadFromToken() \n got = %v,\n want = %v", payload, wantPayload)
 	}
+
+	// 有効期限切れのトークンは署名が正しくても拒否される
+	expired, _, _ := km.GenerateToken(userID.String(), email, sessionID, -time.Hour)
+	if err = km.ValidateAccessToken(expired); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("ValidateAccessToken() error = %v, want %v", err, ErrTokenExpired)
+	}
+}
+
+func Test_validateTimeClaims(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	patterns := []struct {
+		name    string
+		payload Payload
+		wantErr error
+	}{
+		{
+			name:    "success",
+			payload: Payload{IssuedAt: now.Unix(), NotBefore: now.Unix(), ExpiresAt: now.Add(time.Minute).Unix()},
+			wantErr: nil,
+		},
+		{
+			name:    "success: clock skew within leeway",
+			payload: Payload{IssuedAt: now.Add(10 * time.Second).Unix(), NotBefore: now.Add(10 * time.Second).Unix(), ExpiresAt: now.Add(-10 * time.Second).Unix()},
+			wantErr: nil,
+		},
+		{
+			name:    "Fail: expired",
+			payload: Payload{IssuedAt: now.Add(-time.Hour).Unix(), NotBefore: now.Add(-time.Hour).Unix(), ExpiresAt: now.Add(-time.Minute).Unix()},
+			wantErr: ErrTokenExpired,
+		},
+		{
+			name:    "Fail: not valid yet",
+			payload: Payload{IssuedAt: now.Unix(), NotBefore: now.Add(time.Minute).Unix(), ExpiresAt: now.Add(time.Hour).Unix()},
+			wantErr: ErrTokenNotValidYet,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := validateTimeClaims(tt.payload, now); !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateTimeClaims() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := validateTimeClaims(Payload{}, now); err == nil {
+		t.Error("validateTimeClaims() expected an error for a token without exp")
+	}
+}
+
+func Test_RefreshToken(t *testing.T) {
+	t.Parallel()
+
+	token, err := GenerateRefreshToken()
+	if err != nil {
+		t.Fatalf("Failed to GenerateRefreshToken: %s", err)
+	}
+	other, _ := GenerateRefreshToken()
+	if token == other {
+		t.Error("GenerateRefreshToken() returned the same token twice")
+	}
+	if HashRefreshToken(token) != HashRefreshToken(token) || HashRefreshToken(token) == HashRefreshToken(other) {
+		t.Error("HashRefreshToken() is not deterministic per token")
+	}
 }
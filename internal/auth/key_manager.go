@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+)
+
+// minRSAKeyBits は、署名鍵として受け付けるRSA鍵の最小のビット数です。
+const minRSAKeyBits = 2048
+
+const keyFileExt = ".pem"
+
+var (
+	ErrNoSigningKey = errors.New("no signing key")
+	ErrUnknownKeyID = errors.New("unknown key id")
+)
+
+// keySet は、ある時点で読み込んだ鍵の一覧です。再読み込みでは丸ごと差し替えます。
+type keySet struct {
+	signingKID string
+	signingKey *rsa.PrivateKey
+	publicKeys map[string]*rsa.PublicKey
+	kids       []string
+}
+
+// KeyManager は、アクセストークンの署名と検証に使う鍵を保持します。
+// 鍵は起動時とReloadの呼び出し時にだけ読み込み、JWTのヘッダのkidで検証に使う鍵を選びます。
+type KeyManager struct {
+	keyDir string
+
+	mu   sync.RWMutex
+	keys *keySet
+}
+
+// NewKeyManager は、keyDirの鍵を読み込みます。
+// keyDirには<kid>.pemという名前でPKCS#8形式のRSA秘密鍵を置き、名前が最も後ろの鍵で新しいトークンに署名します。
+// 他の鍵は発行済みのトークンの検証にだけ使うため、ローテーションでは新しい鍵を追加して再読み込みし、
+// 古い鍵はアクセストークンの有効期間が過ぎてから削除します。
+// keyDirが空の場合は、環境変数PRIVATE_KEY_PATHとPUBLIC_KEY_PATHの鍵を使います。
+func NewKeyManager(keyDir string) (*KeyManager, error) {
+	km := &KeyManager{keyDir: keyDir}
+	if err := km.Reload(); err != nil {
+		return nil, err
+	}
+	return km, nil
+}
+
+// Reload は、鍵を読み込み直します。読み込みに失敗した場合は、それまでの鍵を使い続けます。
+func (km *KeyManager) Reload() error {
+	var keys *keySet
+	var err error
+	if km.keyDir != "" {
+		keys, err = loadKeyDir(km.keyDir)
+	} else {
+		keys, err = loadKeyFiles(os.Getenv("PRIVATE_KEY_PATH"), os.Getenv("PUBLIC_KEY_PATH"))
+	}
+	if err != nil {
+		log.Error("Failed to load signing keys", log.Fstring("keyDir", km.keyDir), log.Ferror(err))
+		return err
+	}
+
+	km.mu.Lock()
+	km.keys = keys
+	km.mu.Unlock()
+	log.Info("Signing keys loaded", log.Fstring("signingKID", keys.signingKID), log.Fint("keys", len(keys.kids)))
+	return nil
+}
+
+// ReloadOnSignal は、sigを受け取るたびに鍵を読み込み直します。ctxが終了するまで戻りません。
+func (km *KeyManager) ReloadOnSignal(ctx context.Context, sig ...os.Signal) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sig...)
+	defer signal.Stop(ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ch:
+			// 失敗した場合はReloadがログに残し、それまでの鍵を使い続けます
+			_ = km.Reload()
+		}
+	}
+}
+
+func (km *KeyManager) current() *keySet {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	return km.keys
+}
+
+// JWK は、RFC 7517のJSON Web Keyで表したRSA公開鍵です。
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS は、/.well-known/jwks.jsonで公開する公開鍵の一覧です。
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS は、トークンの検証に使う全ての公開鍵を返します。
+func (km *KeyManager) JWKS() JWKS {
+	keys := km.current()
+	jwks := JWKS{Keys: make([]JWK, 0, len(keys.kids))}
+	for _, kid := range keys.kids {
+		n, e := encodeRSAPublicKey(keys.publicKeys[kid])
+		jwks.Keys = append(jwks.Keys, JWK{Kty: "RSA", Kid: kid, Use: "sig", Alg: "RS256", N: n, E: e})
+	}
+	return jwks
+}
+
+// loadKeyDir は、dirの<kid>.pemを全て読み込み、名前が最も後ろの鍵を署名に使います。
+func loadKeyDir(dir string) (*keySet, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key directory: %w", err)
+	}
+
+	keys := &keySet{publicKeys: make(map[string]*rsa.PublicKey)}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != keyFileExt {
+			continue
+		}
+		kid := strings.TrimSuffix(entry.Name(), keyFileExt)
+		privKey, err := loadPrivateKeyFromFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load key %s: %w", kid, err)
+		}
+		if err = validateRSAKey(&privKey.PublicKey); err != nil {
+			return nil, fmt.Errorf("invalid key %s: %w", kid, err)
+		}
+		keys.publicKeys[kid] = &privKey.PublicKey
+		keys.kids = append(keys.kids, kid)
+		if kid > keys.signingKID {
+			keys.signingKID = kid
+			keys.signingKey = privKey
+		}
+	}
+	if len(keys.kids) == 0 {
+		return nil, fmt.Errorf("no keys found in %s", dir)
+	}
+	sort.Strings(keys.kids)
+	return keys, nil
+}
+
+// loadKeyFiles は、秘密鍵と公開鍵のファイルを読み込みます。kidには公開鍵のRFC 7638のサムプリントを使います。
+// 公開鍵だけを指定した場合は、トークンの検証だけができます。
+func loadKeyFiles(privateKeyPath, publicKeyPath string) (*keySet, error) {
+	if privateKeyPath == "" && publicKeyPath == "" {
+		return nil, fmt.Errorf("PRIVATE_KEY_PATH or PUBLIC_KEY_PATH is required")
+	}
+
+	keys := &keySet{publicKeys: make(map[string]*rsa.PublicKey)}
+	add := func(pubKey *rsa.PublicKey) (string, error) {
+		if err := validateRSAKey(pubKey); err != nil {
+			return "", err
+		}
+		kid := thumbprint(pubKey)
+		if _, ok := keys.publicKeys[kid]; !ok {
+			keys.publicKeys[kid] = pubKey
+			keys.kids = append(keys.kids, kid)
+		}
+		return kid, nil
+	}
+
+	if privateKeyPath != "" {
+		privKey, err := loadPrivateKeyFromFile(privateKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		if keys.signingKID, err = add(&privKey.PublicKey); err != nil {
+			return nil, err
+		}
+		keys.signingKey = privKey
+	}
+	if publicKeyPath != "" {
+		pubKey, err := loadPublicKeyFromFile(publicKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = add(pubKey); err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(keys.kids)
+	return keys, nil
+}
+
+func validateRSAKey(pubKey *rsa.PublicKey) error {
+	if pubKey.N.BitLen() < minRSAKeyBits {
+		return fmt.Errorf("RSA key must be at least %d bits", minRSAKeyBits)
+	}
+	return nil
+}
+
+// encodeRSAPublicKey は、公開鍵のnとeをJWKの形式でエンコードします。
+func encodeRSAPublicKey(pubKey *rsa.PublicKey) (string, string) {
+	return base64UrlEncode(pubKey.N.Bytes()), base64UrlEncode(big.NewInt(int64(pubKey.E)).Bytes())
+}
+
+// thumbprint は、公開鍵のRFC 7638のJWKサムプリントを返します。
+func thumbprint(pubKey *rsa.PublicKey) string {
+	n, e := encodeRSAPublicKey(pubKey)
+	// メンバーは辞書順に並べ、空白を含めずにハッシュ化します
+	b, _ := json.Marshal(struct {
+		E   string `json:"e"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+	}{E: e, Kty: "RSA", N: n})
+	sum := sha256.Sum256(b)
+	return base64UrlEncode(sum[:])
+}
@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestKey(t *testing.T, dir, kid string) *rsa.PrivateKey {
+	t.Helper()
+	privKey, err := rsa.GenerateKey(rand.Reader, minRSAKeyBits)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %s", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privKey)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %s", err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err = os.WriteFile(filepath.Join(dir, kid+keyFileExt), data, 0o600); err != nil {
+		t.Fatalf("Failed to write key: %s", err)
+	}
+	return privKey
+}
+
+func Test_KeyManager_Rotation(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	writeTestKey(t, dir, "2024-01")
+
+	km, err := NewKeyManager(dir)
+	if err != nil {
+		t.Fatalf("Failed to NewKeyManager: %s", err)
+	}
+	oldToken, _, err := km.GenerateToken("user", "test@gmail.com", "session", time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to GenerateToken: %s", err)
+	}
+
+	// 新しい鍵を追加すると、新しいトークンはその鍵で署名され、発行済みのトークンも有効なまま
+	newKey := writeTestKey(t, dir, "2024-02")
+	if err = km.Reload(); err != nil {
+		t.Fatalf("Failed to Reload: %s", err)
+	}
+	newToken, _, err := km.GenerateToken("user", "test@gmail.com", "session", time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to GenerateToken: %s", err)
+	}
+	if err = km.ValidateAccessToken(oldToken); err != nil {
+		t.Errorf("Expected the token signed by the old key to be valid, got %s", err)
+	}
+	if err = km.ValidateAccessToken(newToken); err != nil {
+		t.Errorf("Expected the token signed by the new key to be valid, got %s", err)
+	}
+	jwks := km.JWKS()
+	if len(jwks.Keys) != 2 || jwks.Keys[1].Kid != "2024-02" {
+		t.Fatalf("JWKS() = %v", jwks)
+	}
+	if n, _ := base64UrlDecode(jwks.Keys[1].N); new(big.Int).SetBytes(n).Cmp(newKey.N) != 0 {
+		t.Error("JWKS() published a wrong modulus")
+	}
+
+	// 壊れた鍵を読み込もうとしても、それまでの鍵を使い続ける
+	if err = os.WriteFile(filepath.Join(dir, "2024-03"+keyFileExt), []byte("broken"), 0o600); err != nil {
+		t.Fatalf("Failed to write key: %s", err)
+	}
+	if err = km.Reload(); err == nil {
+		t.Error("Expected Reload to fail with a broken key")
+	}
+	if err = km.ValidateAccessToken(newToken); err != nil {
+		t.Errorf("Expected the keys to be kept after a failed reload, got %s", err)
+	}
+
+	// 古い鍵を削除すると、その鍵で署名したトークンは使えなくなる
+	for _, kid := range []string{"2024-01", "2024-03"} {
+		if err = os.Remove(filepath.Join(dir, kid+keyFileExt)); err != nil {
+			t.Fatalf("Failed to remove key: %s", err)
+		}
+	}
+	if err = km.Reload(); err != nil {
+		t.Fatalf("Failed to Reload: %s", err)
+	}
+	if err = km.ValidateAccessToken(oldToken); !errors.Is(err, ErrUnknownKeyID) {
+		t.Errorf("ValidateAccessToken() error = %v, want %v", err, ErrUnknownKeyID)
+	}
+}
+
+func Test_KeyManager_VerifyOnly(t *testing.T) {
+	t.Setenv("PRIVATE_KEY_PATH", "")
+	t.Setenv("PUBLIC_KEY_PATH", "../../.certificate/public_key.pem")
+
+	km, err := NewKeyManager("")
+	if err != nil {
+		t.Fatalf("Failed to NewKeyManager: %s", err)
+	}
+	if _, _, err = km.GenerateToken("user", "test@gmail.com", "session", time.Minute); !errors.Is(err, ErrNoSigningKey) {
+		t.Errorf("GenerateToken() error = %v, want %v", err, ErrNoSigningKey)
+	}
+	if len(km.JWKS().Keys) != 1 {
+		t.Errorf("JWKS() = %v", km.JWKS())
+	}
+}
+
+func Test_thumbprint(t *testing.T) {
+	t.Parallel()
+
+	// RFC 7638 3.1の例
+	n, _ := base64UrlDecode("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")
+	pubKey := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: 65537}
+
+	if got, want := thumbprint(pubKey), "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; got != want {
+		t.Errorf("thumbprint() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+const refreshTokenBytes = 32
+
+// GenerateRefreshToken は、推測できないランダムなリフレッシュトークンを生成します。
+func GenerateRefreshToken() (string, error) {
+	b := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return base64UrlEncode(b), nil
+}
+
+// HashRefreshToken は、リフレッシュトークンを保存や検索に使うSHA-256のハッシュ値に変換します。
+// ストアが漏洩してもトークンそのものは復元できないよう、トークンは平文で保存しません。
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
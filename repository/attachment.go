@@ -0,0 +1,23 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+)
+
+// ErrAttachmentNotFound は、対象の添付ファイルが存在しない場合に返されます。
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
+type AttachmentRepository interface {
+	Get(ctx context.Context, id string) (*entity.Attachment, error)
+	ListByIDs(ctx context.Context, ids []string) ([]entity.Attachment, error)
+	Create(ctx context.Context, attachment entity.Attachment) error
+	Delete(ctx context.Context, id string) error
+	// AttachToMessage は、membershipIDがアップロードしてまだメッセージに添付していないidsのファイルをmessageIDに添付し、添付した件数を返します。
+	AttachToMessage(ctx context.Context, messageID, membershipID string, ids []string) (int64, error)
+	// DetachFromMessage は、messageIDに添付したファイルを未添付に戻します。
+	DetachFromMessage(ctx context.Context, messageID string) error
+}
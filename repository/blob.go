@@ -0,0 +1,22 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrBlobNotFound は、キーに対応する内容が保存されていない場合に返されます。
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobRepository は、添付ファイルなどの内容をキーごとに保存します。
+// キーは/で区切った相対パスで、実装はこれをファイルのパスやオブジェクトのキーとして使います。
+type BlobRepository interface {
+	// Put は、bodyからsizeバイトを読み込んで保存します。同じキーの内容は上書きします。
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get は、保存した内容を返します。呼び出し側は読み終えたら閉じてください。
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete は、保存した内容を削除します。存在しない場合は何もしません。
+	Delete(ctx context.Context, key string) error
+}
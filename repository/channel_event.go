@@ -0,0 +1,21 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrChannelEventsExpired は、要求された連番の直後のイベントが保存期間を過ぎて残っていない場合に返されます。
+var ErrChannelEventsExpired = errors.New("channel events have expired")
+
+// ChannelEventRepository は、チャンネルのイベントにチャンネルごとの連番を付けて配信します。
+// 切断したクライアントが取りこぼしたイベントを再送できるよう、直近のイベントを保存します。
+type ChannelEventRepository interface {
+	// Publish は、JSONオブジェクトのpayloadに"seq"を付けて保存し、チャンネルのトピックに配信します。付けた連番を返します。
+	// トピックはconfig.PubSubChannelPrefixにチャンネルIDを付けたものです。
+	Publish(ctx context.Context, channelID string, payload []byte) (int64, error)
+	// ListSince は、seqより後のイベントを連番の昇順で、チャンネルの最新の連番と合わせて返します。
+	// seqの直後のイベントが既に残っていない場合は、最新の連番とErrChannelEventsExpiredを返します。
+	ListSince(ctx context.Context, channelID string, seq int64) ([][]byte, int64, error)
+}
@@ -0,0 +1,15 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// IdempotencyRepository は、クライアントの再送を重複排除するため、冪等キーと作成したリソースのIDを一定期間保存します。
+type IdempotencyRepository interface {
+	// Reserve は、keyが未使用であればidを記録して空文字を返します。使用済みの場合は記録済みのIDを返します。
+	Reserve(ctx context.Context, key, id string, ttl time.Duration) (string, error)
+	// Release は、keyの記録を削除します。作成に失敗した場合に、再送を受け付けられるようにするために使います。
+	Release(ctx context.Context, key string) error
+}
@@ -0,0 +1,109 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+type blobRepository struct {
+	dir string
+}
+
+// NewBlobRepository は、dir以下のファイルとして内容を保存します。
+// 複数のサーバーで共有するにはdirを共有のボリュームに置いてください。
+func NewBlobRepository(dir string) repository.BlobRepository {
+	return &blobRepository{dir: dir}
+}
+
+// Put は、一時ファイルに書き込んでから名前を変えることで、書き込み途中の内容を読まれないようにします。
+func (br *blobRepository) Put(ctx context.Context, key string, body io.Reader, size int64, _ string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	name, err := br.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		log.Error("Failed to create blob directory", log.Fstring("key", key), log.Ferror(err))
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		log.Error("Failed to create temporary blob file", log.Fstring("key", key), log.Ferror(err))
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // 名前を変えた後は存在しないため、失敗は無視します
+
+	n, err := io.Copy(tmp, io.LimitReader(body, size+1))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		log.Error("Failed to write blob", log.Fstring("key", key), log.Ferror(err))
+		return err
+	}
+	if n != size {
+		log.Warn("Blob size mismatch", log.Fstring("key", key), log.Fint64("want", size), log.Fint64("got", n))
+		return fmt.Errorf("blob size mismatch: want %d bytes, got %d", size, n)
+	}
+
+	if err = os.Rename(tmp.Name(), name); err != nil {
+		log.Error("Failed to rename blob", log.Fstring("key", key), log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+func (br *blobRepository) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	name, err := br.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, repository.ErrBlobNotFound
+		}
+		log.Error("Failed to open blob", log.Fstring("key", key), log.Ferror(err))
+		return nil, err
+	}
+	return f, nil
+}
+
+func (br *blobRepository) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	name, err := br.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Error("Failed to delete blob", log.Fstring("key", key), log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+// path は、keyをdir以下のファイルのパスに変換します。dirの外を指すキーは受け付けません。
+func (br *blobRepository) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if key == "" || cleaned == "/" || cleaned != "/"+key {
+		log.Warn("Invalid blob key", log.Fstring("key", key))
+		return "", fmt.Errorf("invalid blob key: %q", key)
+	}
+	return filepath.Join(br.dir, filepath.FromSlash(cleaned)), nil
+}
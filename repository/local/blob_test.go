@@ -0,0 +1,64 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+func Test_BlobRepository(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	repo := NewBlobRepository(dir)
+	ctx := context.Background()
+	key := "workspace/channel/attachment"
+
+	if err := repo.Put(ctx, key, strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	body, err := repo.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	got, err := io.ReadAll(body)
+	body.Close()
+	if err != nil || string(got) != "hello" {
+		t.Errorf("Get() = %q, %v, want %q", got, err, "hello")
+	}
+
+	// A body which doesn't match the declared size is not stored
+	if err = repo.Put(ctx, "workspace/channel/short", strings.NewReader("hi"), 5, "text/plain"); err == nil {
+		t.Errorf("Put() with short body error = nil, want error")
+	}
+	if _, err = repo.Get(ctx, "workspace/channel/short"); !errors.Is(err, repository.ErrBlobNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, repository.ErrBlobNotFound)
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "workspace", "channel"))
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Expected only the stored blob to remain, got %v, %v", entries, err)
+	}
+
+	// Keys must stay inside the directory
+	for _, invalid := range []string{"", "../outside", "workspace/../../outside", "/absolute"} {
+		if err = repo.Put(ctx, invalid, strings.NewReader("x"), 1, "text/plain"); err == nil {
+			t.Errorf("Put(%q) error = nil, want error", invalid)
+		}
+	}
+
+	if err = repo.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err = repo.Get(ctx, key); !errors.Is(err, repository.ErrBlobNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, repository.ErrBlobNotFound)
+	}
+	if err = repo.Delete(ctx, key); err != nil {
+		t.Errorf("Delete() of missing blob error = %v, want nil", err)
+	}
+}
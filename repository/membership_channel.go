@@ -3,10 +3,14 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/tusmasoma/connectHub-backend/entity"
 )
 
+// ErrMembershipChannelNotFound は、メンバーシップがチャンネルに参加していない場合に返されます。
+var ErrMembershipChannelNotFound = errors.New("membership channel not found")
+
 type MembershipChannelRepository interface {
 	List(ctx context.Context, qcs []QueryCondition) ([]entity.MembershipChannel, error)
 	Get(ctx context.Context, membershipID, channelID string) (*entity.MembershipChannel, error)
@@ -14,4 +18,6 @@ type MembershipChannelRepository interface {
 	BatchCreate(ctx context.Context, membershipChannels []entity.MembershipChannel) error
 	Update(ctx context.Context, id string, membershipChannel entity.MembershipChannel) error
 	Delete(ctx context.Context, membershipID, channelID string) error
+	// UpdateLastRead は、membershipChannelの既読の位置を保存します。
+	UpdateLastRead(ctx context.Context, membershipChannel entity.MembershipChannel) error
 }
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+// channelEventsMaxLen を超えた古いイベントは削除し、それより前から再開するクライアントには再同期を求めます。
+const channelEventsMaxLen = 1000
+
+// channelEventRepository は、イベントの連番と直近のイベントをプロセス内に保持します。
+// 単一のサーバーで動かす場合に、PubSubRepositoryと組み合わせて使います。
+type channelEventRepository struct {
+	mu     sync.Mutex
+	psr    repository.PubSubRepository
+	latest map[string]int64
+	events map[string][][]byte // 連番の昇順に並べた直近のイベント
+	maxLen int
+}
+
+func NewChannelEventRepository(psr repository.PubSubRepository) repository.ChannelEventRepository {
+	return &channelEventRepository{
+		psr:    psr,
+		latest: make(map[string]int64),
+		events: make(map[string][][]byte),
+		maxLen: channelEventsMaxLen,
+	}
+}
+
+func (cer *channelEventRepository) Publish(ctx context.Context, channelID string, payload []byte) (int64, error) {
+	// 連番を埋め込めるのは、空でないJSONオブジェクトのみです。
+	if len(payload) <= len("{}") || payload[0] != '{' {
+		log.Warn("Channel event must be a non-empty JSON object", log.Fstring("channelID", channelID))
+		return 0, fmt.Errorf("channel event must be a non-empty JSON object")
+	}
+
+	// 配信までロックを保持し、配信の順序と連番の順序を一致させます。
+	cer.mu.Lock()
+	defer cer.mu.Unlock()
+
+	seq := cer.latest[channelID] + 1
+	event := append([]byte(`{"seq":`+strconv.FormatInt(seq, 10)+`,`), payload[1:]...)
+	if err := cer.psr.Publish(ctx, config.PubSubChannelPrefix+channelID, event); err != nil {
+		log.Error("Failed to publish channel event", log.Fstring("channelID", channelID), log.Ferror(err))
+		return 0, err
+	}
+
+	cer.latest[channelID] = seq
+	events := append(cer.events[channelID], event)
+	if len(events) > cer.maxLen {
+		events = events[len(events)-cer.maxLen:]
+	}
+	cer.events[channelID] = events
+	return seq, nil
+}
+
+func (cer *channelEventRepository) ListSince(_ context.Context, channelID string, seq int64) ([][]byte, int64, error) {
+	cer.mu.Lock()
+	defer cer.mu.Unlock()
+
+	latest := cer.latest[channelID]
+	if seq == latest {
+		return nil, latest, nil
+	}
+
+	// 保持しているイベントは連番が連続しているため、最も古いイベントの連番から位置を求めます。
+	events := cer.events[channelID]
+	oldest := latest - int64(len(events)) + 1
+	if seq > latest || len(events) == 0 || seq+1 < oldest {
+		return nil, latest, repository.ErrChannelEventsExpired
+	}
+
+	since := events[seq+1-oldest:]
+	payloads := make([][]byte, len(since))
+	copy(payloads, since)
+	return payloads, latest, nil
+}
@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+func Test_ChannelEventRepository(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	channelID := "channel"
+
+	psr := NewPubSubRepository()
+	repo := NewChannelEventRepository(psr)
+
+	sub, err := psr.Subscribe(ctx, config.PubSubChannelPrefix+channelID)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer sub.Close()
+
+	for i, text := range []string{"first", "second", "third"} {
+		seq, err := repo.Publish(ctx, channelID, []byte(`{"text":"`+text+`"}`))
+		if err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+		if seq != int64(i+1) {
+			t.Errorf("Expected seq %d, got %d", i+1, seq)
+		}
+	}
+
+	// Published payloads carry the sequence number
+	if got := receive(t, sub.Messages()); got != `{"seq":1,"text":"first"}` {
+		t.Errorf("Unexpected event: %s", got)
+	}
+
+	if _, err = repo.Publish(ctx, channelID, []byte(`{}`)); err == nil {
+		t.Errorf("Expected error for empty payload")
+	}
+
+	events, latest, err := repo.ListSince(ctx, channelID, 1)
+	if err != nil {
+		t.Fatalf("ListSince() error = %v", err)
+	}
+	if latest != 3 || len(events) != 2 {
+		t.Fatalf("Expected 2 events up to seq 3, got %d events up to seq %d", len(events), latest)
+	}
+	if string(events[0]) != `{"seq":2,"text":"second"}` {
+		t.Errorf("Unexpected event: %s", events[0])
+	}
+
+	events, latest, err = repo.ListSince(ctx, channelID, 3)
+	if err != nil || latest != 3 || len(events) != 0 {
+		t.Errorf("Expected no events, got %d events up to seq %d: %v", len(events), latest, err)
+	}
+
+	_, _, err = repo.ListSince(ctx, channelID, 5)
+	if !errors.Is(err, repository.ErrChannelEventsExpired) {
+		t.Errorf("Expected ErrChannelEventsExpired, got %v", err)
+	}
+}
+
+func Test_ChannelEventRepository_Trim(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	channelID := "channel"
+
+	repo := &channelEventRepository{
+		psr:    NewPubSubRepository(),
+		latest: make(map[string]int64),
+		events: make(map[string][][]byte),
+		maxLen: 2,
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := repo.Publish(ctx, channelID, []byte(`{"text":"message"}`)); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+
+	// Events older than the retained log require a resync
+	_, latest, err := repo.ListSince(ctx, channelID, 0)
+	if !errors.Is(err, repository.ErrChannelEventsExpired) || latest != 3 {
+		t.Errorf("Expected ErrChannelEventsExpired at seq 3, got %v at seq %d", err, latest)
+	}
+
+	events, latest, err := repo.ListSince(ctx, channelID, 1)
+	if err != nil || latest != 3 || len(events) != 2 {
+		t.Fatalf("Expected 2 events up to seq 3, got %d events up to seq %d: %v", len(events), latest, err)
+	}
+	if string(events[1]) != `{"seq":3,"text":"message"}` {
+		t.Errorf("Unexpected event: %s", events[1])
+	}
+}
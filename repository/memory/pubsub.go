@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+// pubsubRepository は、同じプロセスの購読者にだけメッセージを配信します。単一のサーバーで動かす場合とテストで使います。
+type pubsubRepository struct {
+	mu            sync.RWMutex
+	subscriptions map[string]map[*subscription]bool
+}
+
+func NewPubSubRepository() repository.PubSubRepository {
+	return &pubsubRepository{
+		subscriptions: make(map[string]map[*subscription]bool),
+	}
+}
+
+// Publish は、購読者がメッセージを受け取るのを待たずに戻ります。
+func (r *pubsubRepository) Publish(_ context.Context, topic string, message []byte) error {
+	payload := append([]byte(nil), message...)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for sub := range r.subscriptions[topic] {
+		sub.push(payload)
+	}
+	return nil
+}
+
+func (r *pubsubRepository) Subscribe(ctx context.Context, topic string) (repository.Subscription, error) {
+	sub := &subscription{
+		messages: make(chan []byte),
+		notify:   make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	sub.unsubscribe = func() { r.unsubscribe(topic, sub) }
+
+	r.mu.Lock()
+	if r.subscriptions[topic] == nil {
+		r.subscriptions[topic] = make(map[*subscription]bool)
+	}
+	r.subscriptions[topic][sub] = true
+	r.mu.Unlock()
+
+	go sub.forward(ctx)
+	return sub, nil
+}
+
+func (r *pubsubRepository) unsubscribe(topic string, sub *subscription) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.subscriptions[topic], sub)
+	if len(r.subscriptions[topic]) == 0 {
+		delete(r.subscriptions, topic)
+	}
+}
+
+// subscription は、配信されたメッセージを上限なく溜め、配信の順に Messages へ渡します。
+// 受け取りの遅い購読者がいても Publish は止まりません。
+type subscription struct {
+	messages    chan []byte
+	notify      chan struct{}
+	done        chan struct{}
+	unsubscribe func()
+	once        sync.Once
+	mu          sync.Mutex
+	queue       [][]byte
+	err         error
+}
+
+func (s *subscription) Messages() <-chan []byte {
+	return s.messages
+}
+
+func (s *subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *subscription) Close() error {
+	s.stop(nil)
+	return nil
+}
+
+func (s *subscription) stop(cause error) {
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.err = cause
+		s.queue = nil
+		s.mu.Unlock()
+		s.unsubscribe()
+		close(s.done)
+	})
+}
+
+func (s *subscription) push(payload []byte) {
+	s.mu.Lock()
+	s.queue = append(s.queue, payload)
+	s.mu.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (s *subscription) forward(ctx context.Context) {
+	defer close(s.messages)
+
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			s.mu.Unlock()
+			select {
+			case <-s.notify:
+				continue
+			case <-s.done:
+				return
+			case <-ctx.Done():
+				s.stop(ctx.Err())
+				return
+			}
+		}
+		payload := s.queue[0]
+		s.queue[0] = nil
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+
+		select {
+		case s.messages <- payload:
+		case <-s.done:
+			return
+		case <-ctx.Done():
+			s.stop(ctx.Err())
+			return
+		}
+	}
+}
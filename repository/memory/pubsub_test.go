@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func receive(t *testing.T, messages <-chan []byte) string {
+	t.Helper()
+
+	select {
+	case msg, ok := <-messages:
+		if !ok {
+			t.Fatal("Messages() closed unexpectedly")
+		}
+		return string(msg)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for message")
+		return ""
+	}
+}
+
+func Test_PubSubRepository(t *testing.T) {
+	t.Parallel()
+
+	repo := NewPubSubRepository()
+	ctx := context.Background()
+
+	first, err := repo.Subscribe(ctx, "topic")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	second, err := repo.Subscribe(ctx, "topic")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	other, err := repo.Subscribe(ctx, "other")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	// 受け取りを待たずに配信し、購読者ごとに配信の順で届くことを確認します。
+	const messages = 100
+	for i := 0; i < messages; i++ {
+		if err = repo.Publish(ctx, "topic", []byte(fmt.Sprint(i))); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+	for i := 0; i < messages; i++ {
+		if got := receive(t, first.Messages()); got != fmt.Sprint(i) {
+			t.Errorf("first subscriber got = %s, want %d", got, i)
+		}
+		if got := receive(t, second.Messages()); got != fmt.Sprint(i) {
+			t.Errorf("second subscriber got = %s, want %d", got, i)
+		}
+	}
+	select {
+	case msg := <-other.Messages():
+		t.Errorf("subscriber of another topic got = %s", msg)
+	default:
+	}
+
+	if err = first.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if _, ok := <-first.Messages(); ok {
+		t.Error("Messages() is not closed after Close()")
+	}
+	if err = first.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil after Close()", err)
+	}
+
+	if err = repo.Publish(ctx, "topic", []byte("after close")); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if got := receive(t, second.Messages()); got != "after close" {
+		t.Errorf("second subscriber got = %s, want after close", got)
+	}
+}
+
+func Test_PubSubRepository_ContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	repo := NewPubSubRepository()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sub, err := repo.Subscribe(ctx, "topic")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	cancel()
+
+	select {
+	case _, ok := <-sub.Messages():
+		if ok {
+			t.Fatal("Messages() is not closed after the context is canceled")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for Messages() to close")
+	}
+	if err = sub.Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Err() = %v, want %v", err, context.Canceled)
+	}
+}
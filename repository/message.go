@@ -3,28 +3,103 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/tusmasoma/connectHub-backend/entity"
 )
 
+// ErrMessageNotCached は、操作対象のメッセージがキャッシュに載っていない場合に返されます。
+var ErrMessageNotCached = errors.New("message not cached")
+
+// ErrMessageRejected は、制約違反や値の不正などメッセージの内容が原因で永続化できない場合に返されます。
+// 再試行しても結果が変わらないため、接続の問題などの一時的なエラーと区別して扱います。
+var ErrMessageRejected = errors.New("message rejected")
+
 type MessageRepository interface {
 	List(ctx context.Context, qcs []QueryCondition) ([]entity.Message, error)
+	ListChannelMessages(ctx context.Context, channelID string, start, end time.Time) ([]entity.Message, error)
+	ListPage(ctx context.Context, channelID string, query MessagePageQuery) ([]entity.Message, error)
+	ListThread(ctx context.Context, parentID string) ([]entity.Message, error)
 	Get(ctx context.Context, id string) (*entity.Message, error)
 	Create(ctx context.Context, message entity.Message) error
 	BatchCreate(ctx context.Context, messages []entity.Message) error
 	Update(ctx context.Context, id string, message entity.Message) error
 	Delete(ctx context.Context, id string) error
+	BatchDelete(ctx context.Context, ids []string) error
 	CreateOrUpdate(ctx context.Context, id string, qcs []QueryCondition, message entity.Message) error
+	// Search は、永続化済みのメッセージから条件に一致するものを関連度の高い順に取得します。
+	Search(ctx context.Context, query MessageSearchQuery) ([]entity.Message, error)
 }
 
 type MessageCacheRepository interface {
 	Set(ctx context.Context, key string, message entity.Message) error
 	Get(ctx context.Context, id string) (*entity.Message, error)
 	List(ctx context.Context, channelID string, start, end time.Time) ([]entity.Message, error)
+	ListPage(ctx context.Context, channelID string, query MessagePageQuery) ([]entity.Message, error)
+	ListThread(ctx context.Context, parentID string) ([]entity.Message, error)
+	// CountAfter は、チャンネルごとにカーソルより後のメッセージの数を返します。カーソルがnilの場合は全てのメッセージを数えます。
+	CountAfter(ctx context.Context, cursors map[string]*entity.MessageCursor) (map[string]int64, error)
 	Create(ctx context.Context, channelID string, message entity.Message) error
+	CreateReply(ctx context.Context, reply entity.Message) (*entity.Message, error)
+	// Fill は、MySQLから読み込んだメッセージをキャッシュに載せます。削除待ちか、削除を反映した直後のメッセージは載せません。
+	Fill(ctx context.Context, channelID string, messages []entity.Message) error
+	// CoveredSince は、チャンネルのメッセージをキャッシュが漏れなく保持している範囲の始まりを返します。記録がない場合はnilを返します。
+	CoveredSince(ctx context.Context, channelID string) (*time.Time, error)
+	// MarkCovered は、チャンネルのメッセージをsince以降キャッシュが漏れなく保持していることを記録します。
+	MarkCovered(ctx context.Context, channelID string, since time.Time) error
 	Update(ctx context.Context, message entity.Message) error
 	Delete(ctx context.Context, channelID, messageID string) error
+	DeleteReply(ctx context.Context, parentID, messageID string) (*entity.Message, error)
 	Exists(ctx context.Context, key string) bool
 	Scan(ctx context.Context, match string) ([]string, error)
+	// ListPending は、作成・編集と削除を合わせて、変更の古い順に最大limit件の永続化待ちのメッセージを返します。
+	ListPending(ctx context.Context, limit int64) ([]PendingMessage, error)
+	PendingDeletes(ctx context.Context, ids []string) (map[string]bool, error)
+	AckPending(ctx context.Context, pending []PendingMessage) error
+	DeadLetter(ctx context.Context, messages []DeadLetterMessage) error
+	// RequeueDeadLetters は、デッドレターのメッセージを永続化待ちに戻し、戻した件数を返します。idsが空の場合は全て戻します。
+	RequeueDeadLetters(ctx context.Context, ids []string) (int, error)
+	PendingStats(ctx context.Context) (*PendingStats, error)
+}
+
+// MessagePageQuery は、カーソルを起点にチャンネルのメッセージを指定件数だけ取得する条件です。
+// 結果はカーソルに近い順(Beforeなら新しい順、Afterなら古い順)で返します。
+type MessagePageQuery struct {
+	Cursor    *entity.MessageCursor // nilの場合、Beforeは最新から、Afterは最古から取得します
+	Direction string                // entity.PageDirectionBefore or entity.PageDirectionAfter
+	Limit     int64
+}
+
+// MessageSearchQuery は、メッセージを検索する条件です。
+type MessageSearchQuery struct {
+	Text         string     // 空の場合は本文で絞らず、新しい順に返します
+	ChannelIDs   []string   // 検索するチャンネル。空の場合は何も返しません
+	MembershipID string     // 作成者。空の場合は絞りません
+	Since        *time.Time // この日時以降に作成されたメッセージに絞ります
+	Until        *time.Time // この日時より前に作成されたメッセージに絞ります
+	HasLink      bool       // URLを含むメッセージに絞ります
+	Offset       int64
+	Limit        int64
+}
+
+// PendingMessage は、キャッシュ上で変更されたがまだ永続化されていないメッセージを表します。
+type PendingMessage struct {
+	ID       string
+	Message  *entity.Message // Deletedの場合、またはキャッシュから失われた場合はnil
+	Deleted  bool
+	QueuedAt time.Time
+}
+
+// DeadLetterMessage は、永続化できずに書き戻しを諦めたメッセージと、その理由を表します。
+type DeadLetterMessage struct {
+	PendingMessage
+	Reason string
+}
+
+// PendingStats は、永続化待ちのメッセージの件数と最も古い変更時刻を表します。
+type PendingStats struct {
+	Count          int64
+	OldestQueuedAt time.Time // Countが0の場合はゼロ値
+	DeadLetters    int64     // 書き戻しを諦めたメッセージの件数
 }
@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: blob.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockBlobRepository is a mock of BlobRepository interface.
+type MockBlobRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlobRepositoryMockRecorder
+}
+
+// MockBlobRepositoryMockRecorder is the mock recorder for MockBlobRepository.
+type MockBlobRepositoryMockRecorder struct {
+	mock *MockBlobRepository
+}
+
+// NewMockBlobRepository creates a new mock instance.
+func NewMockBlobRepository(ctrl *gomock.Controller) *MockBlobRepository {
+	mock := &MockBlobRepository{ctrl: ctrl}
+	mock.recorder = &MockBlobRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBlobRepository) EXPECT() *MockBlobRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockBlobRepository) Delete(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockBlobRepositoryMockRecorder) Delete(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockBlobRepository)(nil).Delete), ctx, key)
+}
+
+// Get mocks base method.
+func (m *MockBlobRepository) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, key)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockBlobRepositoryMockRecorder) Get(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockBlobRepository)(nil).Get), ctx, key)
+}
+
+// Put mocks base method.
+func (m *MockBlobRepository) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", ctx, key, body, size, contentType)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockBlobRepositoryMockRecorder) Put(ctx, key, body, size, contentType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockBlobRepository)(nil).Put), ctx, key, body, size, contentType)
+}
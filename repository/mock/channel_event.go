@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: channel_event.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockChannelEventRepository is a mock of ChannelEventRepository interface.
+type MockChannelEventRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockChannelEventRepositoryMockRecorder
+}
+
+// MockChannelEventRepositoryMockRecorder is the mock recorder for MockChannelEventRepository.
+type MockChannelEventRepositoryMockRecorder struct {
+	mock *MockChannelEventRepository
+}
+
+// NewMockChannelEventRepository creates a new mock instance.
+func NewMockChannelEventRepository(ctrl *gomock.Controller) *MockChannelEventRepository {
+	mock := &MockChannelEventRepository{ctrl: ctrl}
+	mock.recorder = &MockChannelEventRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChannelEventRepository) EXPECT() *MockChannelEventRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ListSince mocks base method.
+func (m *MockChannelEventRepository) ListSince(ctx context.Context, channelID string, seq int64) ([][]byte, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSince", ctx, channelID, seq)
+	ret0, _ := ret[0].([][]byte)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListSince indicates an expected call of ListSince.
+func (mr *MockChannelEventRepositoryMockRecorder) ListSince(ctx, channelID, seq interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSince", reflect.TypeOf((*MockChannelEventRepository)(nil).ListSince), ctx, channelID, seq)
+}
+
+// Publish mocks base method.
+func (m *MockChannelEventRepository) Publish(ctx context.Context, channelID string, payload []byte) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, channelID, payload)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockChannelEventRepositoryMockRecorder) Publish(ctx, channelID, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockChannelEventRepository)(nil).Publish), ctx, channelID, payload)
+}
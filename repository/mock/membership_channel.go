@@ -122,3 +122,17 @@ func (mr *MockMembershipChannelRepositoryMockRecorder) Update(ctx, id, membershi
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockMembershipChannelRepository)(nil).Update), ctx, id, membershipChannel)
 }
+
+// UpdateLastRead mocks base method.
+func (m *MockMembershipChannelRepository) UpdateLastRead(ctx context.Context, membershipChannel entity.MembershipChannel) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLastRead", ctx, membershipChannel)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLastRead indicates an expected call of UpdateLastRead.
+func (mr *MockMembershipChannelRepositoryMockRecorder) UpdateLastRead(ctx, membershipChannel interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLastRead", reflect.TypeOf((*MockMembershipChannelRepository)(nil).UpdateLastRead), ctx, membershipChannel)
+}
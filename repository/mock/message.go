@@ -52,6 +52,20 @@ func (mr *MockMessageRepositoryMockRecorder) BatchCreate(ctx, messages interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchCreate", reflect.TypeOf((*MockMessageRepository)(nil).BatchCreate), ctx, messages)
 }
 
+// BatchDelete mocks base method.
+func (m *MockMessageRepository) BatchDelete(ctx context.Context, ids []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchDelete", ctx, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BatchDelete indicates an expected call of BatchDelete.
+func (mr *MockMessageRepositoryMockRecorder) BatchDelete(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchDelete", reflect.TypeOf((*MockMessageRepository)(nil).BatchDelete), ctx, ids)
+}
+
 // Create mocks base method.
 func (m *MockMessageRepository) Create(ctx context.Context, message entity.Message) error {
 	m.ctrl.T.Helper()
@@ -124,6 +138,66 @@ func (mr *MockMessageRepositoryMockRecorder) List(ctx, qcs interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockMessageRepository)(nil).List), ctx, qcs)
 }
 
+// ListChannelMessages mocks base method.
+func (m *MockMessageRepository) ListChannelMessages(ctx context.Context, channelID string, start, end time.Time) ([]entity.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListChannelMessages", ctx, channelID, start, end)
+	ret0, _ := ret[0].([]entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListChannelMessages indicates an expected call of ListChannelMessages.
+func (mr *MockMessageRepositoryMockRecorder) ListChannelMessages(ctx, channelID, start, end interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChannelMessages", reflect.TypeOf((*MockMessageRepository)(nil).ListChannelMessages), ctx, channelID, start, end)
+}
+
+// ListPage mocks base method.
+func (m *MockMessageRepository) ListPage(ctx context.Context, channelID string, query repository.MessagePageQuery) ([]entity.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPage", ctx, channelID, query)
+	ret0, _ := ret[0].([]entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPage indicates an expected call of ListPage.
+func (mr *MockMessageRepositoryMockRecorder) ListPage(ctx, channelID, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPage", reflect.TypeOf((*MockMessageRepository)(nil).ListPage), ctx, channelID, query)
+}
+
+// ListThread mocks base method.
+func (m *MockMessageRepository) ListThread(ctx context.Context, parentID string) ([]entity.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListThread", ctx, parentID)
+	ret0, _ := ret[0].([]entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListThread indicates an expected call of ListThread.
+func (mr *MockMessageRepositoryMockRecorder) ListThread(ctx, parentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListThread", reflect.TypeOf((*MockMessageRepository)(nil).ListThread), ctx, parentID)
+}
+
+// Search mocks base method.
+func (m *MockMessageRepository) Search(ctx context.Context, query repository.MessageSearchQuery) ([]entity.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, query)
+	ret0, _ := ret[0].([]entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockMessageRepositoryMockRecorder) Search(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockMessageRepository)(nil).Search), ctx, query)
+}
+
 // Update mocks base method.
 func (m *MockMessageRepository) Update(ctx context.Context, id string, message entity.Message) error {
 	m.ctrl.T.Helper()
@@ -161,6 +235,50 @@ func (m *MockMessageCacheRepository) EXPECT() *MockMessageCacheRepositoryMockRec
 	return m.recorder
 }
 
+// AckPending mocks base method.
+func (m *MockMessageCacheRepository) AckPending(ctx context.Context, pending []repository.PendingMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AckPending", ctx, pending)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AckPending indicates an expected call of AckPending.
+func (mr *MockMessageCacheRepositoryMockRecorder) AckPending(ctx, pending interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AckPending", reflect.TypeOf((*MockMessageCacheRepository)(nil).AckPending), ctx, pending)
+}
+
+// CountAfter mocks base method.
+func (m *MockMessageCacheRepository) CountAfter(ctx context.Context, cursors map[string]*entity.MessageCursor) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAfter", ctx, cursors)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountAfter indicates an expected call of CountAfter.
+func (mr *MockMessageCacheRepositoryMockRecorder) CountAfter(ctx, cursors interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAfter", reflect.TypeOf((*MockMessageCacheRepository)(nil).CountAfter), ctx, cursors)
+}
+
+// CoveredSince mocks base method.
+func (m *MockMessageCacheRepository) CoveredSince(ctx context.Context, channelID string) (*time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CoveredSince", ctx, channelID)
+	ret0, _ := ret[0].(*time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CoveredSince indicates an expected call of CoveredSince.
+func (mr *MockMessageCacheRepositoryMockRecorder) CoveredSince(ctx, channelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CoveredSince", reflect.TypeOf((*MockMessageCacheRepository)(nil).CoveredSince), ctx, channelID)
+}
+
 // Create mocks base method.
 func (m *MockMessageCacheRepository) Create(ctx context.Context, channelID string, message entity.Message) error {
 	m.ctrl.T.Helper()
@@ -175,6 +293,35 @@ func (mr *MockMessageCacheRepositoryMockRecorder) Create(ctx, channelID, message
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockMessageCacheRepository)(nil).Create), ctx, channelID, message)
 }
 
+// CreateReply mocks base method.
+func (m *MockMessageCacheRepository) CreateReply(ctx context.Context, reply entity.Message) (*entity.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReply", ctx, reply)
+	ret0, _ := ret[0].(*entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReply indicates an expected call of CreateReply.
+func (mr *MockMessageCacheRepositoryMockRecorder) CreateReply(ctx, reply interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReply", reflect.TypeOf((*MockMessageCacheRepository)(nil).CreateReply), ctx, reply)
+}
+
+// DeadLetter mocks base method.
+func (m *MockMessageCacheRepository) DeadLetter(ctx context.Context, messages []repository.DeadLetterMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeadLetter", ctx, messages)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeadLetter indicates an expected call of DeadLetter.
+func (mr *MockMessageCacheRepositoryMockRecorder) DeadLetter(ctx, messages interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeadLetter", reflect.TypeOf((*MockMessageCacheRepository)(nil).DeadLetter), ctx, messages)
+}
+
 // Delete mocks base method.
 func (m *MockMessageCacheRepository) Delete(ctx context.Context, channelID, messageID string) error {
 	m.ctrl.T.Helper()
@@ -189,6 +336,21 @@ func (mr *MockMessageCacheRepositoryMockRecorder) Delete(ctx, channelID, message
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockMessageCacheRepository)(nil).Delete), ctx, channelID, messageID)
 }
 
+// DeleteReply mocks base method.
+func (m *MockMessageCacheRepository) DeleteReply(ctx context.Context, parentID, messageID string) (*entity.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReply", ctx, parentID, messageID)
+	ret0, _ := ret[0].(*entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteReply indicates an expected call of DeleteReply.
+func (mr *MockMessageCacheRepositoryMockRecorder) DeleteReply(ctx, parentID, messageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReply", reflect.TypeOf((*MockMessageCacheRepository)(nil).DeleteReply), ctx, parentID, messageID)
+}
+
 // Exists mocks base method.
 func (m *MockMessageCacheRepository) Exists(ctx context.Context, key string) bool {
 	m.ctrl.T.Helper()
@@ -203,6 +365,20 @@ func (mr *MockMessageCacheRepositoryMockRecorder) Exists(ctx, key interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockMessageCacheRepository)(nil).Exists), ctx, key)
 }
 
+// Fill mocks base method.
+func (m *MockMessageCacheRepository) Fill(ctx context.Context, channelID string, messages []entity.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fill", ctx, channelID, messages)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Fill indicates an expected call of Fill.
+func (mr *MockMessageCacheRepositoryMockRecorder) Fill(ctx, channelID, messages interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fill", reflect.TypeOf((*MockMessageCacheRepository)(nil).Fill), ctx, channelID, messages)
+}
+
 // Get mocks base method.
 func (m *MockMessageCacheRepository) Get(ctx context.Context, id string) (*entity.Message, error) {
 	m.ctrl.T.Helper()
@@ -233,6 +409,110 @@ func (mr *MockMessageCacheRepositoryMockRecorder) List(ctx, channelID, start, en
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockMessageCacheRepository)(nil).List), ctx, channelID, start, end)
 }
 
+// ListPage mocks base method.
+func (m *MockMessageCacheRepository) ListPage(ctx context.Context, channelID string, query repository.MessagePageQuery) ([]entity.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPage", ctx, channelID, query)
+	ret0, _ := ret[0].([]entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPage indicates an expected call of ListPage.
+func (mr *MockMessageCacheRepositoryMockRecorder) ListPage(ctx, channelID, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPage", reflect.TypeOf((*MockMessageCacheRepository)(nil).ListPage), ctx, channelID, query)
+}
+
+// ListPending mocks base method.
+func (m *MockMessageCacheRepository) ListPending(ctx context.Context, limit int64) ([]repository.PendingMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPending", ctx, limit)
+	ret0, _ := ret[0].([]repository.PendingMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPending indicates an expected call of ListPending.
+func (mr *MockMessageCacheRepositoryMockRecorder) ListPending(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPending", reflect.TypeOf((*MockMessageCacheRepository)(nil).ListPending), ctx, limit)
+}
+
+// ListThread mocks base method.
+func (m *MockMessageCacheRepository) ListThread(ctx context.Context, parentID string) ([]entity.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListThread", ctx, parentID)
+	ret0, _ := ret[0].([]entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListThread indicates an expected call of ListThread.
+func (mr *MockMessageCacheRepositoryMockRecorder) ListThread(ctx, parentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListThread", reflect.TypeOf((*MockMessageCacheRepository)(nil).ListThread), ctx, parentID)
+}
+
+// MarkCovered mocks base method.
+func (m *MockMessageCacheRepository) MarkCovered(ctx context.Context, channelID string, since time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkCovered", ctx, channelID, since)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkCovered indicates an expected call of MarkCovered.
+func (mr *MockMessageCacheRepositoryMockRecorder) MarkCovered(ctx, channelID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkCovered", reflect.TypeOf((*MockMessageCacheRepository)(nil).MarkCovered), ctx, channelID, since)
+}
+
+// PendingDeletes mocks base method.
+func (m *MockMessageCacheRepository) PendingDeletes(ctx context.Context, ids []string) (map[string]bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingDeletes", ctx, ids)
+	ret0, _ := ret[0].(map[string]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PendingDeletes indicates an expected call of PendingDeletes.
+func (mr *MockMessageCacheRepositoryMockRecorder) PendingDeletes(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingDeletes", reflect.TypeOf((*MockMessageCacheRepository)(nil).PendingDeletes), ctx, ids)
+}
+
+// PendingStats mocks base method.
+func (m *MockMessageCacheRepository) PendingStats(ctx context.Context) (*repository.PendingStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingStats", ctx)
+	ret0, _ := ret[0].(*repository.PendingStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PendingStats indicates an expected call of PendingStats.
+func (mr *MockMessageCacheRepositoryMockRecorder) PendingStats(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingStats", reflect.TypeOf((*MockMessageCacheRepository)(nil).PendingStats), ctx)
+}
+
+// RequeueDeadLetters mocks base method.
+func (m *MockMessageCacheRepository) RequeueDeadLetters(ctx context.Context, ids []string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueDeadLetters", ctx, ids)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueDeadLetters indicates an expected call of RequeueDeadLetters.
+func (mr *MockMessageCacheRepositoryMockRecorder) RequeueDeadLetters(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueDeadLetters", reflect.TypeOf((*MockMessageCacheRepository)(nil).RequeueDeadLetters), ctx, ids)
+}
+
 // Scan mocks base method.
 func (m *MockMessageCacheRepository) Scan(ctx context.Context, match string) ([]string, error) {
 	m.ctrl.T.Helper()
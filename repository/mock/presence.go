@@ -0,0 +1,97 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: presence.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/connectHub-backend/entity"
+)
+
+// MockPresenceRepository is a mock of PresenceRepository interface.
+type MockPresenceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPresenceRepositoryMockRecorder
+}
+
+// MockPresenceRepositoryMockRecorder is the mock recorder for MockPresenceRepository.
+type MockPresenceRepositoryMockRecorder struct {
+	mock *MockPresenceRepository
+}
+
+// NewMockPresenceRepository creates a new mock instance.
+func NewMockPresenceRepository(ctrl *gomock.Controller) *MockPresenceRepository {
+	mock := &MockPresenceRepository{ctrl: ctrl}
+	mock.recorder = &MockPresenceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPresenceRepository) EXPECT() *MockPresenceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockPresenceRepository) List(ctx context.Context, membershipIDs []string) ([]entity.Presence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, membershipIDs)
+	ret0, _ := ret[0].([]entity.Presence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockPresenceRepositoryMockRecorder) List(ctx, membershipIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockPresenceRepository)(nil).List), ctx, membershipIDs)
+}
+
+// Remove mocks base method.
+func (m *MockPresenceRepository) Remove(ctx context.Context, membershipID, connectionID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", ctx, membershipID, connectionID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockPresenceRepositoryMockRecorder) Remove(ctx, membershipID, connectionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockPresenceRepository)(nil).Remove), ctx, membershipID, connectionID)
+}
+
+// SetStatus mocks base method.
+func (m *MockPresenceRepository) SetStatus(ctx context.Context, membershipID, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStatus", ctx, membershipID, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetStatus indicates an expected call of SetStatus.
+func (mr *MockPresenceRepositoryMockRecorder) SetStatus(ctx, membershipID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStatus", reflect.TypeOf((*MockPresenceRepository)(nil).SetStatus), ctx, membershipID, status)
+}
+
+// Touch mocks base method.
+func (m *MockPresenceRepository) Touch(ctx context.Context, membershipID, connectionID string, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Touch", ctx, membershipID, connectionID, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Touch indicates an expected call of Touch.
+func (mr *MockPresenceRepositoryMockRecorder) Touch(ctx, membershipID, connectionID, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Touch", reflect.TypeOf((*MockPresenceRepository)(nil).Touch), ctx, membershipID, connectionID, ttl)
+}
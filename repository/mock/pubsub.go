@@ -8,10 +8,76 @@ import (
 	context "context"
 	reflect "reflect"
 
-	redis "github.com/go-redis/redis/v8"
 	gomock "github.com/golang/mock/gomock"
+
+	repository "github.com/tusmasoma/connectHub-backend/repository"
 )
 
+// MockSubscription is a mock of Subscription interface.
+type MockSubscription struct {
+	ctrl     *gomock.Controller
+	recorder *MockSubscriptionMockRecorder
+}
+
+// MockSubscriptionMockRecorder is the mock recorder for MockSubscription.
+type MockSubscriptionMockRecorder struct {
+	mock *MockSubscription
+}
+
+// NewMockSubscription creates a new mock instance.
+func NewMockSubscription(ctrl *gomock.Controller) *MockSubscription {
+	mock := &MockSubscription{ctrl: ctrl}
+	mock.recorder = &MockSubscriptionMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSubscription) EXPECT() *MockSubscriptionMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockSubscription) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockSubscriptionMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockSubscription)(nil).Close))
+}
+
+// Err mocks base method.
+func (m *MockSubscription) Err() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Err")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Err indicates an expected call of Err.
+func (mr *MockSubscriptionMockRecorder) Err() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Err", reflect.TypeOf((*MockSubscription)(nil).Err))
+}
+
+// Messages mocks base method.
+func (m *MockSubscription) Messages() <-chan []byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Messages")
+	ret0, _ := ret[0].(<-chan []byte)
+	return ret0
+}
+
+// Messages indicates an expected call of Messages.
+func (mr *MockSubscriptionMockRecorder) Messages() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Messages", reflect.TypeOf((*MockSubscription)(nil).Messages))
+}
+
 // MockPubSubRepository is a mock of PubSubRepository interface.
 type MockPubSubRepository struct {
 	ctrl     *gomock.Controller
@@ -36,29 +102,30 @@ func (m *MockPubSubRepository) EXPECT() *MockPubSubRepositoryMockRecorder {
 }
 
 // Publish mocks base method.
-func (m *MockPubSubRepository) Publish(ctx context.Context, channel string, message any) error {
+func (m *MockPubSubRepository) Publish(ctx context.Context, topic string, message []byte) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Publish", ctx, channel, message)
+	ret := m.ctrl.Call(m, "Publish", ctx, topic, message)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Publish indicates an expected call of Publish.
-func (mr *MockPubSubRepositoryMockRecorder) Publish(ctx, channel, message interface{}) *gomock.Call {
+func (mr *MockPubSubRepositoryMockRecorder) Publish(ctx, topic, message interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockPubSubRepository)(nil).Publish), ctx, channel, message)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockPubSubRepository)(nil).Publish), ctx, topic, message)
 }
 
 // Subscribe mocks base method.
-func (m *MockPubSubRepository) Subscribe(ctx context.Context, channel string) *redis.PubSub {
+func (m *MockPubSubRepository) Subscribe(ctx context.Context, topic string) (repository.Subscription, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Subscribe", ctx, channel)
-	ret0, _ := ret[0].(*redis.PubSub)
-	return ret0
+	ret := m.ctrl.Call(m, "Subscribe", ctx, topic)
+	ret0, _ := ret[0].(repository.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Subscribe indicates an expected call of Subscribe.
-func (mr *MockPubSubRepositoryMockRecorder) Subscribe(ctx, channel interface{}) *gomock.Call {
+func (mr *MockPubSubRepositoryMockRecorder) Subscribe(ctx, topic interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockPubSubRepository)(nil).Subscribe), ctx, channel)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockPubSubRepository)(nil).Subscribe), ctx, topic)
 }
@@ -0,0 +1,160 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: reaction.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/connectHub-backend/entity"
+)
+
+// MockReactionRepository is a mock of ReactionRepository interface.
+type MockReactionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReactionRepositoryMockRecorder
+}
+
+// MockReactionRepositoryMockRecorder is the mock recorder for MockReactionRepository.
+type MockReactionRepositoryMockRecorder struct {
+	mock *MockReactionRepository
+}
+
+// NewMockReactionRepository creates a new mock instance.
+func NewMockReactionRepository(ctrl *gomock.Controller) *MockReactionRepository {
+	mock := &MockReactionRepository{ctrl: ctrl}
+	mock.recorder = &MockReactionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReactionRepository) EXPECT() *MockReactionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockReactionRepository) Create(ctx context.Context, reaction entity.Reaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, reaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockReactionRepositoryMockRecorder) Create(ctx, reaction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockReactionRepository)(nil).Create), ctx, reaction)
+}
+
+// Delete mocks base method.
+func (m *MockReactionRepository) Delete(ctx context.Context, reaction entity.Reaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, reaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockReactionRepositoryMockRecorder) Delete(ctx, reaction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockReactionRepository)(nil).Delete), ctx, reaction)
+}
+
+// ListByMessageIDs mocks base method.
+func (m *MockReactionRepository) ListByMessageIDs(ctx context.Context, messageIDs []string) ([]entity.Reaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByMessageIDs", ctx, messageIDs)
+	ret0, _ := ret[0].([]entity.Reaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByMessageIDs indicates an expected call of ListByMessageIDs.
+func (mr *MockReactionRepositoryMockRecorder) ListByMessageIDs(ctx, messageIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByMessageIDs", reflect.TypeOf((*MockReactionRepository)(nil).ListByMessageIDs), ctx, messageIDs)
+}
+
+// MockReactionCacheRepository is a mock of ReactionCacheRepository interface.
+type MockReactionCacheRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReactionCacheRepositoryMockRecorder
+}
+
+// MockReactionCacheRepositoryMockRecorder is the mock recorder for MockReactionCacheRepository.
+type MockReactionCacheRepositoryMockRecorder struct {
+	mock *MockReactionCacheRepository
+}
+
+// NewMockReactionCacheRepository creates a new mock instance.
+func NewMockReactionCacheRepository(ctrl *gomock.Controller) *MockReactionCacheRepository {
+	mock := &MockReactionCacheRepository{ctrl: ctrl}
+	mock.recorder = &MockReactionCacheRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReactionCacheRepository) EXPECT() *MockReactionCacheRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockReactionCacheRepository) Add(ctx context.Context, reaction entity.Reaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, reaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockReactionCacheRepositoryMockRecorder) Add(ctx, reaction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockReactionCacheRepository)(nil).Add), ctx, reaction)
+}
+
+// Fill mocks base method.
+func (m *MockReactionCacheRepository) Fill(ctx context.Context, messageIDs []string, reactions []entity.Reaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fill", ctx, messageIDs, reactions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Fill indicates an expected call of Fill.
+func (mr *MockReactionCacheRepositoryMockRecorder) Fill(ctx, messageIDs, reactions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fill", reflect.TypeOf((*MockReactionCacheRepository)(nil).Fill), ctx, messageIDs, reactions)
+}
+
+// List mocks base method.
+func (m *MockReactionCacheRepository) List(ctx context.Context, messageIDs []string) (map[string][]entity.Reaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, messageIDs)
+	ret0, _ := ret[0].(map[string][]entity.Reaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockReactionCacheRepositoryMockRecorder) List(ctx, messageIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockReactionCacheRepository)(nil).List), ctx, messageIDs)
+}
+
+// Remove mocks base method.
+func (m *MockReactionCacheRepository) Remove(ctx context.Context, reaction entity.Reaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", ctx, reaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockReactionCacheRepositoryMockRecorder) Remove(ctx, reaction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockReactionCacheRepository)(nil).Remove), ctx, reaction)
+}
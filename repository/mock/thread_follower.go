@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: thread_follower.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/connectHub-backend/entity"
+	repository "github.com/tusmasoma/connectHub-backend/repository"
+)
+
+// MockThreadFollowerRepository is a mock of ThreadFollowerRepository interface.
+type MockThreadFollowerRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockThreadFollowerRepositoryMockRecorder
+}
+
+// MockThreadFollowerRepositoryMockRecorder is the mock recorder for MockThreadFollowerRepository.
+type MockThreadFollowerRepositoryMockRecorder struct {
+	mock *MockThreadFollowerRepository
+}
+
+// NewMockThreadFollowerRepository creates a new mock instance.
+func NewMockThreadFollowerRepository(ctrl *gomock.Controller) *MockThreadFollowerRepository {
+	mock := &MockThreadFollowerRepository{ctrl: ctrl}
+	mock.recorder = &MockThreadFollowerRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockThreadFollowerRepository) EXPECT() *MockThreadFollowerRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockThreadFollowerRepository) Create(ctx context.Context, follower entity.ThreadFollower) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, follower)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockThreadFollowerRepositoryMockRecorder) Create(ctx, follower interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockThreadFollowerRepository)(nil).Create), ctx, follower)
+}
+
+// Delete mocks base method.
+func (m *MockThreadFollowerRepository) Delete(ctx context.Context, messageID, membershipID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, messageID, membershipID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockThreadFollowerRepositoryMockRecorder) Delete(ctx, messageID, membershipID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockThreadFollowerRepository)(nil).Delete), ctx, messageID, membershipID)
+}
+
+// List mocks base method.
+func (m *MockThreadFollowerRepository) List(ctx context.Context, qcs []repository.QueryCondition) ([]entity.ThreadFollower, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, qcs)
+	ret0, _ := ret[0].([]entity.ThreadFollower)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockThreadFollowerRepositoryMockRecorder) List(ctx, qcs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockThreadFollowerRepository)(nil).List), ctx, qcs)
+}
@@ -190,21 +190,6 @@ func (mr *MockUserCacheRepositoryMockRecorder) Get(ctx, key interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockUserCacheRepository)(nil).Get), ctx, key)
 }
 
-// GetUserSession mocks base method.
-func (m *MockUserCacheRepository) GetUserSession(ctx context.Context, userID string) (string, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserSession", ctx, userID)
-	ret0, _ := ret[0].(string)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetUserSession indicates an expected call of GetUserSession.
-func (mr *MockUserCacheRepositoryMockRecorder) GetUserSession(ctx, userID interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSession", reflect.TypeOf((*MockUserCacheRepository)(nil).GetUserSession), ctx, userID)
-}
-
 // Scan mocks base method.
 func (m *MockUserCacheRepository) Scan(ctx context.Context, match string) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -233,17 +218,3 @@ func (mr *MockUserCacheRepositoryMockRecorder) Set(ctx, key, user interface{}) *
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockUserCacheRepository)(nil).Set), ctx, key, user)
 }
-
-// SetUserSession mocks base method.
-func (m *MockUserCacheRepository) SetUserSession(ctx context.Context, userID, sessionData string) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetUserSession", ctx, userID, sessionData)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// SetUserSession indicates an expected call of SetUserSession.
-func (mr *MockUserCacheRepositoryMockRecorder) SetUserSession(ctx, userID, sessionData interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserSession", reflect.TypeOf((*MockUserCacheRepository)(nil).SetUserSession), ctx, userID, sessionData)
-}
@@ -0,0 +1,115 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/doug-martin/goqu/v9"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+type attachmentRepository struct {
+	*base[entity.Attachment]
+}
+
+func NewAttachmentRepository(db *sql.DB, dialect *goqu.DialectWrapper) repository.AttachmentRepository {
+	return &attachmentRepository{
+		base: newBase[entity.Attachment](db, dialect, "Attachments"),
+	}
+}
+
+func (ar *attachmentRepository) Get(ctx context.Context, id string) (*entity.Attachment, error) {
+	attachment, err := ar.base.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrAttachmentNotFound
+		}
+		log.Error("Failed to get attachment", log.Fstring("attachmentID", id), log.Ferror(err))
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func (ar *attachmentRepository) ListByIDs(ctx context.Context, ids []string) ([]entity.Attachment, error) {
+	executor := ar.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	query, _, err := ar.dialect.From(ar.tableName).Select(ar.columns()...).
+		Where(goqu.C("id").In(ids)).
+		ToSQL()
+	if err != nil {
+		log.Error("Failed to generate SQL query", log.Ferror(err))
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, query)
+	if err != nil {
+		log.Error("Failed to execute query", log.Ferror(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	return ar.structScanRows(rows)
+}
+
+// AttachToMessage は、他のメッセージに添付済みのファイルを更新の条件から除くことで、同じファイルを2つのメッセージに添付できないようにします。
+func (ar *attachmentRepository) AttachToMessage(ctx context.Context, messageID, membershipID string, ids []string) (int64, error) {
+	executor := ar.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	query, _, err := ar.dialect.Update(ar.tableName).
+		Set(goqu.Record{"message_id": messageID}).
+		Where(
+			goqu.C("id").In(ids),
+			goqu.C("membership_id").Eq(membershipID),
+			goqu.C("message_id").Eq(""),
+		).
+		ToSQL()
+	if err != nil {
+		log.Error("Failed to generate SQL query", log.Ferror(err))
+		return 0, err
+	}
+
+	result, err := executor.ExecContext(ctx, query)
+	if err != nil {
+		log.Error("Failed to execute query", log.Ferror(err))
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (ar *attachmentRepository) DetachFromMessage(ctx context.Context, messageID string) error {
+	executor := ar.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query, _, err := ar.dialect.Update(ar.tableName).
+		Set(goqu.Record{"message_id": ""}).
+		Where(goqu.C("message_id").Eq(messageID)).
+		ToSQL()
+	if err != nil {
+		log.Error("Failed to generate SQL query", log.Ferror(err))
+		return err
+	}
+
+	if _, err = executor.ExecContext(ctx, query); err != nil {
+		log.Error("Failed to execute query", log.Ferror(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+func Test_AttachmentRepository(t *testing.T) {
+	dialect := goqu.Dialect("mysql")
+	ctx := context.Background()
+	workspaceID := "5fe0e237-6b49-11ee-b686-0242c0a87001"
+	channelID := "5fe0e239-6b49-11ee-b686-0242c0a87001"
+	johnID := "5fe0e23e-6b49-11ee-b686-0242c0a87001_5fe0e237-6b49-11ee-b686-0242c0a87001"
+	janeID := "5fe0e23f-6b49-11ee-b686-0242c0a87001_5fe0e237-6b49-11ee-b686-0242c0a87001"
+	createdAt := time.Date(2023, 1, 1, 10, 30, 0, 0, time.UTC)
+	attachments := []entity.Attachment{
+		{ID: uuid.New().String(), WorkspaceID: workspaceID, ChannelID: channelID, MembershipID: johnID, Name: "a.png", Size: 1, MimeType: "image/png", Checksum: "a", CreatedAt: createdAt},
+		{ID: uuid.New().String(), WorkspaceID: workspaceID, ChannelID: channelID, MembershipID: janeID, Name: "b.png", Size: 2, MimeType: "image/png", Checksum: "b", CreatedAt: createdAt},
+	}
+
+	repo := NewAttachmentRepository(db, &dialect)
+
+	for _, attachment := range attachments {
+		err := repo.Create(ctx, attachment)
+		ValidateErr(t, err, nil)
+	}
+
+	got, err := repo.Get(ctx, attachments[0].ID)
+	ValidateErr(t, err, nil)
+	if got.Name != attachments[0].Name || got.MessageID != "" {
+		t.Errorf("Expected unattached attachment %v, got %v", attachments[0], got)
+	}
+	_, err = repo.Get(ctx, uuid.New().String())
+	ValidateErr(t, err, repository.ErrAttachmentNotFound)
+
+	// Only the uploader's attachments which are not yet attached are claimed
+	messageID := uuid.New().String()
+	ids := []string{attachments[0].ID, attachments[1].ID}
+	claimed, err := repo.AttachToMessage(ctx, messageID, johnID, ids)
+	ValidateErr(t, err, nil)
+	if claimed != 1 {
+		t.Errorf("Expected 1 attachment to be claimed, got %d", claimed)
+	}
+	claimed, err = repo.AttachToMessage(ctx, uuid.New().String(), johnID, ids[:1])
+	ValidateErr(t, err, nil)
+	if claimed != 0 {
+		t.Errorf("Expected an attached attachment not to be claimed again, got %d", claimed)
+	}
+
+	listed, err := repo.ListByIDs(ctx, ids)
+	ValidateErr(t, err, nil)
+	if len(listed) != 2 {
+		t.Fatalf("Expected 2 attachments, got %v", listed)
+	}
+	for _, attachment := range listed {
+		if (attachment.ID == attachments[0].ID) != (attachment.MessageID == messageID) {
+			t.Errorf("Expected only %s to be attached to %s, got %v", attachments[0].ID, messageID, attachment)
+		}
+	}
+
+	err = repo.DetachFromMessage(ctx, messageID)
+	ValidateErr(t, err, nil)
+	got, err = repo.Get(ctx, attachments[0].ID)
+	ValidateErr(t, err, nil)
+	if got.MessageID != "" {
+		t.Errorf("Expected attachment to be detached, got %v", got)
+	}
+
+	for _, attachment := range attachments {
+		err = repo.Delete(ctx, attachment.ID)
+		ValidateErr(t, err, nil)
+	}
+}
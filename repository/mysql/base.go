@@ -28,17 +28,45 @@ func newBase[T any](db *sql.DB, dialect *goqu.DialectWrapper, tableName string)
 	}
 }
 
-// structScanは、構造体のフィールドをスキャンするためのヘルパー関数です。
-func (b *base[T]) structScanRow(entity *T, row *sql.Row) error {
+// columnsは、構造体のdbタグからSELECTするカラムを組み立てます。
+// テーブルのカラム順と構造体のフィールド順が異なっていても、structScanRow(s)と同じ順序でスキャンできます。
+func (b *base[T]) columns() []interface{} {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+
+	var columns []interface{}
+	for i := 0; i < t.NumField(); i++ {
+		if tag, ok := dbTag(t.Field(i)); ok {
+			columns = append(columns, goqu.C(tag))
+		}
+	}
+	return columns
+}
+
+// scanFieldsは、dbタグを持つフィールドのポインタをcolumnsと同じ順序で返します。
+func (b *base[T]) scanFields(entity *T) []interface{} {
 	v := reflect.ValueOf(entity).Elem()
 	t := v.Type()
 
-	fields := make([]interface{}, t.NumField())
+	var fields []interface{}
 	for i := 0; i < t.NumField(); i++ {
-		fields[i] = v.Field(i).Addr().Interface()
+		if _, ok := dbTag(t.Field(i)); ok {
+			fields = append(fields, v.Field(i).Addr().Interface())
+		}
 	}
+	return fields
+}
+
+func dbTag(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("db")
+	if tag == "" || tag == "-" {
+		return "", false
+	}
+	return tag, true
+}
 
-	if err := row.Scan(fields...); err != nil {
+// structScanは、構造体のフィールドをスキャンするためのヘルパー関数です。
+func (b *base[T]) structScanRow(entity *T, row *sql.Row) error {
+	if err := row.Scan(b.scanFields(entity)...); err != nil {
 		log.Error("Failed to scan row", log.Ferror(err))
 		return err
 	}
@@ -51,14 +79,7 @@ func (b *base[T]) structScanRows(rows *sql.Rows) ([]T, error) {
 	var entities []T
 	for rows.Next() {
 		var entity T
-		v := reflect.ValueOf(&entity).Elem()
-		t := v.Type()
-
-		fields := make([]interface{}, t.NumField())
-		for i := 0; i < t.NumField(); i++ {
-			fields[i] = v.Field(i).Addr().Interface()
-		}
-		if err := rows.Scan(fields...); err != nil {
+		if err := rows.Scan(b.scanFields(&entity)...); err != nil {
 			log.Error("Failed to scan rows", log.Ferror(err))
 			return nil, err
 		}
@@ -82,7 +103,7 @@ func (b *base[T]) List(ctx context.Context, qcs []repository.QueryCondition) ([]
 		whereClauses = append(whereClauses, goqu.C(qc.Field).Eq(qc.Value))
 	}
 
-	query, _, err := b.dialect.From(b.tableName).Select("*").Where(whereClauses...).ToSQL()
+	query, _, err := b.dialect.From(b.tableName).Select(b.columns()...).Where(whereClauses...).ToSQL()
 	if err != nil {
 		log.Error("Failed to generate SQL query", log.Ferror(err))
 		return nil, err
@@ -109,7 +130,7 @@ func (b *base[T]) Get(ctx context.Context, id string) (*T, error) {
 	}
 
 	var entity T
-	query, _, err := b.dialect.From(b.tableName).Select("*").Where(goqu.C("id").Eq(id)).ToSQL()
+	query, _, err := b.dialect.From(b.tableName).Select(b.columns()...).Where(goqu.C("id").Eq(id)).ToSQL()
 	if err != nil {
 		log.Error("Failed to generate SQL query", log.Ferror(err))
 		return nil, err
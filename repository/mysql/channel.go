@@ -23,7 +23,7 @@ func NewChannelRepository(db *sql.DB, dialect *goqu.DialectWrapper) repository.C
 
 func (rr *channelRepository) ListMembershipChannels(ctx context.Context, membershipID string) ([]entity.Channel, error) {
 	query := `
-	SELECT Channels.id, Channels.workspace_id, Channels.name, Channels.description, Channels.private
+	SELECT Channels.id, Channels.workspace_id, Channels.name, Channels.description, Channels.private, Channels.direct
 	FROM Channels
 	JOIN Membership_Channels ON Channels.id = Membership_Channels.channel_id
 	JOIN Memberships ON Membership_Channels.membership_id = Memberships.id
@@ -46,6 +46,7 @@ func (rr *channelRepository) ListMembershipChannels(ctx context.Context, members
 			&channel.Name,
 			&channel.Description,
 			&channel.Private,
+			&channel.Direct,
 		)
 		if err != nil {
 			log.Error("Failed to scan channel", log.Ferror(err))
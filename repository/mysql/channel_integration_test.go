@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/doug-martin/goqu/v9"
+
+	"github.com/tusmasoma/connectHub-backend/repository"
 )
 
 func Test_ChannelRepository(t *testing.T) {
@@ -24,4 +26,19 @@ func Test_ChannelRepository(t *testing.T) {
 	if channels[0].ID != "5fe0e239-6b49-11ee-b686-0242c0a87001" {
 		t.Errorf("Expected channel ID to be 5fe0e239-6b49-11ee-b686-0242c0a87001, got %s", channels[0].ID)
 	}
+
+	// List scans columns by name even though the table orders private before description
+	channels, err = repo.List(ctx, []repository.QueryCondition{{Field: "workspace_id", Value: workspaceID}})
+	ValidateErr(t, err, nil)
+	if len(channels) != 2 {
+		t.Errorf("Expected 2 channels, got %d", len(channels))
+	}
+	for _, channel := range channels {
+		if channel.ID == "5fe0e23a-6b49-11ee-b686-0242c0a87001" && !channel.Private {
+			t.Errorf("Expected channel %s to be private", channel.ID)
+		}
+		if channel.Description == "" {
+			t.Errorf("Expected channel %s to have a description", channel.ID)
+		}
+	}
 }
@@ -80,6 +80,12 @@ func startMySQL() (*sql.DB, string, func(), error) {
 					Source: pwd + "/init/my.cnf",
 					Target: "/etc/mysql/my.cnf",
 				},
+				{
+					// 本番用のDDLも読み込み、構文の誤りがあればコンテナの起動で失敗させます
+					Type:   "bind",
+					Source: pwd + "/init/ddl.sql",
+					Target: "/docker-entrypoint-initdb.d/ddl.sql",
+				},
 				{
 					Type:   "bind",
 					Source: pwd + "/test/dml.test.sql",
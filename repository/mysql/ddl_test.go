@@ -0,0 +1,65 @@
+package mysql
+
+import (
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var createTableRe = regexp.MustCompile(`(?s)CREATE TABLE (\w+) \((.*?)\n\);`)
+
+// parseTables は、DDLのCREATE TABLEごとに、コメントと空白を取り除いた定義の行を返します。
+func parseTables(t *testing.T, path string) map[string][]string {
+	t.Helper()
+
+	ddl, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+
+	tables := make(map[string][]string)
+	for _, m := range createTableRe.FindAllStringSubmatch(string(ddl), -1) {
+		var lines []string
+		for _, line := range strings.Split(m[2], "\n") {
+			if i := strings.Index(line, "--"); i >= 0 {
+				line = line[:i]
+			}
+			if line = strings.Join(strings.Fields(line), " "); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		tables[m[1]] = lines
+	}
+	return tables
+}
+
+// Test_DDL は、本番用のDDLとテスト用のDDLのテーブル定義が一致し、カンマが抜けていないことを確認します。
+// テスト用のDDLだけを読み込む結合テストでは、本番用のDDLの誤りに気付けないためです。
+func Test_DDL(t *testing.T) {
+	t.Parallel()
+
+	schema := parseTables(t, "init/ddl.sql")
+	testSchema := parseTables(t, "test/ddl.test.sql")
+	if len(schema) == 0 {
+		t.Fatal("No tables found in init/ddl.sql")
+	}
+
+	for name, lines := range schema {
+		for i, line := range lines[:len(lines)-1] {
+			if !strings.HasSuffix(line, ",") {
+				t.Errorf("%s: missing comma after %q (next: %q)", name, line, lines[i+1])
+			}
+		}
+		if !reflect.DeepEqual(lines, testSchema[name]) {
+			t.Errorf("%s differs between init/ddl.sql and test/ddl.test.sql:\n%v\n%v", name, lines, testSchema[name])
+		}
+	}
+	for name := range testSchema {
+		// TestItemsはbaseのテスト専用のテーブルです。
+		if _, ok := schema[name]; !ok && name != "TestItems" {
+			t.Errorf("%s is missing from init/ddl.sql", name)
+		}
+	}
+}
@@ -33,7 +33,7 @@ func (mr *membershipRepository) Get(ctx context.Context, id string) (*entity.Mem
 		log.Error("Failed to split membership ID", log.Ferror(err))
 		return nil, err
 	}
-	query, _, err := mr.dialect.Select("*").From(mr.tableName).Where(
+	query, _, err := mr.dialect.Select(mr.columns()...).From(mr.tableName).Where(
 		goqu.C("user_id").Eq(userID),
 		goqu.C("workspace_id").Eq(workspaceID),
 	).ToSQL()
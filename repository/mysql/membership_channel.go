@@ -3,6 +3,7 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"errors"
 
 	"github.com/doug-martin/goqu/v9"
 
@@ -28,7 +29,7 @@ func (mrr *membershipChannelRepository) Get(ctx context.Context, membershipID, c
 	}
 
 	var membershipChannel entity.MembershipChannel
-	query, _, err := mrr.dialect.Select("*").From(mrr.tableName).Where(
+	query, _, err := mrr.dialect.Select(mrr.columns()...).From(mrr.tableName).Where(
 		goqu.C("membership_id").Eq(membershipID),
 		goqu.C("channel_id").Eq(channelID),
 	).ToSQL()
@@ -39,6 +40,9 @@ func (mrr *membershipChannelRepository) Get(ctx context.Context, membershipID, c
 
 	row := executor.QueryRowContext(ctx, query)
 	if err = mrr.structScanRow(&membershipChannel, row); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrMembershipChannelNotFound
+		}
 		return nil, err
 	}
 	return &membershipChannel, nil
@@ -66,3 +70,28 @@ func (mrr *membershipChannelRepository) Delete(ctx context.Context, membershipID
 	}
 	return nil
 }
+
+func (mrr *membershipChannelRepository) UpdateLastRead(ctx context.Context, membershipChannel entity.MembershipChannel) error {
+	executor := mrr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query, _, err := mrr.dialect.Update(mrr.tableName).Set(goqu.Record{
+		"last_read_message_id": membershipChannel.LastReadMessageID,
+		"last_read_at":         membershipChannel.LastReadAt,
+	}).Where(
+		goqu.C("membership_id").Eq(membershipChannel.MembershipID),
+		goqu.C("channel_id").Eq(membershipChannel.ChannelID),
+	).ToSQL()
+	if err != nil {
+		log.Error("Failed to generate SQL query", log.Ferror(err))
+		return err
+	}
+
+	if _, err = executor.ExecContext(ctx, query); err != nil {
+		log.Error("Failed to execute query", log.Ferror(err))
+		return err
+	}
+	return nil
+}
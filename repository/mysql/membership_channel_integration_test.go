@@ -2,13 +2,16 @@ package mysql
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/doug-martin/goqu/v9"
 	"github.com/google/uuid"
 
 	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository"
 )
 
 func Test_MembershipChannelRepository(t *testing.T) {
@@ -71,12 +74,23 @@ func Test_MembershipChannelRepository(t *testing.T) {
 		t.Errorf("Get() = %v, want %v", getMembershipChannel, membershipChannel)
 	}
 
+	membershipChannel.MarkRead(entity.Message{ID: uuid.New().String(), CreatedAt: time.Now()})
+	err = membershipChannelRepo.UpdateLastRead(ctx, membershipChannel)
+	ValidateErr(t, err, nil)
+
+	getMembershipChannel, err = membershipChannelRepo.Get(ctx, membershipID, channelID)
+	ValidateErr(t, err, nil)
+	if getMembershipChannel.LastReadMessageID != membershipChannel.LastReadMessageID ||
+		getMembershipChannel.LastReadAt == nil || !getMembershipChannel.LastReadAt.Equal(*membershipChannel.LastReadAt) {
+		t.Errorf("Get() = %v, want last read %v", getMembershipChannel, membershipChannel)
+	}
+
 	err = membershipChannelRepo.Delete(ctx, membershipID, channelID)
 	ValidateErr(t, err, nil)
 
 	getMembershipChannel, err = membershipChannelRepo.Get(ctx, membershipID, channelID)
-	if err == nil {
-		t.Errorf("Expected error for deleted item, got nil")
+	if !errors.Is(err, repository.ErrMembershipChannelNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, repository.ErrMembershipChannelNotFound)
 	}
 	if getMembershipChannel != nil {
 		t.Errorf("Expected nil for deleted item, got %v", getMembershipChannel)
@@ -1,11 +1,16 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
+	"strings"
+	"time"
 
 	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
 
 	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
 	"github.com/tusmasoma/connectHub-backend/repository"
 )
 
@@ -18,3 +23,257 @@ func NewMessageRepository(db *sql.DB, dialect *goqu.DialectWrapper) repository.M
 		base: newBase[entity.Message](db, dialect, "Messages"),
 	}
 }
+
+// BatchCreate は、キャッシュからの書き戻しで編集済みのメッセージも受け取るため、既存の行はtextとupdated_at、返信の集計を更新します。
+// goquのOnConflictはMySQLでINSERT IGNOREを生成し外部キー違反まで握りつぶしてしまうため、句を直接付与しています。
+func (mr *messageRepository) BatchCreate(ctx context.Context, messages []entity.Message) error {
+	executor := mr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+	if len(messages) == 0 {
+		log.Warn("No messages to insert")
+		return nil
+	}
+
+	query, _, err := mr.dialect.Insert(mr.tableName).Rows(messages).ToSQL()
+	if err != nil {
+		log.Error("Failed to generate SQL query", log.Ferror(err))
+		return err
+	}
+	query += " ON DUPLICATE KEY UPDATE `text` = VALUES(`text`), `updated_at` = VALUES(`updated_at`)," +
+		" `reply_count` = VALUES(`reply_count`), `last_reply_at` = VALUES(`last_reply_at`)"
+
+	if _, err = executor.ExecContext(ctx, query); err != nil {
+		log.Error("Failed to execute query", log.Ferror(err))
+		return wrapRejected(err, repository.ErrMessageRejected)
+	}
+	return nil
+}
+
+func (mr *messageRepository) BatchDelete(ctx context.Context, ids []string) error {
+	executor := mr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+	if len(ids) == 0 {
+		log.Warn("No messages to delete")
+		return nil
+	}
+
+	// メッセージと返信に付けられたリアクションを先に削除します。
+	query, _, err := mr.dialect.Delete("Reactions").Where(goqu.Or(
+		goqu.C("message_id").In(ids),
+		goqu.L("`message_id` IN (SELECT `id` FROM `Messages` WHERE `parent_id` IN ?)", ids),
+	)).ToSQL()
+	if err != nil {
+		log.Error("Failed to generate SQL query", log.Ferror(err))
+		return err
+	}
+
+	if _, err = executor.ExecContext(ctx, query); err != nil {
+		log.Error("Failed to execute query", log.Ferror(err))
+		return wrapRejected(err, repository.ErrMessageRejected)
+	}
+
+	// 親メッセージが削除された場合、スレッドの返信も合わせて削除します。
+	query, _, err = mr.dialect.Delete(mr.tableName).Where(goqu.Or(
+		goqu.C("id").In(ids),
+		goqu.C("parent_id").In(ids),
+	)).ToSQL()
+	if err != nil {
+		log.Error("Failed to generate SQL query", log.Ferror(err))
+		return err
+	}
+
+	if _, err = executor.ExecContext(ctx, query); err != nil {
+		log.Error("Failed to execute query", log.Ferror(err))
+		return wrapRejected(err, repository.ErrMessageRejected)
+	}
+	return nil
+}
+
+// MySQLのTIMESTAMP型が表現できる範囲です。範囲外の値はクエリ生成時に丸めます。
+var (
+	minTimestamp = time.Date(1970, 1, 1, 0, 0, 1, 0, time.UTC)
+	maxTimestamp = time.Date(2038, 1, 19, 3, 14, 7, 0, time.UTC)
+)
+
+// ListChannelMessages は、チャンネル内でstartからendまでに作成されたメッセージを作成日時の昇順で取得します。
+// スレッドの返信は含みません。
+func (mr *messageRepository) ListChannelMessages(ctx context.Context, channelID string, start, end time.Time) ([]entity.Message, error) { //nolint:lll // Ignore long line length
+	executor := mr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query, _, err := mr.dialect.From(mr.tableName).Select(mr.columns()...).Where(
+		goqu.C("channel_id").Eq(channelID),
+		goqu.C("parent_id").Eq(""),
+		goqu.C("created_at").Between(goqu.Range(clampTimestamp(start), clampTimestamp(end))),
+	).Order(goqu.C("created_at").Asc(), goqu.C("id").Asc()).ToSQL()
+	if err != nil {
+		log.Error("Failed to generate SQL query", log.Ferror(err))
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, query)
+	if err != nil {
+		log.Error("Failed to execute query", log.Ferror(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	return mr.structScanRows(rows)
+}
+
+// ListPage は、(created_at, id)の順序でカーソルから指定件数のメッセージを取得します。スレッドの返信は含みません。
+func (mr *messageRepository) ListPage(ctx context.Context, channelID string, query repository.MessagePageQuery) ([]entity.Message, error) { //nolint:lll // Ignore long line length
+	executor := mr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	before := query.Direction != entity.PageDirectionAfter
+
+	where := []goqu.Expression{goqu.C("channel_id").Eq(channelID), goqu.C("parent_id").Eq("")}
+	if query.Cursor != nil {
+		createdAt := time.Unix(query.Cursor.Score, 0).UTC()
+		if before {
+			where = append(where, goqu.Or(
+				goqu.C("created_at").Lt(createdAt),
+				goqu.And(goqu.C("created_at").Eq(createdAt), goqu.C("id").Lt(query.Cursor.ID)),
+			))
+		} else {
+			where = append(where, goqu.Or(
+				goqu.C("created_at").Gt(createdAt),
+				goqu.And(goqu.C("created_at").Eq(createdAt), goqu.C("id").Gt(query.Cursor.ID)),
+			))
+		}
+	}
+
+	order := []exp.OrderedExpression{goqu.C("created_at").Asc(), goqu.C("id").Asc()}
+	if before {
+		order = []exp.OrderedExpression{goqu.C("created_at").Desc(), goqu.C("id").Desc()}
+	}
+
+	sqlQuery, _, err := mr.dialect.From(mr.tableName).Select(mr.columns()...).
+		Where(where...).
+		Order(order...).
+		Limit(uint(query.Limit)).
+		ToSQL()
+	if err != nil {
+		log.Error("Failed to generate SQL query", log.Ferror(err))
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, sqlQuery)
+	if err != nil {
+		log.Error("Failed to execute query", log.Ferror(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	return mr.structScanRows(rows)
+}
+
+// ListThread は、スレッドの返信を作成日時の昇順で取得します。
+func (mr *messageRepository) ListThread(ctx context.Context, parentID string) ([]entity.Message, error) {
+	executor := mr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query, _, err := mr.dialect.From(mr.tableName).Select(mr.columns()...).
+		Where(goqu.C("parent_id").Eq(parentID)).
+		Order(goqu.C("created_at").Asc(), goqu.C("id").Asc()).
+		ToSQL()
+	if err != nil {
+		log.Error("Failed to generate SQL query", log.Ferror(err))
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, query)
+	if err != nil {
+		log.Error("Failed to execute query", log.Ferror(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	return mr.structScanRows(rows)
+}
+
+// Search は、FULLTEXTインデックスを使って本文を検索します。スレッドの返信も含みます。
+// 本文を指定した場合は関連度の高い順、同じ関連度では新しい順に、指定しない場合は新しい順に並べます。
+func (mr *messageRepository) Search(ctx context.Context, query repository.MessageSearchQuery) ([]entity.Message, error) {
+	executor := mr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+	if len(query.ChannelIDs) == 0 {
+		return nil, nil
+	}
+
+	where := []goqu.Expression{goqu.C("channel_id").In(query.ChannelIDs)}
+	var order []exp.OrderedExpression
+	if terms := fulltextQuery(query.Text); terms != "" {
+		match := goqu.L("MATCH(`text`) AGAINST(? IN BOOLEAN MODE)", terms)
+		where = append(where, match)
+		order = append(order, match.Desc())
+	}
+	if query.MembershipID != "" {
+		where = append(where, goqu.C("membership_id").Eq(query.MembershipID))
+	}
+	if query.Since != nil {
+		where = append(where, goqu.C("created_at").Gte(clampTimestamp(*query.Since)))
+	}
+	if query.Until != nil {
+		where = append(where, goqu.C("created_at").Lt(clampTimestamp(*query.Until)))
+	}
+	if query.HasLink {
+		where = append(where, goqu.Or(goqu.C("text").ILike("%http://%"), goqu.C("text").ILike("%https://%")))
+	}
+	order = append(order, goqu.C("created_at").Desc(), goqu.C("id").Desc())
+
+	sqlQuery, _, err := mr.dialect.From(mr.tableName).Select(mr.columns()...).
+		Where(where...).
+		Order(order...).
+		Offset(uint(query.Offset)).
+		Limit(uint(query.Limit)).
+		ToSQL()
+	if err != nil {
+		log.Error("Failed to generate SQL query", log.Ferror(err))
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, sqlQuery)
+	if err != nil {
+		log.Error("Failed to execute query", log.Ferror(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	return mr.structScanRows(rows)
+}
+
+// fulltextQuery は、空白で区切った語を全て含むメッセージに一致するBOOLEAN MODEの検索式を作ります。
+// 各語はフレーズとして囲むため、ngramで分割されても語の並びのまま一致し、語に含まれる演算子も文字として扱われます。
+func fulltextQuery(text string) string {
+	var terms []string
+	for _, term := range strings.Fields(text) {
+		if term = strings.ReplaceAll(term, `"`, ""); term != "" {
+			terms = append(terms, `+"`+term+`"`)
+		}
+	}
+	return strings.Join(terms, " ")
+}
+
+func clampTimestamp(t time.Time) time.Time {
+	if t.Before(minTimestamp) {
+		return minTimestamp
+	}
+	if t.After(maxTimestamp) {
+		return maxTimestamp
+	}
+	return t
+}
@@ -0,0 +1,218 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+func Test_MessageRepository(t *testing.T) {
+	dialect := goqu.Dialect("mysql")
+	ctx := context.Background()
+	membershipID := "5fe0e23e-6b49-11ee-b686-0242c0a87001_5fe0e237-6b49-11ee-b686-0242c0a87001"
+	channelID := "5fe0e239-6b49-11ee-b686-0242c0a87001"
+
+	repo := NewMessageRepository(db, &dialect)
+
+	msgs := []entity.Message{
+		{ID: uuid.New().String(), MembershipID: membershipID, ChannelID: channelID, Text: "content1", CreatedAt: time.Now()},
+		{ID: uuid.New().String(), MembershipID: membershipID, ChannelID: channelID, Text: "content2", CreatedAt: time.Now()},
+	}
+
+	// BatchCreate
+	err := repo.BatchCreate(ctx, msgs)
+	ValidateErr(t, err, nil)
+
+	// BatchCreate updates messages which already exist
+	updatedAt := time.Now()
+	msgs[0].Text = "updated content"
+	msgs[0].UpdatedAt = &updatedAt
+	err = repo.BatchCreate(ctx, msgs[:1])
+	ValidateErr(t, err, nil)
+
+	got, err := repo.Get(ctx, msgs[0].ID)
+	ValidateErr(t, err, nil)
+	if got.Text != "updated content" {
+		t.Errorf("Expected message text 'updated content', got %s", got.Text)
+	}
+	if got.UpdatedAt == nil {
+		t.Errorf("Expected updated_at to be set")
+	}
+
+	// BatchDelete
+	err = repo.BatchDelete(ctx, []string{msgs[0].ID, msgs[1].ID})
+	ValidateErr(t, err, nil)
+
+	_, err = repo.Get(ctx, msgs[0].ID)
+	if err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+
+	// ListChannelMessages
+	listed, err := repo.ListChannelMessages(
+		ctx,
+		channelID,
+		time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+	)
+	ValidateErr(t, err, nil)
+	if len(listed) != 1 || listed[0].ID != "5fe0e241-6b49-11ee-b686-0242c0a87001" {
+		t.Errorf("Expected message 5fe0e241-6b49-11ee-b686-0242c0a87001, got %v", listed)
+	}
+
+	// ListChannelMessages clamps windows outside the range of TIMESTAMP
+	listed, err = repo.ListChannelMessages(ctx, channelID, time.Unix(0, 0), time.Unix(1<<63-62135596801, 999999999))
+	ValidateErr(t, err, nil)
+	if len(listed) != 1 {
+		t.Errorf("Expected 1 message, got %d", len(listed))
+	}
+}
+
+func Test_MessageRepository_ListPage(t *testing.T) {
+	dialect := goqu.Dialect("mysql")
+	ctx := context.Background()
+	membershipID := "5fe0e23e-6b49-11ee-b686-0242c0a87001_5fe0e237-6b49-11ee-b686-0242c0a87001"
+	channelID := "5fe0e239-6b49-11ee-b686-0242c0a87001"
+	createdAt := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	repo := NewMessageRepository(db, &dialect)
+
+	// 既存の5fe0e241...と同じ秒に作成されたメッセージを追加します
+	msgs := []entity.Message{
+		{ID: "5fe0e240-0000-0000-0000-000000000000", MembershipID: membershipID, ChannelID: channelID, Text: "tie before", CreatedAt: createdAt},
+		{ID: "5fe0e242-0000-0000-0000-000000000000", MembershipID: membershipID, ChannelID: channelID, Text: "tie after", CreatedAt: createdAt},
+	}
+	err := repo.BatchCreate(ctx, msgs)
+	ValidateErr(t, err, nil)
+	defer func() {
+		err = repo.BatchDelete(ctx, []string{msgs[0].ID, msgs[1].ID})
+		ValidateErr(t, err, nil)
+	}()
+
+	cursor := &entity.MessageCursor{Score: createdAt.Unix(), ID: "5fe0e241-6b49-11ee-b686-0242c0a87001"}
+
+	got, err := repo.ListPage(ctx, channelID, repository.MessagePageQuery{
+		Cursor:    cursor,
+		Direction: entity.PageDirectionBefore,
+		Limit:     10,
+	})
+	ValidateErr(t, err, nil)
+	if len(got) != 1 || got[0].ID != msgs[0].ID {
+		t.Errorf("Expected message %s before the cursor, got %v", msgs[0].ID, got)
+	}
+
+	got, err = repo.ListPage(ctx, channelID, repository.MessagePageQuery{
+		Cursor:    cursor,
+		Direction: entity.PageDirectionAfter,
+		Limit:     10,
+	})
+	ValidateErr(t, err, nil)
+	if len(got) != 1 || got[0].ID != msgs[1].ID {
+		t.Errorf("Expected message %s after the cursor, got %v", msgs[1].ID, got)
+	}
+
+	got, err = repo.ListPage(ctx, channelID, repository.MessagePageQuery{
+		Direction: entity.PageDirectionBefore,
+		Limit:     2,
+	})
+	ValidateErr(t, err, nil)
+	if len(got) != 2 || got[0].ID != msgs[1].ID {
+		t.Errorf("Expected the latest message %s first, got %v", msgs[1].ID, got)
+	}
+}
+
+func Test_MessageRepository_Thread(t *testing.T) {
+	dialect := goqu.Dialect("mysql")
+	ctx := context.Background()
+	membershipID := "5fe0e23e-6b49-11ee-b686-0242c0a87001_5fe0e237-6b49-11ee-b686-0242c0a87001"
+	channelID := "5fe0e239-6b49-11ee-b686-0242c0a87001"
+	parentID := "5fe0e241-6b49-11ee-b686-0242c0a87001"
+	createdAt := time.Date(2023, 1, 1, 10, 30, 0, 0, time.UTC)
+
+	repo := NewMessageRepository(db, &dialect)
+
+	replies := []entity.Message{
+		{ID: uuid.New().String(), MembershipID: membershipID, ChannelID: channelID, ParentID: parentID, Text: "reply1", CreatedAt: createdAt},
+		{ID: uuid.New().String(), MembershipID: membershipID, ChannelID: channelID, ParentID: parentID, Text: "reply2", CreatedAt: createdAt.Add(time.Minute)},
+	}
+	err := repo.BatchCreate(ctx, replies)
+	ValidateErr(t, err, nil)
+
+	got, err := repo.ListThread(ctx, parentID)
+	ValidateErr(t, err, nil)
+	if len(got) != 2 || got[0].ID != replies[0].ID || got[1].ID != replies[1].ID {
+		t.Errorf("Expected replies %s and %s, got %v", replies[0].ID, replies[1].ID, got)
+	}
+
+	// Replies are not part of the channel history
+	got, err = repo.ListChannelMessages(ctx, channelID, createdAt, createdAt.Add(time.Hour))
+	ValidateErr(t, err, nil)
+	if len(got) != 0 {
+		t.Errorf("Expected no channel messages, got %v", got)
+	}
+
+	// Deleting a reply keeps the rest of the thread
+	err = repo.BatchDelete(ctx, []string{replies[0].ID})
+	ValidateErr(t, err, nil)
+	got, err = repo.ListThread(ctx, parentID)
+	ValidateErr(t, err, nil)
+	if len(got) != 1 {
+		t.Errorf("Expected 1 reply, got %d", len(got))
+	}
+	err = repo.BatchDelete(ctx, []string{replies[1].ID})
+	ValidateErr(t, err, nil)
+}
+
+func Test_MessageRepository_Search(t *testing.T) {
+	dialect := goqu.Dialect("mysql")
+	ctx := context.Background()
+	membershipID := "5fe0e23e-6b49-11ee-b686-0242c0a87001_5fe0e237-6b49-11ee-b686-0242c0a87001"
+	channelID := "5fe0e239-6b49-11ee-b686-0242c0a87001"
+	createdAt := time.Date(2023, 2, 1, 10, 30, 0, 0, time.UTC)
+
+	repo := NewMessageRepository(db, &dialect)
+
+	msgs := []entity.Message{
+		{ID: uuid.New().String(), MembershipID: membershipID, ChannelID: channelID, Text: "デプロイ手順 https://example.com", CreatedAt: createdAt},
+		{ID: uuid.New().String(), MembershipID: membershipID, ChannelID: channelID, Text: "デプロイ完了", CreatedAt: createdAt.Add(time.Minute)},
+		{ID: uuid.New().String(), MembershipID: membershipID, ChannelID: channelID, Text: "ランチ", CreatedAt: createdAt.Add(2 * time.Minute)},
+	}
+	err := repo.BatchCreate(ctx, msgs)
+	ValidateErr(t, err, nil)
+
+	got, err := repo.Search(ctx, repository.MessageSearchQuery{Text: "デプロイ", ChannelIDs: []string{channelID}, Limit: 10})
+	ValidateErr(t, err, nil)
+	if len(got) != 2 {
+		t.Errorf("Expected 2 messages, got %v", got)
+	}
+
+	// Filters narrow the matches
+	until := createdAt.Add(time.Minute)
+	got, err = repo.Search(ctx, repository.MessageSearchQuery{
+		Text:       "デプロイ",
+		ChannelIDs: []string{channelID},
+		Until:      &until,
+		HasLink:    true,
+		Limit:      10,
+	})
+	ValidateErr(t, err, nil)
+	if len(got) != 1 || got[0].ID != msgs[0].ID {
+		t.Errorf("Expected message %s, got %v", msgs[0].ID, got)
+	}
+
+	// Channels outside the scope are never searched
+	got, err = repo.Search(ctx, repository.MessageSearchQuery{Text: "デプロイ", Limit: 10})
+	ValidateErr(t, err, nil)
+	if len(got) != 0 {
+		t.Errorf("Expected no messages, got %v", got)
+	}
+
+	err = repo.BatchDelete(ctx, []string{msgs[0].ID, msgs[1].ID, msgs[2].ID})
+	ValidateErr(t, err, nil)
+}
@@ -3,9 +3,10 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
-	_ "github.com/go-sql-driver/mysql" // This blank import is used for its init function
+	"github.com/go-sql-driver/mysql"
 
 	"github.com/tusmasoma/connectHub-backend/config"
 	"github.com/tusmasoma/connectHub-backend/internal/log"
@@ -61,6 +62,26 @@ func (tr *transactionRepository) Transaction(ctx context.Context, fn func(ctx co
 	return nil
 }
 
+// rejectedRowErrors は、行の内容が原因でMySQLが書き込みを拒否したことを表すエラー番号です。
+var rejectedRowErrors = map[uint16]bool{
+	1048: true, // ER_BAD_NULL_ERROR
+	1264: true, // ER_WARN_DATA_OUT_OF_RANGE
+	1292: true, // ER_TRUNCATED_WRONG_VALUE
+	1366: true, // ER_TRUNCATED_WRONG_VALUE_FOR_FIELD
+	1406: true, // ER_DATA_TOO_LONG
+	1451: true, // ER_ROW_IS_REFERENCED_2
+	1452: true, // ER_NO_REFERENCED_ROW_2
+}
+
+// wrapRejected は、行の内容が原因のエラーをrejectedで包み、呼び出し元が一時的なエラーと区別できるようにします。
+func wrapRejected(err, rejected error) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && rejectedRowErrors[mysqlErr.Number] {
+		return fmt.Errorf("%w: %w", rejected, err)
+	}
+	return err
+}
+
 type TxKey string
 
 func CtxTxKey() TxKey {
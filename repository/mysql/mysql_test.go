@@ -0,0 +1,56 @@
+package mysql
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+func Test_wrapRejected(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "foreign key constraint",
+			err:  &mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row"},
+			want: true,
+		},
+		{
+			name: "data too long",
+			err:  fmt.Errorf("exec: %w", &mysql.MySQLError{Number: 1406, Message: "Data too long for column"}),
+			want: true,
+		},
+		{
+			name: "lock wait timeout",
+			err:  &mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"},
+			want: false,
+		},
+		{
+			name: "connection error",
+			err:  mysql.ErrInvalidConn,
+			want: false,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := wrapRejected(tt.err, repository.ErrMessageRejected)
+			if got := errors.Is(err, repository.ErrMessageRejected); got != tt.want {
+				t.Errorf("wrapRejected() rejected = %v, want %v", got, tt.want)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("wrapRejected() = %v, want to wrap %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+type notificationRepository struct {
+	*base[entity.Notification]
+}
+
+func NewNotificationRepository(db *sql.DB, dialect *goqu.DialectWrapper) repository.NotificationRepository {
+	return &notificationRepository{
+		base: newBase[entity.Notification](db, dialect, "Notifications"),
+	}
+}
+
+func (nr *notificationRepository) List(ctx context.Context, membershipID string, unreadOnly bool, limit int64) ([]entity.Notification, error) { //nolint:lll // Ignore long line length
+	executor := nr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	where := []goqu.Expression{goqu.C("membership_id").Eq(membershipID)}
+	if unreadOnly {
+		where = append(where, goqu.C("read_at").IsNull())
+	}
+
+	query, _, err := nr.dialect.From(nr.tableName).Select(nr.columns()...).
+		Where(where...).
+		Order(goqu.C("created_at").Desc(), goqu.C("id").Desc()).
+		Limit(uint(limit)).
+		ToSQL()
+	if err != nil {
+		log.Error("Failed to generate SQL query", log.Ferror(err))
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, query)
+	if err != nil {
+		log.Error("Failed to execute query", log.Ferror(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	return nr.structScanRows(rows)
+}
+
+func (nr *notificationRepository) BatchCreate(ctx context.Context, notifications []entity.Notification) error {
+	executor := nr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+	if len(notifications) == 0 {
+		log.Warn("No notifications to insert")
+		return nil
+	}
+
+	query, _, err := nr.dialect.Insert(nr.tableName).Rows(notifications).ToSQL()
+	if err != nil {
+		log.Error("Failed to generate SQL query", log.Ferror(err))
+		return err
+	}
+
+	if _, err = executor.ExecContext(ctx, query); err != nil {
+		log.Error("Failed to execute query", log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+func (nr *notificationRepository) MarkRead(ctx context.Context, membershipID string, ids []string, readAt time.Time) error {
+	executor := nr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	where := []goqu.Expression{
+		goqu.C("membership_id").Eq(membershipID),
+		goqu.C("read_at").IsNull(),
+	}
+	if len(ids) > 0 {
+		where = append(where, goqu.C("id").In(ids))
+	}
+
+	query, _, err := nr.dialect.Update(nr.tableName).
+		Set(goqu.Record{"read_at": readAt}).
+		Where(where...).
+		ToSQL()
+	if err != nil {
+		log.Error("Failed to generate SQL query", log.Ferror(err))
+		return err
+	}
+
+	if _, err = executor.ExecContext(ctx, query); err != nil {
+		log.Error("Failed to execute query", log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+func (nr *notificationRepository) MarkChannelRead(ctx context.Context, membershipID, channelID string, before, readAt time.Time) error { //nolint:lll // Ignore long line length
+	executor := nr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query, _, err := nr.dialect.Update(nr.tableName).
+		Set(goqu.Record{"read_at": readAt}).
+		Where(
+			goqu.C("membership_id").Eq(membershipID),
+			goqu.C("channel_id").Eq(channelID),
+			goqu.C("created_at").Lt(before),
+			goqu.C("read_at").IsNull(),
+		).
+		ToSQL()
+	if err != nil {
+		log.Error("Failed to generate SQL query", log.Ferror(err))
+		return err
+	}
+
+	if _, err = executor.ExecContext(ctx, query); err != nil {
+		log.Error("Failed to execute query", log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+func (nr *notificationRepository) CountUnreadByChannel(ctx context.Context, membershipID string) (map[string]int64, error) {
+	executor := nr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query, _, err := nr.dialect.From(nr.tableName).
+		Select(goqu.C("channel_id"), goqu.COUNT("*")).
+		Where(
+			goqu.C("membership_id").Eq(membershipID),
+			goqu.C("read_at").IsNull(),
+		).
+		GroupBy(goqu.C("channel_id")).
+		ToSQL()
+	if err != nil {
+		log.Error("Failed to generate SQL query", log.Ferror(err))
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, query)
+	if err != nil {
+		log.Error("Failed to execute query", log.Ferror(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var channelID string
+		var count int64
+		if err = rows.Scan(&channelID, &count); err != nil {
+			log.Error("Failed to scan row", log.Ferror(err))
+			return nil, err
+		}
+		counts[channelID] = count
+	}
+	if err = rows.Err(); err != nil {
+		log.Error("Failed to iterate rows", log.Ferror(err))
+		return nil, err
+	}
+	return counts, nil
+}
@@ -0,0 +1,81 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+)
+
+func Test_NotificationRepository(t *testing.T) {
+	dialect := goqu.Dialect("mysql")
+	ctx := context.Background()
+	johnID := "5fe0e23e-6b49-11ee-b686-0242c0a87001_5fe0e237-6b49-11ee-b686-0242c0a87001"
+	janeID := "5fe0e23f-6b49-11ee-b686-0242c0a87001_5fe0e237-6b49-11ee-b686-0242c0a87001"
+	message := entity.Message{
+		ID:           "5fe0e241-6b49-11ee-b686-0242c0a87001",
+		MembershipID: johnID,
+		ChannelID:    "5fe0e239-6b49-11ee-b686-0242c0a87001",
+		Text:         "@Jane Smith @channel",
+	}
+
+	repo := NewNotificationRepository(db, &dialect)
+
+	var notifications []entity.Notification
+	for i, kind := range []string{entity.MentionKindMembership, entity.MentionKindChannel} {
+		notification, err := entity.NewNotification(janeID, message, kind)
+		ValidateErr(t, err, nil)
+		notification.CreatedAt = time.Date(2023, 1, 1, 10, i, 0, 0, time.UTC)
+		notifications = append(notifications, *notification)
+	}
+	err := repo.BatchCreate(ctx, notifications)
+	ValidateErr(t, err, nil)
+
+	got, err := repo.List(ctx, janeID, true, 10)
+	ValidateErr(t, err, nil)
+	if len(got) != 2 || got[0].ID != notifications[1].ID {
+		t.Errorf("Expected newest notification %s first, got %v", notifications[1].ID, got)
+	}
+
+	// Only the recipient can mark notifications as read
+	err = repo.MarkRead(ctx, johnID, nil, time.Now())
+	ValidateErr(t, err, nil)
+	err = repo.MarkRead(ctx, janeID, []string{notifications[0].ID}, time.Now())
+	ValidateErr(t, err, nil)
+
+	got, err = repo.List(ctx, janeID, true, 10)
+	ValidateErr(t, err, nil)
+	if len(got) != 1 || got[0].ID != notifications[1].ID {
+		t.Errorf("Expected only notification %s to be unread, got %v", notifications[1].ID, got)
+	}
+	got, err = repo.List(ctx, janeID, false, 10)
+	ValidateErr(t, err, nil)
+	if len(got) != 2 || got[1].ReadAt == nil {
+		t.Errorf("Expected read notification to be listed with read_at, got %v", got)
+	}
+
+	counts, err := repo.CountUnreadByChannel(ctx, janeID)
+	ValidateErr(t, err, nil)
+	if counts[message.ChannelID] != 1 {
+		t.Errorf("Expected 1 unread notification in channel, got %v", counts)
+	}
+
+	// Reading the channel marks only the notifications created before the read message
+	err = repo.MarkChannelRead(ctx, janeID, message.ChannelID, notifications[1].CreatedAt, time.Now())
+	ValidateErr(t, err, nil)
+	counts, err = repo.CountUnreadByChannel(ctx, janeID)
+	ValidateErr(t, err, nil)
+	if counts[message.ChannelID] != 1 {
+		t.Errorf("Expected newer notification to stay unread, got %v", counts)
+	}
+	err = repo.MarkChannelRead(ctx, janeID, message.ChannelID, notifications[1].CreatedAt.Add(time.Second), time.Now())
+	ValidateErr(t, err, nil)
+	counts, err = repo.CountUnreadByChannel(ctx, janeID)
+	ValidateErr(t, err, nil)
+	if len(counts) != 0 {
+		t.Errorf("Expected no unread notifications, got %v", counts)
+	}
+}
@@ -0,0 +1,95 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/doug-martin/goqu/v9"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+type reactionRepository struct {
+	*base[entity.Reaction]
+}
+
+func NewReactionRepository(db *sql.DB, dialect *goqu.DialectWrapper) repository.ReactionRepository {
+	return &reactionRepository{
+		base: newBase[entity.Reaction](db, dialect, "Reactions"),
+	}
+}
+
+// ListByMessageIDs は、メッセージのリアクションを付けられた順に取得します。
+func (rr *reactionRepository) ListByMessageIDs(ctx context.Context, messageIDs []string) ([]entity.Reaction, error) {
+	executor := rr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+	if len(messageIDs) == 0 {
+		return nil, nil
+	}
+
+	query, _, err := rr.dialect.From(rr.tableName).Select(rr.columns()...).
+		Where(goqu.C("message_id").In(messageIDs)).
+		Order(goqu.C("created_at").Asc(), goqu.C("membership_id").Asc()).
+		ToSQL()
+	if err != nil {
+		log.Error("Failed to generate SQL query", log.Ferror(err))
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, query)
+	if err != nil {
+		log.Error("Failed to execute query", log.Ferror(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	return rr.structScanRows(rows)
+}
+
+// Create は、同じリアクションが既にある場合は何もしません。
+func (rr *reactionRepository) Create(ctx context.Context, reaction entity.Reaction) error {
+	executor := rr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query, _, err := rr.dialect.Insert(rr.tableName).Rows(reaction).ToSQL()
+	if err != nil {
+		log.Error("Failed to generate SQL query", log.Ferror(err))
+		return err
+	}
+	query += " ON DUPLICATE KEY UPDATE `created_at` = `created_at`"
+
+	if _, err = executor.ExecContext(ctx, query); err != nil {
+		log.Error("Failed to execute query", log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+func (rr *reactionRepository) Delete(ctx context.Context, reaction entity.Reaction) error {
+	executor := rr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query, _, err := rr.dialect.Delete(rr.tableName).Where(
+		goqu.C("message_id").Eq(reaction.MessageID),
+		goqu.C("membership_id").Eq(reaction.MembershipID),
+		goqu.C("emoji").Eq(reaction.Emoji),
+	).ToSQL()
+	if err != nil {
+		log.Error("Failed to generate SQL query", log.Ferror(err))
+		return err
+	}
+
+	if _, err = executor.ExecContext(ctx, query); err != nil {
+		log.Error("Failed to execute query", log.Ferror(err))
+		return err
+	}
+	return nil
+}
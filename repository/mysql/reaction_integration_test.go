@@ -0,0 +1,63 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+)
+
+func Test_ReactionRepository(t *testing.T) {
+	dialect := goqu.Dialect("mysql")
+	ctx := context.Background()
+	messageID := uuid.New().String()
+	johnID := "5fe0e23e-6b49-11ee-b686-0242c0a87001_5fe0e237-6b49-11ee-b686-0242c0a87001"
+	janeID := "5fe0e23f-6b49-11ee-b686-0242c0a87001_5fe0e237-6b49-11ee-b686-0242c0a87001"
+	createdAt := time.Date(2023, 1, 1, 10, 30, 0, 0, time.UTC)
+	reactions := []entity.Reaction{
+		{MessageID: messageID, MembershipID: johnID, Emoji: "👍", CreatedAt: createdAt},
+		{MessageID: messageID, MembershipID: janeID, Emoji: "👍", CreatedAt: createdAt.Add(time.Second)},
+		{MessageID: messageID, MembershipID: johnID, Emoji: "🎉", CreatedAt: createdAt.Add(2 * time.Second)},
+	}
+
+	repo := NewReactionRepository(db, &dialect)
+
+	// Reacting twice with the same emoji is not an error
+	for _, reaction := range append(reactions, reactions[0]) {
+		err := repo.Create(ctx, reaction)
+		ValidateErr(t, err, nil)
+	}
+
+	got, err := repo.ListByMessageIDs(ctx, []string{messageID})
+	ValidateErr(t, err, nil)
+	if len(got) != len(reactions) {
+		t.Fatalf("Expected %d reactions, got %v", len(reactions), got)
+	}
+	for i := range reactions {
+		if got[i].MembershipID != reactions[i].MembershipID || got[i].Emoji != reactions[i].Emoji {
+			t.Errorf("Expected reaction %v, got %v", reactions[i], got[i])
+		}
+	}
+
+	err = repo.Delete(ctx, reactions[0])
+	ValidateErr(t, err, nil)
+	got, err = repo.ListByMessageIDs(ctx, []string{messageID})
+	ValidateErr(t, err, nil)
+	if len(got) != len(reactions)-1 {
+		t.Errorf("Expected %d reactions, got %v", len(reactions)-1, got)
+	}
+
+	// Reactions are removed together with their message
+	messages := NewMessageRepository(db, &dialect)
+	err = messages.BatchDelete(ctx, []string{messageID})
+	ValidateErr(t, err, nil)
+	got, err = repo.ListByMessageIDs(ctx, []string{messageID})
+	ValidateErr(t, err, nil)
+	if len(got) != 0 {
+		t.Errorf("Expected no reactions, got %v", got)
+	}
+}
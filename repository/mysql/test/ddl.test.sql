@@ -17,6 +17,10 @@ CREATE TABLE TestItems (
 );
 
 -- ドメインのテスト用のテーブル
+DROP TABLE IF EXISTS Attachments CASCADE;
+DROP TABLE IF EXISTS Notifications CASCADE;
+DROP TABLE IF EXISTS Reactions CASCADE;
+DROP TABLE IF EXISTS Thread_Followers CASCADE;
 DROP TABLE IF EXISTS Messages CASCADE;
 DROP TABLE IF EXISTS Membership_Channels CASCADE;
 DROP TABLE IF EXISTS Memberships CASCADE;
@@ -28,7 +32,9 @@ DROP TABLE IF EXISTS Workspaces CASCADE;
 CREATE TABLE Workspaces (
     id CHAR(36) PRIMARY KEY, -- UUIDは36文字の文字列として格納されます
     name VARCHAR(50) NOT NULL,
-    description TEXT
+    description TEXT,
+    max_attachment_size BIGINT NOT NULL DEFAULT 0, -- 0の場合はサーバーの設定値を使います
+    allowed_attachment_types VARCHAR(255) NOT NULL DEFAULT '' -- MIMEタイプのカンマ区切り。空の場合はサーバーの設定値を使います
 );
 
 CREATE TABLE Channels (
@@ -36,6 +42,7 @@ CREATE TABLE Channels (
     workspace_id CHAR(36) NOT NULL,
     name VARCHAR(50) NOT NULL,
     private BOOLEAN NOT NULL,
+    direct BOOLEAN NOT NULL DEFAULT FALSE, -- DMチャンネルの場合はTRUE。nameは参加者から決まる"dm:"始まりの値になります
     description TEXT,
     FOREIGN KEY (workspace_id) REFERENCES Workspaces(id) ON DELETE CASCADE,
     UNIQUE (workspace_id, name)
@@ -69,6 +76,8 @@ CREATE TABLE Memberships (
 CREATE TABLE Membership_Channels (
     membership_id CHAR(73) NOT NULL,
     channel_id CHAR(36) NOT NULL,
+    last_read_message_id CHAR(36) NOT NULL DEFAULT '', -- 既読にしていないチャンネルは空文字
+    last_read_at TIMESTAMP NULL DEFAULT NULL, -- 最後に既読にしたメッセージの作成日時。既読にしていないチャンネルはNULL
     PRIMARY KEY (membership_id, channel_id),
     FOREIGN KEY (membership_id) REFERENCES Memberships(id) ON DELETE CASCADE,
     FOREIGN KEY (channel_id) REFERENCES Channels(id) ON DELETE CASCADE
@@ -78,11 +87,66 @@ CREATE TABLE Messages (
     id CHAR(36) PRIMARY KEY, -- UUIDは36文字の文字列として格納されます
     membership_id CHAR(73) NOT NULL,
     channel_id CHAR(36) NOT NULL,
-    action_tag_id CHAR(36) NOT NULL,
+    parent_id CHAR(36) NOT NULL DEFAULT '', -- スレッドの返信でないメッセージは空文字
     text TEXT NOT NULL,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL DEFAULT NULL, -- 編集されていないメッセージはNULL
+    reply_count INT NOT NULL DEFAULT 0,
+    last_reply_at TIMESTAMP NULL DEFAULT NULL, -- 返信がないメッセージはNULL
+    attachments JSON NULL DEFAULT NULL, -- 添付ファイルがないメッセージはNULL
+    FOREIGN KEY (membership_id) REFERENCES Memberships(id) ON DELETE CASCADE,
+    FOREIGN KEY (channel_id) REFERENCES Channels(id) ON DELETE CASCADE,
+    INDEX idx_messages_channel_created_at (channel_id, parent_id, created_at),
+    INDEX idx_messages_parent_created_at (parent_id, created_at),
+    FULLTEXT INDEX ft_messages_text (text) WITH PARSER ngram -- 日本語のように単語を空白で区切らない本文も検索できるようにします
+);
+
+-- 返信は書き戻しの順序によって親メッセージより先に永続化されることがあるため、message_idには外部キーを張りません
+CREATE TABLE Thread_Followers (
+    message_id CHAR(36) NOT NULL,
+    membership_id CHAR(73) NOT NULL,
+    PRIMARY KEY (message_id, membership_id),
+    FOREIGN KEY (membership_id) REFERENCES Memberships(id) ON DELETE CASCADE
+);
+
+-- リアクションもメッセージの書き戻しより先に永続化されるため、message_idには外部キーを張りません
+CREATE TABLE Reactions (
+    message_id CHAR(36) NOT NULL,
+    membership_id CHAR(73) NOT NULL,
+    emoji VARCHAR(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL, -- 絵文字同士を区別するためバイナリで比較します
+    created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (message_id, membership_id, emoji),
+    FOREIGN KEY (membership_id) REFERENCES Memberships(id) ON DELETE CASCADE
+);
+
+-- 通知はメッセージの書き戻しより先に作成されるため、message_idには外部キーを張りません
+CREATE TABLE Notifications (
+    id CHAR(36) PRIMARY KEY, -- UUIDは36文字の文字列として格納されます
+    membership_id CHAR(73) NOT NULL, -- 通知を受け取るメンバーシップ
+    message_id CHAR(36) NOT NULL,
+    channel_id CHAR(36) NOT NULL,
+    sender_id CHAR(73) NOT NULL,
+    kind VARCHAR(20) NOT NULL, -- membership, channel, here
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    read_at TIMESTAMP NULL DEFAULT NULL, -- 未読の通知はNULL
     FOREIGN KEY (membership_id) REFERENCES Memberships(id) ON DELETE CASCADE,
     FOREIGN KEY (channel_id) REFERENCES Channels(id) ON DELETE CASCADE,
-    FOREIGN KEY (action_tag_id) REFERENCES ActionTags(id) ON DELETE CASCADE
-);
\ No newline at end of file
+    INDEX idx_notifications_membership_created_at (membership_id, created_at)
+);
+
+CREATE TABLE Attachments (
+    id CHAR(36) PRIMARY KEY, -- UUIDは36文字の文字列として格納されます
+    workspace_id CHAR(36) NOT NULL,
+    channel_id CHAR(36) NOT NULL,
+    membership_id CHAR(73) NOT NULL, -- アップロードしたメンバーシップ
+    message_id CHAR(36) NOT NULL DEFAULT '', -- メッセージに添付されていないファイルは空文字
+    name VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    mime_type VARCHAR(255) NOT NULL,
+    checksum CHAR(64) NOT NULL, -- 内容のSHA-256
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (workspace_id) REFERENCES Workspaces(id) ON DELETE CASCADE,
+    FOREIGN KEY (channel_id) REFERENCES Channels(id) ON DELETE CASCADE,
+    FOREIGN KEY (membership_id) REFERENCES Memberships(id) ON DELETE CASCADE,
+    INDEX idx_attachments_message_id (message_id)
+);
@@ -0,0 +1,65 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/doug-martin/goqu/v9"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+type threadFollowerRepository struct {
+	*base[entity.ThreadFollower]
+}
+
+func NewThreadFollowerRepository(db *sql.DB, dialect *goqu.DialectWrapper) repository.ThreadFollowerRepository {
+	return &threadFollowerRepository{
+		base: newBase[entity.ThreadFollower](db, dialect, "Thread_Followers"),
+	}
+}
+
+// Create は、返信のたびに呼ばれるため、既にフォローしている場合は何もしません。
+func (tfr *threadFollowerRepository) Create(ctx context.Context, follower entity.ThreadFollower) error {
+	executor := tfr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query, _, err := tfr.dialect.Insert(tfr.tableName).Rows(follower).ToSQL()
+	if err != nil {
+		log.Error("Failed to generate SQL query", log.Ferror(err))
+		return err
+	}
+	query += " ON DUPLICATE KEY UPDATE `membership_id` = `membership_id`"
+
+	if _, err = executor.ExecContext(ctx, query); err != nil {
+		log.Error("Failed to execute query", log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+func (tfr *threadFollowerRepository) Delete(ctx context.Context, messageID, membershipID string) error {
+	executor := tfr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query, _, err := tfr.dialect.Delete(tfr.tableName).Where(
+		goqu.C("message_id").Eq(messageID),
+		goqu.C("membership_id").Eq(membershipID),
+	).ToSQL()
+	if err != nil {
+		log.Error("Failed to generate SQL query", log.Ferror(err))
+		return err
+	}
+
+	if _, err = executor.ExecContext(ctx, query); err != nil {
+		log.Error("Failed to execute query", log.Ferror(err))
+		return err
+	}
+	return nil
+}
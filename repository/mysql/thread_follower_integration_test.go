@@ -0,0 +1,42 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+func Test_ThreadFollowerRepository(t *testing.T) {
+	dialect := goqu.Dialect("mysql")
+	ctx := context.Background()
+	messageID := uuid.New().String()
+	membershipID := "5fe0e23e-6b49-11ee-b686-0242c0a87001_5fe0e237-6b49-11ee-b686-0242c0a87001"
+	follower := entity.ThreadFollower{MessageID: messageID, MembershipID: membershipID}
+
+	repo := NewThreadFollowerRepository(db, &dialect)
+
+	// Following twice is not an error
+	err := repo.Create(ctx, follower)
+	ValidateErr(t, err, nil)
+	err = repo.Create(ctx, follower)
+	ValidateErr(t, err, nil)
+
+	followers, err := repo.List(ctx, []repository.QueryCondition{{Field: "message_id", Value: messageID}})
+	ValidateErr(t, err, nil)
+	if len(followers) != 1 || followers[0] != follower {
+		t.Errorf("Expected follower %v, got %v", follower, followers)
+	}
+
+	err = repo.Delete(ctx, messageID, membershipID)
+	ValidateErr(t, err, nil)
+	followers, err = repo.List(ctx, []repository.QueryCondition{{Field: "message_id", Value: messageID}})
+	ValidateErr(t, err, nil)
+	if len(followers) != 0 {
+		t.Errorf("Expected no followers, got %v", followers)
+	}
+}
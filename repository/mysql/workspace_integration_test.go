@@ -0,0 +1,33 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/doug-martin/goqu/v9"
+
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+func Test_WorkspaceRepository(t *testing.T) {
+	dialect := goqu.Dialect("mysql")
+	ctx := context.Background()
+	workspaceID := "5fe0e237-6b49-11ee-b686-0242c0a87001"
+
+	repo := NewWorkspaceRepository(db, &dialect)
+
+	workspaces, err := repo.List(ctx, nil)
+	ValidateErr(t, err, nil)
+	if len(workspaces) < 2 {
+		t.Errorf("Expected at least 2 workspaces, got %d", len(workspaces))
+	}
+
+	workspaces, err = repo.List(ctx, []repository.QueryCondition{{Field: "id", Value: workspaceID}})
+	ValidateErr(t, err, nil)
+	if len(workspaces) != 1 {
+		t.Fatalf("Expected 1 workspace, got %d", len(workspaces))
+	}
+	if workspaces[0].Name != "Workspace 1" || workspaces[0].Description != "Description for Workspace 1" {
+		t.Errorf("Unexpected workspace: %v", workspaces[0])
+	}
+}
@@ -0,0 +1,21 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+)
+
+type NotificationRepository interface {
+	// List は、メンバーシップの通知を新しい順に最大limit件取得します。
+	List(ctx context.Context, membershipID string, unreadOnly bool, limit int64) ([]entity.Notification, error)
+	BatchCreate(ctx context.Context, notifications []entity.Notification) error
+	// MarkRead は、メンバーシップの未読の通知を既読にします。idsが空の場合は全ての通知を既読にします。
+	MarkRead(ctx context.Context, membershipID string, ids []string, readAt time.Time) error
+	// MarkChannelRead は、メンバーシップのチャンネルでのbeforeより前に作成された未読の通知を既読にします。
+	MarkChannelRead(ctx context.Context, membershipID, channelID string, before, readAt time.Time) error
+	// CountUnreadByChannel は、メンバーシップの未読の通知の数をチャンネルごとに返します。
+	CountUnreadByChannel(ctx context.Context, membershipID string) (map[string]int64, error)
+}
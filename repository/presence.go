@@ -0,0 +1,22 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+)
+
+// PresenceRepository は、メンバーシップの接続とプレゼンスを全てのサーバーで共有します。
+// 接続はハートビートのたびに期限を延ばし、サーバーが停止して期限が切れた接続はオフラインとして扱います。
+type PresenceRepository interface {
+	// Touch は、接続の期限をttl後に延ばします。メンバーシップに有効な接続が他に無かった場合はtrueを返します。
+	Touch(ctx context.Context, membershipID, connectionID string, ttl time.Duration) (bool, error)
+	// Remove は、接続を削除します。メンバーシップの有効な接続が無くなった場合はtrueを返します。
+	Remove(ctx context.Context, membershipID, connectionID string) (bool, error)
+	// SetStatus は、メンバーが設定したプレゼンスの状態を保存します。onlineを指定すると設定を解除します。
+	SetStatus(ctx context.Context, membershipID, status string) error
+	// List は、membershipIDsのプレゼンスを同じ順に返します。
+	List(ctx context.Context, membershipIDs []string) ([]entity.Presence, error)
+}
@@ -3,11 +3,19 @@ package repository
 
 import (
 	"context"
-
-	"github.com/go-redis/redis/v8"
 )
 
+// Subscription は、PubSubのトピックの購読です。購読の実装に依存せずにメッセージを受け取れます。
+type Subscription interface {
+	// Messages は、購読したトピックに配信されたメッセージを配信の順に返します。購読が終了すると閉じられます。
+	Messages() <-chan []byte
+	// Err は、Messagesが閉じられた後に、購読が終了した原因を返します。Closeで終了した場合はnilです。
+	Err() error
+	Close() error
+}
+
 type PubSubRepository interface {
-	Publish(ctx context.Context, channel string, message any) error
-	Subscribe(ctx context.Context, channel string) *redis.PubSub // TODO: *redis.PubSub を汎用化する
+	Publish(ctx context.Context, topic string, message []byte) error
+	// Subscribe は、購読を開始してから戻ります。戻った後にトピックに配信されたメッセージは全て届きます。
+	Subscribe(ctx context.Context, topic string) (Subscription, error)
 }
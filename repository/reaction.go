@@ -0,0 +1,26 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+)
+
+type ReactionRepository interface {
+	ListByMessageIDs(ctx context.Context, messageIDs []string) ([]entity.Reaction, error)
+	Create(ctx context.Context, reaction entity.Reaction) error
+	Delete(ctx context.Context, reaction entity.Reaction) error
+}
+
+// ReactionCacheRepository は、メッセージごとのリアクションをキャッシュします。
+// MySQLへの書き込みと同時に更新するため、書き戻しは行いません。
+type ReactionCacheRepository interface {
+	// List は、キャッシュ済みのメッセージのリアクションを返します。キャッシュにないメッセージのIDはmapに含まれません。
+	List(ctx context.Context, messageIDs []string) (map[string][]entity.Reaction, error)
+	// Fill は、MySQLから読み込んだリアクションをキャッシュに載せます。リアクションのないメッセージもキャッシュ済みとして扱います。
+	Fill(ctx context.Context, messageIDs []string, reactions []entity.Reaction) error
+	// Add は、メッセージのリアクションがキャッシュ済みの場合のみリアクションを追加します。
+	Add(ctx context.Context, reaction entity.Reaction) error
+	Remove(ctx context.Context, reaction entity.Reaction) error
+}
@@ -0,0 +1,118 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+const (
+	channelSeqKeyPrefix    = "channel_seq:"
+	channelEventsKeyPrefix = "channel_events:"
+	// channelEventsMaxLen を超えた古いイベントは削除し、それより前から再開するクライアントには再同期を求めます。
+	channelEventsMaxLen = 1000
+	channelEventsTTL    = 24 * time.Hour
+)
+
+// publishEventScript は、連番の発行、イベントの保存と配信をまとめて実行し、配信の順序と連番の順序を一致させます。
+// 連番はpayloadのJSONオブジェクトの先頭に"seq"として埋め込みます。
+//
+// KEYS: channel_seq:<channelID>, channel_events:<channelID>
+// ARGV: payload, maxLen, ttl(seconds), topic
+var publishEventScript = redis.NewScript(`
+local seq = redis.call('INCR', KEYS[1])
+local payload = '{"seq":' .. seq .. ',' .. string.sub(ARGV[1], 2)
+redis.call('ZADD', KEYS[2], seq, payload)
+redis.call('ZREMRANGEBYRANK', KEYS[2], 0, -(tonumber(ARGV[2]) + 1))
+redis.call('EXPIRE', KEYS[2], ARGV[3])
+redis.call('PUBLISH', ARGV[4], payload)
+return seq
+`)
+
+type channelEventRepository struct {
+	client *redis.Client
+}
+
+func NewChannelEventRepository(client *redis.Client) repository.ChannelEventRepository {
+	return &channelEventRepository{
+		client: client,
+	}
+}
+
+func (cer *channelEventRepository) Publish(ctx context.Context, channelID string, payload []byte) (int64, error) {
+	// 連番を埋め込めるのは、空でないJSONオブジェクトのみです。
+	if len(payload) <= len("{}") || payload[0] != '{' {
+		log.Warn("Channel event must be a non-empty JSON object", log.Fstring("channelID", channelID))
+		return 0, fmt.Errorf("channel event must be a non-empty JSON object")
+	}
+
+	seq, err := publishEventScript.Run(
+		ctx,
+		cer.client,
+		[]string{channelSeqKey(channelID), channelEventsKey(channelID)},
+		payload,
+		channelEventsMaxLen,
+		int(channelEventsTTL.Seconds()),
+		config.PubSubChannelPrefix+channelID,
+	).Int64()
+	if err != nil {
+		log.Error("Failed to publish channel event", log.Fstring("channelID", channelID), log.Ferror(err))
+		return 0, err
+	}
+	return seq, nil
+}
+
+func (cer *channelEventRepository) ListSince(ctx context.Context, channelID string, seq int64) ([][]byte, int64, error) {
+	pipe := cer.client.TxPipeline()
+	latestCmd := pipe.Get(ctx, channelSeqKey(channelID))
+	eventsCmd := pipe.ZRangeByScoreWithScores(ctx, channelEventsKey(channelID), &redis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(seq, 10),
+		Max: "+inf",
+	})
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		log.Error("Failed to list channel events", log.Fstring("channelID", channelID), log.Ferror(err))
+		return nil, 0, err
+	}
+
+	latest, err := latestCmd.Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		log.Error("Failed to get channel sequence", log.Fstring("channelID", channelID), log.Ferror(err))
+		return nil, 0, err
+	}
+	if seq == latest {
+		return nil, latest, nil
+	}
+
+	// 最新の連番より先を要求された場合は、Redisのデータが失われているため同じく再同期を求めます。
+	events := eventsCmd.Val()
+	if seq > latest || len(events) == 0 || int64(events[0].Score) != seq+1 {
+		return nil, latest, repository.ErrChannelEventsExpired
+	}
+
+	payloads := make([][]byte, len(events))
+	for i, event := range events {
+		member, ok := event.Member.(string)
+		if !ok {
+			log.Error("Invalid channel event", log.Fstring("channelID", channelID))
+			return nil, 0, fmt.Errorf("invalid channel event: %v", event.Member)
+		}
+		payloads[i] = []byte(member)
+	}
+	return payloads, latest, nil
+}
+
+func channelSeqKey(channelID string) string {
+	return channelSeqKeyPrefix + channelID
+}
+
+func channelEventsKey(channelID string) string {
+	return channelEventsKeyPrefix + channelID
+}
@@ -0,0 +1,81 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+func Test_ChannelEventRepository(t *testing.T) {
+	ctx := context.Background()
+	channelID := uuid.New().String()
+
+	repo := NewChannelEventRepository(client)
+
+	pubsub := client.Subscribe(ctx, config.PubSubChannelPrefix+channelID)
+	defer pubsub.Close()
+	if _, err := pubsub.Receive(ctx); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	for i, text := range []string{"first", "second", "third"} {
+		seq, err := repo.Publish(ctx, channelID, []byte(`{"text":"`+text+`"}`))
+		ValidateErr(t, err, nil)
+		if seq != int64(i+1) {
+			t.Errorf("Expected seq %d, got %d", i+1, seq)
+		}
+	}
+
+	// Published payloads carry the sequence number
+	msg, err := pubsub.ReceiveMessage(ctx)
+	ValidateErr(t, err, nil)
+	var event struct {
+		Seq  int64  `json:"seq"`
+		Text string `json:"text"`
+	}
+	if err = json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+		t.Fatalf("Failed to unmarshal event: %v", err)
+	}
+	if event.Seq != 1 || event.Text != "first" {
+		t.Errorf("Expected first event with seq 1, got %+v", event)
+	}
+
+	_, err = repo.Publish(ctx, channelID, []byte(`{}`))
+	if err == nil {
+		t.Errorf("Expected error for empty payload")
+	}
+
+	events, latest, err := repo.ListSince(ctx, channelID, 1)
+	ValidateErr(t, err, nil)
+	if latest != 3 || len(events) != 2 {
+		t.Fatalf("Expected 2 events up to seq 3, got %d events up to seq %d", len(events), latest)
+	}
+	if string(events[0]) != `{"seq":2,"text":"second"}` {
+		t.Errorf("Unexpected event: %s", events[0])
+	}
+
+	events, latest, err = repo.ListSince(ctx, channelID, 3)
+	ValidateErr(t, err, nil)
+	if latest != 3 || len(events) != 0 {
+		t.Errorf("Expected no events, got %d events up to seq %d", len(events), latest)
+	}
+
+	// Events older than the retained log require a resync
+	if err = client.ZRemRangeByRank(ctx, channelEventsKey(channelID), 0, 0).Err(); err != nil {
+		t.Fatalf("Failed to trim events: %v", err)
+	}
+	_, latest, err = repo.ListSince(ctx, channelID, 0)
+	if !errors.Is(err, repository.ErrChannelEventsExpired) || latest != 3 {
+		t.Errorf("Expected ErrChannelEventsExpired at seq 3, got %v at seq %d", err, latest)
+	}
+	_, _, err = repo.ListSince(ctx, channelID, 5)
+	if !errors.Is(err, repository.ErrChannelEventsExpired) {
+		t.Errorf("Expected ErrChannelEventsExpired, got %v", err)
+	}
+}
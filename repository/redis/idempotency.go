@@ -0,0 +1,58 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+const idempotencyKeyPrefix = "idempotency:"
+
+// reserveScript は、キーが未使用であればIDを記録して空文字を、使用済みであれば記録済みのIDを返します。
+// 同時に届いた再送のどちらか一方だけが記録できるよう、確認と記録をまとめて実行します。
+//
+// KEYS: idempotency:<key>
+// ARGV: id, ttl(milliseconds)
+var reserveScript = redis.NewScript(`
+local existing = redis.call('GET', KEYS[1])
+if existing then
+	return existing
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return ''
+`)
+
+type idempotencyRepository struct {
+	client *redis.Client
+}
+
+func NewIdempotencyRepository(client *redis.Client) repository.IdempotencyRepository {
+	return &idempotencyRepository{
+		client: client,
+	}
+}
+
+func (ir *idempotencyRepository) Reserve(ctx context.Context, key, id string, ttl time.Duration) (string, error) {
+	existing, err := reserveScript.Run(ctx, ir.client, []string{idempotencyKey(key)}, id, ttl.Milliseconds()).Text()
+	if err != nil {
+		log.Error("Failed to reserve idempotency key", log.Fstring("key", key), log.Ferror(err))
+		return "", err
+	}
+	return existing, nil
+}
+
+func (ir *idempotencyRepository) Release(ctx context.Context, key string) error {
+	if err := ir.client.Del(ctx, idempotencyKey(key)).Err(); err != nil {
+		log.Error("Failed to release idempotency key", log.Fstring("key", key), log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+func idempotencyKey(key string) string {
+	return idempotencyKeyPrefix + key
+}
@@ -0,0 +1,39 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func Test_IdempotencyRepository(t *testing.T) {
+	ctx := context.Background()
+	key := uuid.New().String()
+	messageID := uuid.New().String()
+	retryID := uuid.New().String()
+
+	repo := NewIdempotencyRepository(client)
+
+	existing, err := repo.Reserve(ctx, key, messageID, time.Minute)
+	ValidateErr(t, err, nil)
+	if existing != "" {
+		t.Errorf("Expected key %s to be reserved, got existing id %s", key, existing)
+	}
+
+	// A retry with the same key returns the id recorded first
+	existing, err = repo.Reserve(ctx, key, retryID, time.Minute)
+	ValidateErr(t, err, nil)
+	if existing != messageID {
+		t.Errorf("Expected existing id %s, got %s", messageID, existing)
+	}
+
+	err = repo.Release(ctx, key)
+	ValidateErr(t, err, nil)
+	existing, err = repo.Reserve(ctx, key, retryID, time.Minute)
+	ValidateErr(t, err, nil)
+	if existing != "" {
+		t.Errorf("Expected released key %s to be reserved again, got existing id %s", key, existing)
+	}
+}
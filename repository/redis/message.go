@@ -3,7 +3,10 @@ package redis
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -13,6 +16,179 @@ import (
 	"github.com/tusmasoma/connectHub-backend/repository"
 )
 
+const (
+	messagesKey        = "messages"
+	pendingUpsertsKey  = "messages:pending"
+	pendingDeletesKey  = "messages:pending:deleted"
+	deadLetterKey      = "messages:dead_letter"
+	threadKeyPrefix    = "thread:"
+	tombstoneKeyPrefix = "messages:tombstone:"
+	coveredKeyPrefix   = "messages:covered:"
+
+	// tombstoneTTL は、削除をMySQLに反映した後もキャッシュへの再投入を拒む期間です。
+	// MySQLから読み込んでからFillするまでの間に削除が反映されても、メッセージが復活しないようにします。
+	tombstoneTTL = time.Minute
+	// coveredTTL は、チャンネルのキャッシュが漏れなく保持している範囲の記録を保持する期間です。
+	// 期限が切れるとMySQLから読み直して記録し直すため、キャッシュの欠落が残り続けることを防ぎます。
+	coveredTTL = 24 * time.Hour
+)
+
+// ackPendingScript は、読み出した時点から再度変更されていない場合のみ永続化待ちの集合から取り除きます。
+// 書き戻し中に編集されたメッセージはスコアが更新されているため、次回の書き戻しまで残ります。
+var ackPendingScript = redis.NewScript(`
+for i = 1, #ARGV, 2 do
+	local score = redis.call('ZSCORE', KEYS[1], ARGV[i])
+	if score and tonumber(score) <= tonumber(ARGV[i + 1]) then
+		redis.call('ZREM', KEYS[1], ARGV[i])
+	end
+end
+return 1
+`)
+
+// ackDeletedScript は、ackPendingScriptと同じ条件で削除待ちの集合から取り除き、削除済みの印を残します。
+//
+// KEYS: messages:pending:deleted, messages:tombstone:<id>の繰り返し
+// ARGV: ttl, id, scoreの繰り返し
+var ackDeletedScript = redis.NewScript(`
+for i = 2, #KEYS do
+	local id = ARGV[i * 2 - 2]
+	local score = redis.call('ZSCORE', KEYS[1], id)
+	if score and tonumber(score) <= tonumber(ARGV[i * 2 - 1]) then
+		redis.call('ZREM', KEYS[1], id)
+		redis.call('SET', KEYS[i], 1, 'EX', ARGV[1])
+	end
+end
+return 1
+`)
+
+// fillScript は、削除待ちか削除済みの印があるメッセージを除いて、MySQLから読み込んだメッセージをキャッシュに載せます。
+// 既にキャッシュにあるメッセージは書き戻し前の編集を含むため上書きしません。
+//
+// KEYS: messages, messages:pending:deleted, (<channelID> or thread:<parentID>, messages:tombstone:<id>)の繰り返し
+// ARGV: id, message(JSON), scoreの繰り返し
+var fillScript = redis.NewScript(`
+for i = 0, #ARGV / 3 - 1 do
+	local id = ARGV[i * 3 + 1]
+	if not redis.call('ZSCORE', KEYS[2], id) and redis.call('EXISTS', KEYS[i * 2 + 4]) == 0 then
+		redis.call('HSETNX', KEYS[1], id, ARGV[i * 3 + 2])
+		redis.call('ZADD', KEYS[i * 2 + 3], ARGV[i * 3 + 3], id)
+	end
+end
+return 1
+`)
+
+// markCoveredScript は、記録済みの時点より古い場合のみチャンネルのキャッシュが網羅する範囲を広げます。
+//
+// KEYS: messages:covered:<channelID>
+// ARGV: since(Unix秒), ttl
+var markCoveredScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if not current or tonumber(ARGV[1]) < tonumber(current) then
+	redis.call('SET', KEYS[1], ARGV[1], 'EX', ARGV[2])
+end
+return 1
+`)
+
+// deadLetterScript は、ackPendingScriptと同じく読み出した時点から再度変更されていない場合のみ、
+// 永続化待ちの集合から取り除いてデッドレターに移します。
+//
+// KEYS: messages:pending or messages:pending:deleted, messages:dead_letter
+// ARGV: id, score, entry(JSON)の繰り返し
+var deadLetterScript = redis.NewScript(`
+for i = 1, #ARGV, 3 do
+	local score = redis.call('ZSCORE', KEYS[1], ARGV[i])
+	if score and tonumber(score) <= tonumber(ARGV[i + 1]) then
+		redis.call('ZREM', KEYS[1], ARGV[i])
+		redis.call('HSET', KEYS[2], ARGV[i], ARGV[i + 2])
+	end
+end
+return 1
+`)
+
+// requeueDeadLetterScript は、デッドレターのメッセージを永続化待ちに戻します。
+// デッドレターに移した後に削除されたメッセージは、キャッシュに戻さずデッドレターから取り除くだけにします。
+//
+// KEYS: messages:dead_letter, messages, messages:pending, messages:pending:deleted, messages:tombstone:<id>の繰り返し
+// ARGV: score, (id, deleted("1" or "0"), message(JSON、キャッシュにない場合は空文字))の繰り返し
+var requeueDeadLetterScript = redis.NewScript(`
+local requeued = 0
+for i = 5, #KEYS do
+	local j = (i - 5) * 3 + 2
+	local id = ARGV[j]
+	if redis.call('HDEL', KEYS[1], id) == 1 then
+		if ARGV[j + 1] == '1' then
+			redis.call('ZADD', KEYS[4], ARGV[1], id)
+			requeued = requeued + 1
+		elseif not redis.call('ZSCORE', KEYS[4], id) and redis.call('EXISTS', KEYS[i]) == 0 then
+			if ARGV[j + 2] ~= '' then
+				redis.call('HSETNX', KEYS[2], id, ARGV[j + 2])
+			end
+			redis.call('ZADD', KEYS[3], ARGV[1], id)
+			requeued = requeued + 1
+		end
+	end
+end
+return requeued
+`)
+
+// deadLetterEntry は、デッドレターに保存する内容です。手動で書き戻せるよう、メッセージ全体を残します。
+type deadLetterEntry struct {
+	ID       string          `json:"id"`
+	Message  *entity.Message `json:"message,omitempty"`
+	Deleted  bool            `json:"deleted"`
+	QueuedAt time.Time       `json:"queued_at"`
+	Reason   string          `json:"reason"`
+	FailedAt time.Time       `json:"failed_at"`
+}
+
+// createReplyScript は、返信の追加と親メッセージの返信数・最終返信日時の更新をまとめて行います。
+// 親メッセージがキャッシュにない場合は何もせずにfalseを返します。
+//
+// KEYS: messages, thread:<parentID>, messages:pending
+// ARGV: parentID, replyID, reply(JSON), replyScore, lastReplyAt, pendingScore
+var createReplyScript = redis.NewScript(`
+local parent = redis.call('HGET', KEYS[1], ARGV[1])
+if not parent then
+	return false
+end
+local message = cjson.decode(parent)
+message['reply_count'] = (tonumber(message['reply_count']) or 0) + 1
+message['last_reply_at'] = ARGV[5]
+parent = cjson.encode(message)
+redis.call('HSET', KEYS[1], ARGV[1], parent)
+redis.call('HSET', KEYS[1], ARGV[2], ARGV[3])
+redis.call('ZADD', KEYS[2], ARGV[4], ARGV[2])
+redis.call('ZADD', KEYS[3], ARGV[6], ARGV[1])
+redis.call('ZADD', KEYS[3], ARGV[6], ARGV[2])
+return parent
+`)
+
+// deleteReplyScript は、返信の削除と親メッセージの返信数の更新をまとめて行います。
+// 親メッセージがキャッシュにない場合は返信のみ削除してfalseを返します。
+//
+// KEYS: messages, thread:<parentID>, messages:pending, messages:pending:deleted
+// ARGV: parentID, replyID, pendingScore
+var deleteReplyScript = redis.NewScript(`
+redis.call('HDEL', KEYS[1], ARGV[2])
+redis.call('ZREM', KEYS[2], ARGV[2])
+redis.call('ZREM', KEYS[3], ARGV[2])
+redis.call('ZADD', KEYS[4], ARGV[3], ARGV[2])
+local parent = redis.call('HGET', KEYS[1], ARGV[1])
+if not parent then
+	return false
+end
+local message = cjson.decode(parent)
+local count = (tonumber(message['reply_count']) or 0) - 1
+if count < 0 then
+	count = 0
+end
+message['reply_count'] = count
+parent = cjson.encode(message)
+redis.call('HSET', KEYS[1], ARGV[1], parent)
+redis.call('ZADD', KEYS[3], ARGV[3], ARGV[1])
+return parent
+`)
+
 type messageRepository struct {
 	*base[entity.Message]
 }
@@ -26,7 +202,7 @@ func NewMessageRepository(client *redis.Client) repository.MessageCacheRepositor
 func (mr *messageRepository) Get(ctx context.Context, id string) (*entity.Message, error) {
 	var message entity.Message
 
-	messageBytes, err := mr.client.HGet(ctx, "messages", id).Result()
+	messageBytes, err := mr.client.HGet(ctx, messagesKey, id).Result()
 	if err != nil {
 		log.Error("Failed to get message from hash", log.Ferror(err))
 		return nil, err
@@ -55,7 +231,7 @@ func (mr *messageRepository) List(ctx context.Context, channelID string, start,
 
 	for _, id := range messageIDs {
 		var messageBytes string
-		messageBytes, err = mr.client.HGet(ctx, "messages", id).Result()
+		messageBytes, err = mr.client.HGet(ctx, messagesKey, id).Result()
 		if err != nil {
 			log.Error("Failed to get message from hash", log.Ferror(err))
 			return nil, err
@@ -73,6 +249,139 @@ func (mr *messageRepository) List(ctx context.Context, channelID string, start,
 	return messages, nil
 }
 
+// ListPage は、ZREVRANGEBYSCORE(ZRANGEBYSCORE) ... LIMIT でカーソルから指定件数のメッセージを取得します。
+// カーソルと同じスコアのメッセージはIDで比較するため、同じ秒に作成されたメッセージも取りこぼしません。
+func (mr *messageRepository) ListPage(ctx context.Context, channelID string, query repository.MessagePageQuery) ([]entity.Message, error) { //nolint:lll // Ignore long line length
+	before := query.Direction != entity.PageDirectionAfter
+
+	var messageIDs []string
+	opt := &redis.ZRangeBy{Min: "-inf", Max: "+inf", Count: query.Limit}
+	if query.Cursor != nil {
+		score := strconv.FormatInt(query.Cursor.Score, 10)
+		ties, err := mr.client.ZRangeByScore(ctx, channelID, &redis.ZRangeBy{Min: score, Max: score}).Result()
+		if err != nil {
+			log.Error("Failed to get message IDs from sorted set", log.Ferror(err))
+			return nil, err
+		}
+		// 同じスコアのメンバーはIDの辞書順に並んでいます。
+		if before {
+			for i := len(ties) - 1; i >= 0; i-- {
+				if ties[i] < query.Cursor.ID {
+					messageIDs = append(messageIDs, ties[i])
+				}
+			}
+			opt.Max = "(" + score
+		} else {
+			for _, id := range ties {
+				if id > query.Cursor.ID {
+					messageIDs = append(messageIDs, id)
+				}
+			}
+			opt.Min = "(" + score
+		}
+	}
+
+	if int64(len(messageIDs)) < query.Limit {
+		var rest []string
+		var err error
+		if before {
+			rest, err = mr.client.ZRevRangeByScore(ctx, channelID, opt).Result()
+		} else {
+			rest, err = mr.client.ZRangeByScore(ctx, channelID, opt).Result()
+		}
+		if err != nil {
+			log.Error("Failed to get message IDs from sorted set", log.Ferror(err))
+			return nil, err
+		}
+		messageIDs = append(messageIDs, rest...)
+	}
+	if int64(len(messageIDs)) > query.Limit {
+		messageIDs = messageIDs[:query.Limit]
+	}
+	if len(messageIDs) == 0 {
+		return nil, nil
+	}
+
+	return mr.getMessages(ctx, messageIDs)
+}
+
+// ListThread は、スレッドの返信を作成日時の昇順で取得します。
+func (mr *messageRepository) ListThread(ctx context.Context, parentID string) ([]entity.Message, error) {
+	messageIDs, err := mr.client.ZRange(ctx, threadKey(parentID), 0, -1).Result()
+	if err != nil {
+		log.Error("Failed to get reply IDs from sorted set", log.Ferror(err))
+		return nil, err
+	}
+	if len(messageIDs) == 0 {
+		return nil, nil
+	}
+	return mr.getMessages(ctx, messageIDs)
+}
+
+// CountAfter は、メッセージを読み込まずにZCOUNTでカーソルより後のメッセージを数えます。
+// カーソルと同じスコアのメッセージはListPageと同じくIDで比較します。全てのチャンネルを一度のパイプラインで数えます。
+func (mr *messageRepository) CountAfter(ctx context.Context, cursors map[string]*entity.MessageCursor) (map[string]int64, error) { //nolint:lll // Ignore long line length
+	counts := make(map[string]int64, len(cursors))
+	if len(cursors) == 0 {
+		return counts, nil
+	}
+
+	pipe := mr.client.Pipeline()
+	after := make(map[string]*redis.IntCmd, len(cursors))
+	ties := make(map[string]*redis.StringSliceCmd)
+	for channelID, cursor := range cursors {
+		if cursor == nil {
+			after[channelID] = pipe.ZCard(ctx, channelID)
+			continue
+		}
+		score := strconv.FormatInt(cursor.Score, 10)
+		after[channelID] = pipe.ZCount(ctx, channelID, "("+score, "+inf")
+		ties[channelID] = pipe.ZRangeByScore(ctx, channelID, &redis.ZRangeBy{Min: score, Max: score})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Error("Failed to count messages", log.Ferror(err))
+		return nil, err
+	}
+
+	for channelID, cmd := range after {
+		counts[channelID] = cmd.Val()
+		if tie, ok := ties[channelID]; ok {
+			for _, id := range tie.Val() {
+				if id > cursors[channelID].ID {
+					counts[channelID]++
+				}
+			}
+		}
+	}
+	return counts, nil
+}
+
+// getMessages は、IDの順序を保ったままハッシュからメッセージを取得します。
+func (mr *messageRepository) getMessages(ctx context.Context, messageIDs []string) ([]entity.Message, error) {
+	values, err := mr.client.HMGet(ctx, messagesKey, messageIDs...).Result()
+	if err != nil {
+		log.Error("Failed to get messages from hash", log.Ferror(err))
+		return nil, err
+	}
+
+	messages := make([]entity.Message, 0, len(values))
+	for i, value := range values {
+		messageBytes, ok := value.(string)
+		if !ok {
+			// ソートセットとハッシュの更新の間に削除されたメッセージです。
+			log.Warn("Message is missing from hash", log.Fstring("msgID", messageIDs[i]))
+			continue
+		}
+		var message entity.Message
+		if err = json.Unmarshal([]byte(messageBytes), &message); err != nil {
+			log.Error("Failed to unmarshal message", log.Ferror(err))
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
 func (mr *messageRepository) Create(ctx context.Context, channelID string, message entity.Message) error {
 	messageBytes, err := json.Marshal(message)
 	if err != nil {
@@ -82,13 +391,15 @@ func (mr *messageRepository) Create(ctx context.Context, channelID string, messa
 
 	pipe := mr.client.TxPipeline()
 
-	pipe.HSet(ctx, "messages", message.ID, messageBytes)
+	pipe.HSet(ctx, messagesKey, message.ID, messageBytes)
 
 	pipe.ZAdd(ctx, channelID, &redis.Z{
 		Score:  float64(message.CreatedAt.Unix()),
 		Member: message.ID,
 	})
 
+	markPending(ctx, pipe, pendingUpsertsKey, message.ID)
+
 	_, err = pipe.Exec(ctx)
 	if err != nil {
 		log.Error("Failed to create message", log.Ferror(err))
@@ -97,6 +408,133 @@ func (mr *messageRepository) Create(ctx context.Context, channelID string, messa
 	return nil
 }
 
+// CreateReply は、返信をスレッドに追加し、更新後の親メッセージを返します。
+// 親メッセージがキャッシュにない場合はrepository.ErrMessageNotCachedを返します。
+func (mr *messageRepository) CreateReply(ctx context.Context, reply entity.Message) (*entity.Message, error) {
+	replyBytes, err := json.Marshal(reply)
+	if err != nil {
+		log.Error("Failed to serialize message", log.Ferror(err))
+		return nil, err
+	}
+	lastReplyAt, err := json.Marshal(reply.CreatedAt)
+	if err != nil {
+		log.Error("Failed to serialize reply time", log.Ferror(err))
+		return nil, err
+	}
+
+	result, err := createReplyScript.Run(
+		ctx,
+		mr.client,
+		[]string{messagesKey, threadKey(reply.ParentID), pendingUpsertsKey},
+		reply.ParentID,
+		reply.ID,
+		replyBytes,
+		reply.CreatedAt.Unix(),
+		strings.Trim(string(lastReplyAt), `"`),
+		timeToScore(time.Now()),
+	).Text()
+	if errors.Is(err, redis.Nil) {
+		return nil, repository.ErrMessageNotCached
+	}
+	if err != nil {
+		log.Error("Failed to create reply", log.Ferror(err))
+		return nil, err
+	}
+
+	var parent entity.Message
+	if err = json.Unmarshal([]byte(result), &parent); err != nil {
+		log.Error("Failed to unmarshal message", log.Ferror(err))
+		return nil, err
+	}
+	return &parent, nil
+}
+
+// DeleteReply は、返信をスレッドから削除し、更新後の親メッセージを返します。
+// 親メッセージがキャッシュにない場合、返信は削除した上でrepository.ErrMessageNotCachedを返します。
+func (mr *messageRepository) DeleteReply(ctx context.Context, parentID, messageID string) (*entity.Message, error) {
+	result, err := deleteReplyScript.Run(
+		ctx,
+		mr.client,
+		[]string{messagesKey, threadKey(parentID), pendingUpsertsKey, pendingDeletesKey},
+		parentID,
+		messageID,
+		timeToScore(time.Now()),
+	).Text()
+	if errors.Is(err, redis.Nil) {
+		return nil, repository.ErrMessageNotCached
+	}
+	if err != nil {
+		log.Error("Failed to delete reply", log.Ferror(err))
+		return nil, err
+	}
+
+	var parent entity.Message
+	if err = json.Unmarshal([]byte(result), &parent); err != nil {
+		log.Error("Failed to unmarshal message", log.Ferror(err))
+		return nil, err
+	}
+	return &parent, nil
+}
+
+// Fill は、MySQLから読み込んだメッセージをキャッシュに載せ直します。
+// 永続化済みのメッセージのため書き戻し待ちには追加せず、キャッシュ上のより新しい内容も上書きしません。
+// スレッドの返信はチャンネルではなくスレッドのソートセットに追加します。
+// Fill は、MySQLから読み込んだメッセージをキャッシュに載せます。
+// 読み込んだ後に削除されたメッセージは、削除がMySQLに反映済みでも載せません。
+func (mr *messageRepository) Fill(ctx context.Context, channelID string, messages []entity.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, 2+len(messages)*2)
+	keys = append(keys, messagesKey, pendingDeletesKey)
+	args := make([]interface{}, 0, len(messages)*3)
+	for _, message := range messages {
+		messageBytes, err := json.Marshal(message)
+		if err != nil {
+			log.Error("Failed to serialize message", log.Ferror(err))
+			return err
+		}
+
+		key := channelID
+		if message.ParentID != "" {
+			key = threadKey(message.ParentID)
+		}
+		keys = append(keys, key, tombstoneKey(message.ID))
+		args = append(args, message.ID, messageBytes, message.CreatedAt.Unix())
+	}
+
+	if err := fillScript.Run(ctx, mr.client, keys, args...).Err(); err != nil {
+		log.Error("Failed to fill messages", log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+// CoveredSince は、チャンネルのメッセージをキャッシュが漏れなく保持している範囲の始まりを返します。記録がない場合はnilを返します。
+func (mr *messageRepository) CoveredSince(ctx context.Context, channelID string) (*time.Time, error) {
+	since, err := mr.client.Get(ctx, coveredKey(channelID)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil //nolint:nilnil // 記録がないことをnilで表します
+	}
+	if err != nil {
+		log.Error("Failed to get covered range of channel", log.Ferror(err))
+		return nil, err
+	}
+	t := time.Unix(since, 0)
+	return &t, nil
+}
+
+// MarkCovered は、チャンネルのメッセージをsince以降キャッシュが漏れなく保持していることを記録します。
+func (mr *messageRepository) MarkCovered(ctx context.Context, channelID string, since time.Time) error {
+	keys := []string{coveredKey(channelID)}
+	if err := markCoveredScript.Run(ctx, mr.client, keys, since.Unix(), int64(coveredTTL.Seconds())).Err(); err != nil {
+		log.Error("Failed to mark covered range of channel", log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
 func (mr *messageRepository) Update(ctx context.Context, message entity.Message) error {
 	messageBytes, err := json.Marshal(message)
 	if err != nil {
@@ -104,7 +542,13 @@ func (mr *messageRepository) Update(ctx context.Context, message entity.Message)
 		return err
 	}
 
-	if err = mr.client.HSet(ctx, "messages", message.ID, messageBytes).Err(); err != nil {
+	pipe := mr.client.TxPipeline()
+
+	pipe.HSet(ctx, messagesKey, message.ID, messageBytes)
+
+	markPending(ctx, pipe, pendingUpsertsKey, message.ID)
+
+	if _, err = pipe.Exec(ctx); err != nil {
 		log.Error("Failed to update message in hash", log.Ferror(err))
 		return err
 	}
@@ -115,10 +559,13 @@ func (mr *messageRepository) Update(ctx context.Context, message entity.Message)
 func (mr *messageRepository) Delete(ctx context.Context, channelID, messageID string) error {
 	pipe := mr.client.TxPipeline()
 
-	pipe.HDel(ctx, "messages", messageID)
+	pipe.HDel(ctx, messagesKey, messageID)
 
 	pipe.ZRem(ctx, channelID, messageID)
 
+	pipe.ZRem(ctx, pendingUpsertsKey, messageID)
+	markPending(ctx, pipe, pendingDeletesKey, messageID)
+
 	_, err := pipe.Exec(ctx)
 	if err != nil {
 		log.Error("Failed to delete message", log.Ferror(err))
@@ -127,3 +574,279 @@ func (mr *messageRepository) Delete(ctx context.Context, channelID, messageID st
 
 	return nil
 }
+
+// ListPending は、作成・編集と削除をそれぞれ最大limit件読み出し、合わせて変更の古い順にlimit件を返します。
+// 一方の変更が溜まっていても、もう一方の古い変更が後回しにされ続けることはありません。
+func (mr *messageRepository) ListPending(ctx context.Context, limit int64) ([]repository.PendingMessage, error) {
+	upserts, err := mr.client.ZRangeWithScores(ctx, pendingUpsertsKey, 0, limit-1).Result()
+	if err != nil {
+		log.Error("Failed to get pending messages", log.Ferror(err))
+		return nil, err
+	}
+	deletes, err := mr.client.ZRangeWithScores(ctx, pendingDeletesKey, 0, limit-1).Result()
+	if err != nil {
+		log.Error("Failed to get pending deleted messages", log.Ferror(err))
+		return nil, err
+	}
+
+	// 両方の集合から古い順に取り出し、合わせてlimit件に収めます。
+	var takeUpserts, takeDeletes int
+	for int64(takeUpserts+takeDeletes) < limit && (takeUpserts < len(upserts) || takeDeletes < len(deletes)) {
+		if takeDeletes >= len(deletes) ||
+			(takeUpserts < len(upserts) && upserts[takeUpserts].Score <= deletes[takeDeletes].Score) {
+			takeUpserts++
+		} else {
+			takeDeletes++
+		}
+	}
+	upserts, deletes = upserts[:takeUpserts], deletes[:takeDeletes]
+
+	pending := make([]repository.PendingMessage, 0, len(upserts)+len(deletes))
+	if len(upserts) > 0 {
+		ids := make([]string, len(upserts))
+		for i, z := range upserts {
+			ids[i] = fmt.Sprint(z.Member)
+		}
+		var values []interface{}
+		values, err = mr.client.HMGet(ctx, messagesKey, ids...).Result()
+		if err != nil {
+			log.Error("Failed to get pending messages from hash", log.Ferror(err))
+			return nil, err
+		}
+		for i, z := range upserts {
+			pm := repository.PendingMessage{ID: ids[i], QueuedAt: scoreToTime(z.Score)}
+			if messageBytes, ok := values[i].(string); ok {
+				var message entity.Message
+				if err = json.Unmarshal([]byte(messageBytes), &message); err != nil {
+					log.Error("Failed to unmarshal message", log.Ferror(err))
+					return nil, err
+				}
+				pm.Message = &message
+			}
+			pending = append(pending, pm)
+		}
+	}
+	for _, z := range deletes {
+		pending = append(pending, repository.PendingMessage{
+			ID:       fmt.Sprint(z.Member),
+			Deleted:  true,
+			QueuedAt: scoreToTime(z.Score),
+		})
+	}
+	return pending, nil
+}
+
+// PendingDeletes は、指定したメッセージのうち削除がまだMySQLに反映されていないものを返します。
+func (mr *messageRepository) PendingDeletes(ctx context.Context, ids []string) (map[string]bool, error) {
+	deleted := make(map[string]bool)
+	if len(ids) == 0 {
+		return deleted, nil
+	}
+
+	pipe := mr.client.Pipeline()
+	cmds := make([]*redis.FloatCmd, len(ids))
+	for i, id := range ids {
+		cmds[i] = pipe.ZScore(ctx, pendingDeletesKey, id)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		log.Error("Failed to get pending deleted messages", log.Ferror(err))
+		return nil, err
+	}
+
+	for i, cmd := range cmds {
+		if cmd.Err() == nil {
+			deleted[ids[i]] = true
+		}
+	}
+	return deleted, nil
+}
+
+func (mr *messageRepository) AckPending(ctx context.Context, pending []repository.PendingMessage) error {
+	var upsertArgs []interface{}
+	deleteKeys := []string{pendingDeletesKey}
+	deleteArgs := []interface{}{int64(tombstoneTTL.Seconds())}
+	for _, pm := range pending {
+		if pm.Deleted {
+			deleteKeys = append(deleteKeys, tombstoneKey(pm.ID))
+			deleteArgs = append(deleteArgs, pm.ID, timeToScore(pm.QueuedAt))
+		} else {
+			upsertArgs = append(upsertArgs, pm.ID, timeToScore(pm.QueuedAt))
+		}
+	}
+	if len(upsertArgs) > 0 {
+		if err := ackPendingScript.Run(ctx, mr.client, []string{pendingUpsertsKey}, upsertArgs...).Err(); err != nil {
+			log.Error("Failed to ack pending messages", log.Ferror(err))
+			return err
+		}
+	}
+	if len(deleteKeys) > 1 {
+		if err := ackDeletedScript.Run(ctx, mr.client, deleteKeys, deleteArgs...).Err(); err != nil {
+			log.Error("Failed to ack pending deleted messages", log.Ferror(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// DeadLetter は、書き戻しを諦めたメッセージを永続化待ちの集合から取り除き、理由と共にデッドレターに保存します。
+func (mr *messageRepository) DeadLetter(ctx context.Context, messages []repository.DeadLetterMessage) error {
+	now := time.Now()
+	var upsertArgs, deleteArgs []interface{}
+	for _, dl := range messages {
+		entry, err := json.Marshal(deadLetterEntry{
+			ID:       dl.ID,
+			Message:  dl.Message,
+			Deleted:  dl.Deleted,
+			QueuedAt: dl.QueuedAt,
+			Reason:   dl.Reason,
+			FailedAt: now,
+		})
+		if err != nil {
+			log.Error("Failed to marshal dead letter", log.Fstring("msgID", dl.ID), log.Ferror(err))
+			return err
+		}
+		args := []interface{}{dl.ID, timeToScore(dl.QueuedAt), string(entry)}
+		if dl.Deleted {
+			deleteArgs = append(deleteArgs, args...)
+		} else {
+			upsertArgs = append(upsertArgs, args...)
+		}
+	}
+	if len(upsertArgs) > 0 {
+		if err := deadLetterScript.Run(ctx, mr.client, []string{pendingUpsertsKey, deadLetterKey}, upsertArgs...).Err(); err != nil {
+			log.Error("Failed to dead letter pending messages", log.Ferror(err))
+			return err
+		}
+	}
+	if len(deleteArgs) > 0 {
+		if err := deadLetterScript.Run(ctx, mr.client, []string{pendingDeletesKey, deadLetterKey}, deleteArgs...).Err(); err != nil {
+			log.Error("Failed to dead letter pending deleted messages", log.Ferror(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// RequeueDeadLetters は、デッドレターのメッセージを永続化待ちに戻します。
+// キャッシュから消えているメッセージは、デッドレターに残した内容をキャッシュに戻してから書き戻します。
+func (mr *messageRepository) RequeueDeadLetters(ctx context.Context, ids []string) (int, error) {
+	if len(ids) == 0 {
+		var err error
+		ids, err = mr.client.HKeys(ctx, deadLetterKey).Result()
+		if err != nil {
+			log.Error("Failed to list dead letters", log.Ferror(err))
+			return 0, err
+		}
+		if len(ids) == 0 {
+			return 0, nil
+		}
+	}
+
+	values, err := mr.client.HMGet(ctx, deadLetterKey, ids...).Result()
+	if err != nil {
+		log.Error("Failed to get dead letters", log.Ferror(err))
+		return 0, err
+	}
+
+	keys := []string{deadLetterKey, messagesKey, pendingUpsertsKey, pendingDeletesKey}
+	args := []interface{}{timeToScore(time.Now())}
+	for i, id := range ids {
+		entryBytes, ok := values[i].(string)
+		if !ok {
+			continue
+		}
+		var entry deadLetterEntry
+		if err = json.Unmarshal([]byte(entryBytes), &entry); err != nil {
+			log.Error("Failed to unmarshal dead letter", log.Fstring("msgID", id), log.Ferror(err))
+			return 0, err
+		}
+
+		deleted, message := "0", ""
+		if entry.Deleted {
+			deleted = "1"
+		}
+		if entry.Message != nil {
+			var messageBytes []byte
+			if messageBytes, err = json.Marshal(entry.Message); err != nil {
+				log.Error("Failed to serialize message", log.Fstring("msgID", id), log.Ferror(err))
+				return 0, err
+			}
+			message = string(messageBytes)
+		}
+		keys = append(keys, tombstoneKey(id))
+		args = append(args, id, deleted, message)
+	}
+	if len(args) == 1 {
+		return 0, nil
+	}
+
+	requeued, err := requeueDeadLetterScript.Run(ctx, mr.client, keys, args...).Int()
+	if err != nil {
+		log.Error("Failed to requeue dead letters", log.Ferror(err))
+		return 0, err
+	}
+	return requeued, nil
+}
+
+func (mr *messageRepository) PendingStats(ctx context.Context) (*repository.PendingStats, error) {
+	var stats repository.PendingStats
+	for _, key := range []string{pendingUpsertsKey, pendingDeletesKey} {
+		count, err := mr.client.ZCard(ctx, key).Result()
+		if err != nil {
+			log.Error("Failed to count pending messages", log.Fstring("key", key), log.Ferror(err))
+			return nil, err
+		}
+		if count == 0 {
+			continue
+		}
+		stats.Count += count
+
+		oldest, err := mr.client.ZRangeWithScores(ctx, key, 0, 0).Result()
+		if err != nil {
+			log.Error("Failed to get oldest pending message", log.Fstring("key", key), log.Ferror(err))
+			return nil, err
+		}
+		if len(oldest) == 0 {
+			continue
+		}
+		if queuedAt := scoreToTime(oldest[0].Score); stats.OldestQueuedAt.IsZero() || queuedAt.Before(stats.OldestQueuedAt) {
+			stats.OldestQueuedAt = queuedAt
+		}
+	}
+
+	deadLetters, err := mr.client.HLen(ctx, deadLetterKey).Result()
+	if err != nil {
+		log.Error("Failed to count dead letters", log.Ferror(err))
+		return nil, err
+	}
+	stats.DeadLetters = deadLetters
+	return &stats, nil
+}
+
+// markPending は、メッセージを永続化待ちの集合に追加します。スコアは変更時刻(マイクロ秒)です。
+func markPending(ctx context.Context, pipe redis.Pipeliner, key, messageID string) {
+	pipe.ZAdd(ctx, key, &redis.Z{
+		Score:  timeToScore(time.Now()),
+		Member: messageID,
+	})
+}
+
+func threadKey(parentID string) string {
+	return threadKeyPrefix + parentID
+}
+
+func tombstoneKey(messageID string) string {
+	return tombstoneKeyPrefix + messageID
+}
+
+func coveredKey(channelID string) string {
+	return coveredKeyPrefix + channelID
+}
+
+func timeToScore(t time.Time) float64 {
+	return float64(t.UnixMicro())
+}
+
+func scoreToTime(score float64) time.Time {
+	return time.UnixMicro(int64(score))
+}
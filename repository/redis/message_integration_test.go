@@ -2,12 +2,14 @@ package redis
 
 import (
 	"context"
+	"reflect"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository"
 )
 
 func Test_MessageRepository(t *testing.T) {
@@ -64,4 +66,430 @@ func Test_MessageRepository(t *testing.T) {
 	if err == nil {
 		t.Errorf("Expected error, got nil")
 	}
+
+	// List pending changes
+	pending, err := repo.ListPending(ctx, 100)
+	ValidateErr(t, err, nil)
+	if len(pending) != len(msgs) {
+		t.Errorf("Expected %d pending messages, got %d", len(msgs), len(pending))
+	}
+	for _, pm := range pending {
+		if pm.ID == msgs[0].ID && !pm.Deleted {
+			t.Errorf("Expected message %s to be pending deletion", pm.ID)
+		}
+		if pm.ID != msgs[0].ID && (pm.Deleted || pm.Message == nil) {
+			t.Errorf("Expected message %s to be pending upsert", pm.ID)
+		}
+	}
+
+	stats, err := repo.PendingStats(ctx)
+	ValidateErr(t, err, nil)
+	if stats.Count != int64(len(msgs)) {
+		t.Errorf("Expected %d pending messages, got %d", len(msgs), stats.Count)
+	}
+
+	// A message edited after it was listed must stay pending
+	msgs[1].Text = "edited while flushing"
+	if err = repo.Update(ctx, msgs[1]); err != nil {
+		t.Errorf("Failed to update message: %v", err)
+	}
+	if err = repo.AckPending(ctx, pending); err != nil {
+		t.Errorf("Failed to ack pending messages: %v", err)
+	}
+	pending, err = repo.ListPending(ctx, 100)
+	ValidateErr(t, err, nil)
+	if len(pending) != 1 || pending[0].ID != msgs[1].ID {
+		t.Errorf("Expected only message %s to be pending, got %v", msgs[1].ID, pending)
+	}
+
+	// PendingDeletes reports messages whose deletion has not been persisted yet
+	deleted, err := repo.PendingDeletes(ctx, []string{msgs[0].ID, msgs[1].ID})
+	ValidateErr(t, err, nil)
+	if !deleted[msgs[0].ID] || deleted[msgs[1].ID] {
+		t.Errorf("Expected only message %s to be pending deletion, got %v", msgs[0].ID, deleted)
+	}
+
+	// Fill caches persisted messages without queueing them for write-back
+	// and keeps the cached version of messages which already exist
+	stored := entity.Message{
+		ID:           uuid.New().String(),
+		MembershipID: msgs[1].MembershipID,
+		ChannelID:    channelID,
+		Text:         "loaded from database",
+		CreatedAt:    time.Now(),
+	}
+	staleMsg := msgs[1]
+	staleMsg.Text = "stale content"
+	if err = repo.Fill(ctx, channelID, []entity.Message{stored, staleMsg}); err != nil {
+		t.Errorf("Failed to fill messages: %v", err)
+	}
+	got, err := repo.Get(ctx, msgs[1].ID)
+	ValidateErr(t, err, nil)
+	if got.Text != msgs[1].Text {
+		t.Errorf("Expected message text %s, got %s", msgs[1].Text, got.Text)
+	}
+	listed, err := repo.List(ctx, channelID, time.Now().Add(-1*time.Hour), time.Now().Add(1*time.Hour))
+	ValidateErr(t, err, nil)
+	found := false
+	for _, m := range listed {
+		if m.ID == stored.ID {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected filled message %s to be listed", stored.ID)
+	}
+	pending, err = repo.ListPending(ctx, 100)
+	ValidateErr(t, err, nil)
+	if len(pending) != 1 {
+		t.Errorf("Expected filled messages not to be pending, got %v", pending)
+	}
+
+	// DeadLetter keeps messages which changed after they were listed
+	stale := pending[0]
+	stale.QueuedAt = time.Unix(0, 0)
+	err = repo.DeadLetter(ctx, []repository.DeadLetterMessage{{PendingMessage: stale, Reason: "stale"}})
+	ValidateErr(t, err, nil)
+	pending, err = repo.ListPending(ctx, 100)
+	ValidateErr(t, err, nil)
+	if len(pending) != 1 {
+		t.Errorf("Expected message %s to stay pending, got %v", stale.ID, pending)
+	}
+
+	// DeadLetter moves messages out of the pending set
+	err = repo.DeadLetter(ctx, []repository.DeadLetterMessage{{PendingMessage: pending[0], Reason: "invalid row"}})
+	ValidateErr(t, err, nil)
+	pending, err = repo.ListPending(ctx, 100)
+	ValidateErr(t, err, nil)
+	if len(pending) != 0 {
+		t.Errorf("Expected no pending messages, got %v", pending)
+	}
+	stats, err = repo.PendingStats(ctx)
+	ValidateErr(t, err, nil)
+	if stats.Count != 0 || stats.DeadLetters != 1 {
+		t.Errorf("Expected no pending messages and 1 dead letter, got %v", stats)
+	}
+
+	// RequeueDeadLetters moves dead letters back to the pending set
+	requeued, err := repo.RequeueDeadLetters(ctx, nil)
+	ValidateErr(t, err, nil)
+	if requeued != 1 {
+		t.Errorf("Expected 1 requeued message, got %d", requeued)
+	}
+	pending, err = repo.ListPending(ctx, 100)
+	ValidateErr(t, err, nil)
+	if len(pending) != 1 || pending[0].ID != msgs[1].ID || pending[0].Message == nil {
+		t.Errorf("Expected message %s to be pending again, got %v", msgs[1].ID, pending)
+	}
+	stats, err = repo.PendingStats(ctx)
+	ValidateErr(t, err, nil)
+	if stats.DeadLetters != 0 {
+		t.Errorf("Expected no dead letters, got %d", stats.DeadLetters)
+	}
+	requeued, err = repo.RequeueDeadLetters(ctx, []string{msgs[1].ID})
+	ValidateErr(t, err, nil)
+	if requeued != 0 {
+		t.Errorf("Expected nothing to requeue, got %d", requeued)
+	}
+}
+
+func Test_MessageRepository_ListPendingLimit(t *testing.T) {
+	ctx := context.Background()
+	channelID := uuid.New().String()
+
+	repo := NewMessageRepository(client)
+
+	// 他のテストで残った変更を片付けます
+	leftover, err := repo.ListPending(ctx, 1000)
+	ValidateErr(t, err, nil)
+	if err = repo.AckPending(ctx, leftover); err != nil {
+		t.Errorf("Failed to ack pending messages: %v", err)
+	}
+
+	// 作成と削除を交互に行い、合わせて古い順にlimit件だけ返ることを確かめます
+	var ids []string
+	for i := 0; i < 4; i++ {
+		message := entity.Message{
+			ID:           uuid.New().String(),
+			MembershipID: uuid.New().String(),
+			ChannelID:    channelID,
+			Text:         "pending message",
+			CreatedAt:    time.Now(),
+		}
+		if err = repo.Create(ctx, channelID, message); err != nil {
+			t.Errorf("Failed to create message: %v", err)
+		}
+		if i%2 == 1 {
+			if err = repo.Delete(ctx, channelID, message.ID); err != nil {
+				t.Errorf("Failed to delete message: %v", err)
+			}
+		}
+		ids = append(ids, message.ID)
+	}
+
+	pending, err := repo.ListPending(ctx, 2)
+	ValidateErr(t, err, nil)
+	if len(pending) != 2 {
+		t.Fatalf("Expected 2 pending messages, got %v", pending)
+	}
+	if pending[0].ID != ids[0] || pending[1].ID != ids[1] || !pending[1].Deleted {
+		t.Errorf("Expected the oldest changes %v, got %v", ids[:2], pending)
+	}
+}
+
+func Test_MessageRepository_ListPage(t *testing.T) {
+	ctx := context.Background()
+	channelID := uuid.New().String()
+	membershipID := uuid.New().String()
+	createdAt := time.Now().Truncate(time.Second)
+	// 後ろの3件は同じ秒に作成され、IDの順に並びます
+	msgs := []entity.Message{
+		{ID: "a", MembershipID: membershipID, ChannelID: channelID, Text: "content1", CreatedAt: createdAt.Add(-1 * time.Minute)},
+		{ID: "b", MembershipID: membershipID, ChannelID: channelID, Text: "content2", CreatedAt: createdAt},
+		{ID: "c", MembershipID: membershipID, ChannelID: channelID, Text: "content3", CreatedAt: createdAt},
+		{ID: "d", MembershipID: membershipID, ChannelID: channelID, Text: "content4", CreatedAt: createdAt},
+	}
+
+	repo := NewMessageRepository(client)
+
+	if err := repo.Fill(ctx, channelID, msgs); err != nil {
+		t.Errorf("Failed to fill messages: %v", err)
+	}
+
+	ids := func(messages []entity.Message) []string {
+		var ids []string
+		for _, m := range messages {
+			ids = append(ids, m.ID)
+		}
+		return ids
+	}
+
+	patterns := []struct {
+		name  string
+		query repository.MessagePageQuery
+		want  []string
+	}{
+		{
+			name:  "latest",
+			query: repository.MessagePageQuery{Direction: entity.PageDirectionBefore, Limit: 2},
+			want:  []string{"d", "c"},
+		},
+		{
+			name: "before a cursor sharing its score",
+			query: repository.MessagePageQuery{
+				Cursor:    &entity.MessageCursor{Score: createdAt.Unix(), ID: "c"},
+				Direction: entity.PageDirectionBefore,
+				Limit:     2,
+			},
+			want: []string{"b", "a"},
+		},
+		{
+			name:  "oldest",
+			query: repository.MessagePageQuery{Direction: entity.PageDirectionAfter, Limit: 2},
+			want:  []string{"a", "b"},
+		},
+		{
+			name: "after a cursor sharing its score",
+			query: repository.MessagePageQuery{
+				Cursor:    &entity.MessageCursor{Score: createdAt.Unix(), ID: "b"},
+				Direction: entity.PageDirectionAfter,
+				Limit:     5,
+			},
+			want: []string{"c", "d"},
+		},
+	}
+	for _, tt := range patterns {
+		got, err := repo.ListPage(ctx, channelID, tt.query)
+		ValidateErr(t, err, nil)
+		if !reflect.DeepEqual(ids(got), tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, ids(got))
+		}
+	}
+
+	// Unread messages are counted from a cursor sharing its score, and from the start without one
+	otherChannelID := uuid.New().String()
+	counts, err := repo.CountAfter(ctx, map[string]*entity.MessageCursor{
+		channelID:      {Score: createdAt.Unix(), ID: "b"},
+		otherChannelID: nil,
+	})
+	ValidateErr(t, err, nil)
+	if want := map[string]int64{channelID: 2, otherChannelID: 0}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("CountAfter() = %v, want %v", counts, want)
+	}
+}
+
+func Test_MessageRepository_Thread(t *testing.T) {
+	ctx := context.Background()
+	channelID := uuid.New().String()
+	membershipID := uuid.New().String()
+	parent := entity.Message{ID: uuid.New().String(), MembershipID: membershipID, ChannelID: channelID, Text: "parent", CreatedAt: time.Now()}
+	reply := entity.Message{
+		ID:           uuid.New().String(),
+		MembershipID: membershipID,
+		ChannelID:    channelID,
+		ParentID:     parent.ID,
+		Text:         "reply",
+		CreatedAt:    time.Now(),
+	}
+
+	repo := NewMessageRepository(client)
+
+	// CreateReply requires the parent to be cached
+	_, err := repo.CreateReply(ctx, reply)
+	ValidateErr(t, err, repository.ErrMessageNotCached)
+
+	if err = repo.Create(ctx, channelID, parent); err != nil {
+		t.Errorf("Failed to create message: %v", err)
+	}
+
+	updated, err := repo.CreateReply(ctx, reply)
+	ValidateErr(t, err, nil)
+	if updated.ReplyCount != 1 || updated.LastReplyAt == nil || !updated.LastReplyAt.Equal(reply.CreatedAt) {
+		t.Errorf("Expected parent to have 1 reply at %v, got %+v", reply.CreatedAt, updated)
+	}
+	cached, err := repo.Get(ctx, parent.ID)
+	ValidateErr(t, err, nil)
+	if cached.ReplyCount != 1 || cached.Text != parent.Text {
+		t.Errorf("Expected cached parent to be updated, got %+v", cached)
+	}
+
+	// Replies are listed in the thread but not in the channel
+	replies, err := repo.ListThread(ctx, parent.ID)
+	ValidateErr(t, err, nil)
+	if len(replies) != 1 || replies[0].ID != reply.ID {
+		t.Errorf("Expected reply %s in thread, got %v", reply.ID, replies)
+	}
+	listed, err := repo.ListPage(ctx, channelID, repository.MessagePageQuery{Direction: entity.PageDirectionBefore, Limit: 10})
+	ValidateErr(t, err, nil)
+	if len(listed) != 1 || listed[0].ID != parent.ID {
+		t.Errorf("Expected only parent %s in channel, got %v", parent.ID, listed)
+	}
+
+	updated, err = repo.DeleteReply(ctx, parent.ID, reply.ID)
+	ValidateErr(t, err, nil)
+	if updated.ReplyCount != 0 {
+		t.Errorf("Expected parent to have no replies, got %d", updated.ReplyCount)
+	}
+	replies, err = repo.ListThread(ctx, parent.ID)
+	ValidateErr(t, err, nil)
+	if len(replies) != 0 {
+		t.Errorf("Expected thread to be empty, got %v", replies)
+	}
+	deleted, err := repo.PendingDeletes(ctx, []string{reply.ID})
+	ValidateErr(t, err, nil)
+	if !deleted[reply.ID] {
+		t.Errorf("Expected reply %s to be pending deletion", reply.ID)
+	}
+
+	// Fill does not bring back replies pending deletion
+	if err = repo.Fill(ctx, channelID, []entity.Message{reply}); err != nil {
+		t.Errorf("Failed to fill messages: %v", err)
+	}
+	replies, err = repo.ListThread(ctx, parent.ID)
+	ValidateErr(t, err, nil)
+	if len(replies) != 0 {
+		t.Errorf("Expected deleted reply not to be filled, got %v", replies)
+	}
+
+	// Fill puts replies back into their thread
+	stored := reply
+	stored.ID = uuid.New().String()
+	if err = repo.Fill(ctx, channelID, []entity.Message{stored}); err != nil {
+		t.Errorf("Failed to fill messages: %v", err)
+	}
+	replies, err = repo.ListThread(ctx, parent.ID)
+	ValidateErr(t, err, nil)
+	if len(replies) != 1 || replies[0].ID != stored.ID {
+		t.Errorf("Expected filled reply %s in thread, got %v", stored.ID, replies)
+	}
+}
+
+func Test_MessageRepository_FillAfterDelete(t *testing.T) {
+	ctx := context.Background()
+	channelID := uuid.New().String()
+	message := entity.Message{
+		ID:           uuid.New().String(),
+		MembershipID: uuid.New().String(),
+		ChannelID:    channelID,
+		Text:         "deleted while reading from database",
+		CreatedAt:    time.Now().Truncate(time.Second),
+	}
+
+	repo := NewMessageRepository(client)
+
+	listChannel := func() []entity.Message {
+		listed, err := repo.List(ctx, channelID, time.Now().Add(-1*time.Hour), time.Now().Add(1*time.Hour))
+		ValidateErr(t, err, nil)
+		return listed
+	}
+
+	if err := repo.Create(ctx, channelID, message); err != nil {
+		t.Errorf("Failed to create message: %v", err)
+	}
+	if err := repo.Delete(ctx, channelID, message.ID); err != nil {
+		t.Errorf("Failed to delete message: %v", err)
+	}
+
+	// A message read from MySQL before its deletion was persisted is not filled
+	if err := repo.Fill(ctx, channelID, []entity.Message{message}); err != nil {
+		t.Errorf("Failed to fill messages: %v", err)
+	}
+	if listed := listChannel(); len(listed) != 0 {
+		t.Errorf("Expected message pending deletion not to be filled, got %v", listed)
+	}
+
+	// Neither is it filled right after the deletion was persisted
+	pending, err := repo.ListPending(ctx, 100)
+	ValidateErr(t, err, nil)
+	if err = repo.AckPending(ctx, pending); err != nil {
+		t.Errorf("Failed to ack pending messages: %v", err)
+	}
+	deleted, err := repo.PendingDeletes(ctx, []string{message.ID})
+	ValidateErr(t, err, nil)
+	if deleted[message.ID] {
+		t.Errorf("Expected deletion of message %s to be acked", message.ID)
+	}
+	if err = repo.Fill(ctx, channelID, []entity.Message{message}); err != nil {
+		t.Errorf("Failed to fill messages: %v", err)
+	}
+	if listed := listChannel(); len(listed) != 0 {
+		t.Errorf("Expected persisted deletion not to be filled, got %v", listed)
+	}
+}
+
+func Test_MessageRepository_Covered(t *testing.T) {
+	ctx := context.Background()
+	channelID := uuid.New().String()
+	since := time.Now().Add(-1 * time.Hour).Truncate(time.Second)
+
+	repo := NewMessageRepository(client)
+
+	covered, err := repo.CoveredSince(ctx, channelID)
+	ValidateErr(t, err, nil)
+	if covered != nil {
+		t.Errorf("Expected no covered range, got %v", covered)
+	}
+
+	if err = repo.MarkCovered(ctx, channelID, since); err != nil {
+		t.Errorf("Failed to mark covered range: %v", err)
+	}
+	// A later start does not shrink the covered range
+	if err = repo.MarkCovered(ctx, channelID, since.Add(30*time.Minute)); err != nil {
+		t.Errorf("Failed to mark covered range: %v", err)
+	}
+	covered, err = repo.CoveredSince(ctx, channelID)
+	ValidateErr(t, err, nil)
+	if covered == nil || !covered.Equal(since) {
+		t.Errorf("Expected covered since %v, got %v", since, covered)
+	}
+
+	// An earlier start extends it
+	if err = repo.MarkCovered(ctx, channelID, since.Add(-30*time.Minute)); err != nil {
+		t.Errorf("Failed to mark covered range: %v", err)
+	}
+	covered, err = repo.CoveredSince(ctx, channelID)
+	ValidateErr(t, err, nil)
+	if covered == nil || !covered.Equal(since.Add(-30*time.Minute)) {
+		t.Errorf("Expected covered since %v, got %v", since.Add(-30*time.Minute), covered)
+	}
 }
@@ -0,0 +1,164 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+const (
+	presenceSessionsKeyPrefix = "presence_sessions:"
+	presenceStatusKeyPrefix   = "presence_status:"
+	presenceLastSeenKeyPrefix = "presence_last_seen:"
+)
+
+// touchPresenceScript は、期限の切れた接続を削除してから接続の期限を延ばし、他に有効な接続が無かった場合に1を返します。
+// 接続は期限のミリ秒をスコアとするソート済みセットに保存します。
+//
+// KEYS: presence_sessions:<membershipID>, presence_last_seen:<membershipID>
+// ARGV: connectionID, now(milliseconds), ttl(milliseconds)
+var touchPresenceScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[2])
+local others = redis.call('ZCARD', KEYS[1])
+if redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+	others = others - 1
+end
+redis.call('ZADD', KEYS[1], tonumber(ARGV[2]) + tonumber(ARGV[3]), ARGV[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+redis.call('SET', KEYS[2], ARGV[2])
+if others == 0 then
+	return 1
+end
+return 0
+`)
+
+// removePresenceScript は、接続を削除し、メンバーシップの有効な接続が無くなった場合に1を返します。
+//
+// KEYS: presence_sessions:<membershipID>, presence_last_seen:<membershipID>
+// ARGV: connectionID, now(milliseconds)
+var removePresenceScript = redis.NewScript(`
+local removed = redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[2])
+redis.call('SET', KEYS[2], ARGV[2])
+if removed == 1 and redis.call('ZCARD', KEYS[1]) == 0 then
+	return 1
+end
+return 0
+`)
+
+type presenceRepository struct {
+	client *redis.Client
+}
+
+func NewPresenceRepository(client *redis.Client) repository.PresenceRepository {
+	return &presenceRepository{
+		client: client,
+	}
+}
+
+func (pr *presenceRepository) Touch(ctx context.Context, membershipID, connectionID string, ttl time.Duration) (bool, error) {
+	first, err := touchPresenceScript.Run(
+		ctx,
+		pr.client,
+		[]string{presenceSessionsKey(membershipID), presenceLastSeenKey(membershipID)},
+		connectionID,
+		time.Now().UnixMilli(),
+		ttl.Milliseconds(),
+	).Int()
+	if err != nil {
+		log.Error("Failed to touch presence", log.Fstring("membershipID", membershipID), log.Ferror(err))
+		return false, err
+	}
+	return first == 1, nil
+}
+
+func (pr *presenceRepository) Remove(ctx context.Context, membershipID, connectionID string) (bool, error) {
+	last, err := removePresenceScript.Run(
+		ctx,
+		pr.client,
+		[]string{presenceSessionsKey(membershipID), presenceLastSeenKey(membershipID)},
+		connectionID,
+		time.Now().UnixMilli(),
+	).Int()
+	if err != nil {
+		log.Error("Failed to remove presence", log.Fstring("membershipID", membershipID), log.Ferror(err))
+		return false, err
+	}
+	return last == 1, nil
+}
+
+func (pr *presenceRepository) SetStatus(ctx context.Context, membershipID, status string) error {
+	var err error
+	if status == entity.PresenceOnline {
+		err = pr.client.Del(ctx, presenceStatusKey(membershipID)).Err()
+	} else {
+		err = pr.client.Set(ctx, presenceStatusKey(membershipID), status, 0).Err()
+	}
+	if err != nil {
+		log.Error("Failed to set presence status", log.Fstring("membershipID", membershipID), log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+func (pr *presenceRepository) List(ctx context.Context, membershipIDs []string) ([]entity.Presence, error) {
+	if len(membershipIDs) == 0 {
+		return nil, nil
+	}
+
+	type presenceCmds struct {
+		sessions *redis.IntCmd
+		status   *redis.StringCmd
+		lastSeen *redis.StringCmd
+	}
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	cmds := make([]presenceCmds, len(membershipIDs))
+	pipe := pr.client.Pipeline()
+	for i, membershipID := range membershipIDs {
+		cmds[i] = presenceCmds{
+			sessions: pipe.ZCount(ctx, presenceSessionsKey(membershipID), "("+now, "+inf"),
+			status:   pipe.Get(ctx, presenceStatusKey(membershipID)),
+			lastSeen: pipe.Get(ctx, presenceLastSeenKey(membershipID)),
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		log.Error("Failed to list presences", log.Ferror(err))
+		return nil, err
+	}
+
+	presences := make([]entity.Presence, len(membershipIDs))
+	for i, membershipID := range membershipIDs {
+		presence := entity.Presence{MembershipID: membershipID, Status: entity.PresenceOffline}
+		if cmds[i].sessions.Val() > 0 {
+			presence.Status = entity.PresenceOnline
+			if status := cmds[i].status.Val(); status != "" {
+				presence.Status = status
+			}
+		}
+		if ms, err := cmds[i].lastSeen.Int64(); err == nil {
+			lastSeenAt := time.UnixMilli(ms)
+			presence.LastSeenAt = &lastSeenAt
+		}
+		presences[i] = presence
+	}
+	return presences, nil
+}
+
+func presenceSessionsKey(membershipID string) string {
+	return presenceSessionsKeyPrefix + membershipID
+}
+
+func presenceStatusKey(membershipID string) string {
+	return presenceStatusKeyPrefix + membershipID
+}
+
+func presenceLastSeenKey(membershipID string) string {
+	return presenceLastSeenKeyPrefix + membershipID
+}
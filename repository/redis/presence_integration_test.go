@@ -0,0 +1,78 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+)
+
+func Test_PresenceRepository(t *testing.T) {
+	ctx := context.Background()
+	membershipID := uuid.New().String()
+	neverSeenID := uuid.New().String()
+
+	repo := NewPresenceRepository(client)
+
+	first, err := repo.Touch(ctx, membershipID, "conn1", time.Minute)
+	ValidateErr(t, err, nil)
+	if !first {
+		t.Errorf("Expected the first connection to bring the membership online")
+	}
+	first, err = repo.Touch(ctx, membershipID, "conn2", time.Minute)
+	ValidateErr(t, err, nil)
+	if first {
+		t.Errorf("Expected the second connection not to change presence")
+	}
+	// Heartbeats of an existing connection do not change presence
+	first, err = repo.Touch(ctx, membershipID, "conn1", time.Minute)
+	ValidateErr(t, err, nil)
+	if first {
+		t.Errorf("Expected a heartbeat not to change presence")
+	}
+
+	err = repo.SetStatus(ctx, membershipID, entity.PresenceDoNotDisturb)
+	ValidateErr(t, err, nil)
+	presences, err := repo.List(ctx, []string{membershipID, neverSeenID})
+	ValidateErr(t, err, nil)
+	if len(presences) != 2 || presences[0].Status != entity.PresenceDoNotDisturb || presences[0].LastSeenAt == nil {
+		t.Fatalf("Expected dnd presence with last seen, got %+v", presences)
+	}
+	if presences[1].Status != entity.PresenceOffline || presences[1].LastSeenAt != nil {
+		t.Errorf("Expected offline presence without last seen, got %+v", presences[1])
+	}
+
+	last, err := repo.Remove(ctx, membershipID, "conn1")
+	ValidateErr(t, err, nil)
+	if last {
+		t.Errorf("Expected a remaining connection to keep the membership online")
+	}
+	last, err = repo.Remove(ctx, membershipID, "conn2")
+	ValidateErr(t, err, nil)
+	if !last {
+		t.Errorf("Expected the last connection to take the membership offline")
+	}
+	presences, err = repo.List(ctx, []string{membershipID})
+	ValidateErr(t, err, nil)
+	if presences[0].Status != entity.PresenceOffline {
+		t.Errorf("Expected offline presence, got %+v", presences[0])
+	}
+
+	// Connections of a crashed server expire without being removed
+	_, err = repo.Touch(ctx, membershipID, "conn3", 100*time.Millisecond)
+	ValidateErr(t, err, nil)
+	time.Sleep(200 * time.Millisecond)
+	presences, err = repo.List(ctx, []string{membershipID})
+	ValidateErr(t, err, nil)
+	if presences[0].Status != entity.PresenceOffline {
+		t.Errorf("Expected expired connection to be offline, got %+v", presences[0])
+	}
+	first, err = repo.Touch(ctx, membershipID, "conn4", time.Minute)
+	ValidateErr(t, err, nil)
+	if !first {
+		t.Errorf("Expected a new connection after expiry to bring the membership online")
+	}
+}
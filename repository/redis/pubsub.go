@@ -2,9 +2,11 @@ package redis
 
 import (
 	"context"
+	"sync"
 
 	"github.com/go-redis/redis/v8"
 
+	"github.com/tusmasoma/connectHub-backend/internal/log"
 	"github.com/tusmasoma/connectHub-backend/repository"
 )
 
@@ -18,10 +20,85 @@ func NewPubSubRepository(client *redis.Client) repository.PubSubRepository {
 	}
 }
 
-func (r *pubsubRepository) Publish(ctx context.Context, channel string, message any) error {
-	return r.client.Publish(ctx, channel, message).Err()
+func (r *pubsubRepository) Publish(ctx context.Context, topic string, message []byte) error {
+	return r.client.Publish(ctx, topic, message).Err()
 }
 
-func (r *pubsubRepository) Subscribe(ctx context.Context, channel string) *redis.PubSub {
-	return r.client.Subscribe(ctx, channel)
+func (r *pubsubRepository) Subscribe(ctx context.Context, topic string) (repository.Subscription, error) {
+	pubsub := r.client.Subscribe(ctx, topic)
+	// SUBSCRIBEの応答を待ち、戻った後に配信されたメッセージを取りこぼさないようにします。
+	if _, err := pubsub.Receive(ctx); err != nil {
+		log.Error("Failed to subscribe", log.Fstring("topic", topic), log.Ferror(err))
+		pubsub.Close()
+		return nil, err
+	}
+
+	sub := &subscription{
+		topic:    topic,
+		pubsub:   pubsub,
+		messages: make(chan []byte),
+		done:     make(chan struct{}),
+	}
+	go sub.receive(ctx)
+	return sub, nil
+}
+
+type subscription struct {
+	topic    string
+	pubsub   *redis.PubSub
+	messages chan []byte
+	done     chan struct{}
+	once     sync.Once
+	mu       sync.Mutex
+	err      error
+}
+
+func (s *subscription) Messages() <-chan []byte {
+	return s.messages
+}
+
+func (s *subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *subscription) Close() error {
+	return s.stop(nil)
+}
+
+func (s *subscription) stop(cause error) error {
+	var err error
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.err = cause
+		s.mu.Unlock()
+		close(s.done)
+		err = s.pubsub.Close()
+	})
+	return err
+}
+
+func (s *subscription) receive(ctx context.Context) {
+	defer close(s.messages)
+
+	for {
+		msg, err := s.pubsub.ReceiveMessage(ctx)
+		if err != nil {
+			select {
+			case <-s.done:
+				// Closeで接続を閉じたため、エラーにはしません。
+			default:
+				log.Warn("Subscription ended", log.Fstring("topic", s.topic), log.Ferror(err))
+				s.stop(err) //nolint:errcheck // 購読の終了の原因はerrとして返します
+			}
+			return
+		}
+
+		select {
+		case s.messages <- []byte(msg.Payload):
+		case <-s.done:
+			return
+		}
+	}
 }
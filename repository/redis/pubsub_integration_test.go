@@ -13,21 +13,26 @@ func Test_PubSubRepository(t *testing.T) {
 	channel := "testChannel"
 	message := "testMessage"
 
-	pubsub := repo.Subscribe(ctx, channel)
-	defer pubsub.Close()
-
-	time.Sleep(5 * time.Second) // PublishとSubscribeの間に少し遅延を入れます
+	sub, err := repo.Subscribe(ctx, channel)
+	ValidateErr(t, err, nil)
 
-	err := repo.Publish(ctx, channel, message)
+	err = repo.Publish(ctx, channel, []byte(message))
 	ValidateErr(t, err, nil)
 
-	ch := pubsub.Channel()
 	select {
-	case msg := <-ch:
-		if msg.Payload != message {
-			t.Errorf("Subscribe() \n got = %v,\n want = %v", msg.Payload, message)
+	case msg := <-sub.Messages():
+		if string(msg) != message {
+			t.Errorf("Subscribe() \n got = %v,\n want = %v", string(msg), message)
 		}
 	case <-time.After(10 * time.Second):
 		t.Error("Timeout waiting for message")
 	}
+
+	if err = sub.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if _, ok := <-sub.Messages(); ok {
+		t.Error("Messages() is not closed after Close()")
+	}
+	ValidateErr(t, sub.Err(), nil)
 }
@@ -0,0 +1,179 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+const (
+	reactionsKeyPrefix = "reactions:"
+	// reactionsLoadedMember は、リアクションのないメッセージもキャッシュ済みと判別するための番兵です。
+	reactionsLoadedMember = ""
+	// reactionsTTL が過ぎたリアクションは、次に参照されたときにMySQLから読み込み直します。
+	reactionsTTL = 24 * time.Hour
+)
+
+// addReactionScript は、リアクションがキャッシュ済みのメッセージにのみリアクションを追加します。
+// キャッシュにないメッセージに追加すると他のリアクションが欠けたまま読み出されるため、次の参照時の読み込みに任せます。
+//
+// KEYS: reactions:<messageID>
+// ARGV: member, score
+var addReactionScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+return redis.call('ZADD', KEYS[1], 'NX', ARGV[2], ARGV[1])
+`)
+
+// fillReactionsScript は、まだキャッシュにないメッセージのリアクションを番兵と合わせて追加します。
+//
+// KEYS: reactions:<messageID>
+// ARGV: ttl(seconds), sentinel, [score, member]...
+var fillReactionsScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+redis.call('ZADD', KEYS[1], 0, ARGV[2])
+for i = 3, #ARGV, 2 do
+	redis.call('ZADD', KEYS[1], ARGV[i], ARGV[i + 1])
+end
+redis.call('EXPIRE', KEYS[1], ARGV[1])
+return 1
+`)
+
+// reactionMember は、ソートセットのメンバーとして保存するリアクションです。
+// 削除時にメンバーを一意に特定できるよう、作成日時はスコアに保存します。
+type reactionMember struct {
+	MembershipID string `json:"membership_id"`
+	Emoji        string `json:"emoji"`
+}
+
+type reactionRepository struct {
+	client *redis.Client
+}
+
+func NewReactionRepository(client *redis.Client) repository.ReactionCacheRepository {
+	return &reactionRepository{
+		client: client,
+	}
+}
+
+func (rr *reactionRepository) List(ctx context.Context, messageIDs []string) (map[string][]entity.Reaction, error) {
+	pipe := rr.client.Pipeline()
+	cmds := make([]*redis.ZSliceCmd, len(messageIDs))
+	for i, id := range messageIDs {
+		cmds[i] = pipe.ZRangeWithScores(ctx, reactionsKey(id), 0, -1)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		log.Error("Failed to get reactions", log.Ferror(err))
+		return nil, err
+	}
+
+	reactions := make(map[string][]entity.Reaction, len(messageIDs))
+	for i, cmd := range cmds {
+		members := cmd.Val()
+		if len(members) == 0 {
+			continue
+		}
+		list := make([]entity.Reaction, 0, len(members)-1)
+		for _, z := range members {
+			data, _ := z.Member.(string)
+			if data == reactionsLoadedMember {
+				continue
+			}
+			var member reactionMember
+			if err := json.Unmarshal([]byte(data), &member); err != nil {
+				log.Error("Failed to unmarshal reaction", log.Ferror(err))
+				return nil, err
+			}
+			list = append(list, entity.Reaction{
+				MessageID:    messageIDs[i],
+				MembershipID: member.MembershipID,
+				Emoji:        member.Emoji,
+				CreatedAt:    scoreToTime(z.Score),
+			})
+		}
+		reactions[messageIDs[i]] = list
+	}
+	return reactions, nil
+}
+
+func (rr *reactionRepository) Fill(ctx context.Context, messageIDs []string, reactions []entity.Reaction) error {
+	args := make(map[string][]interface{}, len(messageIDs))
+	for _, id := range messageIDs {
+		args[id] = []interface{}{int(reactionsTTL.Seconds()), reactionsLoadedMember}
+	}
+	for _, reaction := range reactions {
+		member, err := encodeReactionMember(reaction)
+		if err != nil {
+			return err
+		}
+		args[reaction.MessageID] = append(args[reaction.MessageID], timeToScore(reaction.CreatedAt), member)
+	}
+
+	// パイプライン内ではスクリプトの未登録エラーから再送できないため、EVALで送ります。
+	pipe := rr.client.Pipeline()
+	for id, argv := range args {
+		fillReactionsScript.Eval(ctx, pipe, []string{reactionsKey(id)}, argv...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Error("Failed to fill reactions", log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+func (rr *reactionRepository) Add(ctx context.Context, reaction entity.Reaction) error {
+	member, err := encodeReactionMember(reaction)
+	if err != nil {
+		return err
+	}
+	err = addReactionScript.Run(
+		ctx,
+		rr.client,
+		[]string{reactionsKey(reaction.MessageID)},
+		member,
+		timeToScore(reaction.CreatedAt),
+	).Err()
+	if err != nil {
+		log.Error("Failed to add reaction", log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+func (rr *reactionRepository) Remove(ctx context.Context, reaction entity.Reaction) error {
+	member, err := encodeReactionMember(reaction)
+	if err != nil {
+		return err
+	}
+	if err = rr.client.ZRem(ctx, reactionsKey(reaction.MessageID), member).Err(); err != nil {
+		log.Error("Failed to remove reaction", log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+func encodeReactionMember(reaction entity.Reaction) (string, error) {
+	member, err := json.Marshal(reactionMember{
+		MembershipID: reaction.MembershipID,
+		Emoji:        reaction.Emoji,
+	})
+	if err != nil {
+		log.Error("Failed to serialize reaction", log.Ferror(err))
+		return "", err
+	}
+	return string(member), nil
+}
+
+func reactionsKey(messageID string) string {
+	return reactionsKeyPrefix + messageID
+}
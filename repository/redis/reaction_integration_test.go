@@ -0,0 +1,55 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+)
+
+func Test_ReactionRepository(t *testing.T) {
+	ctx := context.Background()
+	messageID := uuid.New().String()
+	emptyID := uuid.New().String()
+	membershipID := uuid.New().String()
+	createdAt := time.Now()
+	stored := entity.Reaction{MessageID: messageID, MembershipID: membershipID, Emoji: "👍", CreatedAt: createdAt}
+	added := entity.Reaction{MessageID: messageID, MembershipID: membershipID, Emoji: "🎉", CreatedAt: createdAt.Add(time.Second)}
+
+	repo := NewReactionRepository(client)
+
+	// Reactions are not added to messages which are not cached yet
+	err := repo.Add(ctx, added)
+	ValidateErr(t, err, nil)
+	cached, err := repo.List(ctx, []string{messageID, emptyID})
+	ValidateErr(t, err, nil)
+	if len(cached) != 0 {
+		t.Errorf("Expected no cached reactions, got %v", cached)
+	}
+
+	err = repo.Fill(ctx, []string{messageID, emptyID}, []entity.Reaction{stored})
+	ValidateErr(t, err, nil)
+	err = repo.Add(ctx, added)
+	ValidateErr(t, err, nil)
+
+	cached, err = repo.List(ctx, []string{messageID, emptyID})
+	ValidateErr(t, err, nil)
+	if reactions, ok := cached[emptyID]; !ok || len(reactions) != 0 {
+		t.Errorf("Expected message %s to be cached without reactions, got %v", emptyID, cached)
+	}
+	reactions := cached[messageID]
+	if len(reactions) != 2 || reactions[0].Emoji != stored.Emoji || reactions[1].Emoji != added.Emoji {
+		t.Errorf("Expected reactions %v and %v, got %v", stored, added, reactions)
+	}
+
+	err = repo.Remove(ctx, stored)
+	ValidateErr(t, err, nil)
+	cached, err = repo.List(ctx, []string{messageID})
+	ValidateErr(t, err, nil)
+	if len(cached[messageID]) != 1 || cached[messageID][0].Emoji != added.Emoji {
+		t.Errorf("Expected only reaction %v, got %v", added, cached[messageID])
+	}
+}
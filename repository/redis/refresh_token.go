@@ -0,0 +1,68 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+const (
+	refreshTokenKeyPrefix     = "refresh_token:"
+	refreshTokenUsedKeyPrefix = "refresh_token_used:"
+)
+
+type refreshTokenRepository struct {
+	*base[entity.RefreshToken]
+}
+
+func NewRefreshTokenRepository(client *redis.Client) repository.RefreshTokenRepository {
+	return &refreshTokenRepository{
+		base: newBase[entity.RefreshToken](client),
+	}
+}
+
+func (rtr *refreshTokenRepository) Create(ctx context.Context, token entity.RefreshToken) error {
+	data, err := rtr.serialize(token)
+	if err != nil {
+		return err
+	}
+	if err = rtr.client.Set(ctx, refreshTokenKey(token.ID), data, time.Until(token.ExpiresAt)).Err(); err != nil {
+		log.Error("Failed to create refresh token", log.Fstring("userID", token.UserID), log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+func (rtr *refreshTokenRepository) Get(ctx context.Context, id string) (*entity.RefreshToken, error) {
+	token, err := rtr.base.Get(ctx, refreshTokenKey(id))
+	if errors.Is(err, ErrCacheMiss) {
+		return nil, repository.ErrRefreshTokenNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (rtr *refreshTokenRepository) MarkUsed(ctx context.Context, token entity.RefreshToken) (bool, error) {
+	// 同時に届いた同じトークンのどちらか一方だけが使用済みにできるよう、SETNXで記録します。
+	marked, err := rtr.client.SetNX(ctx, refreshTokenUsedKey(token.ID), 1, time.Until(token.ExpiresAt)).Result()
+	if err != nil {
+		log.Error("Failed to mark refresh token as used", log.Fstring("userID", token.UserID), log.Ferror(err))
+		return false, err
+	}
+	return marked, nil
+}
+
+func refreshTokenKey(id string) string {
+	return refreshTokenKeyPrefix + id
+}
+
+func refreshTokenUsedKey(id string) string {
+	return refreshTokenUsedKeyPrefix + id
+}
@@ -0,0 +1,46 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+func Test_RefreshTokenRepository(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New().String()
+	familyID := uuid.New().String()
+
+	repo := NewRefreshTokenRepository(client)
+
+	token, err := entity.NewRefreshToken(uuid.New().String(), userID, familyID, time.Minute)
+	ValidateErr(t, err, nil)
+	err = repo.Create(ctx, *token)
+	ValidateErr(t, err, nil)
+
+	got, err := repo.Get(ctx, token.ID)
+	ValidateErr(t, err, nil)
+	if got.UserID != userID || got.FamilyID != familyID {
+		t.Errorf("Get() = %v, want %v", got, token)
+	}
+
+	_, err = repo.Get(ctx, uuid.New().String())
+	ValidateErr(t, err, repository.ErrRefreshTokenNotFound)
+
+	// Only the first use succeeds
+	marked, err := repo.MarkUsed(ctx, *token)
+	ValidateErr(t, err, nil)
+	if !marked {
+		t.Error("Expected the first MarkUsed to succeed")
+	}
+	marked, err = repo.MarkUsed(ctx, *token)
+	ValidateErr(t, err, nil)
+	if marked {
+		t.Error("Expected the second MarkUsed to detect reuse")
+	}
+}
@@ -0,0 +1,234 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+const (
+	userSessionsKeyPrefix         = "user_sessions:"
+	userSessionsLastUsedKeyPrefix = "user_sessions_last_used:"
+)
+
+// saveSessionScript は、セッションを保存し、ユーザーのセッションの期限を最も遅いセッションの有効期限まで延ばします。
+// 最終利用日時は認証のたびに更新するため、セッションとは別のハッシュに保存します。
+//
+// KEYS: user_sessions:<userID>, user_sessions_last_used:<userID>
+// ARGV: sessionID, session(JSON), lastUsedAt(milliseconds), ttl(milliseconds)
+var saveSessionScript = redis.NewScript(`
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+redis.call('HSET', KEYS[2], ARGV[1], ARGV[3])
+local ttl = tonumber(ARGV[4])
+if redis.call('PTTL', KEYS[1]) < ttl then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+	redis.call('PEXPIRE', KEYS[2], ttl)
+end
+return 1
+`)
+
+// renewSessionScript は、セッションが存在する場合だけsaveSessionScriptと同じ更新を行い、更新したかどうかを返します。
+// 再利用の検知やログアウトで削除されたセッションを、同時に処理していたリフレッシュが作り直さないようにします。
+//
+// KEYS: user_sessions:<userID>, user_sessions_last_used:<userID>
+// ARGV: sessionID, session(JSON), lastUsedAt(milliseconds), ttl(milliseconds)
+var renewSessionScript = redis.NewScript(`
+if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+redis.call('HSET', KEYS[2], ARGV[1], ARGV[3])
+local ttl = tonumber(ARGV[4])
+if redis.call('PTTL', KEYS[1]) < ttl then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+	redis.call('PEXPIRE', KEYS[2], ttl)
+end
+return 1
+`)
+
+// touchSessionScript は、セッションが存在する場合だけ最終利用日時を更新します。
+// 削除と同時に届いた認証で、削除したセッションの最終利用日時が残らないようにします。
+//
+// KEYS: user_sessions:<userID>, user_sessions_last_used:<userID>
+// ARGV: sessionID, lastUsedAt(milliseconds)
+var touchSessionScript = redis.NewScript(`
+if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 1 then
+	redis.call('HSET', KEYS[2], ARGV[1], ARGV[2])
+end
+return 1
+`)
+
+type sessionRepository struct {
+	*base[entity.Session]
+}
+
+func NewSessionRepository(client *redis.Client) repository.SessionRepository {
+	return &sessionRepository{
+		base: newBase[entity.Session](client),
+	}
+}
+
+func (sr *sessionRepository) Save(ctx context.Context, session entity.Session) error {
+	data, err := sr.serialize(session)
+	if err != nil {
+		return err
+	}
+	keys := []string{userSessionsKey(session.UserID), userSessionsLastUsedKey(session.UserID)}
+	ttl := time.Until(session.ExpiresAt)
+	if err = saveSessionScript.Run(ctx, sr.client, keys, session.ID, data, session.LastUsedAt.UnixMilli(), ttl.Milliseconds()).Err(); err != nil {
+		log.Error("Failed to save session", log.Fstring("userID", session.UserID), log.Fstring("sessionID", session.ID), log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+func (sr *sessionRepository) Renew(ctx context.Context, session entity.Session) error {
+	data, err := sr.serialize(session)
+	if err != nil {
+		return err
+	}
+	keys := []string{userSessionsKey(session.UserID), userSessionsLastUsedKey(session.UserID)}
+	ttl := time.Until(session.ExpiresAt)
+	renewed, err := renewSessionScript.Run(ctx, sr.client, keys, session.ID, data, session.LastUsedAt.UnixMilli(), ttl.Milliseconds()).Int()
+	if err != nil {
+		log.Error("Failed to renew session", log.Fstring("userID", session.UserID), log.Fstring("sessionID", session.ID), log.Ferror(err))
+		return err
+	}
+	if renewed == 0 {
+		return repository.ErrSessionNotFound
+	}
+	return nil
+}
+
+func (sr *sessionRepository) Get(ctx context.Context, userID, sessionID string) (*entity.Session, error) {
+	pipe := sr.client.Pipeline()
+	dataCmd := pipe.HGet(ctx, userSessionsKey(userID), sessionID)
+	lastUsedCmd := pipe.HGet(ctx, userSessionsLastUsedKey(userID), sessionID)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		log.Error("Failed to get session", log.Fstring("userID", userID), log.Fstring("sessionID", sessionID), log.Ferror(err))
+		return nil, err
+	}
+	if errors.Is(dataCmd.Err(), redis.Nil) {
+		return nil, repository.ErrSessionNotFound
+	}
+
+	session, err := sr.decode(dataCmd.Val(), lastUsedCmd.Val())
+	if err != nil {
+		return nil, err
+	}
+	if session.Expired(time.Now()) {
+		if err = sr.deleteFields(ctx, userID, sessionID); err != nil {
+			return nil, err
+		}
+		return nil, repository.ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func (sr *sessionRepository) List(ctx context.Context, userID string) ([]entity.Session, error) {
+	pipe := sr.client.Pipeline()
+	dataCmd := pipe.HGetAll(ctx, userSessionsKey(userID))
+	lastUsedCmd := pipe.HGetAll(ctx, userSessionsLastUsedKey(userID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Error("Failed to list sessions", log.Fstring("userID", userID), log.Ferror(err))
+		return nil, err
+	}
+
+	now := time.Now()
+	lastUsed := lastUsedCmd.Val()
+	sessions := make([]entity.Session, 0, len(dataCmd.Val()))
+	var expired []string
+	for sessionID, data := range dataCmd.Val() {
+		session, err := sr.decode(data, lastUsed[sessionID])
+		if err != nil {
+			return nil, err
+		}
+		if session.Expired(now) {
+			expired = append(expired, sessionID)
+			continue
+		}
+		sessions = append(sessions, *session)
+	}
+	if len(expired) > 0 {
+		if err := sr.deleteFields(ctx, userID, expired...); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+	return sessions, nil
+}
+
+func (sr *sessionRepository) Touch(ctx context.Context, userID, sessionID string, at time.Time) error {
+	keys := []string{userSessionsKey(userID), userSessionsLastUsedKey(userID)}
+	if err := touchSessionScript.Run(ctx, sr.client, keys, sessionID, at.UnixMilli()).Err(); err != nil {
+		log.Error("Failed to touch session", log.Fstring("userID", userID), log.Fstring("sessionID", sessionID), log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+func (sr *sessionRepository) Delete(ctx context.Context, userID, sessionID string) error {
+	deleted, err := sr.client.HDel(ctx, userSessionsKey(userID), sessionID).Result()
+	if err != nil {
+		log.Error("Failed to delete session", log.Fstring("userID", userID), log.Fstring("sessionID", sessionID), log.Ferror(err))
+		return err
+	}
+	if err = sr.client.HDel(ctx, userSessionsLastUsedKey(userID), sessionID).Err(); err != nil {
+		log.Error("Failed to delete session", log.Fstring("userID", userID), log.Fstring("sessionID", sessionID), log.Ferror(err))
+		return err
+	}
+	if deleted == 0 {
+		return repository.ErrSessionNotFound
+	}
+	return nil
+}
+
+func (sr *sessionRepository) DeleteAll(ctx context.Context, userID string) error {
+	if err := sr.client.Del(ctx, userSessionsKey(userID), userSessionsLastUsedKey(userID)).Err(); err != nil {
+		log.Error("Failed to delete sessions", log.Fstring("userID", userID), log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+// decode は、保存したセッションに別に保存した最終利用日時を反映します。
+func (sr *sessionRepository) decode(data, lastUsed string) (*entity.Session, error) {
+	session, err := sr.deserialize(data)
+	if err != nil {
+		return nil, err
+	}
+	if ms, err := strconv.ParseInt(lastUsed, 10, 64); err == nil && ms > session.LastUsedAt.UnixMilli() {
+		session.LastUsedAt = time.UnixMilli(ms)
+	}
+	return session, nil
+}
+
+func (sr *sessionRepository) deleteFields(ctx context.Context, userID string, sessionIDs ...string) error {
+	pipe := sr.client.Pipeline()
+	pipe.HDel(ctx, userSessionsKey(userID), sessionIDs...)
+	pipe.HDel(ctx, userSessionsLastUsedKey(userID), sessionIDs...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Error("Failed to delete expired sessions", log.Fstring("userID", userID), log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+func userSessionsKey(userID string) string {
+	return userSessionsKeyPrefix + userID
+}
+
+func userSessionsLastUsedKey(userID string) string {
+	return userSessionsLastUsedKeyPrefix + userID
+}
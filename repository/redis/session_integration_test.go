@@ -0,0 +1,90 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+func Test_SessionRepository(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New().String()
+	now := time.Now()
+
+	repo := NewSessionRepository(client)
+
+	laptop, err := entity.NewSession(userID, "laptop", "Mozilla/5.0", "192.0.2.1")
+	ValidateErr(t, err, nil)
+	laptop.Renew(uuid.New().String(), time.Minute, now)
+	phone, err := entity.NewSession(userID, "phone", "Mobile Safari", "192.0.2.2")
+	ValidateErr(t, err, nil)
+	phone.Renew(uuid.New().String(), time.Minute, now)
+
+	err = repo.Save(ctx, *laptop)
+	ValidateErr(t, err, nil)
+	err = repo.Save(ctx, *phone)
+	ValidateErr(t, err, nil)
+
+	got, err := repo.Get(ctx, userID, phone.ID)
+	ValidateErr(t, err, nil)
+	if got.JTI != phone.JTI || got.Device != "phone" {
+		t.Errorf("Get() = %v, want %v", got, phone)
+	}
+
+	// Touch updates the last used time without rewriting the session
+	lastUsed := now.Add(30 * time.Second)
+	err = repo.Touch(ctx, userID, phone.ID, lastUsed)
+	ValidateErr(t, err, nil)
+
+	sessions, err := repo.List(ctx, userID)
+	ValidateErr(t, err, nil)
+	if len(sessions) != 2 || sessions[0].ID != laptop.ID || sessions[1].ID != phone.ID {
+		t.Fatalf("List() = %v", sessions)
+	}
+	if sessions[1].LastUsedAt.UnixMilli() != lastUsed.UnixMilli() {
+		t.Errorf("Expected last used at %v, got %v", lastUsed, sessions[1].LastUsedAt)
+	}
+
+	err = repo.Delete(ctx, userID, laptop.ID)
+	ValidateErr(t, err, nil)
+	_, err = repo.Get(ctx, userID, laptop.ID)
+	ValidateErr(t, err, repository.ErrSessionNotFound)
+	err = repo.Delete(ctx, userID, laptop.ID)
+	ValidateErr(t, err, repository.ErrSessionNotFound)
+
+	// Renew updates existing sessions but never recreates deleted ones
+	phone.Renew(uuid.New().String(), time.Minute, now)
+	err = repo.Renew(ctx, *phone)
+	ValidateErr(t, err, nil)
+	got, err = repo.Get(ctx, userID, phone.ID)
+	ValidateErr(t, err, nil)
+	if got.JTI != phone.JTI {
+		t.Errorf("Expected renewed jti %s, got %s", phone.JTI, got.JTI)
+	}
+	err = repo.Renew(ctx, *laptop)
+	ValidateErr(t, err, repository.ErrSessionNotFound)
+	_, err = repo.Get(ctx, userID, laptop.ID)
+	ValidateErr(t, err, repository.ErrSessionNotFound)
+
+	// Expired sessions are treated as missing
+	expired, err := entity.NewSession(userID, "tablet", "", "")
+	ValidateErr(t, err, nil)
+	expired.Renew(uuid.New().String(), time.Minute, now.Add(-time.Hour))
+	err = repo.Save(ctx, *expired)
+	ValidateErr(t, err, nil)
+	_, err = repo.Get(ctx, userID, expired.ID)
+	ValidateErr(t, err, repository.ErrSessionNotFound)
+
+	err = repo.DeleteAll(ctx, userID)
+	ValidateErr(t, err, nil)
+	sessions, err = repo.List(ctx, userID)
+	ValidateErr(t, err, nil)
+	if len(sessions) != 0 {
+		t.Errorf("Expected no sessions, got %v", sessions)
+	}
+}
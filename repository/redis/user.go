@@ -1,8 +1,6 @@
 package redis
 
 import (
-	"context"
-
 	"github.com/go-redis/redis/v8"
 
 	"github.com/tusmasoma/connectHub-backend/entity"
@@ -18,11 +16,3 @@ func NewUserRepository(client *redis.Client) repository.UserCacheRepository {
 		base: newBase[entity.User](client),
 	}
 }
-
-func (ur *userRepository) GetUserSession(ctx context.Context, userID string) (string, error) {
-	return ur.client.Get(ctx, userID).Result()
-}
-
-func (ur *userRepository) SetUserSession(ctx context.Context, userID string, sessionData string) error {
-	return ur.client.Set(ctx, userID, sessionData, 0).Err()
-}
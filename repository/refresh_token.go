@@ -0,0 +1,23 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+)
+
+var ErrRefreshTokenNotFound = errors.New("refresh token not found")
+
+// RefreshTokenRepository は、リフレッシュトークンを保存します。
+// トークンのファミリーはセッションと対応し、セッションを削除するとそのファミリーのトークンは使えなくなります。
+type RefreshTokenRepository interface {
+	// Create は、リフレッシュトークンを有効期限まで保存します。
+	Create(ctx context.Context, token entity.RefreshToken) error
+	// Get は、ハッシュ値がidのリフレッシュトークンを返します。存在しない場合はErrRefreshTokenNotFoundを返します。
+	Get(ctx context.Context, id string) (*entity.RefreshToken, error)
+	// MarkUsed は、リフレッシュトークンを使用済みにします。既に使用済みだった場合はfalseを返します。
+	// 再利用を検知できるよう、使用済みのトークンも有効期限までは残します。
+	MarkUsed(ctx context.Context, token entity.RefreshToken) (bool, error)
+}
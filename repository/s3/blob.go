@@ -0,0 +1,154 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+// maxErrorBodySize は、エラーの応答からログに残す本文の最大バイト数です。
+const maxErrorBodySize = 1024
+
+type blobRepository struct {
+	client    *http.Client
+	endpoint  *url.URL
+	bucket    string
+	pathStyle bool
+	signer    signer
+	now       func() time.Time
+}
+
+// NewBlobRepository は、S3互換のオブジェクトストレージのバケットに内容を保存します。
+func NewBlobRepository(conf *config.AttachmentConfig) (repository.BlobRepository, error) {
+	endpoint, err := url.Parse(conf.S3Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		log.Error("Invalid S3 endpoint", log.Fstring("endpoint", conf.S3Endpoint))
+		return nil, fmt.Errorf("invalid s3 endpoint: %q", conf.S3Endpoint)
+	}
+	if conf.S3Bucket == "" {
+		log.Error("S3 bucket is required")
+		return nil, fmt.Errorf("s3 bucket is required")
+	}
+	return &blobRepository{
+		client:    &http.Client{},
+		endpoint:  endpoint,
+		bucket:    conf.S3Bucket,
+		pathStyle: conf.S3UsePathStyle,
+		signer: signer{
+			accessKeyID:     conf.S3AccessKeyID,
+			secretAccessKey: conf.S3SecretAccessKey,
+			region:          conf.S3Region,
+		},
+		now: time.Now,
+	}, nil
+}
+
+// Put は、本文をストリーミングで送るため、本文のハッシュを署名に含めません。
+func (br *blobRepository) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	req, err := br.newRequest(ctx, http.MethodPut, key, io.LimitReader(body, size))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	br.signer.sign(req, unsignedPayload, br.now())
+
+	resp, err := br.client.Do(req)
+	if err != nil {
+		log.Error("Failed to put object", log.Fstring("key", key), log.Ferror(err))
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp, key)
+	}
+	return nil
+}
+
+func (br *blobRepository) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := br.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	br.signer.sign(req, emptyPayloadHash, br.now())
+
+	resp, err := br.client.Do(req)
+	if err != nil {
+		log.Error("Failed to get object", log.Fstring("key", key), log.Ferror(err))
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, repository.ErrBlobNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, responseError(resp, key)
+	}
+}
+
+// Delete は、S3が存在しないオブジェクトの削除も成功として扱うため、そのまま結果を返します。
+func (br *blobRepository) Delete(ctx context.Context, key string) error {
+	req, err := br.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	br.signer.sign(req, emptyPayloadHash, br.now())
+
+	resp, err := br.client.Do(req)
+	if err != nil {
+		log.Error("Failed to delete object", log.Fstring("key", key), log.Ferror(err))
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return responseError(resp, key)
+	}
+	return nil
+}
+
+// newRequest は、パス形式ではバケットをパスに、仮想ホスト形式ではホスト名に含めたオブジェクトのURLへのリクエストを作成します。
+func (br *blobRepository) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	if key == "" || strings.HasPrefix(key, "/") {
+		log.Warn("Invalid blob key", log.Fstring("key", key))
+		return nil, fmt.Errorf("invalid blob key: %q", key)
+	}
+	u := *br.endpoint
+	if br.pathStyle {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + br.bucket + "/" + key
+	} else {
+		u.Host = br.bucket + "." + u.Host
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + key
+	}
+	u.RawPath = escapePath(u.Path)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		log.Error("Failed to create request", log.Fstring("key", key), log.Ferror(err))
+		return nil, err
+	}
+	return req, nil
+}
+
+func responseError(resp *http.Response, key string) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	log.Error(
+		"Unexpected response from object storage",
+		log.Fstring("key", key),
+		log.Fint("status", resp.StatusCode),
+		log.Fstring("body", string(body)),
+	)
+	return fmt.Errorf("unexpected response from object storage: %s", resp.Status)
+}
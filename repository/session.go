@@ -0,0 +1,32 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionRepository は、ユーザーごとのログインセッションを保存します。
+// 有効期限の切れたセッションは存在しないものとして扱います。
+type SessionRepository interface {
+	// Save は、セッションを作成または更新します。
+	Save(ctx context.Context, session entity.Session) error
+	// Renew は、セッションが存在する場合だけ更新します。存在しない場合はErrSessionNotFoundを返します。
+	// 更新の直前に失効したセッションを復活させないよう、トークンの再発行ではSaveの代わりに使います。
+	Renew(ctx context.Context, session entity.Session) error
+	// Get は、userIDのセッションを返します。存在しない場合はErrSessionNotFoundを返します。
+	Get(ctx context.Context, userID, sessionID string) (*entity.Session, error)
+	// List は、userIDの有効なセッションを作成日時の古い順に返します。
+	List(ctx context.Context, userID string) ([]entity.Session, error)
+	// Touch は、セッションの最終利用日時をatにします。セッションが存在しない場合は何もしません。
+	Touch(ctx context.Context, userID, sessionID string, at time.Time) error
+	// Delete は、userIDのセッションを削除します。存在しない場合はErrSessionNotFoundを返します。
+	Delete(ctx context.Context, userID, sessionID string) error
+	// DeleteAll は、userIDの全てのセッションを削除します。
+	DeleteAll(ctx context.Context, userID string) error
+}
@@ -0,0 +1,14 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+)
+
+type ThreadFollowerRepository interface {
+	List(ctx context.Context, qcs []QueryCondition) ([]entity.ThreadFollower, error)
+	Create(ctx context.Context, follower entity.ThreadFollower) error
+	Delete(ctx context.Context, messageID, membershipID string) error
+}
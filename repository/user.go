@@ -18,9 +18,7 @@ type UserRepository interface {
 
 type UserCacheRepository interface {
 	Set(ctx context.Context, key string, user entity.User) error
-	SetUserSession(ctx context.Context, userID string, sessionData string) error
 	Get(ctx context.Context, key string) (*entity.User, error)
-	GetUserSession(ctx context.Context, userID string) (string, error)
 	Delete(ctx context.Context, key string) error
 	Exists(ctx context.Context, key string) bool
 	Scan(ctx context.Context, match string) ([]string, error)
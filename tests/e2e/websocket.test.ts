@@ -1,6 +1,8 @@
 import WebSocket from "ws";
 import axios from "axios";
 
+import { waitForAction } from "./websocket_helpers";
+
 jest.setTimeout(1000000); // タイムアウトを延長
 
 describe("WebSocket E2E Tests with Go Server", () => {
@@ -10,6 +12,7 @@ describe("WebSocket E2E Tests with Go Server", () => {
   let channelID: string;
   let msgID: string;
   let membershipIDofMsg: string;
+  let createdAtOfMsg: string;
 
   // 全てのテストの前に実行されるセットアップ処理
   beforeAll(async () => {
@@ -105,6 +108,7 @@ describe("WebSocket E2E Tests with Go Server", () => {
     const testChannel = `test_${Date.now()}_Channel`;
     const createChannelMessage = {
       action_tag: "CREATE_PUBLIC_CHANNEL",
+      request_id: `create-public-channel-${Date.now()}`,
       target_id: "",
       sender_id: clientID,
       content: {
@@ -116,15 +120,18 @@ describe("WebSocket E2E Tests with Go Server", () => {
       },
     };
 
-    ws.once("message", (data) => {
-      const receivedMessage = JSON.parse(data.toString());
-      if (receivedMessage.action_tag === "CREATE_PUBLIC_CHANNEL") {
+    waitForAction(
+      ws,
+      "CREATE_PUBLIC_CHANNEL",
+      createChannelMessage.request_id
+    )
+      .then((receivedMessage) => {
         expect(receivedMessage.content.text).toBe(testChannel);
         channelID = receivedMessage.target_id;
         console.log("SUCCESS: CREATE_PUBLIC_CHANNEL");
         done();
-      }
-    });
+      })
+      .catch(done);
 
     if (ws.readyState === WebSocket.OPEN) {
       ws.send(JSON.stringify(createChannelMessage));
@@ -144,6 +151,7 @@ describe("WebSocket E2E Tests with Go Server", () => {
   test("TEST: Create Message", (done) => {
     const testMessage = {
       action_tag: "CREATE_MESSAGE",
+      request_id: `create-message-${Date.now()}`,
       target_id: channelID,
       sender_id: clientID,
       content: {
@@ -155,24 +163,25 @@ describe("WebSocket E2E Tests with Go Server", () => {
       },
     };
 
-    ws.once("message", (data) => {
-      const receivedMessage = JSON.parse(data.toString());
-      if (receivedMessage.action_tag === "CREATE_MESSAGE") {
+    waitForAction(ws, "CREATE_MESSAGE", testMessage.request_id)
+      .then((receivedMessage) => {
         expect(receivedMessage.action_tag).toBe(testMessage.action_tag);
         expect(receivedMessage.target_id).toBe(testMessage.target_id);
         //expect(receivedMessage.sender_id).toBe(testMessage.sender_id);
         expect(receivedMessage.content.id).not.toBe("");
         expect(receivedMessage.content.membership_id).not.toBe("");
         expect(receivedMessage.content.text).toBe(testMessage.content.text);
-        expect(receivedMessage.content.created_at).toBe(
+        // 作成日時はサーバーが設定するため、クライアントが送った値は使われない
+        expect(receivedMessage.content.created_at).not.toBe(
           testMessage.content.created_at
         );
         console.log("SUCCESS: CREATE_MESSAGE");
         msgID = receivedMessage.content.id;
         membershipIDofMsg = receivedMessage.content.membership_id;
+        createdAtOfMsg = receivedMessage.content.created_at;
         done();
-      }
-    });
+      })
+      .catch(done);
 
     if (ws.readyState === WebSocket.OPEN) {
       ws.send(JSON.stringify(testMessage));
@@ -192,6 +201,7 @@ describe("WebSocket E2E Tests with Go Server", () => {
   test("TEST: List Messages", (done) => {
     const listMessagesRequest = {
       action_tag: "LIST_MESSAGES",
+      request_id: `list-messages-${Date.now()}`,
       target_id: channelID,
       sender_id: clientID,
       content: {
@@ -201,11 +211,14 @@ describe("WebSocket E2E Tests with Go Server", () => {
         created_at: "2024-06-11T15:48:00Z",
         updated_at: null,
       },
+      page: {
+        limit: 50,
+        direction: "before",
+      },
     };
 
-    ws.once("message", (data) => {
-      const receivedMessage = JSON.parse(data.toString());
-      if (receivedMessage.action_tag === "LIST_MESSAGES") {
+    waitForAction(ws, "LIST_MESSAGES", listMessagesRequest.request_id)
+      .then((receivedMessage) => {
         expect(receivedMessage.action_tag).toBe(listMessagesRequest.action_tag);
         expect(receivedMessage.target_id).toBe(channelID);
         //expect(receivedMessage.sender_id).toBe(clientID);
@@ -220,8 +233,8 @@ describe("WebSocket E2E Tests with Go Server", () => {
         expect(receivedMessage.contents[0]).toHaveProperty("created_at");
         console.log("SUCCESS: LIST_MESSAGES");
         done();
-      }
-    });
+      })
+      .catch(done);
 
     if (ws.readyState === WebSocket.OPEN) {
       ws.send(JSON.stringify(listMessagesRequest));
@@ -241,6 +254,7 @@ describe("WebSocket E2E Tests with Go Server", () => {
   test("TEST: Update Message", (done) => {
     const testMessage = {
       action_tag: "UPDATE_MESSAGE",
+      request_id: `update-message-${Date.now()}`,
       target_id: channelID,
       sender_id: clientID,
       content: {
@@ -252,9 +266,8 @@ describe("WebSocket E2E Tests with Go Server", () => {
       },
     };
 
-    ws.once("message", (data) => {
-      const receivedMessage = JSON.parse(data.toString());
-      if (receivedMessage.action_tag === "UPDATE_MESSAGE") {
+    waitForAction(ws, "UPDATE_MESSAGE", testMessage.request_id)
+      .then((receivedMessage) => {
         expect(receivedMessage.action_tag).toBe(testMessage.action_tag);
         expect(receivedMessage.target_id).toBe(testMessage.target_id);
         //expect(receivedMessage.sender_id).toBe(testMessage.sender_id);
@@ -263,16 +276,12 @@ describe("WebSocket E2E Tests with Go Server", () => {
           testMessage.content.membership_id
         );
         expect(receivedMessage.content.text).toBe(testMessage.content.text);
-        expect(receivedMessage.content.created_at).toBe(
-          testMessage.content.created_at
-        );
-        expect(receivedMessage.content.updated_at).toBe(
-          testMessage.content.updated_at
-        );
+        expect(receivedMessage.content.created_at).toBe(createdAtOfMsg);
+        expect(receivedMessage.content.updated_at).not.toBeNull();
         console.log("SUCCESS: UPDATE_MESSAGE");
         done();
-      }
-    });
+      })
+      .catch(done);
 
     if (ws.readyState === WebSocket.OPEN) {
       ws.send(JSON.stringify(testMessage));
@@ -292,6 +301,7 @@ describe("WebSocket E2E Tests with Go Server", () => {
   test("TEST: Delete Message", (done) => {
     const testMessage = {
       action_tag: "DELETE_MESSAGE",
+      request_id: `delete-message-${Date.now()}`,
       target_id: channelID,
       sender_id: clientID,
       content: {
@@ -303,23 +313,22 @@ describe("WebSocket E2E Tests with Go Server", () => {
       },
     };
 
-    ws.once("message", (data) => {
-      const receivedMessage = JSON.parse(data.toString());
-      if (receivedMessage.action_tag === "DELETE_MESSAGE") {
+    waitForAction(ws, "DELETE_MESSAGE", testMessage.request_id)
+      .then((receivedMessage) => {
         expect(receivedMessage.action_tag).toBe(testMessage.action_tag);
         expect(receivedMessage.target_id).toBe(testMessage.target_id);
         //expect(receivedMessage.sender_id).toBe(testMessage.sender_id);
         expect(receivedMessage.content.membership_id).toBe(
           testMessage.content.membership_id
         );
-        expect(receivedMessage.content.text).toBe(testMessage.content.text);
-        expect(receivedMessage.content.created_at).toBe(
-          testMessage.content.created_at
-        );
+        // 削除したメッセージは保存済みの内容で配信される
+        expect(receivedMessage.content.id).toBe(testMessage.content.id);
+        expect(receivedMessage.content.text).toBe("更新したいメッセージの内容");
+        expect(receivedMessage.content.created_at).toBe(createdAtOfMsg);
         console.log("SUCCESS: DELETE_MESSAGE");
         done();
-      }
-    });
+      })
+      .catch(done);
 
     if (ws.readyState === WebSocket.OPEN) {
       ws.send(JSON.stringify(testMessage));
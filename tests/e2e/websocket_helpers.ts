@@ -0,0 +1,28 @@
+import WebSocket from "ws";
+
+// waitForAction は、action_tagが一致するフレームを受け取るまで待ちます。
+// 送信元にはブロードキャストより先にACKが届くため、それ以外のフレームは読み飛ばします。
+// request_idが一致するERRORを受け取った場合は失敗します。
+export function waitForAction(
+  ws: WebSocket,
+  action: string,
+  requestID: string
+): Promise<any> {
+  return new Promise((resolve, reject) => {
+    const onMessage = (data: WebSocket.RawData) => {
+      const frame = JSON.parse(data.toString());
+      if (frame.action_tag === "ERROR" && frame.request_id === requestID) {
+        ws.off("message", onMessage);
+        reject(
+          new Error(`${frame.reply_to} failed: ${frame.code} ${frame.message}`)
+        );
+        return;
+      }
+      if (frame.action_tag === action) {
+        ws.off("message", onMessage);
+        resolve(frame);
+      }
+    };
+    ws.on("message", onMessage);
+  });
+}
@@ -0,0 +1,255 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+var (
+	// ErrAttachmentTooLarge は、ワークスペースの上限を超えるファイルをアップロードした場合に返されます。
+	ErrAttachmentTooLarge = errors.New("attachment too large")
+	// ErrAttachmentTypeNotAllowed は、ワークスペースで許可していないMIMEタイプのファイルをアップロードした場合に返されます。
+	ErrAttachmentTypeNotAllowed = errors.New("attachment type not allowed")
+	// ErrAttachmentNotFound は、対象の添付ファイルが存在しないか、参照できない場合に返されます。
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	// ErrAttachmentUnavailable は、他の人のファイルや添付済みのファイルをメッセージに添付しようとした場合に返されます。
+	ErrAttachmentUnavailable = errors.New("attachment unavailable")
+)
+
+// sniffLength は、MIMEタイプの判定に使う先頭のバイト数です。
+const sniffLength = 512
+
+type AttachmentUseCase interface {
+	UploadAttachment(ctx context.Context, membershipID, workspaceID string, upload AttachmentUpload) (*entity.Attachment, error)
+	// OpenAttachment は、添付ファイルの情報と内容を返します。呼び出し側は内容を読み終えたら閉じてください。
+	OpenAttachment(ctx context.Context, membershipID, workspaceID, attachmentID string) (*entity.Attachment, io.ReadCloser, error)
+}
+
+type attachmentUseCase struct {
+	ar  repository.AttachmentRepository
+	br  repository.BlobRepository
+	wr  repository.WorkspaceRepository
+	ur  repository.MembershipRepository
+	mrr repository.MembershipChannelRepository
+
+	conf *config.AttachmentConfig
+}
+
+func NewAttachmentUseCase(
+	ar repository.AttachmentRepository,
+	br repository.BlobRepository,
+	wr repository.WorkspaceRepository,
+	ur repository.MembershipRepository,
+	mrr repository.MembershipChannelRepository,
+	conf *config.AttachmentConfig,
+) AttachmentUseCase {
+	return &attachmentUseCase{
+		ar:   ar,
+		br:   br,
+		wr:   wr,
+		ur:   ur,
+		mrr:  mrr,
+		conf: conf,
+	}
+}
+
+type AttachmentUpload struct {
+	ChannelID string
+	Name      string
+	Size      int64
+	Body      io.Reader
+}
+
+// UploadAttachment は、ファイルをストレージに保存し、メッセージに添付する前の添付ファイルとして登録します。
+// MIMEタイプはクライアントの申告を使わず内容から判定し、判定できない場合のみファイル名の拡張子を使います。
+func (auc *attachmentUseCase) UploadAttachment(
+	ctx context.Context,
+	membershipID, workspaceID string,
+	upload AttachmentUpload,
+) (*entity.Attachment, error) {
+	if err := auc.authorizeMember(ctx, membershipID, workspaceID); err != nil {
+		return nil, err
+	}
+	if err := authorizeChannelMember(ctx, auc.mrr, membershipID, upload.ChannelID); err != nil {
+		return nil, err
+	}
+
+	workspace, err := auc.wr.Get(ctx, workspaceID)
+	if err != nil {
+		log.Error("Failed to get workspace", log.Fstring("workspaceID", workspaceID), log.Ferror(err))
+		return nil, err
+	}
+	policy := workspace.AttachmentPolicy(entity.AttachmentPolicy{
+		MaxSize:      auc.conf.MaxSize,
+		AllowedTypes: auc.conf.AllowedTypes,
+	})
+	if !policy.AllowsSize(upload.Size) {
+		log.Info("Attachment is too large", log.Fstring("workspaceID", workspaceID), log.Fint64("size", upload.Size))
+		return nil, ErrAttachmentTooLarge
+	}
+
+	body := bufio.NewReaderSize(upload.Body, sniffLength)
+	head, err := body.Peek(sniffLength)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		log.Error("Failed to read attachment", log.Ferror(err))
+		return nil, err
+	}
+	mimeType := detectMimeType(head, upload.Name)
+	if !policy.AllowsType(mimeType) {
+		log.Info("Attachment type is not allowed", log.Fstring("workspaceID", workspaceID), log.Fstring("mimeType", mimeType))
+		return nil, ErrAttachmentTypeNotAllowed
+	}
+
+	id := uuid.New().String()
+	key := entity.AttachmentStorageKey(workspaceID, upload.ChannelID, id)
+	hash := sha256.New()
+	if err = auc.br.Put(ctx, key, io.TeeReader(body, hash), upload.Size, mimeType); err != nil {
+		log.Error("Failed to store attachment", log.Fstring("key", key), log.Ferror(err))
+		return nil, err
+	}
+
+	attachment, err := entity.NewAttachment(
+		id, workspaceID, upload.ChannelID, membershipID, upload.Name, mimeType, upload.Size, hex.EncodeToString(hash.Sum(nil)),
+	)
+	if err == nil {
+		err = auc.ar.Create(ctx, *attachment)
+	}
+	if err != nil {
+		log.Error("Failed to create attachment", log.Fstring("attachmentID", id), log.Ferror(err))
+		// 登録できなかったファイルの内容は参照されないため削除します。
+		if derr := auc.br.Delete(ctx, key); derr != nil {
+			log.Warn("Failed to delete orphaned attachment", log.Fstring("key", key), log.Ferror(derr))
+		}
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// OpenAttachment は、添付ファイルのチャンネルのメンバーにだけ内容を返します。
+// メッセージに添付する前のファイルは、アップロードした本人だけが参照できます。
+func (auc *attachmentUseCase) OpenAttachment(
+	ctx context.Context,
+	membershipID, workspaceID, attachmentID string,
+) (*entity.Attachment, io.ReadCloser, error) {
+	if err := auc.authorizeMember(ctx, membershipID, workspaceID); err != nil {
+		return nil, nil, err
+	}
+
+	attachment, err := auc.ar.Get(ctx, attachmentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrAttachmentNotFound) {
+			return nil, nil, ErrAttachmentNotFound
+		}
+		return nil, nil, err
+	}
+	if attachment.WorkspaceID != workspaceID || (attachment.MessageID == "" && attachment.MembershipID != membershipID) {
+		log.Warn("Attachment is not visible", log.Fstring("membershipID", membershipID), log.Fstring("attachmentID", attachmentID))
+		return nil, nil, ErrAttachmentNotFound
+	}
+	if err = authorizeChannelMember(ctx, auc.mrr, membershipID, attachment.ChannelID); err != nil {
+		return nil, nil, err
+	}
+
+	body, err := auc.br.Get(ctx, attachment.StorageKey())
+	if err != nil {
+		if errors.Is(err, repository.ErrBlobNotFound) {
+			log.Error("Attachment content is missing", log.Fstring("attachmentID", attachmentID))
+			return nil, nil, ErrAttachmentNotFound
+		}
+		log.Error("Failed to open attachment", log.Fstring("attachmentID", attachmentID), log.Ferror(err))
+		return nil, nil, err
+	}
+	return attachment, body, nil
+}
+
+func (auc *attachmentUseCase) authorizeMember(ctx context.Context, membershipID, workspaceID string) error {
+	membership, err := auc.ur.Get(ctx, membershipID)
+	if err != nil || membership.IsDeleted || membership.WorkspaceID != workspaceID {
+		log.Warn("Membership cannot access attachments", log.Fstring("membershipID", membershipID), log.Fstring("workspaceID", workspaceID))
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// detectMimeType は、内容から判定したMIMEタイプを返します。
+// 内容から判定できない場合はファイル名の拡張子から、それでも分からない場合はapplication/octet-streamを返します。
+func detectMimeType(head []byte, name string) string {
+	const unknown = "application/octet-stream"
+	if detected := http.DetectContentType(head); detected != unknown {
+		return detected
+	}
+	if byExt := mime.TypeByExtension(filepath.Ext(name)); byExt != "" {
+		return byExt
+	}
+	return unknown
+}
+
+// claimAttachments は、メッセージに指定された添付ファイルを送信者のアップロードしたファイルに置き換え、メッセージに添付します。
+// 添付ファイルはメッセージと同じチャンネルにアップロードした、まだ添付していないものに限ります。
+func claimAttachments(ctx context.Context, ar repository.AttachmentRepository, message *entity.Message) error {
+	if len(message.Attachments) == 0 {
+		return nil
+	}
+	ids := message.Attachments.IDs()
+	stored, err := ar.ListByIDs(ctx, ids)
+	if err != nil {
+		log.Error("Failed to list attachments", log.Fstring("msgID", message.ID), log.Ferror(err))
+		return err
+	}
+
+	byID := make(map[string]entity.Attachment, len(stored))
+	for _, attachment := range stored {
+		byID[attachment.ID] = attachment
+	}
+	attachments := make(entity.Attachments, 0, len(ids))
+	for _, id := range ids {
+		attachment, ok := byID[id]
+		if !ok || attachment.MembershipID != message.MembershipID || attachment.ChannelID != message.ChannelID || attachment.MessageID != "" {
+			log.Warn("Attachment cannot be attached", log.Fstring("msgID", message.ID), log.Fstring("attachmentID", id))
+			return fmt.Errorf("attachment %s: %w", id, ErrAttachmentUnavailable)
+		}
+		attachment.MessageID = message.ID
+		attachments = append(attachments, attachment)
+		delete(byID, id)
+	}
+
+	// 他のメッセージに同時に添付された場合は件数が一致しないため、添付をやめます。
+	claimed, err := ar.AttachToMessage(ctx, message.ID, message.MembershipID, ids)
+	if err != nil {
+		log.Error("Failed to attach attachments", log.Fstring("msgID", message.ID), log.Ferror(err))
+		return err
+	}
+	if claimed != int64(len(ids)) {
+		log.Warn("Attachments were claimed concurrently", log.Fstring("msgID", message.ID))
+		releaseAttachments(ctx, ar, *message)
+		return ErrAttachmentUnavailable
+	}
+	message.Attachments = attachments
+	return nil
+}
+
+// releaseAttachments は、作成できなかったメッセージの添付ファイルを再び添付できるように戻します。
+func releaseAttachments(ctx context.Context, ar repository.AttachmentRepository, message entity.Message) {
+	if len(message.Attachments) == 0 {
+		return
+	}
+	if err := ar.DetachFromMessage(ctx, message.ID); err != nil {
+		log.Warn("Failed to release attachments", log.Fstring("msgID", message.ID), log.Ferror(err))
+	}
+}
@@ -0,0 +1,292 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository"
+	"github.com/tusmasoma/connectHub-backend/repository/mock"
+)
+
+func TestAttachmentUseCase_UploadAttachment(t *testing.T) {
+	t.Parallel()
+	workspaceID := uuid.New().String()
+	membershipID := uuid.New().String() + "_" + workspaceID
+	channelID := uuid.New().String()
+	membership := entity.Membership{ID: membershipID, WorkspaceID: workspaceID}
+	png := "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 24)
+	sum := sha256.Sum256([]byte(png))
+	checksum := hex.EncodeToString(sum[:])
+
+	patterns := []struct {
+		name  string
+		setup func(
+			ar *mock.MockAttachmentRepository,
+			br *mock.MockBlobRepository,
+			wr *mock.MockWorkspaceRepository,
+			ur *mock.MockMembershipRepository,
+			mrr *mock.MockMembershipChannelRepository,
+		)
+		upload   AttachmentUpload
+		wantType string
+		wantErr  error
+	}{
+		{
+			name: "success: type is detected from the content",
+			setup: func(
+				ar *mock.MockAttachmentRepository,
+				br *mock.MockBlobRepository,
+				wr *mock.MockWorkspaceRepository,
+				ur *mock.MockMembershipRepository,
+				mrr *mock.MockMembershipChannelRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				mrr.EXPECT().Get(gomock.Any(), membershipID, channelID).Return(&entity.MembershipChannel{}, nil)
+				wr.EXPECT().Get(gomock.Any(), workspaceID).Return(&entity.Workspace{ID: workspaceID, AllowedAttachmentTypes: "image/*"}, nil)
+				br.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), int64(len(png)), "image/png").DoAndReturn(
+					func(_ context.Context, key string, body io.Reader, _ int64, _ string) error {
+						if !strings.HasPrefix(key, workspaceID+"/"+channelID+"/") {
+							t.Errorf("Put() key = %v, want under %s/%s", key, workspaceID, channelID)
+						}
+						_, err := io.ReadAll(body)
+						return err
+					},
+				)
+				ar.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, attachment entity.Attachment) error {
+						if attachment.Checksum != checksum || attachment.MembershipID != membershipID || attachment.MessageID != "" {
+							t.Errorf("Create() attachment = %+v, want checksum %s by %s", attachment, checksum, membershipID)
+						}
+						return nil
+					},
+				)
+			},
+			upload:   AttachmentUpload{ChannelID: channelID, Name: "photo.txt", Size: int64(len(png)), Body: strings.NewReader(png)},
+			wantType: "image/png",
+			wantErr:  nil,
+		},
+		{
+			name: "Fail: not a member of the workspace",
+			setup: func(
+				ar *mock.MockAttachmentRepository,
+				br *mock.MockBlobRepository,
+				wr *mock.MockWorkspaceRepository,
+				ur *mock.MockMembershipRepository,
+				mrr *mock.MockMembershipChannelRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&entity.Membership{ID: membershipID, WorkspaceID: uuid.New().String()}, nil)
+			},
+			upload:  AttachmentUpload{ChannelID: channelID, Name: "photo.png", Size: int64(len(png)), Body: strings.NewReader(png)},
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name: "Fail: larger than the workspace limit",
+			setup: func(
+				ar *mock.MockAttachmentRepository,
+				br *mock.MockBlobRepository,
+				wr *mock.MockWorkspaceRepository,
+				ur *mock.MockMembershipRepository,
+				mrr *mock.MockMembershipChannelRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				mrr.EXPECT().Get(gomock.Any(), membershipID, channelID).Return(&entity.MembershipChannel{}, nil)
+				wr.EXPECT().Get(gomock.Any(), workspaceID).Return(&entity.Workspace{ID: workspaceID, MaxAttachmentSize: 8}, nil)
+			},
+			upload:  AttachmentUpload{ChannelID: channelID, Name: "photo.png", Size: int64(len(png)), Body: strings.NewReader(png)},
+			wantErr: ErrAttachmentTooLarge,
+		},
+		{
+			name: "Fail: type not allowed in the workspace",
+			setup: func(
+				ar *mock.MockAttachmentRepository,
+				br *mock.MockBlobRepository,
+				wr *mock.MockWorkspaceRepository,
+				ur *mock.MockMembershipRepository,
+				mrr *mock.MockMembershipChannelRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				mrr.EXPECT().Get(gomock.Any(), membershipID, channelID).Return(&entity.MembershipChannel{}, nil)
+				wr.EXPECT().Get(gomock.Any(), workspaceID).Return(&entity.Workspace{ID: workspaceID, AllowedAttachmentTypes: "image/*"}, nil)
+			},
+			upload:  AttachmentUpload{ChannelID: channelID, Name: "photo.png", Size: 5, Body: strings.NewReader("hello")},
+			wantErr: ErrAttachmentTypeNotAllowed,
+		},
+		{
+			name: "Fail: deletes the content when failed to create attachment",
+			setup: func(
+				ar *mock.MockAttachmentRepository,
+				br *mock.MockBlobRepository,
+				wr *mock.MockWorkspaceRepository,
+				ur *mock.MockMembershipRepository,
+				mrr *mock.MockMembershipChannelRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				mrr.EXPECT().Get(gomock.Any(), membershipID, channelID).Return(&entity.MembershipChannel{}, nil)
+				wr.EXPECT().Get(gomock.Any(), workspaceID).Return(&entity.Workspace{ID: workspaceID}, nil)
+				br.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), int64(5), "text/plain; charset=utf-8").Return(nil)
+				ar.EXPECT().Create(gomock.Any(), gomock.Any()).Return(fmt.Errorf("database error"))
+				br.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			upload:  AttachmentUpload{ChannelID: channelID, Name: "notes.txt", Size: 5, Body: strings.NewReader("hello")},
+			wantErr: fmt.Errorf("database error"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			ar := mock.NewMockAttachmentRepository(ctrl)
+			br := mock.NewMockBlobRepository(ctrl)
+			wr := mock.NewMockWorkspaceRepository(ctrl)
+			ur := mock.NewMockMembershipRepository(ctrl)
+			mrr := mock.NewMockMembershipChannelRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ar, br, wr, ur, mrr)
+			}
+
+			usecase := NewAttachmentUseCase(ar, br, wr, ur, mrr, &config.AttachmentConfig{MaxSize: 1024})
+			got, err := usecase.UploadAttachment(context.Background(), membershipID, workspaceID, tt.upload)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Fatalf("UploadAttachment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error() {
+					t.Errorf("UploadAttachment() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if got.MimeType != tt.wantType {
+				t.Errorf("UploadAttachment() mime type = %v, want %v", got.MimeType, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestAttachmentUseCase_OpenAttachment(t *testing.T) {
+	t.Parallel()
+	workspaceID := uuid.New().String()
+	membershipID := uuid.New().String() + "_" + workspaceID
+	uploaderID := uuid.New().String() + "_" + workspaceID
+	channelID := uuid.New().String()
+	membership := entity.Membership{ID: membershipID, WorkspaceID: workspaceID}
+	attachment := entity.Attachment{
+		ID:           uuid.New().String(),
+		WorkspaceID:  workspaceID,
+		ChannelID:    channelID,
+		MembershipID: uploaderID,
+		MessageID:    uuid.New().String(),
+		Name:         "report.pdf",
+	}
+	unattached := attachment
+	unattached.MessageID = ""
+
+	patterns := []struct {
+		name  string
+		setup func(
+			ar *mock.MockAttachmentRepository,
+			br *mock.MockBlobRepository,
+			ur *mock.MockMembershipRepository,
+			mrr *mock.MockMembershipChannelRepository,
+		)
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(
+				ar *mock.MockAttachmentRepository,
+				br *mock.MockBlobRepository,
+				ur *mock.MockMembershipRepository,
+				mrr *mock.MockMembershipChannelRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				ar.EXPECT().Get(gomock.Any(), attachment.ID).Return(&attachment, nil)
+				mrr.EXPECT().Get(gomock.Any(), membershipID, channelID).Return(&entity.MembershipChannel{}, nil)
+				br.EXPECT().Get(gomock.Any(), attachment.StorageKey()).Return(io.NopCloser(strings.NewReader("pdf")), nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: not a member of the channel",
+			setup: func(
+				ar *mock.MockAttachmentRepository,
+				br *mock.MockBlobRepository,
+				ur *mock.MockMembershipRepository,
+				mrr *mock.MockMembershipChannelRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				ar.EXPECT().Get(gomock.Any(), attachment.ID).Return(&attachment, nil)
+				mrr.EXPECT().Get(gomock.Any(), membershipID, channelID).Return(nil, repository.ErrMembershipChannelNotFound)
+			},
+			wantErr: ErrChannelAccessDenied,
+		},
+		{
+			name: "Fail: another membership's upload not yet attached",
+			setup: func(
+				ar *mock.MockAttachmentRepository,
+				br *mock.MockBlobRepository,
+				ur *mock.MockMembershipRepository,
+				mrr *mock.MockMembershipChannelRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				ar.EXPECT().Get(gomock.Any(), attachment.ID).Return(&unattached, nil)
+			},
+			wantErr: ErrAttachmentNotFound,
+		},
+		{
+			name: "Fail: attachment not found",
+			setup: func(
+				ar *mock.MockAttachmentRepository,
+				br *mock.MockBlobRepository,
+				ur *mock.MockMembershipRepository,
+				mrr *mock.MockMembershipChannelRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				ar.EXPECT().Get(gomock.Any(), attachment.ID).Return(nil, repository.ErrAttachmentNotFound)
+			},
+			wantErr: ErrAttachmentNotFound,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			ar := mock.NewMockAttachmentRepository(ctrl)
+			br := mock.NewMockBlobRepository(ctrl)
+			ur := mock.NewMockMembershipRepository(ctrl)
+			mrr := mock.NewMockMembershipChannelRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ar, br, ur, mrr)
+			}
+
+			usecase := NewAttachmentUseCase(ar, br, nil, ur, mrr, &config.AttachmentConfig{MaxSize: 1024})
+			got, body, err := usecase.OpenAttachment(context.Background(), membershipID, workspaceID, attachment.ID)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("OpenAttachment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer body.Close()
+			if got.ID != attachment.ID {
+				t.Errorf("OpenAttachment() = %+v, want %+v", got, attachment)
+			}
+		})
+	}
+}
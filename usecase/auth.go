@@ -13,6 +13,8 @@ import (
 
 type AuthUseCase interface {
 	GetUserFromContext(ctx context.Context) (*entity.User, error)
+	// GetSessionIDFromContext は、リクエストの認証に使ったセッションのIDを返します。
+	GetSessionIDFromContext(ctx context.Context) (string, error)
 }
 
 type authUseCase struct {
@@ -39,3 +41,12 @@ func (auc *authUseCase) GetUserFromContext(ctx context.Context) (*entity.User, e
 	}
 	return user, nil
 }
+
+func (auc *authUseCase) GetSessionIDFromContext(ctx context.Context) (string, error) {
+	sessionID, ok := ctx.Value(config.ContextSessionIDKey).(string)
+	if !ok || sessionID == "" {
+		log.Warn("Failed to retrieve sessionID from context")
+		return "", fmt.Errorf("session id not found in request context")
+	}
+	return sessionID, nil
+}
@@ -102,3 +102,19 @@ func TestAuthUseCase_FetchUserFromContext(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthUseCase_GetSessionIDFromContext(t *testing.T) {
+	t.Parallel()
+
+	auc := NewAuthUseCase(nil)
+
+	ctx := context.WithValue(context.Background(), config.ContextSessionIDKey, "2b1c8d6e-3f4a-4b5c-8d9e-0f1a2b3c4d5e")
+	got, err := auc.GetSessionIDFromContext(ctx)
+	if err != nil || got != "2b1c8d6e-3f4a-4b5c-8d9e-0f1a2b3c4d5e" {
+		t.Errorf("GetSessionIDFromContext() = %v, %v", got, err)
+	}
+
+	if _, err = auc.GetSessionIDFromContext(context.Background()); err == nil {
+		t.Error("GetSessionIDFromContext() expected an error without a session")
+	}
+}
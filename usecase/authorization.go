@@ -0,0 +1,171 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+var ErrPermissionDenied = errors.New("permission denied")
+
+// AuthorizationUseCase は、WebSocketのアクションを実行してよいか判定します。
+// クライアントが送ってくるIDは信用せず、保存済みのチャンネルやメッセージを読み込んで確認します。
+type AuthorizationUseCase interface {
+	AuthorizeAction(ctx context.Context, membershipID string, message entity.WSMessage) error
+	AuthorizeWorkspace(ctx context.Context, membershipID string) error
+}
+
+type authorizationUseCase struct {
+	ur  repository.MembershipRepository
+	cr  repository.ChannelRepository
+	mrr repository.MembershipChannelRepository
+	mr  repository.MessageRepository
+	mcr repository.MessageCacheRepository
+}
+
+func NewAuthorizationUseCase(
+	ur repository.MembershipRepository,
+	cr repository.ChannelRepository,
+	mrr repository.MembershipChannelRepository,
+	mr repository.MessageRepository,
+	mcr repository.MessageCacheRepository,
+) AuthorizationUseCase {
+	return &authorizationUseCase{
+		ur:  ur,
+		cr:  cr,
+		mrr: mrr,
+		mr:  mr,
+		mcr: mcr,
+	}
+}
+
+// AuthorizeAction は、membershipIDがアクションの対象にアクセスできるか確認します。
+// チャンネルを対象とするアクションはTargetIDのチャンネルの、メッセージを対象とするアクションはメッセージが属するチャンネルの
+// メンバーであることを必要とします。メッセージの編集と削除は、加えて作成者か管理者であることを必要とします。
+func (azuc *authorizationUseCase) AuthorizeAction(ctx context.Context, membershipID string, message entity.WSMessage) error {
+	membership, err := azuc.activeMembership(ctx, membershipID)
+	if err != nil {
+		return err
+	}
+
+	switch message.Action {
+	// RESUMEは、クライアントが既に参加しているチャンネルのみを再開するため、メンバーであることの確認で十分です。
+	// SET_PRESENCEは、自分のプレゼンスのみを変更します。
+	case entity.CreatePublicChannelAction, entity.CreatePrivateChannelAction, entity.OpenDirectMessageAction, entity.ResumeAction,
+		entity.SetPresenceAction:
+		return nil
+
+	case entity.ListMessagesAction, entity.CreateMessageAction, entity.InviteToChannelAction, entity.LeavePublicChannelAction,
+		entity.TypingAction, entity.MarkReadAction:
+		return authorizeChannelMember(ctx, azuc.mrr, membershipID, message.TargetID)
+
+	case entity.JoinPublicChannelAction:
+		return azuc.authorizeJoin(ctx, *membership, message.TargetID)
+
+	case entity.UpdateMessageAction, entity.DeleteMessageAction:
+		var stored *entity.Message
+		stored, err = azuc.authorizeMessage(ctx, membershipID, message.Content.ID)
+		if err != nil {
+			return err
+		}
+		return authorizeMessageOwner(*membership, *stored)
+
+	case entity.CreateThreadReplyAction, entity.ListThreadAction, entity.FollowThreadAction, entity.UnfollowThreadAction:
+		_, err = azuc.authorizeMessage(ctx, membershipID, message.TargetID)
+		return err
+
+	case entity.AddReactionAction, entity.RemoveReactionAction:
+		_, err = azuc.authorizeMessage(ctx, membershipID, message.Content.ID)
+		return err
+
+	default:
+		log.Warn("Unknown message action", log.Fstring("action", message.Action))
+		return ErrPermissionDenied
+	}
+}
+
+// AuthorizeWorkspace は、membershipIDが削除されていないメンバーシップであることを確認します。
+// WebSocketの接続時に、ワークスペースのメンバー以外がHubに参加してプレゼンスやチャンネルの作成を受け取らないよう使います。
+func (azuc *authorizationUseCase) AuthorizeWorkspace(ctx context.Context, membershipID string) error {
+	_, err := azuc.activeMembership(ctx, membershipID)
+	return err
+}
+
+// activeMembership は、削除されていないメンバーシップを返します。見つからない場合はErrPermissionDeniedを返します。
+func (azuc *authorizationUseCase) activeMembership(ctx context.Context, membershipID string) (*entity.Membership, error) {
+	membership, err := azuc.ur.Get(ctx, membershipID)
+	if err != nil {
+		log.Warn("Failed to get membership", log.Fstring("membershipID", membershipID), log.Ferror(err))
+		return nil, ErrPermissionDenied
+	}
+	if membership.IsDeleted {
+		log.Warn("Membership is deleted", log.Fstring("membershipID", membershipID))
+		return nil, ErrPermissionDenied
+	}
+	return membership, nil
+}
+
+// authorizeJoin は、参加しようとしているチャンネルが同じワークスペースの公開チャンネルであることを確認します。
+func (azuc *authorizationUseCase) authorizeJoin(ctx context.Context, membership entity.Membership, channelID string) error {
+	channels, err := azuc.cr.List(ctx, []repository.QueryCondition{{Field: "id", Value: channelID}})
+	if err != nil {
+		log.Error("Failed to get channel", log.Fstring("channelID", channelID))
+		return err
+	}
+	if len(channels) == 0 {
+		log.Info("Channel not found", log.Fstring("channelID", channelID))
+		return ErrChannelNotFound
+	}
+	if channels[0].WorkspaceID != membership.WorkspaceID || channels[0].Private {
+		log.Warn("Cannot join channel without invitation", log.Fstring("membershipID", membership.ID), log.Fstring("channelID", channelID))
+		return ErrChannelAccessDenied
+	}
+	return nil
+}
+
+// authorizeMessage は、保存済みのメッセージを読み込み、membershipIDがそのチャンネルのメンバーであることを確認します。
+func (azuc *authorizationUseCase) authorizeMessage(ctx context.Context, membershipID, messageID string) (*entity.Message, error) {
+	stored, err := cacheMessage(ctx, azuc.mr, azuc.mcr, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if err = authorizeChannelMember(ctx, azuc.mrr, membershipID, stored.ChannelID); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// authorizeChannelMember は、membershipIDがチャンネルに参加していることを確認します。
+func authorizeChannelMember(
+	ctx context.Context,
+	mrr repository.MembershipChannelRepository,
+	membershipID, channelID string,
+) error {
+	if _, err := mrr.Get(ctx, membershipID, channelID); err != nil {
+		if errors.Is(err, repository.ErrMembershipChannelNotFound) {
+			log.Warn("Membership is not a member of the channel", log.Fstring("membershipID", membershipID), log.Fstring("channelID", channelID))
+			return ErrChannelAccessDenied
+		}
+		log.Error("Failed to get membership channel", log.Fstring("membershipID", membershipID), log.Fstring("channelID", channelID))
+		return err
+	}
+	return nil
+}
+
+// authorizeMessageOwner は、保存済みのメッセージの作成者か管理者であることを確認します。
+func authorizeMessageOwner(membership entity.Membership, stored entity.Message) error {
+	if !membership.IsAdmin && membership.ID != stored.MembershipID {
+		log.Warn(
+			"Membership don't have permission to modify msg",
+			log.Fstring("membershipID", membership.ID),
+			log.Fstring("msgID", stored.ID),
+		)
+		return fmt.Errorf("don't have permission to modify msg: %w", ErrPermissionDenied)
+	}
+	return nil
+}
@@ -0,0 +1,421 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository"
+	"github.com/tusmasoma/connectHub-backend/repository/mock"
+)
+
+func TestAuthorizationUseCase_AuthorizeAction(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	membershipID := uuid.New().String() + "_" + workspaceID
+	otherMembershipID := uuid.New().String() + "_" + workspaceID
+	channelID := uuid.New().String()
+	otherChannelID := uuid.New().String()
+	membership := entity.Membership{ID: membershipID, WorkspaceID: workspaceID, Name: "test"}
+	own := entity.Message{ID: uuid.New().String(), MembershipID: membershipID, ChannelID: channelID, Text: "own"}
+	others := entity.Message{ID: uuid.New().String(), MembershipID: otherMembershipID, ChannelID: channelID, Text: "others"}
+	// 自分が参加していないチャンネルのメッセージです。
+	private := entity.Message{ID: uuid.New().String(), MembershipID: otherMembershipID, ChannelID: otherChannelID, Text: "private"}
+
+	member := func(mrr *mock.MockMembershipChannelRepository, channelID string) {
+		mrr.EXPECT().Get(gomock.Any(), membershipID, channelID).Return(
+			&entity.MembershipChannel{MembershipID: membershipID, ChannelID: channelID},
+			nil,
+		)
+	}
+	notMember := func(mrr *mock.MockMembershipChannelRepository, channelID string) {
+		mrr.EXPECT().Get(gomock.Any(), membershipID, channelID).Return(nil, repository.ErrMembershipChannelNotFound)
+	}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			ur *mock.MockMembershipRepository,
+			cr *mock.MockChannelRepository,
+			mrr *mock.MockMembershipChannelRepository,
+			mcr *mock.MockMessageCacheRepository,
+		)
+		message entity.WSMessage
+		wantErr error
+	}{
+		{
+			name: "success: create message in joined channel",
+			setup: func(
+				ur *mock.MockMembershipRepository,
+				cr *mock.MockChannelRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				member(mrr, channelID)
+			},
+			message: entity.WSMessage{Action: entity.CreateMessageAction, TargetID: channelID},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: create message in channel not joined",
+			setup: func(
+				ur *mock.MockMembershipRepository,
+				cr *mock.MockChannelRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				notMember(mrr, otherChannelID)
+			},
+			message: entity.WSMessage{Action: entity.CreateMessageAction, TargetID: otherChannelID},
+			wantErr: ErrChannelAccessDenied,
+		},
+		{
+			name: "Fail: list messages in channel not joined",
+			setup: func(
+				ur *mock.MockMembershipRepository,
+				cr *mock.MockChannelRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				notMember(mrr, otherChannelID)
+			},
+			message: entity.WSMessage{Action: entity.ListMessagesAction, TargetID: otherChannelID},
+			wantErr: ErrChannelAccessDenied,
+		},
+		{
+			name: "Fail: typing in channel not joined",
+			setup: func(
+				ur *mock.MockMembershipRepository,
+				cr *mock.MockChannelRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				notMember(mrr, otherChannelID)
+			},
+			message: entity.WSMessage{Action: entity.TypingAction, TargetID: otherChannelID},
+			wantErr: ErrChannelAccessDenied,
+		},
+		{
+			name: "Fail: mark read in channel not joined",
+			setup: func(
+				ur *mock.MockMembershipRepository,
+				cr *mock.MockChannelRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				notMember(mrr, otherChannelID)
+			},
+			message: entity.WSMessage{Action: entity.MarkReadAction, TargetID: otherChannelID, Content: entity.Message{ID: uuid.New().String()}},
+			wantErr: ErrChannelAccessDenied,
+		},
+		{
+			name: "success: delete own message",
+			setup: func(
+				ur *mock.MockMembershipRepository,
+				cr *mock.MockChannelRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				mcr.EXPECT().Get(gomock.Any(), own.ID).Return(&own, nil)
+				member(mrr, channelID)
+			},
+			message: entity.WSMessage{Action: entity.DeleteMessageAction, TargetID: channelID, Content: entity.Message{ID: own.ID}},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: delete others' message with forged membershipID",
+			setup: func(
+				ur *mock.MockMembershipRepository,
+				cr *mock.MockChannelRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				mcr.EXPECT().Get(gomock.Any(), others.ID).Return(&others, nil)
+				member(mrr, channelID)
+			},
+			message: entity.WSMessage{
+				Action:   entity.DeleteMessageAction,
+				TargetID: channelID,
+				Content:  entity.Message{ID: others.ID, MembershipID: membershipID, ChannelID: channelID},
+			},
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name: "Fail: update message in channel not joined with forged targetID",
+			setup: func(
+				ur *mock.MockMembershipRepository,
+				cr *mock.MockChannelRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				mcr.EXPECT().Get(gomock.Any(), private.ID).Return(&private, nil)
+				notMember(mrr, otherChannelID)
+			},
+			message: entity.WSMessage{
+				Action:   entity.UpdateMessageAction,
+				TargetID: channelID,
+				Content:  entity.Message{ID: private.ID, MembershipID: membershipID, ChannelID: channelID},
+			},
+			wantErr: ErrChannelAccessDenied,
+		},
+		{
+			name: "Fail: reply to thread in channel not joined",
+			setup: func(
+				ur *mock.MockMembershipRepository,
+				cr *mock.MockChannelRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				mcr.EXPECT().Get(gomock.Any(), private.ID).Return(&private, nil)
+				notMember(mrr, otherChannelID)
+			},
+			message: entity.WSMessage{Action: entity.CreateThreadReplyAction, TargetID: private.ID},
+			wantErr: ErrChannelAccessDenied,
+		},
+		{
+			name: "success: react to message in joined channel",
+			setup: func(
+				ur *mock.MockMembershipRepository,
+				cr *mock.MockChannelRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				mcr.EXPECT().Get(gomock.Any(), others.ID).Return(&others, nil)
+				member(mrr, channelID)
+			},
+			message: entity.WSMessage{Action: entity.AddReactionAction, Content: entity.Message{ID: others.ID, Text: "👍"}},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: react to message in channel not joined",
+			setup: func(
+				ur *mock.MockMembershipRepository,
+				cr *mock.MockChannelRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				mcr.EXPECT().Get(gomock.Any(), private.ID).Return(&private, nil)
+				notMember(mrr, otherChannelID)
+			},
+			message: entity.WSMessage{Action: entity.RemoveReactionAction, Content: entity.Message{ID: private.ID, Text: "👍"}},
+			wantErr: ErrChannelAccessDenied,
+		},
+		{
+			name: "success: join public channel",
+			setup: func(
+				ur *mock.MockMembershipRepository,
+				cr *mock.MockChannelRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				cr.EXPECT().List(gomock.Any(), []repository.QueryCondition{{Field: "id", Value: channelID}}).Return(
+					[]entity.Channel{{ID: channelID, WorkspaceID: workspaceID, Name: "public"}},
+					nil,
+				)
+			},
+			message: entity.WSMessage{Action: entity.JoinPublicChannelAction, TargetID: channelID},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: join private channel",
+			setup: func(
+				ur *mock.MockMembershipRepository,
+				cr *mock.MockChannelRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				cr.EXPECT().List(gomock.Any(), gomock.Any()).Return(
+					[]entity.Channel{{ID: otherChannelID, WorkspaceID: workspaceID, Name: "private", Private: true}},
+					nil,
+				)
+			},
+			message: entity.WSMessage{Action: entity.JoinPublicChannelAction, TargetID: otherChannelID},
+			wantErr: ErrChannelAccessDenied,
+		},
+		{
+			name: "Fail: join public channel in another workspace",
+			setup: func(
+				ur *mock.MockMembershipRepository,
+				cr *mock.MockChannelRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				cr.EXPECT().List(gomock.Any(), gomock.Any()).Return(
+					[]entity.Channel{{ID: otherChannelID, WorkspaceID: uuid.New().String(), Name: "public"}},
+					nil,
+				)
+			},
+			message: entity.WSMessage{Action: entity.JoinPublicChannelAction, TargetID: otherChannelID},
+			wantErr: ErrChannelAccessDenied,
+		},
+		{
+			name: "Fail: deleted membership",
+			setup: func(
+				ur *mock.MockMembershipRepository,
+				cr *mock.MockChannelRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				deleted := membership
+				deleted.IsDeleted = true
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&deleted, nil)
+			},
+			message: entity.WSMessage{Action: entity.CreatePublicChannelAction},
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name: "Fail: not a member of the workspace",
+			setup: func(
+				ur *mock.MockMembershipRepository,
+				cr *mock.MockChannelRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(nil, fmt.Errorf("sql: no rows in result set"))
+			},
+			message: entity.WSMessage{Action: entity.OpenDirectMessageAction},
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name: "success: resume channels",
+			setup: func(
+				ur *mock.MockMembershipRepository,
+				cr *mock.MockChannelRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+			},
+			message: entity.WSMessage{Action: entity.ResumeAction, Sequences: map[string]int64{channelID: 10}},
+			wantErr: nil,
+		},
+		{
+			name: "Success: member sets own presence",
+			setup: func(
+				ur *mock.MockMembershipRepository,
+				cr *mock.MockChannelRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+			},
+			message: entity.WSMessage{Action: entity.SetPresenceAction, Content: entity.Message{Text: entity.PresenceAway}},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: unknown action",
+			setup: func(
+				ur *mock.MockMembershipRepository,
+				cr *mock.MockChannelRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+			},
+			message: entity.WSMessage{Action: "DROP_TABLES", TargetID: channelID},
+			wantErr: ErrPermissionDenied,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockMembershipRepository(ctrl)
+			cr := mock.NewMockChannelRepository(ctrl)
+			mrr := mock.NewMockMembershipChannelRepository(ctrl)
+			mr := mock.NewMockMessageRepository(ctrl)
+			mcr := mock.NewMockMessageCacheRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ur, cr, mrr, mcr)
+			}
+
+			usecase := NewAuthorizationUseCase(ur, cr, mrr, mr, mcr)
+			err := usecase.AuthorizeAction(context.Background(), membershipID, tt.message)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AuthorizeAction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthorizationUseCase_AuthorizeWorkspace(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	membershipID := uuid.New().String() + "_" + workspaceID
+
+	patterns := []struct {
+		name    string
+		setup   func(ur *mock.MockMembershipRepository)
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(ur *mock.MockMembershipRepository) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&entity.Membership{ID: membershipID, WorkspaceID: workspaceID}, nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: not a member of the workspace",
+			setup: func(ur *mock.MockMembershipRepository) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(nil, sql.ErrNoRows)
+			},
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name: "Fail: membership is deleted",
+			setup: func(ur *mock.MockMembershipRepository) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&entity.Membership{ID: membershipID, WorkspaceID: workspaceID, IsDeleted: true}, nil)
+			},
+			wantErr: ErrPermissionDenied,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockMembershipRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ur)
+			}
+
+			usecase := NewAuthorizationUseCase(ur, nil, nil, nil, nil)
+			err := usecase.AuthorizeWorkspace(context.Background(), membershipID)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AuthorizeWorkspace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
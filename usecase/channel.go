@@ -3,26 +3,45 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/tusmasoma/connectHub-backend/entity"
 	"github.com/tusmasoma/connectHub-backend/internal/log"
 	"github.com/tusmasoma/connectHub-backend/repository"
 )
 
+var (
+	ErrChannelNotFound     = errors.New("channel not found")
+	ErrChannelAccessDenied = errors.New("channel access denied")
+)
+
 type ChannelUseCase interface {
 	CreateChannel(ctx context.Context, params CreateChannelParams) error
 	ListMembershipChannels(ctx context.Context, membershipID string) ([]entity.Channel, error)
+	ListWorkspaceChannels(ctx context.Context, workspaceID string) ([]entity.Channel, error)
+	GetChannel(ctx context.Context, channelID string) (*entity.Channel, error)
+	AuthorizeChannel(ctx context.Context, userID, channelID string) (*entity.Channel, error)
+	InviteToChannel(ctx context.Context, inviterID, channelID string, inviteeIDs []string) (*ChannelInvitation, error)
+	OpenDirectMessage(ctx context.Context, membershipID, workspaceID string, participantIDs []string) (*entity.Channel, bool, error)
 }
 
 type channelUseCase struct {
 	cr  repository.ChannelRepository
+	mr  repository.MembershipRepository
 	mrr repository.MembershipChannelRepository
 	tr  repository.TransactionRepository
 }
 
-func NewChannelUseCase(cr repository.ChannelRepository, mrr repository.MembershipChannelRepository, tr repository.TransactionRepository) ChannelUseCase {
+func NewChannelUseCase(
+	cr repository.ChannelRepository,
+	mr repository.MembershipRepository,
+	mrr repository.MembershipChannelRepository,
+	tr repository.TransactionRepository,
+) ChannelUseCase {
 	return &channelUseCase{
 		cr:  cr,
+		mr:  mr,
 		mrr: mrr,
 		tr:  tr,
 	}
@@ -81,3 +100,205 @@ func (ruc *channelUseCase) ListMembershipChannels(ctx context.Context, membershi
 	}
 	return channels, nil
 }
+
+func (ruc *channelUseCase) ListWorkspaceChannels(ctx context.Context, workspaceID string) ([]entity.Channel, error) {
+	channels, err := ruc.cr.List(ctx, []repository.QueryCondition{{Field: "workspace_id", Value: workspaceID}})
+	if err != nil {
+		log.Error("Failed to list workspace channels", log.Fstring("workspaceID", workspaceID))
+		return nil, err
+	}
+	return channels, nil
+}
+
+// AuthorizeChannel は、ユーザーがチャンネルを閲覧・投稿できるか確認し、チャンネルを返します。
+// 公開チャンネルはワークスペースのメンバーであれば、プライベートチャンネルとDMはチャンネルのメンバーであれば許可します。
+func (ruc *channelUseCase) AuthorizeChannel(ctx context.Context, userID, channelID string) (*entity.Channel, error) {
+	channel, err := ruc.GetChannel(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	membershipID := userID + "_" + channel.WorkspaceID
+	memberships, err := ruc.mr.List(ctx, []repository.QueryCondition{{Field: "id", Value: membershipID}})
+	if err != nil {
+		log.Error("Failed to get membership", log.Fstring("membershipID", membershipID))
+		return nil, err
+	}
+	if len(memberships) == 0 || memberships[0].IsDeleted {
+		log.Warn("User is not a member of the workspace", log.Fstring("membershipID", membershipID))
+		return nil, ErrChannelAccessDenied
+	}
+
+	if !channel.Private {
+		return channel, nil
+	}
+	if err = authorizeChannelMember(ctx, ruc.mrr, membershipID, channelID); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+// ChannelInvitation は、InviteToChannelの結果です。
+type ChannelInvitation struct {
+	Channel entity.Channel
+	Invited []string // 新たに参加したメンバーシップのID
+	Members []string // 招待後のチャンネルの全メンバーシップのID
+}
+
+// InviteToChannel は、チャンネルのメンバーが同じワークスペースのメンバーをチャンネルに招待します。
+// 既に参加しているメンバーは無視します。DMの参加者は変更できません。
+func (ruc *channelUseCase) InviteToChannel(ctx context.Context, inviterID, channelID string, inviteeIDs []string) (*ChannelInvitation, error) { //nolint:lll // Ignore long line length
+	if len(inviteeIDs) == 0 {
+		log.Warn("InviteeIDs are required", log.Fstring("channelID", channelID))
+		return nil, fmt.Errorf("inviteeIDs are required")
+	}
+
+	channel, err := ruc.GetChannel(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel.Direct {
+		log.Warn("Cannot invite to direct channel", log.Fstring("channelID", channelID))
+		return nil, fmt.Errorf("cannot invite to direct channel")
+	}
+
+	members, err := ruc.mrr.List(ctx, []repository.QueryCondition{{Field: "channel_id", Value: channelID}})
+	if err != nil {
+		log.Error("Failed to list membership channels", log.Fstring("channelID", channelID))
+		return nil, err
+	}
+	joined := make(map[string]bool, len(members))
+	for _, member := range members {
+		joined[member.MembershipID] = true
+	}
+	if !joined[inviterID] {
+		log.Warn("Inviter is not a member of the channel", log.Fstring("membershipID", inviterID), log.Fstring("channelID", channelID))
+		return nil, ErrChannelAccessDenied
+	}
+
+	if err = ruc.validateMemberships(ctx, channel.WorkspaceID, inviteeIDs); err != nil {
+		return nil, err
+	}
+
+	invitation := &ChannelInvitation{Channel: *channel}
+	var membershipChannels []entity.MembershipChannel
+	for _, inviteeID := range inviteeIDs {
+		if joined[inviteeID] {
+			continue
+		}
+		joined[inviteeID] = true
+		var membershipChannel *entity.MembershipChannel
+		membershipChannel, err = entity.NewMembershipChannel(inviteeID, channelID)
+		if err != nil {
+			log.Error("Failed to create membership channel", log.Ferror(err))
+			return nil, err
+		}
+		membershipChannels = append(membershipChannels, *membershipChannel)
+		invitation.Invited = append(invitation.Invited, inviteeID)
+	}
+	if len(membershipChannels) > 0 {
+		if err = ruc.mrr.BatchCreate(ctx, membershipChannels); err != nil {
+			log.Error("Failed to batch create membership channels", log.Fstring("channelID", channelID))
+			return nil, err
+		}
+	}
+
+	for _, member := range members {
+		invitation.Members = append(invitation.Members, member.MembershipID)
+	}
+	invitation.Members = append(invitation.Members, invitation.Invited...)
+	return invitation, nil
+}
+
+// OpenDirectMessage は、membershipIDと参加者のDMチャンネルを返します。まだ存在しない場合は作成し、createdにtrueを返します。
+// 参加者の組み合わせが同じであれば、誰が開いても同じチャンネルになります。
+func (ruc *channelUseCase) OpenDirectMessage(ctx context.Context, membershipID, workspaceID string, participantIDs []string) (*entity.Channel, bool, error) { //nolint:lll // Ignore long line length
+	channel, err := entity.NewDirectChannel(workspaceID, append([]string{membershipID}, participantIDs...))
+	if err != nil {
+		log.Error("Failed to create direct channel", log.Ferror(err))
+		return nil, false, err
+	}
+
+	existing, err := ruc.cr.List(ctx, []repository.QueryCondition{{Field: "id", Value: channel.ID}})
+	if err != nil {
+		log.Error("Failed to get channel", log.Fstring("channelID", channel.ID))
+		return nil, false, err
+	}
+	if len(existing) > 0 {
+		return &existing[0], false, nil
+	}
+
+	var members []string
+	seen := make(map[string]bool)
+	for _, id := range append([]string{membershipID}, participantIDs...) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			members = append(members, id)
+		}
+	}
+	if err = ruc.validateMemberships(ctx, workspaceID, members); err != nil {
+		return nil, false, err
+	}
+
+	err = ruc.tr.Transaction(ctx, func(ctx context.Context) error {
+		if err = ruc.cr.Create(ctx, *channel); err != nil {
+			log.Error("Failed to create channel", log.Fstring("channelID", channel.ID))
+			return err
+		}
+		membershipChannels := make([]entity.MembershipChannel, 0, len(members))
+		for _, member := range members {
+			var membershipChannel *entity.MembershipChannel
+			membershipChannel, err = entity.NewMembershipChannel(member, channel.ID)
+			if err != nil {
+				log.Error("Failed to create membership channel", log.Ferror(err))
+				return err
+			}
+			membershipChannels = append(membershipChannels, *membershipChannel)
+		}
+		return ruc.mrr.BatchCreate(ctx, membershipChannels)
+	})
+	if err != nil {
+		// 同じ参加者のDMが同時に開かれた場合、先に作成されたチャンネルを使います。
+		retried, retryErr := ruc.cr.List(ctx, []repository.QueryCondition{{Field: "id", Value: channel.ID}})
+		if retryErr == nil && len(retried) > 0 {
+			return &retried[0], false, nil
+		}
+		log.Error("Failed to open direct message", log.Fstring("channelID", channel.ID), log.Ferror(err))
+		return nil, false, err
+	}
+	return channel, true, nil
+}
+
+// GetChannel は、IDのチャンネルを返します。存在しない場合はErrChannelNotFoundを返します。
+func (ruc *channelUseCase) GetChannel(ctx context.Context, channelID string) (*entity.Channel, error) {
+	channels, err := ruc.cr.List(ctx, []repository.QueryCondition{{Field: "id", Value: channelID}})
+	if err != nil {
+		log.Error("Failed to get channel", log.Fstring("channelID", channelID))
+		return nil, err
+	}
+	if len(channels) == 0 {
+		log.Info("Channel not found", log.Fstring("channelID", channelID))
+		return nil, ErrChannelNotFound
+	}
+	return &channels[0], nil
+}
+
+// validateMemberships は、全てのメンバーシップがワークスペースに所属し、削除されていないことを確認します。
+func (ruc *channelUseCase) validateMemberships(ctx context.Context, workspaceID string, membershipIDs []string) error {
+	memberships, err := ruc.mr.List(ctx, []repository.QueryCondition{{Field: "workspace_id", Value: workspaceID}})
+	if err != nil {
+		log.Error("Failed to list memberships", log.Fstring("workspaceID", workspaceID))
+		return err
+	}
+	active := make(map[string]bool, len(memberships))
+	for _, membership := range memberships {
+		active[membership.ID] = !membership.IsDeleted
+	}
+	for _, membershipID := range membershipIDs {
+		if !active[membershipID] {
+			log.Warn("Membership not found in workspace", log.Fstring("membershipID", membershipID), log.Fstring("workspaceID", workspaceID))
+			return fmt.Errorf("membership not found: %s", membershipID)
+		}
+	}
+	return nil
+}
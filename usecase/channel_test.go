@@ -2,12 +2,16 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 
 	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository"
 	"github.com/tusmasoma/connectHub-backend/repository/mock"
 )
 
@@ -78,6 +82,7 @@ func TestChannelUseCase_CreateChannel(t *testing.T) {
 
 			ctrl := gomock.NewController(t)
 			rr := mock.NewMockChannelRepository(ctrl)
+			mr := mock.NewMockMembershipRepository(ctrl)
 			urr := mock.NewMockMembershipChannelRepository(ctrl)
 			tr := mock.NewMockTransactionRepository(ctrl)
 
@@ -85,7 +90,7 @@ func TestChannelUseCase_CreateChannel(t *testing.T) {
 				tt.setup(rr, urr, tr)
 			}
 
-			usecase := NewChannelUseCase(rr, urr, tr)
+			usecase := NewChannelUseCase(rr, mr, urr, tr)
 			err := usecase.CreateChannel(tt.arg.ctx, tt.arg.params)
 
 			if (err != nil) != (tt.wantErr != nil) {
@@ -155,6 +160,7 @@ func TestChannelUseCase_ListMembershipChannels(t *testing.T) {
 			t.Parallel()
 			ctrl := gomock.NewController(t)
 			rr := mock.NewMockChannelRepository(ctrl)
+			mr := mock.NewMockMembershipRepository(ctrl)
 			urr := mock.NewMockMembershipChannelRepository(ctrl)
 			tr := mock.NewMockTransactionRepository(ctrl)
 
@@ -162,7 +168,7 @@ func TestChannelUseCase_ListMembershipChannels(t *testing.T) {
 				tt.setup(rr)
 			}
 
-			usecase := NewChannelUseCase(rr, urr, tr)
+			usecase := NewChannelUseCase(rr, mr, urr, tr)
 			getChannels, err := usecase.ListMembershipChannels(tt.arg.ctx, tt.arg.membershipID)
 
 			if (err != nil) != (tt.wantErr != nil) {
@@ -177,3 +183,498 @@ func TestChannelUseCase_ListMembershipChannels(t *testing.T) {
 		})
 	}
 }
+
+func TestChannelUseCase_ListWorkspaceChannels(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockChannelRepository,
+		)
+		arg struct {
+			ctx         context.Context
+			workspaceID string
+		}
+		want    []entity.Channel
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(rr *mock.MockChannelRepository) {
+				rr.EXPECT().List(
+					gomock.Any(),
+					[]repository.QueryCondition{{Field: "workspace_id", Value: workspaceID}},
+				).Return(
+					[]entity.Channel{
+						{
+							ID:          "channelID",
+							WorkspaceID: workspaceID,
+							Name:        "test",
+							Description: "test",
+							Private:     false,
+						},
+					},
+					nil,
+				)
+			},
+			arg: struct {
+				ctx         context.Context
+				workspaceID string
+			}{
+				ctx:         context.Background(),
+				workspaceID: workspaceID,
+			},
+			want: []entity.Channel{
+				{
+					ID:          "channelID",
+					WorkspaceID: workspaceID,
+					Name:        "test",
+					Description: "test",
+					Private:     false,
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: failed to list channels",
+			setup: func(rr *mock.MockChannelRepository) {
+				rr.EXPECT().List(
+					gomock.Any(),
+					[]repository.QueryCondition{{Field: "workspace_id", Value: workspaceID}},
+				).Return(nil, fmt.Errorf("connection refused"))
+			},
+			arg: struct {
+				ctx         context.Context
+				workspaceID string
+			}{
+				ctx:         context.Background(),
+				workspaceID: workspaceID,
+			},
+			want:    nil,
+			wantErr: fmt.Errorf("connection refused"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			rr := mock.NewMockChannelRepository(ctrl)
+			mr := mock.NewMockMembershipRepository(ctrl)
+			urr := mock.NewMockMembershipChannelRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(rr)
+			}
+
+			usecase := NewChannelUseCase(rr, mr, urr, tr)
+			getChannels, err := usecase.ListWorkspaceChannels(tt.arg.ctx, tt.arg.workspaceID)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("ListWorkspaceChannels() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("ListWorkspaceChannels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(getChannels, tt.want) {
+				t.Errorf("ListWorkspaceChannels() = %v, want %v", getChannels, tt.want)
+			}
+		})
+	}
+}
+
+func TestChannelUseCase_GetChannel(t *testing.T) {
+	t.Parallel()
+
+	channel := entity.Channel{ID: uuid.New().String(), WorkspaceID: uuid.New().String(), Name: "test"}
+
+	patterns := []struct {
+		name    string
+		setup   func(m *mock.MockChannelRepository)
+		want    *entity.Channel
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(rr *mock.MockChannelRepository) {
+				rr.EXPECT().List(
+					gomock.Any(),
+					[]repository.QueryCondition{{Field: "id", Value: channel.ID}},
+				).Return([]entity.Channel{channel}, nil)
+			},
+			want:    &channel,
+			wantErr: nil,
+		},
+		{
+			name: "Fail: channel not found",
+			setup: func(rr *mock.MockChannelRepository) {
+				rr.EXPECT().List(
+					gomock.Any(),
+					[]repository.QueryCondition{{Field: "id", Value: channel.ID}},
+				).Return([]entity.Channel{}, nil)
+			},
+			want:    nil,
+			wantErr: ErrChannelNotFound,
+		},
+		{
+			name: "Fail: failed to list channels",
+			setup: func(rr *mock.MockChannelRepository) {
+				rr.EXPECT().List(
+					gomock.Any(),
+					[]repository.QueryCondition{{Field: "id", Value: channel.ID}},
+				).Return(nil, fmt.Errorf("connection refused"))
+			},
+			want:    nil,
+			wantErr: fmt.Errorf("connection refused"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			rr := mock.NewMockChannelRepository(ctrl)
+			mr := mock.NewMockMembershipRepository(ctrl)
+			urr := mock.NewMockMembershipChannelRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(rr)
+			}
+
+			usecase := NewChannelUseCase(rr, mr, urr, tr)
+			got, err := usecase.GetChannel(context.Background(), channel.ID)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("GetChannel() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("GetChannel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetChannel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChannelUseCase_AuthorizeChannel(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+	membershipID := userID + "_" + workspaceID
+	publicChannel := entity.Channel{ID: uuid.New().String(), WorkspaceID: workspaceID, Name: "public"}
+	privateChannel := entity.Channel{ID: uuid.New().String(), WorkspaceID: workspaceID, Name: "private", Private: true}
+	membership := entity.Membership{ID: membershipID, UserID: userID, WorkspaceID: workspaceID, Name: "test"}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockChannelRepository,
+			m1 *mock.MockMembershipRepository,
+			m2 *mock.MockMembershipChannelRepository,
+		)
+		channelID string
+		want      *entity.Channel
+		wantErr   error
+	}{
+		{
+			name: "success: public channel",
+			setup: func(cr *mock.MockChannelRepository, mr *mock.MockMembershipRepository, mcr *mock.MockMembershipChannelRepository) {
+				cr.EXPECT().List(gomock.Any(), []repository.QueryCondition{{Field: "id", Value: publicChannel.ID}}).Return([]entity.Channel{publicChannel}, nil)
+				mr.EXPECT().List(gomock.Any(), []repository.QueryCondition{{Field: "id", Value: membershipID}}).Return([]entity.Membership{membership}, nil)
+			},
+			channelID: publicChannel.ID,
+			want:      &publicChannel,
+			wantErr:   nil,
+		},
+		{
+			name: "success: member of private channel",
+			setup: func(cr *mock.MockChannelRepository, mr *mock.MockMembershipRepository, mcr *mock.MockMembershipChannelRepository) {
+				cr.EXPECT().List(gomock.Any(), gomock.Any()).Return([]entity.Channel{privateChannel}, nil)
+				mr.EXPECT().List(gomock.Any(), gomock.Any()).Return([]entity.Membership{membership}, nil)
+				mcr.EXPECT().Get(gomock.Any(), membershipID, privateChannel.ID).Return(
+					&entity.MembershipChannel{MembershipID: membershipID, ChannelID: privateChannel.ID},
+					nil,
+				)
+			},
+			channelID: privateChannel.ID,
+			want:      &privateChannel,
+			wantErr:   nil,
+		},
+		{
+			name: "Fail: not a member of private channel",
+			setup: func(cr *mock.MockChannelRepository, mr *mock.MockMembershipRepository, mcr *mock.MockMembershipChannelRepository) {
+				cr.EXPECT().List(gomock.Any(), gomock.Any()).Return([]entity.Channel{privateChannel}, nil)
+				mr.EXPECT().List(gomock.Any(), gomock.Any()).Return([]entity.Membership{membership}, nil)
+				mcr.EXPECT().Get(gomock.Any(), membershipID, privateChannel.ID).Return(nil, repository.ErrMembershipChannelNotFound)
+			},
+			channelID: privateChannel.ID,
+			want:      nil,
+			wantErr:   ErrChannelAccessDenied,
+		},
+		{
+			name: "Fail: not a member of the workspace",
+			setup: func(cr *mock.MockChannelRepository, mr *mock.MockMembershipRepository, mcr *mock.MockMembershipChannelRepository) {
+				cr.EXPECT().List(gomock.Any(), gomock.Any()).Return([]entity.Channel{publicChannel}, nil)
+				mr.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, nil)
+			},
+			channelID: publicChannel.ID,
+			want:      nil,
+			wantErr:   ErrChannelAccessDenied,
+		},
+		{
+			name: "Fail: channel not found",
+			setup: func(cr *mock.MockChannelRepository, mr *mock.MockMembershipRepository, mcr *mock.MockMembershipChannelRepository) {
+				cr.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, nil)
+			},
+			channelID: uuid.New().String(),
+			want:      nil,
+			wantErr:   ErrChannelNotFound,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			cr := mock.NewMockChannelRepository(ctrl)
+			mr := mock.NewMockMembershipRepository(ctrl)
+			mcr := mock.NewMockMembershipChannelRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(cr, mr, mcr)
+			}
+
+			usecase := NewChannelUseCase(cr, mr, mcr, tr)
+			got, err := usecase.AuthorizeChannel(context.Background(), userID, tt.channelID)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AuthorizeChannel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AuthorizeChannel() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChannelUseCase_InviteToChannel(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	inviterID := uuid.New().String() + "_" + workspaceID
+	memberID := uuid.New().String() + "_" + workspaceID
+	inviteeID := uuid.New().String() + "_" + workspaceID
+	channel := entity.Channel{ID: uuid.New().String(), WorkspaceID: workspaceID, Name: "private", Private: true}
+	direct := entity.Channel{ID: uuid.New().String(), WorkspaceID: workspaceID, Name: "dm:x", Private: true, Direct: true}
+	members := []entity.MembershipChannel{
+		{MembershipID: inviterID, ChannelID: channel.ID},
+		{MembershipID: memberID, ChannelID: channel.ID},
+	}
+	workspaceMemberships := []entity.Membership{{ID: inviterID}, {ID: memberID}, {ID: inviteeID}}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockChannelRepository,
+			m1 *mock.MockMembershipRepository,
+			m2 *mock.MockMembershipChannelRepository,
+		)
+		inviteeIDs []string
+		want       *ChannelInvitation
+		wantErr    error
+	}{
+		{
+			name: "success: existing members are skipped",
+			setup: func(cr *mock.MockChannelRepository, mr *mock.MockMembershipRepository, mcr *mock.MockMembershipChannelRepository) {
+				cr.EXPECT().List(gomock.Any(), gomock.Any()).Return([]entity.Channel{channel}, nil)
+				mcr.EXPECT().List(gomock.Any(), []repository.QueryCondition{{Field: "channel_id", Value: channel.ID}}).Return(members, nil)
+				mr.EXPECT().List(gomock.Any(), []repository.QueryCondition{{Field: "workspace_id", Value: workspaceID}}).Return(workspaceMemberships, nil)
+				mcr.EXPECT().BatchCreate(gomock.Any(), []entity.MembershipChannel{{MembershipID: inviteeID, ChannelID: channel.ID}}).Return(nil)
+			},
+			inviteeIDs: []string{memberID, inviteeID},
+			want: &ChannelInvitation{
+				Channel: channel,
+				Invited: []string{inviteeID},
+				Members: []string{inviterID, memberID, inviteeID},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: inviter is not a member",
+			setup: func(cr *mock.MockChannelRepository, mr *mock.MockMembershipRepository, mcr *mock.MockMembershipChannelRepository) {
+				cr.EXPECT().List(gomock.Any(), gomock.Any()).Return([]entity.Channel{channel}, nil)
+				mcr.EXPECT().List(gomock.Any(), gomock.Any()).Return(members[1:], nil)
+			},
+			inviteeIDs: []string{inviteeID},
+			want:       nil,
+			wantErr:    ErrChannelAccessDenied,
+		},
+		{
+			name: "Fail: invitee is not in the workspace",
+			setup: func(cr *mock.MockChannelRepository, mr *mock.MockMembershipRepository, mcr *mock.MockMembershipChannelRepository) {
+				cr.EXPECT().List(gomock.Any(), gomock.Any()).Return([]entity.Channel{channel}, nil)
+				mcr.EXPECT().List(gomock.Any(), gomock.Any()).Return(members, nil)
+				mr.EXPECT().List(gomock.Any(), gomock.Any()).Return(workspaceMemberships[:2], nil)
+			},
+			inviteeIDs: []string{inviteeID},
+			want:       nil,
+			wantErr:    fmt.Errorf("membership not found: %s", inviteeID),
+		},
+		{
+			name: "Fail: direct channel",
+			setup: func(cr *mock.MockChannelRepository, mr *mock.MockMembershipRepository, mcr *mock.MockMembershipChannelRepository) {
+				cr.EXPECT().List(gomock.Any(), gomock.Any()).Return([]entity.Channel{direct}, nil)
+			},
+			inviteeIDs: []string{inviteeID},
+			want:       nil,
+			wantErr:    fmt.Errorf("cannot invite to direct channel"),
+		},
+		{
+			name:       "Fail: inviteeIDs are required",
+			inviteeIDs: nil,
+			want:       nil,
+			wantErr:    fmt.Errorf("inviteeIDs are required"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			cr := mock.NewMockChannelRepository(ctrl)
+			mr := mock.NewMockMembershipRepository(ctrl)
+			mcr := mock.NewMockMembershipChannelRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(cr, mr, mcr)
+			}
+
+			usecase := NewChannelUseCase(cr, mr, mcr, tr)
+			got, err := usecase.InviteToChannel(context.Background(), inviterID, channel.ID, tt.inviteeIDs)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("InviteToChannel() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("InviteToChannel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("InviteToChannel() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChannelUseCase_OpenDirectMessage(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	membershipID := uuid.New().String() + "_" + workspaceID
+	participantID := uuid.New().String() + "_" + workspaceID
+	direct, err := entity.NewDirectChannel(workspaceID, []string{membershipID, participantID})
+	if err != nil {
+		t.Fatalf("NewDirectChannel() error = %v", err)
+	}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockChannelRepository,
+			m1 *mock.MockMembershipRepository,
+			m2 *mock.MockMembershipChannelRepository,
+			m3 *mock.MockTransactionRepository,
+		)
+		participantIDs []string
+		want           *entity.Channel
+		wantCreated    bool
+		wantErr        error
+	}{
+		{
+			name: "success: create",
+			setup: func(
+				cr *mock.MockChannelRepository,
+				mr *mock.MockMembershipRepository,
+				mcr *mock.MockMembershipChannelRepository,
+				tr *mock.MockTransactionRepository,
+			) {
+				cr.EXPECT().List(gomock.Any(), []repository.QueryCondition{{Field: "id", Value: direct.ID}}).Return(nil, nil)
+				mr.EXPECT().List(gomock.Any(), gomock.Any()).Return([]entity.Membership{{ID: membershipID}, {ID: participantID}}, nil)
+				tr.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				cr.EXPECT().Create(gomock.Any(), *direct).Return(nil)
+				mcr.EXPECT().BatchCreate(gomock.Any(), []entity.MembershipChannel{
+					{MembershipID: membershipID, ChannelID: direct.ID},
+					{MembershipID: participantID, ChannelID: direct.ID},
+				}).Return(nil)
+			},
+			participantIDs: []string{participantID},
+			want:           direct,
+			wantCreated:    true,
+			wantErr:        nil,
+		},
+		{
+			name: "success: already opened",
+			setup: func(
+				cr *mock.MockChannelRepository,
+				mr *mock.MockMembershipRepository,
+				mcr *mock.MockMembershipChannelRepository,
+				tr *mock.MockTransactionRepository,
+			) {
+				cr.EXPECT().List(gomock.Any(), gomock.Any()).Return([]entity.Channel{*direct}, nil)
+			},
+			participantIDs: []string{participantID, membershipID},
+			want:           direct,
+			wantCreated:    false,
+			wantErr:        nil,
+		},
+		{
+			name:           "Fail: no other participant",
+			participantIDs: []string{membershipID},
+			want:           nil,
+			wantCreated:    false,
+			wantErr:        fmt.Errorf("direct channel needs at least two members"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			cr := mock.NewMockChannelRepository(ctrl)
+			mr := mock.NewMockMembershipRepository(ctrl)
+			mcr := mock.NewMockMembershipChannelRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(cr, mr, mcr, tr)
+			}
+
+			usecase := NewChannelUseCase(cr, mr, mcr, tr)
+			got, created, err := usecase.OpenDirectMessage(context.Background(), membershipID, workspaceID, tt.participantIDs)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("OpenDirectMessage() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("OpenDirectMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) || created != tt.wantCreated {
+				t.Errorf("OpenDirectMessage() got = %v, %v, want %v, %v", got, created, tt.want, tt.wantCreated)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/tusmasoma/connectHub-backend/entity"
 	"github.com/tusmasoma/connectHub-backend/internal/log"
@@ -12,15 +14,33 @@ import (
 type MembershipChannelUseCase interface {
 	CreateMembershipChannel(ctx context.Context, membershipID, channelID string) error
 	DeleteMembershipChannel(ctx context.Context, membershipID, channelID string) error
+	// MarkRead は、チャンネルのmessageIDのメッセージまでを既読にし、それまでのメンションの通知も既読にします。
+	MarkRead(ctx context.Context, membershipID, channelID, messageID string) error
+	// ListUnreads は、メンバーシップが参加しているチャンネルごとの未読のメッセージとメンションの数を返します。
+	ListUnreads(ctx context.Context, membershipID string) ([]entity.ChannelUnread, error)
 }
 
 type membershipChannelUseCase struct {
 	mrr repository.MembershipChannelRepository
+	mr  repository.MessageRepository
+	mcr repository.MessageCacheRepository
+	nr  repository.NotificationRepository
+	tr  repository.TransactionRepository
 }
 
-func NewMembershipChannelUseCase(mrr repository.MembershipChannelRepository) MembershipChannelUseCase {
+func NewMembershipChannelUseCase(
+	mrr repository.MembershipChannelRepository,
+	mr repository.MessageRepository,
+	mcr repository.MessageCacheRepository,
+	nr repository.NotificationRepository,
+	tr repository.TransactionRepository,
+) MembershipChannelUseCase {
 	return &membershipChannelUseCase{
 		mrr: mrr,
+		mr:  mr,
+		mcr: mcr,
+		nr:  nr,
+		tr:  tr,
 	}
 }
 
@@ -44,3 +64,86 @@ func (mcuc *membershipChannelUseCase) DeleteMembershipChannel(ctx context.Contex
 	}
 	return nil
 }
+
+// MarkRead は、既読の位置を戻しません。既に既読のメッセージを指定した場合は何もしません。
+// スレッドの返信はチャンネルの未読に数えないため、既読の位置には使えません。
+func (mcuc *membershipChannelUseCase) MarkRead(ctx context.Context, membershipID, channelID, messageID string) error {
+	membershipChannel, err := mcuc.mrr.Get(ctx, membershipID, channelID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMembershipChannelNotFound) {
+			log.Warn("Membership is not a member of the channel", log.Fstring("membershipID", membershipID), log.Fstring("channelID", channelID))
+			return ErrChannelAccessDenied
+		}
+		log.Error("Failed to get membership channel", log.Fstring("membershipID", membershipID), log.Fstring("channelID", channelID))
+		return err
+	}
+
+	message, err := cacheMessage(ctx, mcuc.mr, mcuc.mcr, messageID)
+	if err != nil {
+		return err
+	}
+	if message.ChannelID != channelID || message.ParentID != "" {
+		log.Warn("Message is not in the channel", log.Fstring("msgID", messageID), log.Fstring("channelID", channelID))
+		return ErrMessageNotFound
+	}
+	if !membershipChannel.MarkRead(*message) {
+		return nil
+	}
+
+	// 通知の作成日時は秒より細かいため、既読にしたメッセージと同じ秒に作成された通知まで既読にします。
+	before := membershipChannel.LastReadAt.Add(time.Second)
+	err = mcuc.tr.Transaction(ctx, func(ctx context.Context) error {
+		if err = mcuc.mrr.UpdateLastRead(ctx, *membershipChannel); err != nil {
+			log.Error("Failed to update last read", log.Fstring("membershipID", membershipID), log.Fstring("channelID", channelID))
+			return err
+		}
+		if err = mcuc.nr.MarkChannelRead(ctx, membershipID, channelID, before, time.Now()); err != nil {
+			log.Error("Failed to mark channel notifications as read", log.Fstring("membershipID", membershipID), log.Fstring("channelID", channelID))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("Failed to mark channel as read", log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+// ListUnreads は、未読のメッセージをキャッシュのソートセットで数えるため、メッセージを読み込みません。
+func (mcuc *membershipChannelUseCase) ListUnreads(ctx context.Context, membershipID string) ([]entity.ChannelUnread, error) {
+	membershipChannels, err := mcuc.mrr.List(ctx, []repository.QueryCondition{{Field: "membership_id", Value: membershipID}})
+	if err != nil {
+		log.Error("Failed to list membership channels", log.Fstring("membershipID", membershipID))
+		return nil, err
+	}
+	if len(membershipChannels) == 0 {
+		return nil, nil
+	}
+
+	cursors := make(map[string]*entity.MessageCursor, len(membershipChannels))
+	for i := range membershipChannels {
+		cursors[membershipChannels[i].ChannelID] = membershipChannels[i].LastReadCursor()
+	}
+	unreadCounts, err := mcuc.mcr.CountAfter(ctx, cursors)
+	if err != nil {
+		log.Error("Failed to count unread messages", log.Fstring("membershipID", membershipID))
+		return nil, err
+	}
+	mentionCounts, err := mcuc.nr.CountUnreadByChannel(ctx, membershipID)
+	if err != nil {
+		log.Error("Failed to count unread notifications", log.Fstring("membershipID", membershipID))
+		return nil, err
+	}
+
+	unreads := make([]entity.ChannelUnread, 0, len(membershipChannels))
+	for _, membershipChannel := range membershipChannels {
+		unreads = append(unreads, entity.ChannelUnread{
+			ChannelID:         membershipChannel.ChannelID,
+			LastReadMessageID: membershipChannel.LastReadMessageID,
+			UnreadCount:       unreadCounts[membershipChannel.ChannelID],
+			MentionCount:      mentionCounts[membershipChannel.ChannelID],
+		})
+	}
+	return unreads, nil
+}
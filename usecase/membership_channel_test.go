@@ -2,12 +2,16 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 
 	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository"
 	"github.com/tusmasoma/connectHub-backend/repository/mock"
 )
 
@@ -63,7 +67,7 @@ func TestUserUseCase_CreateMembershipChannel(t *testing.T) {
 				tt.setup(urr)
 			}
 
-			usecase := NewMembershipChannelUseCase(urr)
+			usecase := NewMembershipChannelUseCase(urr, nil, nil, nil, nil)
 
 			err := usecase.CreateMembershipChannel(tt.arg.ctx, tt.arg.membershipID, tt.arg.channelID)
 
@@ -123,7 +127,7 @@ func TestUserUseCase_DeleteMembershipChannel(t *testing.T) {
 				tt.setup(urr)
 			}
 
-			usecase := NewMembershipChannelUseCase(urr)
+			usecase := NewMembershipChannelUseCase(urr, nil, nil, nil, nil)
 
 			err := usecase.DeleteMembershipChannel(tt.arg.ctx, tt.arg.membershipID, tt.arg.channelID)
 
@@ -135,3 +139,151 @@ func TestUserUseCase_DeleteMembershipChannel(t *testing.T) {
 		})
 	}
 }
+
+func TestMembershipChannelUseCase_MarkRead(t *testing.T) {
+	t.Parallel()
+	membershipID := uuid.New().String()
+	channelID := uuid.New().String()
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	message := entity.Message{ID: "b", ChannelID: channelID, CreatedAt: createdAt}
+	readAt := createdAt
+	read := entity.MembershipChannel{MembershipID: membershipID, ChannelID: channelID, LastReadMessageID: "b", LastReadAt: &readAt}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockMembershipChannelRepository,
+			m1 *mock.MockMessageCacheRepository,
+			m2 *mock.MockNotificationRepository,
+			m3 *mock.MockTransactionRepository,
+		)
+		messageID string
+		wantErr   error
+	}{
+		{
+			name: "success",
+			setup: func(
+				m *mock.MockMembershipChannelRepository,
+				m1 *mock.MockMessageCacheRepository,
+				m2 *mock.MockNotificationRepository,
+				m3 *mock.MockTransactionRepository,
+			) {
+				m.EXPECT().Get(gomock.Any(), membershipID, channelID).Return(
+					&entity.MembershipChannel{MembershipID: membershipID, ChannelID: channelID},
+					nil,
+				)
+				m1.EXPECT().Get(gomock.Any(), "b").Return(&message, nil)
+				m3.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				m.EXPECT().UpdateLastRead(gomock.Any(), read).Return(nil)
+				m2.EXPECT().MarkChannelRead(gomock.Any(), membershipID, channelID, createdAt.Add(time.Second), gomock.Any()).Return(nil)
+			},
+			messageID: "b",
+			wantErr:   nil,
+		},
+		{
+			name: "success: already read",
+			setup: func(
+				m *mock.MockMembershipChannelRepository,
+				m1 *mock.MockMessageCacheRepository,
+				m2 *mock.MockNotificationRepository,
+				m3 *mock.MockTransactionRepository,
+			) {
+				m.EXPECT().Get(gomock.Any(), membershipID, channelID).Return(&read, nil)
+				m1.EXPECT().Get(gomock.Any(), "a").Return(&entity.Message{ID: "a", ChannelID: channelID, CreatedAt: createdAt}, nil)
+			},
+			messageID: "a",
+			wantErr:   nil,
+		},
+		{
+			name: "Fail: message in another channel",
+			setup: func(
+				m *mock.MockMembershipChannelRepository,
+				m1 *mock.MockMessageCacheRepository,
+				m2 *mock.MockNotificationRepository,
+				m3 *mock.MockTransactionRepository,
+			) {
+				m.EXPECT().Get(gomock.Any(), membershipID, channelID).Return(&read, nil)
+				m1.EXPECT().Get(gomock.Any(), "c").Return(&entity.Message{ID: "c", ChannelID: uuid.New().String(), CreatedAt: createdAt}, nil)
+			},
+			messageID: "c",
+			wantErr:   ErrMessageNotFound,
+		},
+		{
+			name: "Fail: not a member of the channel",
+			setup: func(
+				m *mock.MockMembershipChannelRepository,
+				m1 *mock.MockMessageCacheRepository,
+				m2 *mock.MockNotificationRepository,
+				m3 *mock.MockTransactionRepository,
+			) {
+				m.EXPECT().Get(gomock.Any(), membershipID, channelID).Return(nil, repository.ErrMembershipChannelNotFound)
+			},
+			messageID: "b",
+			wantErr:   ErrChannelAccessDenied,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mrr := mock.NewMockMembershipChannelRepository(ctrl)
+			mcr := mock.NewMockMessageCacheRepository(ctrl)
+			nr := mock.NewMockNotificationRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(mrr, mcr, nr, tr)
+			}
+
+			usecase := NewMembershipChannelUseCase(mrr, nil, mcr, nr, tr)
+
+			err := usecase.MarkRead(context.Background(), membershipID, channelID, tt.messageID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("MarkRead() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMembershipChannelUseCase_ListUnreads(t *testing.T) {
+	t.Parallel()
+	membershipID := uuid.New().String()
+	readAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	mrr := mock.NewMockMembershipChannelRepository(ctrl)
+	mcr := mock.NewMockMessageCacheRepository(ctrl)
+	nr := mock.NewMockNotificationRepository(ctrl)
+
+	mrr.EXPECT().List(gomock.Any(), []repository.QueryCondition{{Field: "membership_id", Value: membershipID}}).Return(
+		[]entity.MembershipChannel{
+			{MembershipID: membershipID, ChannelID: "read", LastReadMessageID: "m", LastReadAt: &readAt},
+			{MembershipID: membershipID, ChannelID: "unread"},
+		},
+		nil,
+	)
+	mcr.EXPECT().CountAfter(gomock.Any(), map[string]*entity.MessageCursor{
+		"read":   {Score: readAt.Unix(), ID: "m"},
+		"unread": nil,
+	}).Return(map[string]int64{"read": 2, "unread": 5}, nil)
+	nr.EXPECT().CountUnreadByChannel(gomock.Any(), membershipID).Return(map[string]int64{"unread": 1}, nil)
+
+	usecase := NewMembershipChannelUseCase(mrr, nil, mcr, nr, nil)
+
+	got, err := usecase.ListUnreads(context.Background(), membershipID)
+	if err != nil {
+		t.Fatalf("ListUnreads() error = %v", err)
+	}
+	want := []entity.ChannelUnread{
+		{ChannelID: "read", LastReadMessageID: "m", UnreadCount: 2},
+		{ChannelID: "unread", UnreadCount: 5, MentionCount: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListUnreads() = %v, want %v", got, want)
+	}
+}
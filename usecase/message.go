@@ -3,97 +3,464 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
+	"github.com/tusmasoma/connectHub-backend/config"
 	"github.com/tusmasoma/connectHub-backend/entity"
 	"github.com/tusmasoma/connectHub-backend/internal/log"
 	"github.com/tusmasoma/connectHub-backend/repository"
 )
 
+// ErrMessageNotFound は、対象のメッセージが存在しないか削除待ちの場合に返されます。
+var ErrMessageNotFound = errors.New("message not found")
+
 type MessageUseCase interface {
 	ListMessages(ctx context.Context, channelID string, start, end time.Time) ([]entity.Message, error)
-	CreateMessage(ctx context.Context, channelID string, message entity.Message) error
-	UpdateMessage(ctx context.Context, message entity.Message, membershipID string) error
-	DeleteMessage(ctx context.Context, message entity.Message, membershipID, channelID string) error
+	ListMessagesPage(ctx context.Context, channelID string, page entity.Page) (*MessagePage, error)
+	CreateMessage(
+		ctx context.Context,
+		channelID string,
+		message entity.Message,
+		idempotencyKey string,
+		onlineMembershipIDs []string,
+	) (*CreatedMessage, error)
+	UpdateMessage(ctx context.Context, message entity.Message, membershipID string) (*entity.Message, error)
+	DeleteMessage(ctx context.Context, messageID, membershipID string) (*entity.Message, error)
 }
 
 type messageUseCase struct {
 	ur  repository.MembershipRepository
 	mr  repository.MessageRepository
 	mcr repository.MessageCacheRepository
+	rr  repository.ReactionRepository
+	rcr repository.ReactionCacheRepository
+	nr  repository.NotificationRepository
+	ir  repository.IdempotencyRepository
+	ar  repository.AttachmentRepository
+
+	conf *config.MessageConfig
 }
 
 func NewMessageUseCase(
 	ur repository.MembershipRepository,
 	mr repository.MessageRepository,
 	mcr repository.MessageCacheRepository,
+	rr repository.ReactionRepository,
+	rcr repository.ReactionCacheRepository,
+	nr repository.NotificationRepository,
+	ir repository.IdempotencyRepository,
+	ar repository.AttachmentRepository,
+	conf *config.MessageConfig,
 ) MessageUseCase {
 	return &messageUseCase{
-		ur:  ur,
-		mr:  mr,
-		mcr: mcr,
+		ur:   ur,
+		mr:   mr,
+		mcr:  mcr,
+		rr:   rr,
+		rcr:  rcr,
+		nr:   nr,
+		ir:   ir,
+		ar:   ar,
+		conf: conf,
 	}
 }
 
+// messageCacheHotWindow は、MySQLから読み込んだメッセージをキャッシュに載せ直す対象の期間です。
+// これより古い履歴は参照されるたびにMySQLから読み込みます。
+const messageCacheHotWindow = 7 * 24 * time.Hour
+
+// ListMessages は、キャッシュとMySQLのメッセージをID単位で重複排除して作成日時の昇順で返します。
+// キャッシュの内容は書き戻し前の編集を含むため、MySQLより優先します。
+// キャッシュが漏れなく保持している範囲はキャッシュだけで返し、それより古い範囲だけをMySQLから読み込みます。
 func (muc *messageUseCase) ListMessages(ctx context.Context, channelID string, start, end time.Time) ([]entity.Message, error) {
-	messages, err := muc.mcr.List(ctx, channelID, start, end)
-	if err != nil {
-		log.Error("Failed to get messages", log.Ferror(err))
+	cached, err := muc.mcr.List(ctx, channelID, start, end)
+	cacheAvailable := err == nil
+	if !cacheAvailable {
+		log.Warn("Failed to get messages from cache, falling back to database", log.Ferror(err))
+	}
+
+	var covered *time.Time
+	if cacheAvailable {
+		covered, err = muc.mcr.CoveredSince(ctx, channelID)
+		if err != nil {
+			log.Warn("Failed to get covered range of cache", log.Fstring("channelID", channelID), log.Ferror(err))
+			covered = nil
+		}
+	}
+
+	messages := cached
+	if covered == nil || start.Before(*covered) {
+		until := end
+		if covered != nil && covered.Before(end) {
+			until = *covered
+		}
+
+		var stored []entity.Message
+		stored, err = muc.mr.ListChannelMessages(ctx, channelID, start, until)
+		if err != nil {
+			log.Error("Failed to get messages", log.Ferror(err))
+			return nil, err
+		}
+
+		var filled bool
+		messages, filled, err = mergeMessages(ctx, muc.mcr, channelID, cached, stored, cacheAvailable)
+		if err != nil {
+			return nil, err
+		}
+
+		// 読み込んだ範囲がキャッシュの網羅する範囲か現在まで続いていれば、網羅する範囲を広げられます。
+		// MySQLから載せ直すのは直近のメッセージだけなので、それより古い範囲は網羅したことになりません。
+		now := time.Now()
+		contiguous := until.Before(end) || !end.Before(now)
+		if filled && contiguous {
+			since := start
+			if hotSince := now.Add(-messageCacheHotWindow); since.Before(hotSince) {
+				since = hotSince
+			}
+			if err = muc.mcr.MarkCovered(ctx, channelID, since); err != nil {
+				log.Warn("Failed to mark covered range of cache", log.Fstring("channelID", channelID), log.Ferror(err))
+			}
+		}
+	}
+
+	sortMessages(messages)
+	if err = attachReactions(ctx, muc.rr, muc.rcr, messages); err != nil {
 		return nil, err
 	}
 	return messages, nil
 }
 
-func (muc *messageUseCase) CreateMessage(ctx context.Context, channelID string, message entity.Message) error {
-	if err := muc.mcr.Create(ctx, channelID, message); err != nil {
-		log.Error("Failed to cache message", log.Ferror(err))
-		return err
+type MessagePage struct {
+	Messages   []entity.Message // 作成日時の昇順
+	NextCursor string           // より新しいメッセージがある場合に、Direction "after"で使うカーソル
+	PrevCursor string           // より古いメッセージがある場合に、Direction "before"で使うカーソル
+}
+
+// ListMessagesPage は、カーソルを起点にpage.Limit件のメッセージを返します。
+// キャッシュだけで1ページ分を満たせない場合は、MySQLから不足分を補います。
+func (muc *messageUseCase) ListMessagesPage(ctx context.Context, channelID string, page entity.Page) (*MessagePage, error) {
+	query, err := newMessagePageQuery(page)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+
+	// 続きのページがあるかを判定するため、1件多く取得します。
+	fetch := query
+	fetch.Limit = query.Limit + 1
+
+	messages, err := muc.mcr.ListPage(ctx, channelID, fetch)
+	cacheAvailable := err == nil
+	if !cacheAvailable {
+		log.Warn("Failed to get messages from cache, falling back to database", log.Ferror(err))
+	}
+
+	if int64(len(messages)) < fetch.Limit {
+		var stored []entity.Message
+		stored, err = muc.mr.ListPage(ctx, channelID, fetch)
+		if err != nil {
+			log.Error("Failed to get messages", log.Ferror(err))
+			return nil, err
+		}
+		messages, _, err = mergeMessages(ctx, muc.mcr, channelID, messages, stored, cacheAvailable)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sortMessages(messages)
+
+	before := query.Direction == entity.PageDirectionBefore
+	hasMore := int64(len(messages)) > query.Limit
+	if hasMore {
+		if before {
+			messages = messages[int64(len(messages))-query.Limit:]
+		} else {
+			messages = messages[:query.Limit]
+		}
+	}
+
+	if err = attachReactions(ctx, muc.rr, muc.rcr, messages); err != nil {
+		return nil, err
+	}
+
+	result := &MessagePage{Messages: messages}
+	if len(messages) == 0 {
+		return result, nil
+	}
+	oldest := entity.NewMessageCursor(messages[0]).Encode()
+	newest := entity.NewMessageCursor(messages[len(messages)-1]).Encode()
+	if before {
+		if hasMore {
+			result.PrevCursor = oldest
+		}
+		if query.Cursor != nil {
+			result.NextCursor = newest
+		}
+	} else {
+		if hasMore {
+			result.NextCursor = newest
+		}
+		if query.Cursor != nil {
+			result.PrevCursor = oldest
+		}
+	}
+	return result, nil
 }
 
-func (muc *messageUseCase) UpdateMessage(ctx context.Context, message entity.Message, membershipID string) error {
-	membership, err := muc.ur.Get(ctx, membershipID)
+func newMessagePageQuery(page entity.Page) (repository.MessagePageQuery, error) {
+	query := repository.MessagePageQuery{
+		Direction: page.Direction,
+		Limit:     page.Limit,
+	}
+
+	switch query.Direction {
+	case "":
+		query.Direction = entity.PageDirectionBefore
+	case entity.PageDirectionBefore, entity.PageDirectionAfter:
+	default:
+		log.Warn("Invalid page direction", log.Fstring("direction", page.Direction))
+		return query, fmt.Errorf("invalid direction: %s", page.Direction)
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = config.DefaultMessagePageSize
+	}
+	if query.Limit > config.MaxMessagePageSize {
+		query.Limit = config.MaxMessagePageSize
+	}
+
+	if page.Cursor != "" {
+		cursor, err := entity.DecodeMessageCursor(page.Cursor)
+		if err != nil {
+			return query, err
+		}
+		query.Cursor = cursor
+	}
+	return query, nil
+}
+
+// mergeMessages は、キャッシュとMySQLのメッセージをID単位で重複排除します。
+// キャッシュの内容は書き戻し前の編集を含むため、MySQLより優先します。
+// キャッシュになかった直近のメッセージを全てキャッシュに載せ直せた場合はtrueを返します。
+func mergeMessages(
+	ctx context.Context,
+	mcr repository.MessageCacheRepository,
+	channelID string,
+	cached, stored []entity.Message,
+	cacheAvailable bool,
+) ([]entity.Message, bool, error) {
+	messages := make(map[string]entity.Message, len(cached)+len(stored))
+	for _, message := range cached {
+		messages[message.ID] = message
+	}
+
+	var misses []entity.Message
+	for _, message := range stored {
+		if _, ok := messages[message.ID]; !ok {
+			misses = append(misses, message)
+		}
+	}
+
+	filled := cacheAvailable
+	if cacheAvailable && len(misses) > 0 {
+		var err error
+		misses, filled, err = fillCache(ctx, mcr, channelID, misses)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	for _, message := range misses {
+		messages[message.ID] = message
+	}
+
+	result := make([]entity.Message, 0, len(messages))
+	for _, message := range messages {
+		result = append(result, message)
+	}
+	return result, filled, nil
+}
+
+// sortMessages は、メッセージをカーソルと同じ順序(作成日時の秒、IDの昇順)に並べ替えます。
+func sortMessages(messages []entity.Message) {
+	sort.Slice(messages, func(i, j int) bool {
+		return entity.NewMessageCursor(messages[i]).Less(entity.NewMessageCursor(messages[j]))
+	})
+}
+
+// fillCache は、削除がまだMySQLに反映されていないメッセージを取り除き、直近のものをキャッシュに載せ直します。
+// MySQLから読み込んだ後に削除されたメッセージは、キャッシュ側で載せずに除きます。
+// キャッシュに載せ直せた場合はtrueを返します。
+func fillCache(
+	ctx context.Context,
+	mcr repository.MessageCacheRepository,
+	channelID string,
+	misses []entity.Message,
+) ([]entity.Message, bool, error) {
+	ids := make([]string, len(misses))
+	for i, message := range misses {
+		ids[i] = message.ID
+	}
+	deleted, err := mcr.PendingDeletes(ctx, ids)
 	if err != nil {
-		log.Error("Failed to get membership", log.Fstring("membershipID", membershipID))
-		return err
+		log.Error("Failed to get pending deleted messages", log.Ferror(err))
+		return nil, false, err
 	}
 
-	if !membership.IsAdmin && membershipID != message.MembershipID {
-		log.Warn(
-			"Membership don't have permission to update msg",
-			log.Fstring("membershipID", membershipID),
-			log.Fstring("msgID", message.ID),
-		)
-		return fmt.Errorf("don't have permission to update msg")
+	hotSince := time.Now().Add(-messageCacheHotWindow)
+	alive := make([]entity.Message, 0, len(misses))
+	var hot []entity.Message
+	for _, message := range misses {
+		if deleted[message.ID] {
+			continue
+		}
+		alive = append(alive, message)
+		if message.CreatedAt.After(hotSince) {
+			hot = append(hot, message)
+		}
 	}
 
-	if err = muc.mcr.Update(ctx, message); err != nil {
-		log.Error("Failed to update msg in cache", log.Fstring("msgID", message.ID))
-		return err
+	if len(hot) == 0 {
+		return alive, true, nil
 	}
-	return nil
+	if err = mcr.Fill(ctx, channelID, hot); err != nil {
+		// キャッシュへの再投入に失敗しても、MySQLの結果は返せるため処理を続けます。
+		log.Warn("Failed to fill message cache", log.Fstring("channelID", channelID), log.Ferror(err))
+		return alive, false, nil
+	}
+	return alive, true, nil
+}
+
+type CreatedMessage struct {
+	MessageID     string         // 作成したメッセージのID。再送の場合は最初に作成したメッセージのIDです
+	Message       entity.Message // 作成したメッセージ。再送の場合は空です
+	Duplicate     bool           // 冪等キーが一致する作成済みのメッセージがあり、新たに作成しなかった場合にtrueです
+	Notifications []entity.Notification
 }
 
-func (muc *messageUseCase) DeleteMessage(ctx context.Context, message entity.Message, membershipID, channelID string) error {
+// CreateMessage は、メッセージを作成し、メンションされたメンバーへの通知を返します。
+// onlineMembershipIDsは@hereの対象となる接続中のメンバーです。
+// idempotencyKeyを指定すると、同じ送信者が同じキーで再送したメッセージは設定された期間内は作成せず、最初に作成したメッセージのIDを返します。
+// 添付ファイルはIDだけを指定し、送信者がこのチャンネルにアップロードしたファイルの情報に置き換えます。
+// 通知の作成に失敗してもメッセージは作成済みのため、エラーは記録するだけにとどめます。
+func (muc *messageUseCase) CreateMessage(
+	ctx context.Context,
+	channelID string,
+	message entity.Message,
+	idempotencyKey string,
+	onlineMembershipIDs []string,
+) (*CreatedMessage, error) {
+	message.ChannelID = channelID
+
+	var key string
+	if idempotencyKey != "" {
+		// 送信者ごとに区切り、他のメンバーのキーと衝突しないようにします。
+		key = message.MembershipID + ":" + idempotencyKey
+		existing, err := muc.ir.Reserve(ctx, key, message.ID, muc.conf.IdempotencyWindow)
+		if err != nil {
+			log.Error("Failed to reserve idempotency key", log.Fstring("membershipID", message.MembershipID), log.Ferror(err))
+			return nil, err
+		}
+		if existing != "" {
+			log.Info("Duplicate message", log.Fstring("membershipID", message.MembershipID), log.Fstring("msgID", existing))
+			return &CreatedMessage{MessageID: existing, Duplicate: true}, nil
+		}
+	}
+
+	err := claimAttachments(ctx, muc.ar, &message)
+	if err == nil {
+		if err = muc.mcr.Create(ctx, channelID, message); err != nil {
+			log.Error("Failed to cache message", log.Ferror(err))
+			releaseAttachments(ctx, muc.ar, message)
+		}
+	}
+	if err != nil {
+		if key != "" {
+			// 作成に失敗したメッセージの再送を受け付けられるよう、キーを解放します。
+			if rerr := muc.ir.Release(ctx, key); rerr != nil {
+				log.Warn("Failed to release idempotency key", log.Fstring("membershipID", message.MembershipID), log.Ferror(rerr))
+			}
+		}
+		return nil, err
+	}
+
+	result := &CreatedMessage{MessageID: message.ID, Message: message}
+	notifications, err := notifyMentions(ctx, muc.ur, muc.nr, message, onlineMembershipIDs)
+	if err != nil {
+		log.Error("Failed to notify mentions", log.Fstring("msgID", message.ID), log.Ferror(err))
+		return result, nil
+	}
+	result.Notifications = notifications
+	return result, nil
+}
+
+// UpdateMessage は、message.IDのメッセージの本文をmessage.Textに更新し、更新後のメッセージを返します。
+// 作成者やチャンネルなどの他の項目はクライアントの値を使わず、保存済みのメッセージを基に権限を確認します。
+func (muc *messageUseCase) UpdateMessage(ctx context.Context, message entity.Message, membershipID string) (*entity.Message, error) {
+	stored, err := muc.authorizeOwner(ctx, message.ID, membershipID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	stored.Text = message.Text
+	stored.UpdatedAt = &now
+	if err = muc.mcr.Update(ctx, *stored); err != nil {
+		log.Error("Failed to update msg in cache", log.Fstring("msgID", stored.ID))
+		return nil, err
+	}
+	return stored, nil
+}
+
+// DeleteMessage は、messageIDのメッセージを削除し、削除したメッセージを返します。
+func (muc *messageUseCase) DeleteMessage(ctx context.Context, messageID, membershipID string) (*entity.Message, error) {
+	stored, err := muc.authorizeOwner(ctx, messageID, membershipID)
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.ParentID != "" {
+		if err = muc.deleteReply(ctx, *stored); err != nil {
+			return nil, err
+		}
+		return stored, nil
+	}
+
+	if err = muc.mcr.Delete(ctx, stored.ChannelID, stored.ID); err != nil {
+		log.Error("Failed to delete msg from cache", log.Fstring("msgID", stored.ID))
+		return nil, err
+	}
+	return stored, nil
+}
+
+// authorizeOwner は、保存済みのメッセージを読み込み、membershipIDが作成者か管理者であることを確認します。
+func (muc *messageUseCase) authorizeOwner(ctx context.Context, messageID, membershipID string) (*entity.Message, error) {
+	stored, err := cacheMessage(ctx, muc.mr, muc.mcr, messageID)
+	if err != nil {
+		return nil, err
+	}
 	membership, err := muc.ur.Get(ctx, membershipID)
 	if err != nil {
 		log.Error("Failed to get membership", log.Fstring("membershipID", membershipID))
-		return err
+		return nil, err
 	}
+	if err = authorizeMessageOwner(*membership, *stored); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
 
-	if !membership.IsAdmin && membershipID != message.MembershipID {
-		log.Warn(
-			"Membership don't have permission to delete msg",
-			log.Fstring("membershipID", membershipID),
-			log.Fstring("msgID", message.ID),
-		)
-		return fmt.Errorf("don't have permission to delete msg")
+// deleteReply は、返信を削除して親メッセージの返信数を減らします。
+// 親メッセージが既に削除されている場合は返信のみ削除します。
+func (muc *messageUseCase) deleteReply(ctx context.Context, reply entity.Message) error {
+	if _, err := cacheMessage(ctx, muc.mr, muc.mcr, reply.ParentID); err != nil {
+		log.Warn("Failed to load parent message", log.Fstring("parentID", reply.ParentID), log.Ferror(err))
 	}
 
-	if err = muc.mcr.Delete(ctx, channelID, message.ID); err != nil {
-		log.Error("Failed to delete msg from cache", log.Fstring("msgID", message.ID))
+	_, err := muc.mcr.DeleteReply(ctx, reply.ParentID, reply.ID)
+	if err != nil && !errors.Is(err, repository.ErrMessageNotCached) {
+		log.Error("Failed to delete reply from cache", log.Fstring("msgID", reply.ID))
 		return err
 	}
 	return nil
@@ -0,0 +1,290 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+// MessageFlusher は、Redisに書き込まれたメッセージの作成・編集・削除をMySQLへ非同期に書き戻します。
+// 書き戻し待ちの変更はRedis側に残り続けるため、プロセスが停止しても次回起動時に再開されます。
+type MessageFlusher interface {
+	Run(ctx context.Context)
+	Flush(ctx context.Context) (int, error)
+	Lag(ctx context.Context) (*MessageFlushLag, error)
+	RequeueDeadLetters(ctx context.Context, ids []string) (int, error)
+}
+
+type MessageFlushLag struct {
+	Pending     int64         `json:"pending"`
+	Behind      time.Duration `json:"behind"`
+	DeadLetters int64         `json:"dead_letters"` // 書き戻しを諦め、手動での対応を待っているメッセージの件数
+}
+
+type messageFlusher struct {
+	mr   repository.MessageRepository
+	mcr  repository.MessageCacheRepository
+	conf *config.FlusherConfig
+}
+
+func NewMessageFlusher(
+	mr repository.MessageRepository,
+	mcr repository.MessageCacheRepository,
+	conf *config.FlusherConfig,
+) MessageFlusher {
+	return &messageFlusher{
+		mr:   mr,
+		mcr:  mcr,
+		conf: conf,
+	}
+}
+
+func (mf *messageFlusher) Run(ctx context.Context) {
+	ticker := time.NewTicker(mf.conf.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Message flusher stopped")
+			return
+		case <-ticker.C:
+			mf.drain(ctx)
+			mf.reportLag(ctx)
+		}
+	}
+}
+
+// drain は、書き戻し待ちがバッチサイズ未満になるまでFlushを繰り返します。
+func (mf *messageFlusher) drain(ctx context.Context) {
+	for {
+		n, err := mf.Flush(ctx)
+		if err != nil {
+			log.Error("Failed to flush messages", log.Ferror(err))
+			return
+		}
+		if int64(n) < mf.conf.BatchSize {
+			return
+		}
+	}
+}
+
+func (mf *messageFlusher) reportLag(ctx context.Context) {
+	lag, err := mf.Lag(ctx)
+	if err != nil {
+		log.Error("Failed to get message flush lag", log.Ferror(err))
+		return
+	}
+	if lag.Behind > mf.conf.LagWarnThreshold {
+		log.Warn(
+			"Message flusher is lagging behind",
+			log.Fint64("pending", lag.Pending),
+			log.Fduration("behind", lag.Behind),
+		)
+	}
+	if lag.DeadLetters > 0 {
+		log.Warn("Messages are waiting in the dead letter", log.Fint64("count", lag.DeadLetters))
+	}
+}
+
+// Flush は、書き戻し待ちのメッセージをまとめてMySQLへ反映します。
+// メッセージの内容が原因で反映できない場合は1件ずつ反映し直し、それでも反映できないメッセージはデッドレターに移します。
+// 接続の問題など一時的なエラーで反映できなかったメッセージは、デッドレターに移さず次回に持ち越します。
+func (mf *messageFlusher) Flush(ctx context.Context) (int, error) {
+	pending, err := mf.mcr.ListPending(ctx, mf.conf.BatchSize)
+	if err != nil {
+		log.Error("Failed to list pending messages", log.Ferror(err))
+		return 0, err
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	var upserts, deletes []repository.PendingMessage
+	var invalid []repository.DeadLetterMessage
+	for _, pm := range pending {
+		switch {
+		case pm.Deleted:
+			deletes = append(deletes, pm)
+		case pm.Message == nil:
+			log.Error("Pending message is missing from cache", log.Fstring("msgID", pm.ID))
+			invalid = append(invalid, repository.DeadLetterMessage{PendingMessage: pm, Reason: "missing from cache"})
+		case pm.Message.ChannelID == "":
+			log.Error("Pending message has no channel", log.Fstring("msgID", pm.ID))
+			invalid = append(invalid, repository.DeadLetterMessage{PendingMessage: pm, Reason: "no channel"})
+		default:
+			upserts = append(upserts, pm)
+		}
+	}
+
+	// キャッシュ上で壊れているメッセージは何度書き戻しても反映できないため、MySQLの状態に関わらずデッドレターに移します。
+	if len(invalid) > 0 {
+		if err = mf.deadLetter(ctx, invalid); err != nil {
+			return 0, err
+		}
+	}
+
+	// 再試行に時間を使い切ると、後続の書き戻しが止まってしまうため、1回のFlushで再試行に使う時間を制限します。
+	deadline := time.Now().Add(mf.conf.RetryBudget)
+	created, createFailed, createErr := mf.persist(ctx, deadline, upserts, func(ctx context.Context, rows []repository.PendingMessage) error {
+		messages := make([]entity.Message, len(rows))
+		for i, pm := range rows {
+			messages[i] = *pm.Message
+		}
+		return mf.mr.BatchCreate(ctx, messages)
+	})
+	if createErr != nil {
+		log.Error("Failed to persist messages", log.Ferror(createErr))
+	}
+	deleted, deleteFailed, deleteErr := mf.persist(ctx, deadline, deletes, func(ctx context.Context, rows []repository.PendingMessage) error {
+		ids := make([]string, len(rows))
+		for i, pm := range rows {
+			ids[i] = pm.ID
+		}
+		return mf.mr.BatchDelete(ctx, ids)
+	})
+	if deleteErr != nil {
+		log.Error("Failed to delete persisted messages", log.Ferror(deleteErr))
+	}
+
+	failed := make([]repository.DeadLetterMessage, 0, len(createFailed)+len(deleteFailed))
+	failed = append(append(failed, createFailed...), deleteFailed...)
+	if len(failed) > 0 {
+		if err = mf.deadLetter(ctx, failed); err != nil {
+			return 0, err
+		}
+	}
+
+	acked := make([]repository.PendingMessage, 0, len(created)+len(deleted))
+	acked = append(append(acked, created...), deleted...)
+	if len(acked) > 0 {
+		if err = mf.mcr.AckPending(ctx, acked); err != nil {
+			log.Error("Failed to ack pending messages", log.Ferror(err))
+			return 0, err
+		}
+	}
+
+	n := len(invalid) + len(failed) + len(acked)
+	if createErr != nil {
+		return n, createErr
+	}
+	if deleteErr != nil {
+		return n, deleteErr
+	}
+	return n, nil
+}
+
+// persist は、rowsをまとめて反映し、メッセージの内容が原因で失敗した場合は1件ずつ反映し直します。
+// 反映できたものと、内容が原因で反映できなかったものを返します。
+// 一時的なエラーが発生した場合は、残りのメッセージをどちらにも含めずに次回に持ち越し、そのエラーを返します。
+func (mf *messageFlusher) persist(
+	ctx context.Context,
+	deadline time.Time,
+	rows []repository.PendingMessage,
+	fn func(ctx context.Context, rows []repository.PendingMessage) error,
+) ([]repository.PendingMessage, []repository.DeadLetterMessage, error) {
+	if len(rows) == 0 {
+		return nil, nil, nil
+	}
+	err := mf.withRetry(ctx, deadline, func(ctx context.Context) error {
+		return fn(ctx, rows)
+	})
+	if err == nil {
+		return rows, nil, nil
+	}
+	if !errors.Is(err, repository.ErrMessageRejected) {
+		return nil, nil, err
+	}
+	if len(rows) == 1 {
+		return nil, []repository.DeadLetterMessage{{PendingMessage: rows[0], Reason: err.Error()}}, nil
+	}
+	log.Warn("Failed to flush messages in batch, retrying one by one", log.Fint("count", len(rows)), log.Ferror(err))
+
+	var persisted []repository.PendingMessage
+	var failed []repository.DeadLetterMessage
+	for _, pm := range rows {
+		rowErr := mf.withRetry(ctx, deadline, func(ctx context.Context) error {
+			return fn(ctx, []repository.PendingMessage{pm})
+		})
+		switch {
+		case rowErr == nil:
+			persisted = append(persisted, pm)
+		case errors.Is(rowErr, repository.ErrMessageRejected):
+			log.Warn("Failed to flush message", log.Fstring("msgID", pm.ID), log.Ferror(rowErr))
+			failed = append(failed, repository.DeadLetterMessage{PendingMessage: pm, Reason: rowErr.Error()})
+		default:
+			return persisted, failed, rowErr
+		}
+	}
+	return persisted, failed, nil
+}
+
+func (mf *messageFlusher) deadLetter(ctx context.Context, messages []repository.DeadLetterMessage) error {
+	if err := mf.mcr.DeadLetter(ctx, messages); err != nil {
+		log.Error("Failed to move messages to dead letter", log.Ferror(err))
+		return err
+	}
+	for _, dl := range messages {
+		log.Error("Gave up flushing message", log.Fstring("msgID", dl.ID), log.Fstring("reason", dl.Reason))
+	}
+	return nil
+}
+
+// withRetry は、一時的なエラーの場合のみfnを再試行します。deadlineまでに再試行できない場合は諦めます。
+func (mf *messageFlusher) withRetry(ctx context.Context, deadline time.Time, fn func(ctx context.Context) error) error {
+	backoff := mf.conf.RetryBackoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+		// メッセージの内容が原因のエラーは、再試行しても結果が変わりません。
+		if errors.Is(err, repository.ErrMessageRejected) || attempt >= mf.conf.MaxRetries {
+			return err
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			log.Warn("Retry budget for message flush is exhausted", log.Ferror(err))
+			return err
+		}
+		log.Warn("Retrying message flush", log.Fint("attempt", attempt+1), log.Ferror(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (mf *messageFlusher) Lag(ctx context.Context) (*MessageFlushLag, error) {
+	stats, err := mf.mcr.PendingStats(ctx)
+	if err != nil {
+		log.Error("Failed to get pending message stats", log.Ferror(err))
+		return nil, err
+	}
+
+	lag := &MessageFlushLag{Pending: stats.Count, DeadLetters: stats.DeadLetters}
+	if stats.Count > 0 && !stats.OldestQueuedAt.IsZero() {
+		lag.Behind = time.Since(stats.OldestQueuedAt)
+	}
+	return lag, nil
+}
+
+// RequeueDeadLetters は、原因を取り除いた後にデッドレターのメッセージを書き戻し待ちに戻します。idsが空の場合は全て戻します。
+// 戻したメッセージは次回のFlushで改めて書き戻します。
+func (mf *messageFlusher) RequeueDeadLetters(ctx context.Context, ids []string) (int, error) {
+	n, err := mf.mcr.RequeueDeadLetters(ctx, ids)
+	if err != nil {
+		log.Error("Failed to requeue dead letters", log.Ferror(err))
+		return 0, err
+	}
+	log.Info("Requeued dead letters", log.Fint("count", n))
+	return n, nil
+}
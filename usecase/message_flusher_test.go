@@ -0,0 +1,401 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository"
+	"github.com/tusmasoma/connectHub-backend/repository/mock"
+)
+
+func TestMessageFlusher_Flush(t *testing.T) {
+	t.Parallel()
+	channelID := uuid.New().String()
+	membershipID := uuid.New().String()
+	message := entity.Message{
+		ID:           uuid.New().String(),
+		MembershipID: membershipID,
+		ChannelID:    channelID,
+		Text:         "test message",
+		CreatedAt:    time.Now(),
+	}
+	deletedID := uuid.New().String()
+	queuedAt := time.Now().Add(-1 * time.Second)
+	pending := []repository.PendingMessage{
+		{ID: message.ID, Message: &message, QueuedAt: queuedAt},
+		{ID: deletedID, Deleted: true, QueuedAt: queuedAt},
+	}
+	badMessage := entity.Message{
+		ID:           uuid.New().String(),
+		MembershipID: membershipID,
+		ChannelID:    uuid.New().String(),
+		Text:         "message of a deleted channel",
+		CreatedAt:    time.Now(),
+	}
+	withBadRow := []repository.PendingMessage{
+		pending[0],
+		{ID: badMessage.ID, Message: &badMessage, QueuedAt: queuedAt},
+		pending[1],
+	}
+	broken := []repository.PendingMessage{
+		{ID: uuid.New().String(), QueuedAt: queuedAt},
+		{ID: uuid.New().String(), Message: &entity.Message{Text: "no channel"}, QueuedAt: queuedAt},
+		pending[0],
+	}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			mmr *mock.MockMessageRepository,
+			mcr *mock.MockMessageCacheRepository,
+		)
+		want    int
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				mcr.EXPECT().ListPending(gomock.Any(), int64(100)).Return(pending, nil)
+				mmr.EXPECT().BatchCreate(gomock.Any(), []entity.Message{message}).Return(nil)
+				mmr.EXPECT().BatchDelete(gomock.Any(), []string{deletedID}).Return(nil)
+				mcr.EXPECT().AckPending(gomock.Any(), pending).Return(nil)
+			},
+			want:    2,
+			wantErr: nil,
+		},
+		{
+			name: "success: nothing to flush",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				mcr.EXPECT().ListPending(gomock.Any(), int64(100)).Return(nil, nil)
+			},
+			want:    0,
+			wantErr: nil,
+		},
+		{
+			name: "success: retry after transient failure",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				mcr.EXPECT().ListPending(gomock.Any(), int64(100)).Return(pending, nil)
+				gomock.InOrder(
+					mmr.EXPECT().BatchCreate(gomock.Any(), []entity.Message{message}).Return(fmt.Errorf("connection refused")),
+					mmr.EXPECT().BatchCreate(gomock.Any(), []entity.Message{message}).Return(nil),
+				)
+				mmr.EXPECT().BatchDelete(gomock.Any(), []string{deletedID}).Return(nil)
+				mcr.EXPECT().AckPending(gomock.Any(), pending).Return(nil)
+			},
+			want:    2,
+			wantErr: nil,
+		},
+		{
+			name: "success: a rejected row is dead lettered without retrying",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				fkErr := fmt.Errorf("%w: foreign key constraint fails", repository.ErrMessageRejected)
+				mcr.EXPECT().ListPending(gomock.Any(), int64(100)).Return(pending, nil)
+				mmr.EXPECT().BatchCreate(gomock.Any(), []entity.Message{message}).Return(fkErr)
+				mmr.EXPECT().BatchDelete(gomock.Any(), []string{deletedID}).Return(nil)
+				mcr.EXPECT().DeadLetter(gomock.Any(), []repository.DeadLetterMessage{
+					{PendingMessage: pending[0], Reason: fkErr.Error()},
+				}).Return(nil)
+				mcr.EXPECT().AckPending(gomock.Any(), pending[1:]).Return(nil)
+			},
+			want:    2,
+			wantErr: nil,
+		},
+		{
+			name: "success: a row rejected after batch failure is dead lettered",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				fkErr := fmt.Errorf("%w: foreign key constraint fails", repository.ErrMessageRejected)
+				mcr.EXPECT().ListPending(gomock.Any(), int64(100)).Return(withBadRow, nil)
+				mmr.EXPECT().BatchCreate(gomock.Any(), []entity.Message{message, badMessage}).Return(fkErr)
+				mmr.EXPECT().BatchCreate(gomock.Any(), []entity.Message{message}).Return(nil)
+				mmr.EXPECT().BatchCreate(gomock.Any(), []entity.Message{badMessage}).Return(fkErr)
+				mmr.EXPECT().BatchDelete(gomock.Any(), []string{deletedID}).Return(nil)
+				mcr.EXPECT().DeadLetter(gomock.Any(), []repository.DeadLetterMessage{
+					{PendingMessage: withBadRow[1], Reason: fkErr.Error()},
+				}).Return(nil)
+				mcr.EXPECT().AckPending(gomock.Any(), pending).Return(nil)
+			},
+			want:    3,
+			wantErr: nil,
+		},
+		{
+			name: "Fail: a transient failure while retrying one by one keeps the rest pending",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				fkErr := fmt.Errorf("%w: foreign key constraint fails", repository.ErrMessageRejected)
+				mcr.EXPECT().ListPending(gomock.Any(), int64(100)).Return(withBadRow, nil)
+				mmr.EXPECT().BatchCreate(gomock.Any(), []entity.Message{message, badMessage}).Return(fkErr)
+				mmr.EXPECT().BatchCreate(gomock.Any(), []entity.Message{message}).
+					Return(fmt.Errorf("connection refused")).Times(3)
+				mmr.EXPECT().BatchDelete(gomock.Any(), []string{deletedID}).Return(nil)
+				mcr.EXPECT().AckPending(gomock.Any(), pending[1:]).Return(nil)
+			},
+			want:    1,
+			wantErr: fmt.Errorf("connection refused"),
+		},
+		{
+			name: "Fail: transient failures are kept pending while others are acked",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				mcr.EXPECT().ListPending(gomock.Any(), int64(100)).Return(pending, nil)
+				mmr.EXPECT().BatchCreate(gomock.Any(), []entity.Message{message}).
+					Return(fmt.Errorf("connection refused")).Times(3)
+				mmr.EXPECT().BatchDelete(gomock.Any(), []string{deletedID}).Return(nil)
+				mcr.EXPECT().AckPending(gomock.Any(), pending[1:]).Return(nil)
+			},
+			want:    1,
+			wantErr: fmt.Errorf("connection refused"),
+		},
+		{
+			name: "success: broken cache entries are dead lettered",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				mcr.EXPECT().ListPending(gomock.Any(), int64(100)).Return(broken, nil)
+				mcr.EXPECT().DeadLetter(gomock.Any(), []repository.DeadLetterMessage{
+					{PendingMessage: broken[0], Reason: "missing from cache"},
+					{PendingMessage: broken[1], Reason: "no channel"},
+				}).Return(nil)
+				mmr.EXPECT().BatchCreate(gomock.Any(), []entity.Message{message}).Return(nil)
+				mcr.EXPECT().AckPending(gomock.Any(), broken[2:]).Return(nil)
+			},
+			want:    3,
+			wantErr: nil,
+		},
+		{
+			name: "Fail: nothing persisted, pending messages are kept",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				mcr.EXPECT().ListPending(gomock.Any(), int64(100)).Return(pending, nil)
+				mmr.EXPECT().BatchCreate(gomock.Any(), []entity.Message{message}).
+					Return(fmt.Errorf("connection refused")).Times(3)
+				mmr.EXPECT().BatchDelete(gomock.Any(), []string{deletedID}).
+					Return(fmt.Errorf("connection refused")).Times(3)
+			},
+			want:    0,
+			wantErr: fmt.Errorf("connection refused"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			mr := mock.NewMockMessageRepository(ctrl)
+			mcr := mock.NewMockMessageCacheRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(mr, mcr)
+			}
+
+			flusher := NewMessageFlusher(mr, mcr, &config.FlusherConfig{
+				BatchSize:    100,
+				MaxRetries:   2,
+				RetryBackoff: time.Millisecond,
+				RetryBudget:  time.Second,
+			})
+
+			got, err := flusher.Flush(context.Background())
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("Flush() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("Flush() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Flush() got = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageFlusher_Flush_RetryBudget(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	mr := mock.NewMockMessageRepository(ctrl)
+	mcr := mock.NewMockMessageCacheRepository(ctrl)
+
+	message := entity.Message{
+		ID:        uuid.New().String(),
+		ChannelID: uuid.New().String(),
+		Text:      "test message",
+		CreatedAt: time.Now(),
+	}
+	pending := []repository.PendingMessage{{ID: message.ID, Message: &message, QueuedAt: time.Now()}}
+
+	// 再試行に使える時間を使い切った場合は、MaxRetriesに達していなくても次回に持ち越します。
+	mcr.EXPECT().ListPending(gomock.Any(), int64(100)).Return(pending, nil)
+	mr.EXPECT().BatchCreate(gomock.Any(), []entity.Message{message}).Return(fmt.Errorf("connection refused")).Times(1)
+
+	flusher := NewMessageFlusher(mr, mcr, &config.FlusherConfig{
+		BatchSize:    100,
+		MaxRetries:   10,
+		RetryBackoff: time.Hour,
+		RetryBudget:  time.Second,
+	})
+
+	got, err := flusher.Flush(context.Background())
+	if err == nil || err.Error() != "connection refused" {
+		t.Errorf("Flush() error = %v, want connection refused", err)
+	}
+	if got != 0 {
+		t.Errorf("Flush() got = %d, want 0", got)
+	}
+}
+
+func TestMessageFlusher_Lag(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			mcr *mock.MockMessageCacheRepository,
+		)
+		wantPending     int64
+		wantDeadLetters int64
+		wantBehindGE    time.Duration
+		wantErr         error
+	}{
+		{
+			name: "success",
+			setup: func(mcr *mock.MockMessageCacheRepository) {
+				mcr.EXPECT().PendingStats(gomock.Any()).Return(&repository.PendingStats{
+					Count:          5,
+					OldestQueuedAt: time.Now().Add(-1 * time.Minute),
+					DeadLetters:    2,
+				}, nil)
+			},
+			wantPending:     5,
+			wantDeadLetters: 2,
+			wantBehindGE:    time.Minute,
+			wantErr:         nil,
+		},
+		{
+			name: "success: up to date",
+			setup: func(mcr *mock.MockMessageCacheRepository) {
+				mcr.EXPECT().PendingStats(gomock.Any()).Return(&repository.PendingStats{}, nil)
+			},
+			wantPending:  0,
+			wantBehindGE: 0,
+			wantErr:      nil,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			mr := mock.NewMockMessageRepository(ctrl)
+			mcr := mock.NewMockMessageCacheRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(mcr)
+			}
+
+			flusher := NewMessageFlusher(mr, mcr, &config.FlusherConfig{BatchSize: 100})
+
+			got, err := flusher.Lag(context.Background())
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("Lag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if got.Pending != tt.wantPending {
+					t.Errorf("Lag() pending = %d, want %d", got.Pending, tt.wantPending)
+				}
+				if got.DeadLetters != tt.wantDeadLetters {
+					t.Errorf("Lag() dead letters = %d, want %d", got.DeadLetters, tt.wantDeadLetters)
+				}
+				if got.Behind < tt.wantBehindGE {
+					t.Errorf("Lag() behind = %v, want >= %v", got.Behind, tt.wantBehindGE)
+				}
+			}
+		})
+	}
+}
+
+func TestMessageFlusher_RequeueDeadLetters(t *testing.T) {
+	t.Parallel()
+	messageID := uuid.New().String()
+
+	patterns := []struct {
+		name    string
+		setup   func(mcr *mock.MockMessageCacheRepository)
+		ids     []string
+		want    int
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(mcr *mock.MockMessageCacheRepository) {
+				mcr.EXPECT().RequeueDeadLetters(gomock.Any(), []string{messageID}).Return(1, nil)
+			},
+			ids:     []string{messageID},
+			want:    1,
+			wantErr: nil,
+		},
+		{
+			name: "Fail: cache unavailable",
+			setup: func(mcr *mock.MockMessageCacheRepository) {
+				mcr.EXPECT().RequeueDeadLetters(gomock.Any(), nil).Return(0, fmt.Errorf("connection refused"))
+			},
+			ids:     nil,
+			want:    0,
+			wantErr: fmt.Errorf("connection refused"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			mr := mock.NewMockMessageRepository(ctrl)
+			mcr := mock.NewMockMessageCacheRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(mcr)
+			}
+
+			flusher := NewMessageFlusher(mr, mcr, &config.FlusherConfig{BatchSize: 100})
+
+			got, err := flusher.RequeueDeadLetters(context.Background(), tt.ids)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("RequeueDeadLetters() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("RequeueDeadLetters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RequeueDeadLetters() got = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
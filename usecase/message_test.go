@@ -2,14 +2,18 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 
+	"github.com/tusmasoma/connectHub-backend/config"
 	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository"
 	"github.com/tusmasoma/connectHub-backend/repository/mock"
 )
 
@@ -17,9 +21,42 @@ func TestMessageUseCase_ListMessages(t *testing.T) {
 	t.Parallel()
 	channelID := uuid.New().String()
 	membershipID := uuid.New().String()
-	start := time.Now().Add(-1 * time.Hour)
+	start := time.Now().Add(-30 * 24 * time.Hour)
 	end := time.Now().Add(1 * time.Hour)
 
+	cold := entity.Message{
+		ID:           "0a4a1bb4-6c2d-4a0e-9b8c-6f2b3c0a9c10",
+		MembershipID: membershipID,
+		ChannelID:    channelID,
+		Text:         "cold message",
+		CreatedAt:    time.Now().Add(-20 * 24 * time.Hour).Truncate(time.Second),
+	}
+	hot := entity.Message{
+		ID:           "1b5b2cc5-7d3e-4b1f-8c9d-7a3c4d1b0d21",
+		MembershipID: membershipID,
+		ChannelID:    channelID,
+		Text:         "hot message",
+		CreatedAt:    time.Now().Add(-1 * time.Hour).Truncate(time.Second),
+	}
+	edited := entity.Message{
+		ID:           "31894386-3e60-45a8-bc67-f46b72b42554",
+		MembershipID: membershipID,
+		ChannelID:    channelID,
+		Text:         "edited message",
+		CreatedAt:    time.Now().Add(-30 * time.Minute).Truncate(time.Second),
+	}
+	stale := edited
+	stale.Text = "test message"
+	covered := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	coveredBeforeStart := start.Add(-time.Hour)
+	deleted := entity.Message{
+		ID:           "4c6c3dd6-8e4f-4c20-9dae-8b4d5e2c1e32",
+		MembershipID: membershipID,
+		ChannelID:    channelID,
+		Text:         "deleted message",
+		CreatedAt:    time.Now().Add(-10 * time.Minute).Truncate(time.Second),
+	}
+
 	patterns := []struct {
 		name  string
 		setup func(
@@ -33,6 +70,7 @@ func TestMessageUseCase_ListMessages(t *testing.T) {
 			start     time.Time
 			end       time.Time
 		}
+		want    []entity.Message
 		wantErr error
 	}{
 		{
@@ -42,14 +80,24 @@ func TestMessageUseCase_ListMessages(t *testing.T) {
 				mmr *mock.MockMessageRepository,
 				mcr *mock.MockMessageCacheRepository,
 			) {
-				mcr.EXPECT().List(gomock.Any(), channelID, start, end).Return([]entity.Message{
-					{
-						ID:           "31894386-3e60-45a8-bc67-f46b72b42554",
-						MembershipID: membershipID,
-						Text:         "test message",
-						CreatedAt:    time.Now(),
+				mcr.EXPECT().List(gomock.Any(), channelID, start, end).Return([]entity.Message{edited}, nil)
+				mcr.EXPECT().CoveredSince(gomock.Any(), channelID).Return(nil, nil)
+				mmr.EXPECT().ListChannelMessages(gomock.Any(), channelID, start, end).Return(
+					[]entity.Message{cold, hot, stale, deleted}, nil,
+				)
+				mcr.EXPECT().PendingDeletes(gomock.Any(), []string{cold.ID, hot.ID, deleted.ID}).Return(
+					map[string]bool{deleted.ID: true}, nil,
+				)
+				mcr.EXPECT().Fill(gomock.Any(), channelID, []entity.Message{hot}).Return(nil)
+				mcr.EXPECT().MarkCovered(gomock.Any(), channelID, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, since time.Time) error {
+						// 直近のメッセージしか載せ直さないため、それより古い範囲は網羅したことになりません。
+						if since.Before(time.Now().Add(-messageCacheHotWindow - time.Minute)) {
+							t.Errorf("MarkCovered() since = %v, want within hot window", since)
+						}
+						return nil
 					},
-				}, nil)
+				)
 			},
 			arg: struct {
 				ctx       context.Context
@@ -62,8 +110,144 @@ func TestMessageUseCase_ListMessages(t *testing.T) {
 				start:     start,
 				end:       end,
 			},
+			want:    []entity.Message{cold, hot, edited},
 			wantErr: nil,
 		},
+		{
+			name: "success: served from cache only",
+			setup: func(
+				mur *mock.MockMembershipRepository,
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				mcr.EXPECT().List(gomock.Any(), channelID, start, end).Return([]entity.Message{edited}, nil)
+				mcr.EXPECT().CoveredSince(gomock.Any(), channelID).Return(&coveredBeforeStart, nil)
+			},
+			arg: struct {
+				ctx       context.Context
+				channelID string
+				start     time.Time
+				end       time.Time
+			}{
+				ctx:       context.Background(),
+				channelID: channelID,
+				start:     start,
+				end:       end,
+			},
+			want:    []entity.Message{edited},
+			wantErr: nil,
+		},
+		{
+			name: "success: only the range not covered by cache is read from database",
+			setup: func(
+				mur *mock.MockMembershipRepository,
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				mcr.EXPECT().List(gomock.Any(), channelID, start, end).Return([]entity.Message{edited}, nil)
+				mcr.EXPECT().CoveredSince(gomock.Any(), channelID).Return(&covered, nil)
+				mmr.EXPECT().ListChannelMessages(gomock.Any(), channelID, start, covered).Return(
+					[]entity.Message{cold}, nil,
+				)
+				mcr.EXPECT().PendingDeletes(gomock.Any(), []string{cold.ID}).Return(map[string]bool{}, nil)
+				mcr.EXPECT().MarkCovered(gomock.Any(), channelID, gomock.Any()).Return(nil)
+			},
+			arg: struct {
+				ctx       context.Context
+				channelID string
+				start     time.Time
+				end       time.Time
+			}{
+				ctx:       context.Background(),
+				channelID: channelID,
+				start:     start,
+				end:       end,
+			},
+			want:    []entity.Message{cold, edited},
+			wantErr: nil,
+		},
+		{
+			name: "success: coverage is not extended when cache fill fails",
+			setup: func(
+				mur *mock.MockMembershipRepository,
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				mcr.EXPECT().List(gomock.Any(), channelID, start, end).Return(nil, nil)
+				mcr.EXPECT().CoveredSince(gomock.Any(), channelID).Return(nil, nil)
+				mmr.EXPECT().ListChannelMessages(gomock.Any(), channelID, start, end).Return(
+					[]entity.Message{hot}, nil,
+				)
+				mcr.EXPECT().PendingDeletes(gomock.Any(), []string{hot.ID}).Return(map[string]bool{}, nil)
+				mcr.EXPECT().Fill(gomock.Any(), channelID, []entity.Message{hot}).Return(fmt.Errorf("connection refused"))
+			},
+			arg: struct {
+				ctx       context.Context
+				channelID string
+				start     time.Time
+				end       time.Time
+			}{
+				ctx:       context.Background(),
+				channelID: channelID,
+				start:     start,
+				end:       end,
+			},
+			want:    []entity.Message{hot},
+			wantErr: nil,
+		},
+		{
+			name: "success: cache unavailable",
+			setup: func(
+				mur *mock.MockMembershipRepository,
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				mcr.EXPECT().List(gomock.Any(), channelID, start, end).Return(nil, fmt.Errorf("connection refused"))
+				mmr.EXPECT().ListChannelMessages(gomock.Any(), channelID, start, end).Return(
+					[]entity.Message{hot, cold}, nil,
+				)
+			},
+			arg: struct {
+				ctx       context.Context
+				channelID string
+				start     time.Time
+				end       time.Time
+			}{
+				ctx:       context.Background(),
+				channelID: channelID,
+				start:     start,
+				end:       end,
+			},
+			want:    []entity.Message{cold, hot},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: database unavailable",
+			setup: func(
+				mur *mock.MockMembershipRepository,
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				mcr.EXPECT().List(gomock.Any(), channelID, start, end).Return([]entity.Message{edited}, nil)
+				mcr.EXPECT().CoveredSince(gomock.Any(), channelID).Return(nil, nil)
+				mmr.EXPECT().ListChannelMessages(gomock.Any(), channelID, start, end).Return(
+					nil, fmt.Errorf("connection refused"),
+				)
+			},
+			arg: struct {
+				ctx       context.Context
+				channelID string
+				start     time.Time
+				end       time.Time
+			}{
+				ctx:       context.Background(),
+				channelID: channelID,
+				start:     start,
+				end:       end,
+			},
+			want:    nil,
+			wantErr: fmt.Errorf("connection refused"),
+		},
 	}
 	for _, tt := range patterns {
 		tt := tt
@@ -73,14 +257,19 @@ func TestMessageUseCase_ListMessages(t *testing.T) {
 			ur := mock.NewMockMembershipRepository(ctrl)
 			mr := mock.NewMockMessageRepository(ctrl)
 			mcr := mock.NewMockMessageCacheRepository(ctrl)
+			rr := mock.NewMockReactionRepository(ctrl)
+			rcr := mock.NewMockReactionCacheRepository(ctrl)
+			nr := mock.NewMockNotificationRepository(ctrl)
+			ir := mock.NewMockIdempotencyRepository(ctrl)
+			cacheNoReactions(rcr)
 
 			if tt.setup != nil {
 				tt.setup(ur, mr, mcr)
 			}
 
-			usecase := NewMessageUseCase(ur, mr, mcr)
+			usecase := NewMessageUseCase(ur, mr, mcr, rr, rcr, nr, ir, nil, &config.MessageConfig{IdempotencyWindow: time.Hour})
 
-			_, err := usecase.ListMessages(
+			got, err := usecase.ListMessages(
 				tt.arg.ctx,
 				tt.arg.channelID,
 				tt.arg.start,
@@ -92,6 +281,9 @@ func TestMessageUseCase_ListMessages(t *testing.T) {
 			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
 				t.Errorf("MessageList() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MessageList() got = %v, want %v", got, tt.want)
+			}
 		})
 	}
 }
@@ -99,44 +291,239 @@ func TestMessageUseCase_ListMessages(t *testing.T) {
 func TestMessageUseCase_CreateMessage(t *testing.T) {
 	t.Parallel()
 	channelID := "f6bd2530-cd9b-4ac1-8dc1-38c697e6cce2"
+	senderID := "sender_workspace"
 	message := entity.Message{
-		ID:   "31894386-3e60-45a8-bc67-f46b72b42554",
-		Text: "test message",
+		ID:           "31894386-3e60-45a8-bc67-f46b72b42554",
+		MembershipID: senderID,
+		Text:         "test message",
+	}
+	cachedMessage := message
+	cachedMessage.ChannelID = channelID
+	mentionMessage := message
+	mentionMessage.Text = "@Jane please review, @here"
+	cachedMentionMessage := mentionMessage
+	cachedMentionMessage.ChannelID = channelID
+	memberships := []entity.Membership{
+		{ID: senderID, Name: "John"},
+		{ID: "jane_workspace", Name: "Jane"},
+		{ID: "alice_workspace", Name: "Alice"},
+		{ID: "bob_workspace", Name: "Bob"},
 	}
 
 	patterns := []struct {
 		name  string
 		setup func(
 			mur *mock.MockMembershipRepository,
-			mmr *mock.MockMessageRepository,
 			mcr *mock.MockMessageCacheRepository,
+			nr *mock.MockNotificationRepository,
+			ir *mock.MockIdempotencyRepository,
 		)
 		arg struct {
-			ctx       context.Context
-			channelID string
-			message   entity.Message
+			ctx     context.Context
+			message entity.Message
+			key     string
+			online  []string
 		}
-		wantErr error
+		want          []string // 通知の受信者と種類
+		wantMessageID string
+		wantDuplicate bool
+		wantErr       error
 	}{
 		{
 			name: "success",
 			setup: func(
 				mur *mock.MockMembershipRepository,
-				mmr *mock.MockMessageRepository,
 				mcr *mock.MockMessageCacheRepository,
+				nr *mock.MockNotificationRepository,
+				ir *mock.MockIdempotencyRepository,
 			) {
-				mcr.EXPECT().Create(gomock.Any(), channelID, message).Return(nil)
+				mcr.EXPECT().Create(gomock.Any(), channelID, cachedMessage).Return(nil)
 			},
 			arg: struct {
-				ctx       context.Context
-				channelID string
-				message   entity.Message
+				ctx     context.Context
+				message entity.Message
+				key     string
+				online  []string
 			}{
-				ctx:       context.Background(),
-				channelID: channelID,
-				message:   message,
+				ctx:     context.Background(),
+				message: message,
 			},
-			wantErr: nil,
+			want:          nil,
+			wantMessageID: message.ID,
+			wantErr:       nil,
+		},
+		{
+			name: "success: mentions",
+			setup: func(
+				mur *mock.MockMembershipRepository,
+				mcr *mock.MockMessageCacheRepository,
+				nr *mock.MockNotificationRepository,
+				ir *mock.MockIdempotencyRepository,
+			) {
+				mcr.EXPECT().Create(gomock.Any(), channelID, cachedMentionMessage).Return(nil)
+				mur.EXPECT().ListChannelMemberships(gomock.Any(), channelID).Return(memberships, nil)
+				nr.EXPECT().BatchCreate(gomock.Any(), gomock.Len(2)).Return(nil)
+			},
+			arg: struct {
+				ctx     context.Context
+				message entity.Message
+				key     string
+				online  []string
+			}{
+				ctx:     context.Background(),
+				message: mentionMessage,
+				online:  []string{senderID, "jane_workspace", "alice_workspace"},
+			},
+			want: []string{
+				"jane_workspace:" + entity.MentionKindMembership,
+				"alice_workspace:" + entity.MentionKindHere,
+			},
+			wantMessageID: message.ID,
+			wantErr:       nil,
+		},
+		{
+			name: "success: failing to notify does not fail the message",
+			setup: func(
+				mur *mock.MockMembershipRepository,
+				mcr *mock.MockMessageCacheRepository,
+				nr *mock.MockNotificationRepository,
+				ir *mock.MockIdempotencyRepository,
+			) {
+				mcr.EXPECT().Create(gomock.Any(), channelID, cachedMentionMessage).Return(nil)
+				mur.EXPECT().ListChannelMemberships(gomock.Any(), channelID).Return(nil, fmt.Errorf("database error"))
+			},
+			arg: struct {
+				ctx     context.Context
+				message entity.Message
+				key     string
+				online  []string
+			}{
+				ctx:     context.Background(),
+				message: mentionMessage,
+			},
+			want:          nil,
+			wantMessageID: message.ID,
+			wantErr:       nil,
+		},
+		{
+			name: "Fail: failed to cache message",
+			setup: func(
+				mur *mock.MockMembershipRepository,
+				mcr *mock.MockMessageCacheRepository,
+				nr *mock.MockNotificationRepository,
+				ir *mock.MockIdempotencyRepository,
+			) {
+				mcr.EXPECT().Create(gomock.Any(), channelID, cachedMentionMessage).Return(fmt.Errorf("redis error"))
+			},
+			arg: struct {
+				ctx     context.Context
+				message entity.Message
+				key     string
+				online  []string
+			}{
+				ctx:     context.Background(),
+				message: mentionMessage,
+			},
+			want:    nil,
+			wantErr: fmt.Errorf("redis error"),
+		},
+		{
+			name: "success: reserves idempotency key",
+			setup: func(
+				mur *mock.MockMembershipRepository,
+				mcr *mock.MockMessageCacheRepository,
+				nr *mock.MockNotificationRepository,
+				ir *mock.MockIdempotencyRepository,
+			) {
+				ir.EXPECT().Reserve(gomock.Any(), senderID+":client-key", message.ID, time.Hour).Return("", nil)
+				mcr.EXPECT().Create(gomock.Any(), channelID, cachedMessage).Return(nil)
+			},
+			arg: struct {
+				ctx     context.Context
+				message entity.Message
+				key     string
+				online  []string
+			}{
+				ctx:     context.Background(),
+				message: message,
+				key:     "client-key",
+			},
+			want:          nil,
+			wantMessageID: message.ID,
+			wantErr:       nil,
+		},
+		{
+			name: "success: retry returns the first message ID",
+			setup: func(
+				mur *mock.MockMembershipRepository,
+				mcr *mock.MockMessageCacheRepository,
+				nr *mock.MockNotificationRepository,
+				ir *mock.MockIdempotencyRepository,
+			) {
+				ir.EXPECT().Reserve(gomock.Any(), senderID+":client-key", message.ID, time.Hour).Return("first-message-id", nil)
+			},
+			arg: struct {
+				ctx     context.Context
+				message entity.Message
+				key     string
+				online  []string
+			}{
+				ctx:     context.Background(),
+				message: mentionMessage,
+				key:     "client-key",
+			},
+			want:          nil,
+			wantMessageID: "first-message-id",
+			wantDuplicate: true,
+			wantErr:       nil,
+		},
+		{
+			name: "Fail: releases idempotency key when failed to cache message",
+			setup: func(
+				mur *mock.MockMembershipRepository,
+				mcr *mock.MockMessageCacheRepository,
+				nr *mock.MockNotificationRepository,
+				ir *mock.MockIdempotencyRepository,
+			) {
+				ir.EXPECT().Reserve(gomock.Any(), senderID+":client-key", message.ID, time.Hour).Return("", nil)
+				mcr.EXPECT().Create(gomock.Any(), channelID, cachedMessage).Return(fmt.Errorf("redis error"))
+				ir.EXPECT().Release(gomock.Any(), senderID+":client-key").Return(nil)
+			},
+			arg: struct {
+				ctx     context.Context
+				message entity.Message
+				key     string
+				online  []string
+			}{
+				ctx:     context.Background(),
+				message: message,
+				key:     "client-key",
+			},
+			want:    nil,
+			wantErr: fmt.Errorf("redis error"),
+		},
+		{
+			name: "Fail: failed to reserve idempotency key",
+			setup: func(
+				mur *mock.MockMembershipRepository,
+				mcr *mock.MockMessageCacheRepository,
+				nr *mock.MockNotificationRepository,
+				ir *mock.MockIdempotencyRepository,
+			) {
+				ir.EXPECT().Reserve(gomock.Any(), senderID+":client-key", message.ID, time.Hour).Return("", fmt.Errorf("redis error"))
+			},
+			arg: struct {
+				ctx     context.Context
+				message entity.Message
+				key     string
+				online  []string
+			}{
+				ctx:     context.Background(),
+				message: message,
+				key:     "client-key",
+			},
+			want:    nil,
+			wantErr: fmt.Errorf("redis error"),
 		},
 	}
 	for _, tt := range patterns {
@@ -147,17 +534,23 @@ func TestMessageUseCase_CreateMessage(t *testing.T) {
 			ur := mock.NewMockMembershipRepository(ctrl)
 			mr := mock.NewMockMessageRepository(ctrl)
 			mcr := mock.NewMockMessageCacheRepository(ctrl)
+			rr := mock.NewMockReactionRepository(ctrl)
+			rcr := mock.NewMockReactionCacheRepository(ctrl)
+			nr := mock.NewMockNotificationRepository(ctrl)
+			ir := mock.NewMockIdempotencyRepository(ctrl)
 
 			if tt.setup != nil {
-				tt.setup(ur, mr, mcr)
+				tt.setup(ur, mcr, nr, ir)
 			}
 
-			usecase := NewMessageUseCase(ur, mr, mcr)
+			usecase := NewMessageUseCase(ur, mr, mcr, rr, rcr, nr, ir, nil, &config.MessageConfig{IdempotencyWindow: time.Hour})
 
-			err := usecase.CreateMessage(
+			created, err := usecase.CreateMessage(
 				tt.arg.ctx,
-				tt.arg.channelID,
+				channelID,
 				tt.arg.message,
+				tt.arg.key,
+				tt.arg.online,
 			)
 
 			if (err != nil) != (tt.wantErr != nil) {
@@ -165,6 +558,140 @@ func TestMessageUseCase_CreateMessage(t *testing.T) {
 			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
 				t.Errorf("MessageCreate() error = %v, wantErr %v", err, tt.wantErr)
 			}
+
+			if err != nil {
+				return
+			}
+			if created.MessageID != tt.wantMessageID || created.Duplicate != tt.wantDuplicate {
+				t.Errorf(
+					"MessageCreate() = (%s, %v), want (%s, %v)",
+					created.MessageID, created.Duplicate, tt.wantMessageID, tt.wantDuplicate,
+				)
+			}
+
+			var got []string
+			for _, notification := range created.Notifications {
+				if notification.MessageID != message.ID || notification.SenderID != senderID {
+					t.Errorf("MessageCreate() notification = %+v, want message %s from %s", notification, message.ID, senderID)
+				}
+				got = append(got, notification.MembershipID+":"+notification.Kind)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MessageCreate() notifications = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageUseCase_CreateMessage_Attachments(t *testing.T) {
+	t.Parallel()
+	channelID := "f6bd2530-cd9b-4ac1-8dc1-38c697e6cce2"
+	senderID := "sender_workspace"
+	messageID := "31894386-3e60-45a8-bc67-f46b72b42554"
+	uploaded := entity.Attachment{
+		ID:           "5b1f4a43-54f1-4f2c-a6c3-2f4f1c1f3c2a",
+		ChannelID:    channelID,
+		MembershipID: senderID,
+		Name:         "report.pdf",
+		Size:         1024,
+		MimeType:     "application/pdf",
+	}
+	message := entity.Message{
+		ID:           messageID,
+		MembershipID: senderID,
+		Text:         "see attached",
+		Attachments:  entity.Attachments{{ID: uploaded.ID}},
+	}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			mcr *mock.MockMessageCacheRepository,
+			ar *mock.MockAttachmentRepository,
+		)
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(mcr *mock.MockMessageCacheRepository, ar *mock.MockAttachmentRepository) {
+				ar.EXPECT().ListByIDs(gomock.Any(), []string{uploaded.ID}).Return([]entity.Attachment{uploaded}, nil)
+				ar.EXPECT().AttachToMessage(gomock.Any(), messageID, senderID, []string{uploaded.ID}).Return(int64(1), nil)
+				mcr.EXPECT().Create(gomock.Any(), channelID, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, cached entity.Message) error {
+						if len(cached.Attachments) != 1 || cached.Attachments[0].Name != uploaded.Name || cached.Attachments[0].MessageID != messageID {
+							t.Errorf("Create() attachments = %+v, want %s attached to %s", cached.Attachments, uploaded.Name, messageID)
+						}
+						return nil
+					},
+				)
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: attachment uploaded by another membership",
+			setup: func(mcr *mock.MockMessageCacheRepository, ar *mock.MockAttachmentRepository) {
+				other := uploaded
+				other.MembershipID = "other_workspace"
+				ar.EXPECT().ListByIDs(gomock.Any(), []string{uploaded.ID}).Return([]entity.Attachment{other}, nil)
+			},
+			wantErr: ErrAttachmentUnavailable,
+		},
+		{
+			name: "Fail: attachment already attached",
+			setup: func(mcr *mock.MockMessageCacheRepository, ar *mock.MockAttachmentRepository) {
+				attached := uploaded
+				attached.MessageID = "another-message"
+				ar.EXPECT().ListByIDs(gomock.Any(), []string{uploaded.ID}).Return([]entity.Attachment{attached}, nil)
+			},
+			wantErr: ErrAttachmentUnavailable,
+		},
+		{
+			name: "Fail: attachment claimed concurrently",
+			setup: func(mcr *mock.MockMessageCacheRepository, ar *mock.MockAttachmentRepository) {
+				ar.EXPECT().ListByIDs(gomock.Any(), []string{uploaded.ID}).Return([]entity.Attachment{uploaded}, nil)
+				ar.EXPECT().AttachToMessage(gomock.Any(), messageID, senderID, []string{uploaded.ID}).Return(int64(0), nil)
+				ar.EXPECT().DetachFromMessage(gomock.Any(), messageID).Return(nil)
+			},
+			wantErr: ErrAttachmentUnavailable,
+		},
+		{
+			name: "Fail: releases attachments when failed to cache message",
+			setup: func(mcr *mock.MockMessageCacheRepository, ar *mock.MockAttachmentRepository) {
+				ar.EXPECT().ListByIDs(gomock.Any(), []string{uploaded.ID}).Return([]entity.Attachment{uploaded}, nil)
+				ar.EXPECT().AttachToMessage(gomock.Any(), messageID, senderID, []string{uploaded.ID}).Return(int64(1), nil)
+				mcr.EXPECT().Create(gomock.Any(), channelID, gomock.Any()).Return(fmt.Errorf("redis error"))
+				ar.EXPECT().DetachFromMessage(gomock.Any(), messageID).Return(nil)
+			},
+			wantErr: fmt.Errorf("redis error"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			mcr := mock.NewMockMessageCacheRepository(ctrl)
+			ar := mock.NewMockAttachmentRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(mcr, ar)
+			}
+
+			usecase := NewMessageUseCase(nil, nil, mcr, nil, nil, nil, nil, ar, &config.MessageConfig{IdempotencyWindow: time.Hour})
+
+			created, err := usecase.CreateMessage(context.Background(), channelID, message, "", nil)
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Fatalf("CreateMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error() {
+					t.Errorf("CreateMessage() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if len(created.Message.Attachments) != 1 || created.Message.Attachments[0].MimeType != uploaded.MimeType {
+				t.Errorf("CreateMessage() attachments = %+v, want %+v", created.Message.Attachments, uploaded)
+			}
 		})
 	}
 }
@@ -174,12 +701,23 @@ func TestMessageUseCase_UpdateMessage(t *testing.T) {
 	membershipID := uuid.New().String()
 	superMembershipID := uuid.New().String()
 	notAuthorizedMembershipID := uuid.New().String()
+	channelID := uuid.New().String()
 	msgID := uuid.New().String()
-	message := entity.Message{
+	stored := entity.Message{
 		ID:           msgID,
 		MembershipID: membershipID,
+		ChannelID:    channelID,
 		Text:         "test message",
+		CreatedAt:    time.Now().Add(-1 * time.Hour).Truncate(time.Second),
+	}
+	edit := entity.Message{
+		ID:   msgID,
+		Text: "edited message",
 	}
+	// 作成者を自分に書き換えて他人のメッセージを編集しようとするリクエストです。
+	forged := edit
+	forged.MembershipID = notAuthorizedMembershipID
+	forged.ChannelID = uuid.New().String()
 
 	patterns := []struct {
 		name  string
@@ -202,14 +740,20 @@ func TestMessageUseCase_UpdateMessage(t *testing.T) {
 				mmr *mock.MockMessageRepository,
 				mcr *mock.MockMessageCacheRepository,
 			) {
+				mcr.EXPECT().Get(gomock.Any(), msgID).Return(&stored, nil)
 				mur.EXPECT().Get(gomock.Any(), membershipID).
 					Return(&entity.Membership{
-						UserID:      uuid.New().String(),
-						WorkspaceID: uuid.New().String(),
-						Name:        "test",
-						IsAdmin:     false,
+						ID:      membershipID,
+						Name:    "test",
+						IsAdmin: false,
 					}, nil)
-				mcr.EXPECT().Update(gomock.Any(), message).Return(nil)
+				mcr.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, message entity.Message) error {
+					if message.Text != edit.Text || message.MembershipID != membershipID || message.ChannelID != channelID ||
+						!message.CreatedAt.Equal(stored.CreatedAt) || message.UpdatedAt == nil {
+						t.Errorf("Update() message = %+v", message)
+					}
+					return nil
+				})
 			},
 			arg: struct {
 				ctx          context.Context
@@ -217,7 +761,7 @@ func TestMessageUseCase_UpdateMessage(t *testing.T) {
 				membershipID string
 			}{
 				ctx:          context.Background(),
-				message:      message,
+				message:      edit,
 				membershipID: membershipID,
 			},
 			wantErr: nil,
@@ -229,14 +773,14 @@ func TestMessageUseCase_UpdateMessage(t *testing.T) {
 				mmr *mock.MockMessageRepository,
 				mcr *mock.MockMessageCacheRepository,
 			) {
+				mcr.EXPECT().Get(gomock.Any(), msgID).Return(&stored, nil)
 				mur.EXPECT().Get(gomock.Any(), superMembershipID).
 					Return(&entity.Membership{
-						UserID:      uuid.New().String(),
-						WorkspaceID: uuid.New().String(),
-						Name:        "super_test",
-						IsAdmin:     true,
+						ID:      superMembershipID,
+						Name:    "super_test",
+						IsAdmin: true,
 					}, nil)
-				mcr.EXPECT().Update(gomock.Any(), message).Return(nil)
+				mcr.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
 			},
 			arg: struct {
 				ctx          context.Context
@@ -244,24 +788,24 @@ func TestMessageUseCase_UpdateMessage(t *testing.T) {
 				membershipID string
 			}{
 				ctx:          context.Background(),
-				message:      message,
+				message:      edit,
 				membershipID: superMembershipID,
 			},
 			wantErr: nil,
 		},
 		{
-			name: "Fail: Not authorized to update",
+			name: "Fail: forged membershipID in payload",
 			setup: func(
 				mur *mock.MockMembershipRepository,
 				mmr *mock.MockMessageRepository,
 				mcr *mock.MockMessageCacheRepository,
 			) {
+				mcr.EXPECT().Get(gomock.Any(), msgID).Return(&stored, nil)
 				mur.EXPECT().Get(gomock.Any(), notAuthorizedMembershipID).
 					Return(&entity.Membership{
-						UserID:      uuid.New().String(),
-						WorkspaceID: uuid.New().String(),
-						Name:        "not_authorized_test",
-						IsAdmin:     false,
+						ID:      notAuthorizedMembershipID,
+						Name:    "not_authorized_test",
+						IsAdmin: false,
 					}, nil)
 			},
 			arg: struct {
@@ -270,10 +814,31 @@ func TestMessageUseCase_UpdateMessage(t *testing.T) {
 				membershipID string
 			}{
 				ctx:          context.Background(),
-				message:      message,
+				message:      forged,
 				membershipID: notAuthorizedMembershipID,
 			},
-			wantErr: fmt.Errorf("don't have permission to update msg"),
+			wantErr: fmt.Errorf("don't have permission to modify msg: permission denied"),
+		},
+		{
+			name: "Fail: message not found",
+			setup: func(
+				mur *mock.MockMembershipRepository,
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				mcr.EXPECT().Get(gomock.Any(), msgID).Return(nil, fmt.Errorf("redis: nil"))
+				mmr.EXPECT().Get(gomock.Any(), msgID).Return(nil, fmt.Errorf("sql: no rows in result set"))
+			},
+			arg: struct {
+				ctx          context.Context
+				message      entity.Message
+				membershipID string
+			}{
+				ctx:          context.Background(),
+				message:      edit,
+				membershipID: membershipID,
+			},
+			wantErr: fmt.Errorf("message not found"),
 		},
 	}
 	for _, tt := range patterns {
@@ -284,14 +849,18 @@ func TestMessageUseCase_UpdateMessage(t *testing.T) {
 			ur := mock.NewMockMembershipRepository(ctrl)
 			mr := mock.NewMockMessageRepository(ctrl)
 			mcr := mock.NewMockMessageCacheRepository(ctrl)
+			rr := mock.NewMockReactionRepository(ctrl)
+			rcr := mock.NewMockReactionCacheRepository(ctrl)
+			nr := mock.NewMockNotificationRepository(ctrl)
+			ir := mock.NewMockIdempotencyRepository(ctrl)
 
 			if tt.setup != nil {
 				tt.setup(ur, mr, mcr)
 			}
 
-			usecase := NewMessageUseCase(ur, mr, mcr)
+			usecase := NewMessageUseCase(ur, mr, mcr, rr, rcr, nr, ir, nil, &config.MessageConfig{IdempotencyWindow: time.Hour})
 
-			err := usecase.UpdateMessage(
+			got, err := usecase.UpdateMessage(
 				tt.arg.ctx,
 				tt.arg.message,
 				tt.arg.membershipID,
@@ -302,6 +871,9 @@ func TestMessageUseCase_UpdateMessage(t *testing.T) {
 			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
 				t.Errorf("MessageUpdate() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if err == nil && (got.ChannelID != channelID || got.MembershipID != membershipID || got.Text != edit.Text) {
+				t.Errorf("MessageUpdate() got = %+v", got)
+			}
 		})
 	}
 }
@@ -313,11 +885,15 @@ func TestMessageUseCase_DeleteMessage(t *testing.T) {
 	notAuthorizedMembershipID := uuid.New().String()
 	channelID := uuid.New().String()
 	msgID := uuid.New().String()
-	message := entity.Message{
+	stored := entity.Message{
 		ID:           msgID,
 		MembershipID: membershipID,
+		ChannelID:    channelID,
 		Text:         "test message",
 	}
+	parentID := uuid.New().String()
+	reply := stored
+	reply.ParentID = parentID
 
 	patterns := []struct {
 		name  string
@@ -328,10 +904,10 @@ func TestMessageUseCase_DeleteMessage(t *testing.T) {
 		)
 		arg struct {
 			ctx          context.Context
-			message      entity.Message
+			messageID    string
 			membershipID string
-			channelID    string
 		}
+		want    *entity.Message
 		wantErr error
 	}{
 		{
@@ -341,26 +917,54 @@ func TestMessageUseCase_DeleteMessage(t *testing.T) {
 				mmr *mock.MockMessageRepository,
 				mcr *mock.MockMessageCacheRepository,
 			) {
+				mcr.EXPECT().Get(gomock.Any(), msgID).Return(&stored, nil)
 				mur.EXPECT().Get(gomock.Any(), membershipID).
 					Return(&entity.Membership{
-						UserID:      uuid.New().String(),
-						WorkspaceID: uuid.New().String(),
-						Name:        "test",
-						IsAdmin:     false,
+						ID:      membershipID,
+						Name:    "test",
+						IsAdmin: false,
 					}, nil)
 				mcr.EXPECT().Delete(gomock.Any(), channelID, msgID).Return(nil)
 			},
 			arg: struct {
 				ctx          context.Context
-				message      entity.Message
+				messageID    string
 				membershipID string
-				channelID    string
 			}{
 				ctx:          context.Background(),
-				message:      message,
+				messageID:    msgID,
 				membershipID: membershipID,
-				channelID:    channelID,
 			},
+			want:    &stored,
+			wantErr: nil,
+		},
+		{
+			name: "success: thread reply",
+			setup: func(
+				mur *mock.MockMembershipRepository,
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				mcr.EXPECT().Get(gomock.Any(), msgID).Return(&reply, nil)
+				mur.EXPECT().Get(gomock.Any(), membershipID).
+					Return(&entity.Membership{
+						ID:      membershipID,
+						Name:    "test",
+						IsAdmin: false,
+					}, nil)
+				mcr.EXPECT().Get(gomock.Any(), parentID).Return(&entity.Message{ID: parentID, ReplyCount: 1}, nil)
+				mcr.EXPECT().DeleteReply(gomock.Any(), parentID, msgID).Return(&entity.Message{ID: parentID}, nil)
+			},
+			arg: struct {
+				ctx          context.Context
+				messageID    string
+				membershipID string
+			}{
+				ctx:          context.Background(),
+				messageID:    msgID,
+				membershipID: membershipID,
+			},
+			want:    &reply,
 			wantErr: nil,
 		},
 		{
@@ -370,26 +974,25 @@ func TestMessageUseCase_DeleteMessage(t *testing.T) {
 				mmr *mock.MockMessageRepository,
 				mcr *mock.MockMessageCacheRepository,
 			) {
+				mcr.EXPECT().Get(gomock.Any(), msgID).Return(&stored, nil)
 				mur.EXPECT().Get(gomock.Any(), superMembershipID).
 					Return(&entity.Membership{
-						UserID:      uuid.New().String(),
-						WorkspaceID: uuid.New().String(),
-						Name:        "super_test",
-						IsAdmin:     true,
+						ID:      superMembershipID,
+						Name:    "super_test",
+						IsAdmin: true,
 					}, nil)
 				mcr.EXPECT().Delete(gomock.Any(), channelID, msgID).Return(nil)
 			},
 			arg: struct {
 				ctx          context.Context
-				message      entity.Message
+				messageID    string
 				membershipID string
-				channelID    string
 			}{
 				ctx:          context.Background(),
-				message:      message,
+				messageID:    msgID,
 				membershipID: superMembershipID,
-				channelID:    channelID,
 			},
+			want:    &stored,
 			wantErr: nil,
 		},
 		{
@@ -399,26 +1002,25 @@ func TestMessageUseCase_DeleteMessage(t *testing.T) {
 				mmr *mock.MockMessageRepository,
 				mcr *mock.MockMessageCacheRepository,
 			) {
+				mcr.EXPECT().Get(gomock.Any(), msgID).Return(&stored, nil)
 				mur.EXPECT().Get(gomock.Any(), notAuthorizedMembershipID).
 					Return(&entity.Membership{
-						UserID:      uuid.New().String(),
-						WorkspaceID: uuid.New().String(),
-						Name:        "not_authorized_test",
-						IsAdmin:     false,
+						ID:      notAuthorizedMembershipID,
+						Name:    "not_authorized_test",
+						IsAdmin: false,
 					}, nil)
 			},
 			arg: struct {
 				ctx          context.Context
-				message      entity.Message
+				messageID    string
 				membershipID string
-				channelID    string
 			}{
 				ctx:          context.Background(),
-				message:      message,
+				messageID:    msgID,
 				membershipID: notAuthorizedMembershipID,
-				channelID:    channelID,
 			},
-			wantErr: fmt.Errorf("don't have permission to delete msg"),
+			want:    nil,
+			wantErr: fmt.Errorf("don't have permission to modify msg: permission denied"),
 		},
 	}
 	for _, tt := range patterns {
@@ -429,18 +1031,21 @@ func TestMessageUseCase_DeleteMessage(t *testing.T) {
 			ur := mock.NewMockMembershipRepository(ctrl)
 			mr := mock.NewMockMessageRepository(ctrl)
 			mcr := mock.NewMockMessageCacheRepository(ctrl)
+			rr := mock.NewMockReactionRepository(ctrl)
+			rcr := mock.NewMockReactionCacheRepository(ctrl)
+			nr := mock.NewMockNotificationRepository(ctrl)
+			ir := mock.NewMockIdempotencyRepository(ctrl)
 
 			if tt.setup != nil {
 				tt.setup(ur, mr, mcr)
 			}
 
-			usecase := NewMessageUseCase(ur, mr, mcr)
+			usecase := NewMessageUseCase(ur, mr, mcr, rr, rcr, nr, ir, nil, &config.MessageConfig{IdempotencyWindow: time.Hour})
 
-			err := usecase.DeleteMessage(
+			got, err := usecase.DeleteMessage(
 				tt.arg.ctx,
-				tt.arg.message,
+				tt.arg.messageID,
 				tt.arg.membershipID,
-				tt.arg.channelID,
 			)
 
 			if (err != nil) != (tt.wantErr != nil) {
@@ -448,6 +1053,165 @@ func TestMessageUseCase_DeleteMessage(t *testing.T) {
 			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
 				t.Errorf("MessageDelete() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MessageDelete() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageUseCase_ListMessagesPage(t *testing.T) {
+	t.Parallel()
+	channelID := uuid.New().String()
+	membershipID := uuid.New().String()
+	now := time.Now().Truncate(time.Second)
+
+	newMessage := func(id string, createdAt time.Time) entity.Message {
+		return entity.Message{
+			ID:           id,
+			MembershipID: membershipID,
+			ChannelID:    channelID,
+			Text:         "test message",
+			CreatedAt:    createdAt,
+		}
+	}
+	m1 := newMessage("0a4a1bb4-6c2d-4a0e-9b8c-6f2b3c0a9c10", now.Add(-3*time.Minute))
+	m2 := newMessage("1b5b2cc5-7d3e-4b1f-8c9d-7a3c4d1b0d21", now.Add(-2*time.Minute))
+	// m3とm4は同じ秒に作成されたため、IDの順に並びます
+	m3 := newMessage("31894386-3e60-45a8-bc67-f46b72b42554", now.Add(-1*time.Minute))
+	m4 := newMessage("4c6c3dd6-8e4f-4c20-9dae-8b4d5e2c1e32", now.Add(-1*time.Minute))
+	cursor := entity.NewMessageCursor(m2)
+
+	patterns := []struct {
+		name  string
+		setup func(
+			mmr *mock.MockMessageRepository,
+			mcr *mock.MockMessageCacheRepository,
+		)
+		page    entity.Page
+		want    *MessagePage
+		wantErr error
+	}{
+		{
+			name: "success: latest page served from cache",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				mcr.EXPECT().ListPage(gomock.Any(), channelID, repository.MessagePageQuery{
+					Direction: entity.PageDirectionBefore,
+					Limit:     3,
+				}).Return([]entity.Message{m4, m3, m2}, nil)
+			},
+			page: entity.Page{Limit: 2},
+			want: &MessagePage{
+				Messages:   []entity.Message{m3, m4},
+				PrevCursor: entity.NewMessageCursor(m3).Encode(),
+			},
+			wantErr: nil,
+		},
+		{
+			name: "success: short cache page is completed from database",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				query := repository.MessagePageQuery{
+					Cursor:    &cursor,
+					Direction: entity.PageDirectionAfter,
+					Limit:     3,
+				}
+				mcr.EXPECT().ListPage(gomock.Any(), channelID, query).Return([]entity.Message{m4}, nil)
+				mmr.EXPECT().ListPage(gomock.Any(), channelID, query).Return([]entity.Message{m3, m4}, nil)
+				mcr.EXPECT().PendingDeletes(gomock.Any(), []string{m3.ID}).Return(map[string]bool{}, nil)
+				mcr.EXPECT().Fill(gomock.Any(), channelID, []entity.Message{m3}).Return(nil)
+			},
+			page: entity.Page{Limit: 2, Direction: entity.PageDirectionAfter, Cursor: cursor.Encode()},
+			want: &MessagePage{
+				Messages:   []entity.Message{m3, m4},
+				PrevCursor: entity.NewMessageCursor(m3).Encode(),
+			},
+			wantErr: nil,
+		},
+		{
+			name: "success: older page with more history",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				query := repository.MessagePageQuery{
+					Cursor:    &cursor,
+					Direction: entity.PageDirectionBefore,
+					Limit:     2,
+				}
+				mcr.EXPECT().ListPage(gomock.Any(), channelID, query).Return(nil, nil)
+				mmr.EXPECT().ListPage(gomock.Any(), channelID, query).Return([]entity.Message{m1}, nil)
+				mcr.EXPECT().PendingDeletes(gomock.Any(), []string{m1.ID}).Return(map[string]bool{}, nil)
+				mcr.EXPECT().Fill(gomock.Any(), channelID, []entity.Message{m1}).Return(nil)
+			},
+			page: entity.Page{Limit: 1, Cursor: cursor.Encode()},
+			want: &MessagePage{
+				Messages:   []entity.Message{m1},
+				NextCursor: entity.NewMessageCursor(m1).Encode(),
+			},
+			wantErr: nil,
+		},
+		{
+			name:    "Fail: invalid cursor",
+			page:    entity.Page{Cursor: "not a cursor!"},
+			want:    nil,
+			wantErr: fmt.Errorf("invalid cursor"),
+		},
+		{
+			name:    "Fail: invalid direction",
+			page:    entity.Page{Direction: "sideways"},
+			want:    nil,
+			wantErr: fmt.Errorf("invalid direction: sideways"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockMembershipRepository(ctrl)
+			mr := mock.NewMockMessageRepository(ctrl)
+			mcr := mock.NewMockMessageCacheRepository(ctrl)
+			rr := mock.NewMockReactionRepository(ctrl)
+			rcr := mock.NewMockReactionCacheRepository(ctrl)
+			nr := mock.NewMockNotificationRepository(ctrl)
+			ir := mock.NewMockIdempotencyRepository(ctrl)
+			cacheNoReactions(rcr)
+
+			if tt.setup != nil {
+				tt.setup(mr, mcr)
+			}
+
+			usecase := NewMessageUseCase(ur, mr, mcr, rr, rcr, nr, ir, nil, &config.MessageConfig{IdempotencyWindow: time.Hour})
+
+			got, err := usecase.ListMessagesPage(context.Background(), channelID, tt.page)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("ListMessagesPage() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("ListMessagesPage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListMessagesPage() got = %+v, want %+v", got, tt.want)
+			}
 		})
 	}
 }
+
+// cacheNoReactions は、リアクションのキャッシュを全てのメッセージがリアクションなしでキャッシュ済みであるように振る舞わせます。
+func cacheNoReactions(rcr *mock.MockReactionCacheRepository) {
+	rcr.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, ids []string) (map[string][]entity.Reaction, error) {
+			reactions := make(map[string][]entity.Reaction, len(ids))
+			for _, id := range ids {
+				reactions[id] = nil
+			}
+			return reactions, nil
+		},
+	).AnyTimes()
+}
@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: attachment.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/connectHub-backend/entity"
+	usecase "github.com/tusmasoma/connectHub-backend/usecase"
+)
+
+// MockAttachmentUseCase is a mock of AttachmentUseCase interface.
+type MockAttachmentUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockAttachmentUseCaseMockRecorder
+}
+
+// MockAttachmentUseCaseMockRecorder is the mock recorder for MockAttachmentUseCase.
+type MockAttachmentUseCaseMockRecorder struct {
+	mock *MockAttachmentUseCase
+}
+
+// NewMockAttachmentUseCase creates a new mock instance.
+func NewMockAttachmentUseCase(ctrl *gomock.Controller) *MockAttachmentUseCase {
+	mock := &MockAttachmentUseCase{ctrl: ctrl}
+	mock.recorder = &MockAttachmentUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAttachmentUseCase) EXPECT() *MockAttachmentUseCaseMockRecorder {
+	return m.recorder
+}
+
+// OpenAttachment mocks base method.
+func (m *MockAttachmentUseCase) OpenAttachment(ctx context.Context, membershipID, workspaceID, attachmentID string) (*entity.Attachment, io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenAttachment", ctx, membershipID, workspaceID, attachmentID)
+	ret0, _ := ret[0].(*entity.Attachment)
+	ret1, _ := ret[1].(io.ReadCloser)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// OpenAttachment indicates an expected call of OpenAttachment.
+func (mr *MockAttachmentUseCaseMockRecorder) OpenAttachment(ctx, membershipID, workspaceID, attachmentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenAttachment", reflect.TypeOf((*MockAttachmentUseCase)(nil).OpenAttachment), ctx, membershipID, workspaceID, attachmentID)
+}
+
+// UploadAttachment mocks base method.
+func (m *MockAttachmentUseCase) UploadAttachment(ctx context.Context, membershipID, workspaceID string, upload usecase.AttachmentUpload) (*entity.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadAttachment", ctx, membershipID, workspaceID, upload)
+	ret0, _ := ret[0].(*entity.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadAttachment indicates an expected call of UploadAttachment.
+func (mr *MockAttachmentUseCaseMockRecorder) UploadAttachment(ctx, membershipID, workspaceID, upload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadAttachment", reflect.TypeOf((*MockAttachmentUseCase)(nil).UploadAttachment), ctx, membershipID, workspaceID, upload)
+}
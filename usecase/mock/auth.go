@@ -36,6 +36,21 @@ func (m *MockAuthUseCase) EXPECT() *MockAuthUseCaseMockRecorder {
 	return m.recorder
 }
 
+// GetSessionIDFromContext mocks base method.
+func (m *MockAuthUseCase) GetSessionIDFromContext(ctx context.Context) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionIDFromContext", ctx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionIDFromContext indicates an expected call of GetSessionIDFromContext.
+func (mr *MockAuthUseCaseMockRecorder) GetSessionIDFromContext(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionIDFromContext", reflect.TypeOf((*MockAuthUseCase)(nil).GetSessionIDFromContext), ctx)
+}
+
 // GetUserFromContext mocks base method.
 func (m *MockAuthUseCase) GetUserFromContext(ctx context.Context) (*entity.User, error) {
 	m.ctrl.T.Helper()
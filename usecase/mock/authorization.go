@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: authorization.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/connectHub-backend/entity"
+)
+
+// MockAuthorizationUseCase is a mock of AuthorizationUseCase interface.
+type MockAuthorizationUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthorizationUseCaseMockRecorder
+}
+
+// MockAuthorizationUseCaseMockRecorder is the mock recorder for MockAuthorizationUseCase.
+type MockAuthorizationUseCaseMockRecorder struct {
+	mock *MockAuthorizationUseCase
+}
+
+// NewMockAuthorizationUseCase creates a new mock instance.
+func NewMockAuthorizationUseCase(ctrl *gomock.Controller) *MockAuthorizationUseCase {
+	mock := &MockAuthorizationUseCase{ctrl: ctrl}
+	mock.recorder = &MockAuthorizationUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthorizationUseCase) EXPECT() *MockAuthorizationUseCaseMockRecorder {
+	return m.recorder
+}
+
+// AuthorizeAction mocks base method.
+func (m *MockAuthorizationUseCase) AuthorizeAction(ctx context.Context, membershipID string, message entity.WSMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthorizeAction", ctx, membershipID, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AuthorizeAction indicates an expected call of AuthorizeAction.
+func (mr *MockAuthorizationUseCaseMockRecorder) AuthorizeAction(ctx, membershipID, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorizeAction", reflect.TypeOf((*MockAuthorizationUseCase)(nil).AuthorizeAction), ctx, membershipID, message)
+}
+
+// AuthorizeWorkspace mocks base method.
+func (m *MockAuthorizationUseCase) AuthorizeWorkspace(ctx context.Context, membershipID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthorizeWorkspace", ctx, membershipID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AuthorizeWorkspace indicates an expected call of AuthorizeWorkspace.
+func (mr *MockAuthorizationUseCaseMockRecorder) AuthorizeWorkspace(ctx, membershipID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorizeWorkspace", reflect.TypeOf((*MockAuthorizationUseCase)(nil).AuthorizeWorkspace), ctx, membershipID)
+}
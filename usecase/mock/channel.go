@@ -37,6 +37,21 @@ func (m *MockChannelUseCase) EXPECT() *MockChannelUseCaseMockRecorder {
 	return m.recorder
 }
 
+// AuthorizeChannel mocks base method.
+func (m *MockChannelUseCase) AuthorizeChannel(ctx context.Context, userID, channelID string) (*entity.Channel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthorizeChannel", ctx, userID, channelID)
+	ret0, _ := ret[0].(*entity.Channel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthorizeChannel indicates an expected call of AuthorizeChannel.
+func (mr *MockChannelUseCaseMockRecorder) AuthorizeChannel(ctx, userID, channelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorizeChannel", reflect.TypeOf((*MockChannelUseCase)(nil).AuthorizeChannel), ctx, userID, channelID)
+}
+
 // CreateChannel mocks base method.
 func (m *MockChannelUseCase) CreateChannel(ctx context.Context, params usecase.CreateChannelParams) error {
 	m.ctrl.T.Helper()
@@ -51,6 +66,36 @@ func (mr *MockChannelUseCaseMockRecorder) CreateChannel(ctx, params interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateChannel", reflect.TypeOf((*MockChannelUseCase)(nil).CreateChannel), ctx, params)
 }
 
+// GetChannel mocks base method.
+func (m *MockChannelUseCase) GetChannel(ctx context.Context, channelID string) (*entity.Channel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChannel", ctx, channelID)
+	ret0, _ := ret[0].(*entity.Channel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChannel indicates an expected call of GetChannel.
+func (mr *MockChannelUseCaseMockRecorder) GetChannel(ctx, channelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChannel", reflect.TypeOf((*MockChannelUseCase)(nil).GetChannel), ctx, channelID)
+}
+
+// InviteToChannel mocks base method.
+func (m *MockChannelUseCase) InviteToChannel(ctx context.Context, inviterID, channelID string, inviteeIDs []string) (*usecase.ChannelInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InviteToChannel", ctx, inviterID, channelID, inviteeIDs)
+	ret0, _ := ret[0].(*usecase.ChannelInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InviteToChannel indicates an expected call of InviteToChannel.
+func (mr *MockChannelUseCaseMockRecorder) InviteToChannel(ctx, inviterID, channelID, inviteeIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InviteToChannel", reflect.TypeOf((*MockChannelUseCase)(nil).InviteToChannel), ctx, inviterID, channelID, inviteeIDs)
+}
+
 // ListMembershipChannels mocks base method.
 func (m *MockChannelUseCase) ListMembershipChannels(ctx context.Context, membershipID string) ([]entity.Channel, error) {
 	m.ctrl.T.Helper()
@@ -65,3 +110,34 @@ func (mr *MockChannelUseCaseMockRecorder) ListMembershipChannels(ctx, membership
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembershipChannels", reflect.TypeOf((*MockChannelUseCase)(nil).ListMembershipChannels), ctx, membershipID)
 }
+
+// ListWorkspaceChannels mocks base method.
+func (m *MockChannelUseCase) ListWorkspaceChannels(ctx context.Context, workspaceID string) ([]entity.Channel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWorkspaceChannels", ctx, workspaceID)
+	ret0, _ := ret[0].([]entity.Channel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWorkspaceChannels indicates an expected call of ListWorkspaceChannels.
+func (mr *MockChannelUseCaseMockRecorder) ListWorkspaceChannels(ctx, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWorkspaceChannels", reflect.TypeOf((*MockChannelUseCase)(nil).ListWorkspaceChannels), ctx, workspaceID)
+}
+
+// OpenDirectMessage mocks base method.
+func (m *MockChannelUseCase) OpenDirectMessage(ctx context.Context, membershipID, workspaceID string, participantIDs []string) (*entity.Channel, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenDirectMessage", ctx, membershipID, workspaceID, participantIDs)
+	ret0, _ := ret[0].(*entity.Channel)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// OpenDirectMessage indicates an expected call of OpenDirectMessage.
+func (mr *MockChannelUseCaseMockRecorder) OpenDirectMessage(ctx, membershipID, workspaceID, participantIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenDirectMessage", reflect.TypeOf((*MockChannelUseCase)(nil).OpenDirectMessage), ctx, membershipID, workspaceID, participantIDs)
+}
@@ -9,6 +9,8 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/connectHub-backend/entity"
 )
 
 // MockMembershipChannelUseCase is a mock of MembershipChannelUseCase interface.
@@ -61,3 +63,32 @@ func (mr *MockMembershipChannelUseCaseMockRecorder) DeleteMembershipChannel(ctx,
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMembershipChannel", reflect.TypeOf((*MockMembershipChannelUseCase)(nil).DeleteMembershipChannel), ctx, membershipID, channelID)
 }
+
+// ListUnreads mocks base method.
+func (m *MockMembershipChannelUseCase) ListUnreads(ctx context.Context, membershipID string) ([]entity.ChannelUnread, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUnreads", ctx, membershipID)
+	ret0, _ := ret[0].([]entity.ChannelUnread)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUnreads indicates an expected call of ListUnreads.
+func (mr *MockMembershipChannelUseCaseMockRecorder) ListUnreads(ctx, membershipID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUnreads", reflect.TypeOf((*MockMembershipChannelUseCase)(nil).ListUnreads), ctx, membershipID)
+}
+
+// MarkRead mocks base method.
+func (m *MockMembershipChannelUseCase) MarkRead(ctx context.Context, membershipID, channelID, messageID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", ctx, membershipID, channelID, messageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MockMembershipChannelUseCaseMockRecorder) MarkRead(ctx, membershipID, channelID, messageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MockMembershipChannelUseCase)(nil).MarkRead), ctx, membershipID, channelID, messageID)
+}
@@ -12,6 +12,7 @@ import (
 	gomock "github.com/golang/mock/gomock"
 
 	entity "github.com/tusmasoma/connectHub-backend/entity"
+	usecase "github.com/tusmasoma/connectHub-backend/usecase"
 )
 
 // MockMessageUseCase is a mock of MessageUseCase interface.
@@ -38,31 +39,33 @@ func (m *MockMessageUseCase) EXPECT() *MockMessageUseCaseMockRecorder {
 }
 
 // CreateMessage mocks base method.
-func (m *MockMessageUseCase) CreateMessage(ctx context.Context, channelID string, message entity.Message) error {
+func (m *MockMessageUseCase) CreateMessage(ctx context.Context, channelID string, message entity.Message, idempotencyKey string, onlineMembershipIDs []string) (*usecase.CreatedMessage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateMessage", ctx, channelID, message)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "CreateMessage", ctx, channelID, message, idempotencyKey, onlineMembershipIDs)
+	ret0, _ := ret[0].(*usecase.CreatedMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // CreateMessage indicates an expected call of CreateMessage.
-func (mr *MockMessageUseCaseMockRecorder) CreateMessage(ctx, channelID, message interface{}) *gomock.Call {
+func (mr *MockMessageUseCaseMockRecorder) CreateMessage(ctx, channelID, message, idempotencyKey, onlineMembershipIDs interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMessage", reflect.TypeOf((*MockMessageUseCase)(nil).CreateMessage), ctx, channelID, message)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMessage", reflect.TypeOf((*MockMessageUseCase)(nil).CreateMessage), ctx, channelID, message, idempotencyKey, onlineMembershipIDs)
 }
 
 // DeleteMessage mocks base method.
-func (m *MockMessageUseCase) DeleteMessage(ctx context.Context, message entity.Message, membershipID, channelID string) error {
+func (m *MockMessageUseCase) DeleteMessage(ctx context.Context, messageID, membershipID string) (*entity.Message, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteMessage", ctx, message, membershipID, channelID)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "DeleteMessage", ctx, messageID, membershipID)
+	ret0, _ := ret[0].(*entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // DeleteMessage indicates an expected call of DeleteMessage.
-func (mr *MockMessageUseCaseMockRecorder) DeleteMessage(ctx, message, membershipID, channelID interface{}) *gomock.Call {
+func (mr *MockMessageUseCaseMockRecorder) DeleteMessage(ctx, messageID, membershipID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockMessageUseCase)(nil).DeleteMessage), ctx, message, membershipID, channelID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockMessageUseCase)(nil).DeleteMessage), ctx, messageID, membershipID)
 }
 
 // ListMessages mocks base method.
@@ -80,12 +83,28 @@ func (mr *MockMessageUseCaseMockRecorder) ListMessages(ctx, channelID, start, en
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMessages", reflect.TypeOf((*MockMessageUseCase)(nil).ListMessages), ctx, channelID, start, end)
 }
 
+// ListMessagesPage mocks base method.
+func (m *MockMessageUseCase) ListMessagesPage(ctx context.Context, channelID string, page entity.Page) (*usecase.MessagePage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMessagesPage", ctx, channelID, page)
+	ret0, _ := ret[0].(*usecase.MessagePage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMessagesPage indicates an expected call of ListMessagesPage.
+func (mr *MockMessageUseCaseMockRecorder) ListMessagesPage(ctx, channelID, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMessagesPage", reflect.TypeOf((*MockMessageUseCase)(nil).ListMessagesPage), ctx, channelID, page)
+}
+
 // UpdateMessage mocks base method.
-func (m *MockMessageUseCase) UpdateMessage(ctx context.Context, message entity.Message, membershipID string) error {
+func (m *MockMessageUseCase) UpdateMessage(ctx context.Context, message entity.Message, membershipID string) (*entity.Message, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateMessage", ctx, message, membershipID)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(*entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // UpdateMessage indicates an expected call of UpdateMessage.
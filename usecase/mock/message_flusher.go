@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: message_flusher.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	usecase "github.com/tusmasoma/connectHub-backend/usecase"
+)
+
+// MockMessageFlusher is a mock of MessageFlusher interface.
+type MockMessageFlusher struct {
+	ctrl     *gomock.Controller
+	recorder *MockMessageFlusherMockRecorder
+}
+
+// MockMessageFlusherMockRecorder is the mock recorder for MockMessageFlusher.
+type MockMessageFlusherMockRecorder struct {
+	mock *MockMessageFlusher
+}
+
+// NewMockMessageFlusher creates a new mock instance.
+func NewMockMessageFlusher(ctrl *gomock.Controller) *MockMessageFlusher {
+	mock := &MockMessageFlusher{ctrl: ctrl}
+	mock.recorder = &MockMessageFlusherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMessageFlusher) EXPECT() *MockMessageFlusherMockRecorder {
+	return m.recorder
+}
+
+// Flush mocks base method.
+func (m *MockMessageFlusher) Flush(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Flush", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Flush indicates an expected call of Flush.
+func (mr *MockMessageFlusherMockRecorder) Flush(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockMessageFlusher)(nil).Flush), ctx)
+}
+
+// Lag mocks base method.
+func (m *MockMessageFlusher) Lag(ctx context.Context) (*usecase.MessageFlushLag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lag", ctx)
+	ret0, _ := ret[0].(*usecase.MessageFlushLag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lag indicates an expected call of Lag.
+func (mr *MockMessageFlusherMockRecorder) Lag(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lag", reflect.TypeOf((*MockMessageFlusher)(nil).Lag), ctx)
+}
+
+// RequeueDeadLetters mocks base method.
+func (m *MockMessageFlusher) RequeueDeadLetters(ctx context.Context, ids []string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueDeadLetters", ctx, ids)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueDeadLetters indicates an expected call of RequeueDeadLetters.
+func (mr *MockMessageFlusherMockRecorder) RequeueDeadLetters(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueDeadLetters", reflect.TypeOf((*MockMessageFlusher)(nil).RequeueDeadLetters), ctx, ids)
+}
+
+// Run mocks base method.
+func (m *MockMessageFlusher) Run(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", ctx)
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockMessageFlusherMockRecorder) Run(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockMessageFlusher)(nil).Run), ctx)
+}
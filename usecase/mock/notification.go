@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: notification.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/connectHub-backend/entity"
+)
+
+// MockNotificationUseCase is a mock of NotificationUseCase interface.
+type MockNotificationUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationUseCaseMockRecorder
+}
+
+// MockNotificationUseCaseMockRecorder is the mock recorder for MockNotificationUseCase.
+type MockNotificationUseCaseMockRecorder struct {
+	mock *MockNotificationUseCase
+}
+
+// NewMockNotificationUseCase creates a new mock instance.
+func NewMockNotificationUseCase(ctrl *gomock.Controller) *MockNotificationUseCase {
+	mock := &MockNotificationUseCase{ctrl: ctrl}
+	mock.recorder = &MockNotificationUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationUseCase) EXPECT() *MockNotificationUseCaseMockRecorder {
+	return m.recorder
+}
+
+// ListNotifications mocks base method.
+func (m *MockNotificationUseCase) ListNotifications(ctx context.Context, membershipID string, unreadOnly bool) ([]entity.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNotifications", ctx, membershipID, unreadOnly)
+	ret0, _ := ret[0].([]entity.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotifications indicates an expected call of ListNotifications.
+func (mr *MockNotificationUseCaseMockRecorder) ListNotifications(ctx, membershipID, unreadOnly interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotifications", reflect.TypeOf((*MockNotificationUseCase)(nil).ListNotifications), ctx, membershipID, unreadOnly)
+}
+
+// MarkNotificationsRead mocks base method.
+func (m *MockNotificationUseCase) MarkNotificationsRead(ctx context.Context, membershipID string, ids []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkNotificationsRead", ctx, membershipID, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkNotificationsRead indicates an expected call of MarkNotificationsRead.
+func (mr *MockNotificationUseCaseMockRecorder) MarkNotificationsRead(ctx, membershipID, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkNotificationsRead", reflect.TypeOf((*MockNotificationUseCase)(nil).MarkNotificationsRead), ctx, membershipID, ids)
+}
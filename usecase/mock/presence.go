@@ -0,0 +1,97 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: presence.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/connectHub-backend/entity"
+)
+
+// MockPresenceUseCase is a mock of PresenceUseCase interface.
+type MockPresenceUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockPresenceUseCaseMockRecorder
+}
+
+// MockPresenceUseCaseMockRecorder is the mock recorder for MockPresenceUseCase.
+type MockPresenceUseCaseMockRecorder struct {
+	mock *MockPresenceUseCase
+}
+
+// NewMockPresenceUseCase creates a new mock instance.
+func NewMockPresenceUseCase(ctrl *gomock.Controller) *MockPresenceUseCase {
+	mock := &MockPresenceUseCase{ctrl: ctrl}
+	mock.recorder = &MockPresenceUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPresenceUseCase) EXPECT() *MockPresenceUseCaseMockRecorder {
+	return m.recorder
+}
+
+// Connect mocks base method.
+func (m *MockPresenceUseCase) Connect(ctx context.Context, membershipID, connectionID string) (*entity.Presence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Connect", ctx, membershipID, connectionID)
+	ret0, _ := ret[0].(*entity.Presence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Connect indicates an expected call of Connect.
+func (mr *MockPresenceUseCaseMockRecorder) Connect(ctx, membershipID, connectionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Connect", reflect.TypeOf((*MockPresenceUseCase)(nil).Connect), ctx, membershipID, connectionID)
+}
+
+// Disconnect mocks base method.
+func (m *MockPresenceUseCase) Disconnect(ctx context.Context, membershipID, connectionID string) (*entity.Presence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Disconnect", ctx, membershipID, connectionID)
+	ret0, _ := ret[0].(*entity.Presence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Disconnect indicates an expected call of Disconnect.
+func (mr *MockPresenceUseCaseMockRecorder) Disconnect(ctx, membershipID, connectionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disconnect", reflect.TypeOf((*MockPresenceUseCase)(nil).Disconnect), ctx, membershipID, connectionID)
+}
+
+// ListPresences mocks base method.
+func (m *MockPresenceUseCase) ListPresences(ctx context.Context, membershipID, workspaceID string, membershipIDs []string) ([]entity.Presence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPresences", ctx, membershipID, workspaceID, membershipIDs)
+	ret0, _ := ret[0].([]entity.Presence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPresences indicates an expected call of ListPresences.
+func (mr *MockPresenceUseCaseMockRecorder) ListPresences(ctx, membershipID, workspaceID, membershipIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPresences", reflect.TypeOf((*MockPresenceUseCase)(nil).ListPresences), ctx, membershipID, workspaceID, membershipIDs)
+}
+
+// SetStatus mocks base method.
+func (m *MockPresenceUseCase) SetStatus(ctx context.Context, membershipID, status string) (*entity.Presence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStatus", ctx, membershipID, status)
+	ret0, _ := ret[0].(*entity.Presence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetStatus indicates an expected call of SetStatus.
+func (mr *MockPresenceUseCaseMockRecorder) SetStatus(ctx, membershipID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStatus", reflect.TypeOf((*MockPresenceUseCase)(nil).SetStatus), ctx, membershipID, status)
+}
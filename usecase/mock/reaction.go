@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: reaction.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/connectHub-backend/entity"
+)
+
+// MockReactionUseCase is a mock of ReactionUseCase interface.
+type MockReactionUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockReactionUseCaseMockRecorder
+}
+
+// MockReactionUseCaseMockRecorder is the mock recorder for MockReactionUseCase.
+type MockReactionUseCaseMockRecorder struct {
+	mock *MockReactionUseCase
+}
+
+// NewMockReactionUseCase creates a new mock instance.
+func NewMockReactionUseCase(ctrl *gomock.Controller) *MockReactionUseCase {
+	mock := &MockReactionUseCase{ctrl: ctrl}
+	mock.recorder = &MockReactionUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReactionUseCase) EXPECT() *MockReactionUseCaseMockRecorder {
+	return m.recorder
+}
+
+// AddReaction mocks base method.
+func (m *MockReactionUseCase) AddReaction(ctx context.Context, messageID, membershipID, emoji string) (*entity.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddReaction", ctx, messageID, membershipID, emoji)
+	ret0, _ := ret[0].(*entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddReaction indicates an expected call of AddReaction.
+func (mr *MockReactionUseCaseMockRecorder) AddReaction(ctx, messageID, membershipID, emoji interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReaction", reflect.TypeOf((*MockReactionUseCase)(nil).AddReaction), ctx, messageID, membershipID, emoji)
+}
+
+// RemoveReaction mocks base method.
+func (m *MockReactionUseCase) RemoveReaction(ctx context.Context, messageID, membershipID, emoji string) (*entity.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveReaction", ctx, messageID, membershipID, emoji)
+	ret0, _ := ret[0].(*entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveReaction indicates an expected call of RemoveReaction.
+func (mr *MockReactionUseCaseMockRecorder) RemoveReaction(ctx, messageID, membershipID, emoji interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveReaction", reflect.TypeOf((*MockReactionUseCase)(nil).RemoveReaction), ctx, messageID, membershipID, emoji)
+}
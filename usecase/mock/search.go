@@ -0,0 +1,52 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: search.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	usecase "github.com/tusmasoma/connectHub-backend/usecase"
+)
+
+// MockSearchUseCase is a mock of SearchUseCase interface.
+type MockSearchUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockSearchUseCaseMockRecorder
+}
+
+// MockSearchUseCaseMockRecorder is the mock recorder for MockSearchUseCase.
+type MockSearchUseCaseMockRecorder struct {
+	mock *MockSearchUseCase
+}
+
+// NewMockSearchUseCase creates a new mock instance.
+func NewMockSearchUseCase(ctrl *gomock.Controller) *MockSearchUseCase {
+	mock := &MockSearchUseCase{ctrl: ctrl}
+	mock.recorder = &MockSearchUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSearchUseCase) EXPECT() *MockSearchUseCaseMockRecorder {
+	return m.recorder
+}
+
+// SearchMessages mocks base method.
+func (m *MockSearchUseCase) SearchMessages(ctx context.Context, membershipID, workspaceID string, params usecase.SearchMessagesParams) (*usecase.SearchMessagesResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchMessages", ctx, membershipID, workspaceID, params)
+	ret0, _ := ret[0].(*usecase.SearchMessagesResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchMessages indicates an expected call of SearchMessages.
+func (mr *MockSearchUseCaseMockRecorder) SearchMessages(ctx, membershipID, workspaceID, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchMessages", reflect.TypeOf((*MockSearchUseCase)(nil).SearchMessages), ctx, membershipID, workspaceID, params)
+}
@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: thread.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/connectHub-backend/entity"
+	usecase "github.com/tusmasoma/connectHub-backend/usecase"
+)
+
+// MockThreadUseCase is a mock of ThreadUseCase interface.
+type MockThreadUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockThreadUseCaseMockRecorder
+}
+
+// MockThreadUseCaseMockRecorder is the mock recorder for MockThreadUseCase.
+type MockThreadUseCaseMockRecorder struct {
+	mock *MockThreadUseCase
+}
+
+// NewMockThreadUseCase creates a new mock instance.
+func NewMockThreadUseCase(ctrl *gomock.Controller) *MockThreadUseCase {
+	mock := &MockThreadUseCase{ctrl: ctrl}
+	mock.recorder = &MockThreadUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockThreadUseCase) EXPECT() *MockThreadUseCaseMockRecorder {
+	return m.recorder
+}
+
+// CreateReply mocks base method.
+func (m *MockThreadUseCase) CreateReply(ctx context.Context, parentID string, reply entity.Message) (*usecase.ThreadReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReply", ctx, parentID, reply)
+	ret0, _ := ret[0].(*usecase.ThreadReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReply indicates an expected call of CreateReply.
+func (mr *MockThreadUseCaseMockRecorder) CreateReply(ctx, parentID, reply interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReply", reflect.TypeOf((*MockThreadUseCase)(nil).CreateReply), ctx, parentID, reply)
+}
+
+// FollowThread mocks base method.
+func (m *MockThreadUseCase) FollowThread(ctx context.Context, parentID, membershipID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FollowThread", ctx, parentID, membershipID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FollowThread indicates an expected call of FollowThread.
+func (mr *MockThreadUseCaseMockRecorder) FollowThread(ctx, parentID, membershipID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FollowThread", reflect.TypeOf((*MockThreadUseCase)(nil).FollowThread), ctx, parentID, membershipID)
+}
+
+// ListThread mocks base method.
+func (m *MockThreadUseCase) ListThread(ctx context.Context, parentID string) ([]entity.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListThread", ctx, parentID)
+	ret0, _ := ret[0].([]entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListThread indicates an expected call of ListThread.
+func (mr *MockThreadUseCaseMockRecorder) ListThread(ctx, parentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListThread", reflect.TypeOf((*MockThreadUseCase)(nil).ListThread), ctx, parentID)
+}
+
+// UnfollowThread mocks base method.
+func (m *MockThreadUseCase) UnfollowThread(ctx context.Context, parentID, membershipID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnfollowThread", ctx, parentID, membershipID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnfollowThread indicates an expected call of UnfollowThread.
+func (mr *MockThreadUseCaseMockRecorder) UnfollowThread(ctx, parentID, membershipID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnfollowThread", reflect.TypeOf((*MockThreadUseCase)(nil).UnfollowThread), ctx, parentID, membershipID)
+}
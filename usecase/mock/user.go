@@ -9,6 +9,9 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/connectHub-backend/entity"
+	usecase "github.com/tusmasoma/connectHub-backend/usecase"
 )
 
 // MockUserUseCase is a mock of UserUseCase interface.
@@ -34,46 +37,104 @@ func (m *MockUserUseCase) EXPECT() *MockUserUseCaseMockRecorder {
 	return m.recorder
 }
 
+// ListSessions mocks base method.
+func (m *MockUserUseCase) ListSessions(ctx context.Context, userID string) ([]entity.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", ctx, userID)
+	ret0, _ := ret[0].([]entity.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockUserUseCaseMockRecorder) ListSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockUserUseCase)(nil).ListSessions), ctx, userID)
+}
+
 // LoginAndGenerateToken mocks base method.
-func (m *MockUserUseCase) LoginAndGenerateToken(ctx context.Context, email, password string) (string, error) {
+func (m *MockUserUseCase) LoginAndGenerateToken(ctx context.Context, email, password string, meta usecase.SessionMetadata) (*usecase.AuthTokens, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LoginAndGenerateToken", ctx, email, password)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "LoginAndGenerateToken", ctx, email, password, meta)
+	ret0, _ := ret[0].(*usecase.AuthTokens)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // LoginAndGenerateToken indicates an expected call of LoginAndGenerateToken.
-func (mr *MockUserUseCaseMockRecorder) LoginAndGenerateToken(ctx, email, password interface{}) *gomock.Call {
+func (mr *MockUserUseCaseMockRecorder) LoginAndGenerateToken(ctx, email, password, meta interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginAndGenerateToken", reflect.TypeOf((*MockUserUseCase)(nil).LoginAndGenerateToken), ctx, email, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginAndGenerateToken", reflect.TypeOf((*MockUserUseCase)(nil).LoginAndGenerateToken), ctx, email, password, meta)
 }
 
 // LogoutUser mocks base method.
-func (m *MockUserUseCase) LogoutUser(ctx context.Context, userID string) error {
+func (m *MockUserUseCase) LogoutUser(ctx context.Context, userID, sessionID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LogoutUser", ctx, userID)
+	ret := m.ctrl.Call(m, "LogoutUser", ctx, userID, sessionID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // LogoutUser indicates an expected call of LogoutUser.
-func (mr *MockUserUseCaseMockRecorder) LogoutUser(ctx, userID interface{}) *gomock.Call {
+func (mr *MockUserUseCaseMockRecorder) LogoutUser(ctx, userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoutUser", reflect.TypeOf((*MockUserUseCase)(nil).LogoutUser), ctx, userID, sessionID)
+}
+
+// RefreshToken mocks base method.
+func (m *MockUserUseCase) RefreshToken(ctx context.Context, refreshToken string) (*usecase.AuthTokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshToken", ctx, refreshToken)
+	ret0, _ := ret[0].(*usecase.AuthTokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshToken indicates an expected call of RefreshToken.
+func (mr *MockUserUseCaseMockRecorder) RefreshToken(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockUserUseCase)(nil).RefreshToken), ctx, refreshToken)
+}
+
+// RevokeAllSessions mocks base method.
+func (m *MockUserUseCase) RevokeAllSessions(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllSessions", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAllSessions indicates an expected call of RevokeAllSessions.
+func (mr *MockUserUseCaseMockRecorder) RevokeAllSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllSessions", reflect.TypeOf((*MockUserUseCase)(nil).RevokeAllSessions), ctx, userID)
+}
+
+// RevokeSession mocks base method.
+func (m *MockUserUseCase) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, userID, sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockUserUseCaseMockRecorder) RevokeSession(ctx, userID, sessionID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoutUser", reflect.TypeOf((*MockUserUseCase)(nil).LogoutUser), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockUserUseCase)(nil).RevokeSession), ctx, userID, sessionID)
 }
 
 // SignUpAndGenerateToken mocks base method.
-func (m *MockUserUseCase) SignUpAndGenerateToken(ctx context.Context, email, password string) (string, error) {
+func (m *MockUserUseCase) SignUpAndGenerateToken(ctx context.Context, email, password string, meta usecase.SessionMetadata) (*usecase.AuthTokens, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignUpAndGenerateToken", ctx, email, password)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "SignUpAndGenerateToken", ctx, email, password, meta)
+	ret0, _ := ret[0].(*usecase.AuthTokens)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SignUpAndGenerateToken indicates an expected call of SignUpAndGenerateToken.
-func (mr *MockUserUseCaseMockRecorder) SignUpAndGenerateToken(ctx, email, password interface{}) *gomock.Call {
+func (mr *MockUserUseCaseMockRecorder) SignUpAndGenerateToken(ctx, email, password, meta interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUpAndGenerateToken", reflect.TypeOf((*MockUserUseCase)(nil).SignUpAndGenerateToken), ctx, email, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUpAndGenerateToken", reflect.TypeOf((*MockUserUseCase)(nil).SignUpAndGenerateToken), ctx, email, password, meta)
 }
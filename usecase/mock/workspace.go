@@ -9,6 +9,8 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/connectHub-backend/entity"
 )
 
 // MockWorkspaceUseCase is a mock of WorkspaceUseCase interface.
@@ -47,3 +49,33 @@ func (mr *MockWorkspaceUseCaseMockRecorder) CreateWorkspace(ctx, id, name interf
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWorkspace", reflect.TypeOf((*MockWorkspaceUseCase)(nil).CreateWorkspace), ctx, id, name)
 }
+
+// GetWorkspace mocks base method.
+func (m *MockWorkspaceUseCase) GetWorkspace(ctx context.Context, id string) (*entity.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspace", ctx, id)
+	ret0, _ := ret[0].(*entity.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspace indicates an expected call of GetWorkspace.
+func (mr *MockWorkspaceUseCaseMockRecorder) GetWorkspace(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspace", reflect.TypeOf((*MockWorkspaceUseCase)(nil).GetWorkspace), ctx, id)
+}
+
+// ListWorkspaces mocks base method.
+func (m *MockWorkspaceUseCase) ListWorkspaces(ctx context.Context) ([]entity.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWorkspaces", ctx)
+	ret0, _ := ret[0].([]entity.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWorkspaces indicates an expected call of ListWorkspaces.
+func (mr *MockWorkspaceUseCaseMockRecorder) ListWorkspaces(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWorkspaces", reflect.TypeOf((*MockWorkspaceUseCase)(nil).ListWorkspaces), ctx)
+}
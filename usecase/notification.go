@@ -0,0 +1,112 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+// notificationListLimit は、一度に取得する通知の最大件数です。
+const notificationListLimit = 100
+
+type NotificationUseCase interface {
+	ListNotifications(ctx context.Context, membershipID string, unreadOnly bool) ([]entity.Notification, error)
+	MarkNotificationsRead(ctx context.Context, membershipID string, ids []string) error
+}
+
+type notificationUseCase struct {
+	nr repository.NotificationRepository
+}
+
+func NewNotificationUseCase(nr repository.NotificationRepository) NotificationUseCase {
+	return &notificationUseCase{
+		nr: nr,
+	}
+}
+
+func (nuc *notificationUseCase) ListNotifications(ctx context.Context, membershipID string, unreadOnly bool) ([]entity.Notification, error) { //nolint:lll // Ignore long line length
+	notifications, err := nuc.nr.List(ctx, membershipID, unreadOnly, notificationListLimit)
+	if err != nil {
+		log.Error("Failed to list notifications", log.Fstring("membershipID", membershipID))
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// MarkNotificationsRead は、idsの通知を既読にします。idsが空の場合は全ての通知を既読にします。
+func (nuc *notificationUseCase) MarkNotificationsRead(ctx context.Context, membershipID string, ids []string) error {
+	if err := nuc.nr.MarkRead(ctx, membershipID, ids, time.Now()); err != nil {
+		log.Error("Failed to mark notifications as read", log.Fstring("membershipID", membershipID))
+		return err
+	}
+	return nil
+}
+
+// notifyMentions は、メッセージ中のメンションを解決して通知を作成します。
+// @hereはonlineMembershipIDsに含まれるメンバーのみ、送信者自身と削除済みのメンバーは通知の対象外です。
+// 同じメンバーが複数の方法でメンションされた場合は、個別のメンションを優先して1件だけ通知します。
+func notifyMentions(
+	ctx context.Context,
+	ur repository.MembershipRepository,
+	nr repository.NotificationRepository,
+	message entity.Message,
+	onlineMembershipIDs []string,
+) ([]entity.Notification, error) {
+	if !strings.Contains(message.Text, "@") {
+		return nil, nil
+	}
+
+	memberships, err := ur.ListChannelMemberships(ctx, message.ChannelID)
+	if err != nil {
+		log.Error("Failed to list channel memberships", log.Fstring("channelID", message.ChannelID))
+		return nil, err
+	}
+	mentions := entity.ParseMentions(message.Text, memberships)
+
+	online := make(map[string]bool, len(onlineMembershipIDs))
+	for _, membershipID := range onlineMembershipIDs {
+		online[membershipID] = true
+	}
+	kinds := make(map[string]string)
+	for _, membershipID := range mentions.MembershipIDs {
+		kinds[membershipID] = entity.MentionKindMembership
+	}
+	for _, membership := range memberships {
+		if _, ok := kinds[membership.ID]; ok {
+			continue
+		}
+		if mentions.Channel {
+			kinds[membership.ID] = entity.MentionKindChannel
+		} else if mentions.Here && online[membership.ID] {
+			kinds[membership.ID] = entity.MentionKindHere
+		}
+	}
+
+	var notifications []entity.Notification
+	for _, membership := range memberships {
+		kind, ok := kinds[membership.ID]
+		if !ok || membership.IsDeleted || membership.ID == message.MembershipID {
+			continue
+		}
+		var notification *entity.Notification
+		notification, err = entity.NewNotification(membership.ID, message, kind)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, *notification)
+	}
+	if len(notifications) == 0 {
+		return nil, nil
+	}
+
+	if err = nr.BatchCreate(ctx, notifications); err != nil {
+		log.Error("Failed to create notifications", log.Fstring("msgID", message.ID))
+		return nil, err
+	}
+	return notifications, nil
+}
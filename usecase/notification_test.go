@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository/mock"
+)
+
+func TestNotificationUseCase_ListNotifications(t *testing.T) {
+	t.Parallel()
+	membershipID := uuid.New().String()
+	notifications := []entity.Notification{
+		{ID: uuid.New().String(), MembershipID: membershipID, Kind: entity.MentionKindChannel},
+	}
+
+	patterns := []struct {
+		name    string
+		setup   func(nr *mock.MockNotificationRepository)
+		want    []entity.Notification
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(nr *mock.MockNotificationRepository) {
+				nr.EXPECT().List(gomock.Any(), membershipID, true, int64(notificationListLimit)).Return(notifications, nil)
+			},
+			want:    notifications,
+			wantErr: nil,
+		},
+		{
+			name: "Fail: failed to list notifications",
+			setup: func(nr *mock.MockNotificationRepository) {
+				nr.EXPECT().List(gomock.Any(), membershipID, true, int64(notificationListLimit)).Return(nil, fmt.Errorf("database error"))
+			},
+			want:    nil,
+			wantErr: fmt.Errorf("database error"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			nr := mock.NewMockNotificationRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(nr)
+			}
+
+			usecase := NewNotificationUseCase(nr)
+
+			got, err := usecase.ListNotifications(context.Background(), membershipID, true)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("ListNotifications() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("ListNotifications() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListNotifications() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+var ErrInvalidPresenceStatus = errors.New("invalid presence status")
+
+// PresenceUseCase は、WebSocketの接続からメンバーシップのプレゼンスを求めます。
+// Connect、Disconnect、SetStatusは、プレゼンスが変わった場合にだけ変化後のプレゼンスを返します。
+type PresenceUseCase interface {
+	// Connect は、接続を記録します。ハートビートのたびにも呼び出し、接続の期限を延ばします。
+	Connect(ctx context.Context, membershipID, connectionID string) (*entity.Presence, error)
+	Disconnect(ctx context.Context, membershipID, connectionID string) (*entity.Presence, error)
+	SetStatus(ctx context.Context, membershipID, status string) (*entity.Presence, error)
+	// ListPresences は、ワークスペースのメンバーがmembershipIDsのプレゼンスを取得します。
+	ListPresences(ctx context.Context, membershipID, workspaceID string, membershipIDs []string) ([]entity.Presence, error)
+}
+
+type presenceUseCase struct {
+	pr   repository.PresenceRepository
+	ur   repository.MembershipRepository
+	conf *config.PresenceConfig
+}
+
+func NewPresenceUseCase(
+	pr repository.PresenceRepository,
+	ur repository.MembershipRepository,
+	conf *config.PresenceConfig,
+) PresenceUseCase {
+	return &presenceUseCase{
+		pr:   pr,
+		ur:   ur,
+		conf: conf,
+	}
+}
+
+func (puc *presenceUseCase) Connect(ctx context.Context, membershipID, connectionID string) (*entity.Presence, error) {
+	first, err := puc.pr.Touch(ctx, membershipID, connectionID, puc.conf.TTL)
+	if err != nil {
+		log.Error("Failed to touch presence", log.Fstring("membershipID", membershipID))
+		return nil, err
+	}
+	if !first {
+		return nil, nil //nolint:nilnil // プレゼンスが変わらない場合はnilを返します
+	}
+	return puc.getPresence(ctx, membershipID)
+}
+
+func (puc *presenceUseCase) Disconnect(ctx context.Context, membershipID, connectionID string) (*entity.Presence, error) {
+	last, err := puc.pr.Remove(ctx, membershipID, connectionID)
+	if err != nil {
+		log.Error("Failed to remove presence", log.Fstring("membershipID", membershipID))
+		return nil, err
+	}
+	if !last {
+		return nil, nil //nolint:nilnil // プレゼンスが変わらない場合はnilを返します
+	}
+	return puc.getPresence(ctx, membershipID)
+}
+
+// SetStatus は、メンバーが設定したプレゼンスの状態を保存します。online、away、dndのいずれかを指定できます。
+func (puc *presenceUseCase) SetStatus(ctx context.Context, membershipID, status string) (*entity.Presence, error) {
+	if !entity.IsSettablePresenceStatus(status) {
+		log.Warn("Invalid presence status", log.Fstring("status", status))
+		return nil, ErrInvalidPresenceStatus
+	}
+	if err := puc.pr.SetStatus(ctx, membershipID, status); err != nil {
+		log.Error("Failed to set presence status", log.Fstring("membershipID", membershipID))
+		return nil, err
+	}
+	return puc.getPresence(ctx, membershipID)
+}
+
+// ListPresences は、ワークスペースの削除されていないメンバーにだけ、同じワークスペースのメンバーシップのプレゼンスを返します。
+func (puc *presenceUseCase) ListPresences(
+	ctx context.Context,
+	membershipID, workspaceID string,
+	membershipIDs []string,
+) ([]entity.Presence, error) {
+	membership, err := puc.ur.Get(ctx, membershipID)
+	if err != nil || membership.IsDeleted || membership.WorkspaceID != workspaceID {
+		log.Warn("Membership cannot list presences", log.Fstring("membershipID", membershipID), log.Fstring("workspaceID", workspaceID))
+		return nil, ErrPermissionDenied
+	}
+	// メンバーシップのIDは"<userID>_<workspaceID>"のため、他のワークスペースのメンバーシップはIDで判別できます。
+	for _, id := range membershipIDs {
+		if !strings.HasSuffix(id, "_"+workspaceID) {
+			log.Warn("Membership belongs to another workspace", log.Fstring("membershipID", id), log.Fstring("workspaceID", workspaceID))
+			return nil, ErrPermissionDenied
+		}
+	}
+
+	presences, err := puc.pr.List(ctx, membershipIDs)
+	if err != nil {
+		log.Error("Failed to list presences", log.Fstring("workspaceID", workspaceID))
+		return nil, err
+	}
+	return presences, nil
+}
+
+func (puc *presenceUseCase) getPresence(ctx context.Context, membershipID string) (*entity.Presence, error) {
+	presences, err := puc.pr.List(ctx, []string{membershipID})
+	if err != nil || len(presences) == 0 {
+		log.Error("Failed to get presence", log.Fstring("membershipID", membershipID))
+		return nil, err
+	}
+	return &presences[0], nil
+}
@@ -0,0 +1,298 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository/mock"
+)
+
+func TestPresenceUseCase_Connect(t *testing.T) {
+	t.Parallel()
+	membershipID := uuid.New().String()
+	connectionID := uuid.New().String()
+	conf := &config.PresenceConfig{TTL: time.Minute}
+	online := entity.Presence{MembershipID: membershipID, Status: entity.PresenceOnline}
+
+	patterns := []struct {
+		name    string
+		setup   func(pr *mock.MockPresenceRepository)
+		want    *entity.Presence
+		wantErr error
+	}{
+		{
+			name: "success: first connection",
+			setup: func(pr *mock.MockPresenceRepository) {
+				pr.EXPECT().Touch(gomock.Any(), membershipID, connectionID, conf.TTL).Return(true, nil)
+				pr.EXPECT().List(gomock.Any(), []string{membershipID}).Return([]entity.Presence{online}, nil)
+			},
+			want:    &online,
+			wantErr: nil,
+		},
+		{
+			name: "success: already online",
+			setup: func(pr *mock.MockPresenceRepository) {
+				pr.EXPECT().Touch(gomock.Any(), membershipID, connectionID, conf.TTL).Return(false, nil)
+			},
+			want:    nil,
+			wantErr: nil,
+		},
+		{
+			name: "Fail: failed to touch presence",
+			setup: func(pr *mock.MockPresenceRepository) {
+				pr.EXPECT().Touch(gomock.Any(), membershipID, connectionID, conf.TTL).Return(false, fmt.Errorf("connection refused"))
+			},
+			want:    nil,
+			wantErr: fmt.Errorf("connection refused"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			pr := mock.NewMockPresenceRepository(ctrl)
+			ur := mock.NewMockMembershipRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(pr)
+			}
+
+			usecase := NewPresenceUseCase(pr, ur, conf)
+
+			got, err := usecase.Connect(context.Background(), membershipID, connectionID)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("Connect() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("Connect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Connect() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPresenceUseCase_Disconnect(t *testing.T) {
+	t.Parallel()
+	membershipID := uuid.New().String()
+	connectionID := uuid.New().String()
+	lastSeenAt := time.Now()
+	offline := entity.Presence{MembershipID: membershipID, Status: entity.PresenceOffline, LastSeenAt: &lastSeenAt}
+
+	patterns := []struct {
+		name    string
+		setup   func(pr *mock.MockPresenceRepository)
+		want    *entity.Presence
+		wantErr error
+	}{
+		{
+			name: "success: last connection",
+			setup: func(pr *mock.MockPresenceRepository) {
+				pr.EXPECT().Remove(gomock.Any(), membershipID, connectionID).Return(true, nil)
+				pr.EXPECT().List(gomock.Any(), []string{membershipID}).Return([]entity.Presence{offline}, nil)
+			},
+			want:    &offline,
+			wantErr: nil,
+		},
+		{
+			name: "success: other connections remain",
+			setup: func(pr *mock.MockPresenceRepository) {
+				pr.EXPECT().Remove(gomock.Any(), membershipID, connectionID).Return(false, nil)
+			},
+			want:    nil,
+			wantErr: nil,
+		},
+		{
+			name: "Fail: failed to remove presence",
+			setup: func(pr *mock.MockPresenceRepository) {
+				pr.EXPECT().Remove(gomock.Any(), membershipID, connectionID).Return(false, fmt.Errorf("connection refused"))
+			},
+			want:    nil,
+			wantErr: fmt.Errorf("connection refused"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			pr := mock.NewMockPresenceRepository(ctrl)
+			ur := mock.NewMockMembershipRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(pr)
+			}
+
+			usecase := NewPresenceUseCase(pr, ur, &config.PresenceConfig{TTL: time.Minute})
+
+			got, err := usecase.Disconnect(context.Background(), membershipID, connectionID)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("Disconnect() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("Disconnect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Disconnect() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPresenceUseCase_SetStatus(t *testing.T) {
+	t.Parallel()
+	membershipID := uuid.New().String()
+	away := entity.Presence{MembershipID: membershipID, Status: entity.PresenceAway}
+
+	patterns := []struct {
+		name    string
+		status  string
+		setup   func(pr *mock.MockPresenceRepository)
+		want    *entity.Presence
+		wantErr error
+	}{
+		{
+			name:   "success",
+			status: entity.PresenceAway,
+			setup: func(pr *mock.MockPresenceRepository) {
+				pr.EXPECT().SetStatus(gomock.Any(), membershipID, entity.PresenceAway).Return(nil)
+				pr.EXPECT().List(gomock.Any(), []string{membershipID}).Return([]entity.Presence{away}, nil)
+			},
+			want:    &away,
+			wantErr: nil,
+		},
+		{
+			name:    "Fail: offline cannot be set",
+			status:  entity.PresenceOffline,
+			want:    nil,
+			wantErr: ErrInvalidPresenceStatus,
+		},
+		{
+			name:   "Fail: failed to set status",
+			status: entity.PresenceAway,
+			setup: func(pr *mock.MockPresenceRepository) {
+				pr.EXPECT().SetStatus(gomock.Any(), membershipID, entity.PresenceAway).Return(fmt.Errorf("connection refused"))
+			},
+			want:    nil,
+			wantErr: fmt.Errorf("connection refused"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			pr := mock.NewMockPresenceRepository(ctrl)
+			ur := mock.NewMockMembershipRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(pr)
+			}
+
+			usecase := NewPresenceUseCase(pr, ur, &config.PresenceConfig{TTL: time.Minute})
+
+			got, err := usecase.SetStatus(context.Background(), membershipID, tt.status)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("SetStatus() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("SetStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SetStatus() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPresenceUseCase_ListPresences(t *testing.T) {
+	t.Parallel()
+	workspaceID := uuid.New().String()
+	membershipID := uuid.New().String() + "_" + workspaceID
+	memberID := uuid.New().String() + "_" + workspaceID
+	membership := entity.Membership{ID: membershipID, WorkspaceID: workspaceID}
+	presences := []entity.Presence{{MembershipID: memberID, Status: entity.PresenceOnline}}
+
+	patterns := []struct {
+		name          string
+		membershipIDs []string
+		setup         func(pr *mock.MockPresenceRepository, ur *mock.MockMembershipRepository)
+		want          []entity.Presence
+		wantErr       error
+	}{
+		{
+			name:          "success",
+			membershipIDs: []string{memberID},
+			setup: func(pr *mock.MockPresenceRepository, ur *mock.MockMembershipRepository) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				pr.EXPECT().List(gomock.Any(), []string{memberID}).Return(presences, nil)
+			},
+			want:    presences,
+			wantErr: nil,
+		},
+		{
+			name:          "Fail: requester is not a member",
+			membershipIDs: []string{memberID},
+			setup: func(_ *mock.MockPresenceRepository, ur *mock.MockMembershipRepository) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(nil, fmt.Errorf("not found"))
+			},
+			want:    nil,
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name:          "Fail: membership of another workspace",
+			membershipIDs: []string{memberID, uuid.New().String() + "_" + uuid.New().String()},
+			setup: func(_ *mock.MockPresenceRepository, ur *mock.MockMembershipRepository) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+			},
+			want:    nil,
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name:          "Fail: failed to list presences",
+			membershipIDs: []string{memberID},
+			setup: func(pr *mock.MockPresenceRepository, ur *mock.MockMembershipRepository) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				pr.EXPECT().List(gomock.Any(), []string{memberID}).Return(nil, fmt.Errorf("connection refused"))
+			},
+			want:    nil,
+			wantErr: fmt.Errorf("connection refused"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			pr := mock.NewMockPresenceRepository(ctrl)
+			ur := mock.NewMockMembershipRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(pr, ur)
+			}
+
+			usecase := NewPresenceUseCase(pr, ur, &config.PresenceConfig{TTL: time.Minute})
+
+			got, err := usecase.ListPresences(context.Background(), membershipID, workspaceID, tt.membershipIDs)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("ListPresences() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("ListPresences() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListPresences() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
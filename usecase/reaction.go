@@ -0,0 +1,145 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package usecase
+
+import (
+	"context"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+type ReactionUseCase interface {
+	AddReaction(ctx context.Context, messageID, membershipID, emoji string) (*entity.Message, error)
+	RemoveReaction(ctx context.Context, messageID, membershipID, emoji string) (*entity.Message, error)
+}
+
+type reactionUseCase struct {
+	mr  repository.MessageRepository
+	mcr repository.MessageCacheRepository
+	rr  repository.ReactionRepository
+	rcr repository.ReactionCacheRepository
+}
+
+func NewReactionUseCase(
+	mr repository.MessageRepository,
+	mcr repository.MessageCacheRepository,
+	rr repository.ReactionRepository,
+	rcr repository.ReactionCacheRepository,
+) ReactionUseCase {
+	return &reactionUseCase{
+		mr:  mr,
+		mcr: mcr,
+		rr:  rr,
+		rcr: rcr,
+	}
+}
+
+// AddReaction は、メッセージにリアクションを付け、集計し直したメッセージを返します。
+// 同じリアクションが既に付いている場合は何もしません。
+func (ruc *reactionUseCase) AddReaction(ctx context.Context, messageID, membershipID, emoji string) (*entity.Message, error) {
+	message, err := cacheMessage(ctx, ruc.mr, ruc.mcr, messageID)
+	if err != nil {
+		return nil, err
+	}
+	reaction, err := entity.NewReaction(messageID, membershipID, emoji)
+	if err != nil {
+		log.Error("Failed to create reaction", log.Ferror(err))
+		return nil, err
+	}
+
+	if err = ruc.rr.Create(ctx, *reaction); err != nil {
+		log.Error("Failed to create reaction", log.Fstring("msgID", messageID), log.Fstring("membershipID", membershipID))
+		return nil, err
+	}
+	if err = ruc.rcr.Add(ctx, *reaction); err != nil {
+		log.Error("Failed to cache reaction", log.Fstring("msgID", messageID), log.Fstring("membershipID", membershipID))
+		return nil, err
+	}
+
+	return ruc.countReactions(ctx, *message)
+}
+
+// RemoveReaction は、メッセージからリアクションを外し、集計し直したメッセージを返します。
+func (ruc *reactionUseCase) RemoveReaction(ctx context.Context, messageID, membershipID, emoji string) (*entity.Message, error) {
+	message, err := cacheMessage(ctx, ruc.mr, ruc.mcr, messageID)
+	if err != nil {
+		return nil, err
+	}
+	reaction, err := entity.NewReaction(messageID, membershipID, emoji)
+	if err != nil {
+		log.Error("Failed to create reaction", log.Ferror(err))
+		return nil, err
+	}
+
+	if err = ruc.rr.Delete(ctx, *reaction); err != nil {
+		log.Error("Failed to delete reaction", log.Fstring("msgID", messageID), log.Fstring("membershipID", membershipID))
+		return nil, err
+	}
+	if err = ruc.rcr.Remove(ctx, *reaction); err != nil {
+		log.Error("Failed to remove reaction from cache", log.Fstring("msgID", messageID), log.Fstring("membershipID", membershipID))
+		return nil, err
+	}
+
+	return ruc.countReactions(ctx, *message)
+}
+
+func (ruc *reactionUseCase) countReactions(ctx context.Context, message entity.Message) (*entity.Message, error) {
+	messages := []entity.Message{message}
+	if err := attachReactions(ctx, ruc.rr, ruc.rcr, messages); err != nil {
+		return nil, err
+	}
+	return &messages[0], nil
+}
+
+// attachReactions は、メッセージごとにリアクションを集計して設定します。
+// キャッシュにないメッセージのリアクションはMySQLから読み込み、キャッシュに載せ直します。
+func attachReactions(
+	ctx context.Context,
+	rr repository.ReactionRepository,
+	rcr repository.ReactionCacheRepository,
+	messages []entity.Message,
+) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	ids := make([]string, len(messages))
+	for i, message := range messages {
+		ids[i] = message.ID
+	}
+
+	reactions, err := rcr.List(ctx, ids)
+	cacheAvailable := err == nil
+	if !cacheAvailable {
+		log.Warn("Failed to get reactions from cache, falling back to database", log.Ferror(err))
+		reactions = make(map[string][]entity.Reaction, len(ids))
+	}
+
+	var misses []string
+	for _, id := range ids {
+		if _, ok := reactions[id]; !ok {
+			misses = append(misses, id)
+		}
+	}
+	if len(misses) > 0 {
+		var stored []entity.Reaction
+		stored, err = rr.ListByMessageIDs(ctx, misses)
+		if err != nil {
+			log.Error("Failed to get reactions", log.Ferror(err))
+			return err
+		}
+		for _, reaction := range stored {
+			reactions[reaction.MessageID] = append(reactions[reaction.MessageID], reaction)
+		}
+		if cacheAvailable {
+			if err = rcr.Fill(ctx, misses, stored); err != nil {
+				log.Warn("Failed to fill reaction cache", log.Ferror(err))
+			}
+		}
+	}
+
+	for i := range messages {
+		messages[i].Reactions = entity.CountReactions(reactions[messages[i].ID])
+	}
+	return nil
+}
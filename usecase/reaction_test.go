@@ -0,0 +1,242 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository/mock"
+)
+
+func TestReactionUseCase_AddReaction(t *testing.T) {
+	t.Parallel()
+	channelID := uuid.New().String()
+	authorID := uuid.New().String()
+	membershipID := uuid.New().String()
+	createdAt := time.Now().Truncate(time.Second)
+	message := entity.Message{
+		ID:           uuid.New().String(),
+		MembershipID: authorID,
+		ChannelID:    channelID,
+		Text:         "test message",
+		CreatedAt:    createdAt,
+	}
+	existing := entity.Reaction{MessageID: message.ID, MembershipID: authorID, Emoji: "👍", CreatedAt: createdAt}
+	reaction := entity.Reaction{MessageID: message.ID, MembershipID: membershipID, Emoji: "👍", CreatedAt: createdAt.Add(time.Second)}
+	withReactions := message
+	withReactions.Reactions = []entity.ReactionCount{
+		{Emoji: "👍", Count: 2, MembershipIDs: []string{authorID, membershipID}},
+	}
+
+	// 作成日時はユースケース内で設定されるため、引数は型のみで照合します。
+	sameReaction := gomock.AssignableToTypeOf(entity.Reaction{})
+
+	patterns := []struct {
+		name  string
+		emoji string
+		setup func(
+			mmr *mock.MockMessageRepository,
+			mcr *mock.MockMessageCacheRepository,
+			rr *mock.MockReactionRepository,
+			rcr *mock.MockReactionCacheRepository,
+		)
+		want    *entity.Message
+		wantErr error
+	}{
+		{
+			name:  "success",
+			emoji: "👍",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+				rr *mock.MockReactionRepository,
+				rcr *mock.MockReactionCacheRepository,
+			) {
+				mcr.EXPECT().Get(gomock.Any(), message.ID).Return(&message, nil)
+				rr.EXPECT().Create(gomock.Any(), sameReaction).DoAndReturn(
+					func(_ context.Context, r entity.Reaction) error {
+						if r.MessageID != message.ID || r.MembershipID != membershipID || r.Emoji != "👍" {
+							return fmt.Errorf("unexpected reaction: %+v", r)
+						}
+						return nil
+					},
+				)
+				rcr.EXPECT().Add(gomock.Any(), sameReaction).Return(nil)
+				rcr.EXPECT().List(gomock.Any(), []string{message.ID}).Return(
+					map[string][]entity.Reaction{message.ID: {existing, reaction}},
+					nil,
+				)
+			},
+			want:    &withReactions,
+			wantErr: nil,
+		},
+		{
+			name:  "success: reactions loaded from database",
+			emoji: "👍",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+				rr *mock.MockReactionRepository,
+				rcr *mock.MockReactionCacheRepository,
+			) {
+				mcr.EXPECT().Get(gomock.Any(), message.ID).Return(&message, nil)
+				rr.EXPECT().Create(gomock.Any(), sameReaction).Return(nil)
+				rcr.EXPECT().Add(gomock.Any(), sameReaction).Return(nil)
+				rcr.EXPECT().List(gomock.Any(), []string{message.ID}).Return(map[string][]entity.Reaction{}, nil)
+				rr.EXPECT().ListByMessageIDs(gomock.Any(), []string{message.ID}).Return(
+					[]entity.Reaction{existing, reaction},
+					nil,
+				)
+				rcr.EXPECT().Fill(gomock.Any(), []string{message.ID}, []entity.Reaction{existing, reaction}).Return(nil)
+			},
+			want:    &withReactions,
+			wantErr: nil,
+		},
+		{
+			name:  "Fail: emoji is required",
+			emoji: "",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+				rr *mock.MockReactionRepository,
+				rcr *mock.MockReactionCacheRepository,
+			) {
+				mcr.EXPECT().Get(gomock.Any(), message.ID).Return(&message, nil)
+			},
+			want:    nil,
+			wantErr: fmt.Errorf("messageID, membershipID and emoji are required"),
+		},
+		{
+			name:  "Fail: message not found",
+			emoji: "👍",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+				rr *mock.MockReactionRepository,
+				rcr *mock.MockReactionCacheRepository,
+			) {
+				mcr.EXPECT().Get(gomock.Any(), message.ID).Return(nil, fmt.Errorf("redis: nil"))
+				mmr.EXPECT().Get(gomock.Any(), message.ID).Return(nil, fmt.Errorf("sql: no rows in result set"))
+			},
+			want:    nil,
+			wantErr: fmt.Errorf("message not found"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			mr := mock.NewMockMessageRepository(ctrl)
+			mcr := mock.NewMockMessageCacheRepository(ctrl)
+			rr := mock.NewMockReactionRepository(ctrl)
+			rcr := mock.NewMockReactionCacheRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(mr, mcr, rr, rcr)
+			}
+
+			usecase := NewReactionUseCase(mr, mcr, rr, rcr)
+
+			got, err := usecase.AddReaction(context.Background(), message.ID, membershipID, tt.emoji)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("AddReaction() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("AddReaction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AddReaction() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReactionUseCase_RemoveReaction(t *testing.T) {
+	t.Parallel()
+	membershipID := uuid.New().String()
+	message := entity.Message{
+		ID:           uuid.New().String(),
+		MembershipID: membershipID,
+		ChannelID:    uuid.New().String(),
+		Text:         "test message",
+		CreatedAt:    time.Now().Truncate(time.Second),
+	}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			mcr *mock.MockMessageCacheRepository,
+			rr *mock.MockReactionRepository,
+			rcr *mock.MockReactionCacheRepository,
+		)
+		want    *entity.Message
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(
+				mcr *mock.MockMessageCacheRepository,
+				rr *mock.MockReactionRepository,
+				rcr *mock.MockReactionCacheRepository,
+			) {
+				mcr.EXPECT().Get(gomock.Any(), message.ID).Return(&message, nil)
+				rr.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
+				rcr.EXPECT().Remove(gomock.Any(), gomock.Any()).Return(nil)
+				rcr.EXPECT().List(gomock.Any(), []string{message.ID}).Return(
+					map[string][]entity.Reaction{message.ID: nil},
+					nil,
+				)
+			},
+			want:    &message,
+			wantErr: nil,
+		},
+		{
+			name: "Fail: failed to delete reaction",
+			setup: func(
+				mcr *mock.MockMessageCacheRepository,
+				rr *mock.MockReactionRepository,
+				rcr *mock.MockReactionCacheRepository,
+			) {
+				mcr.EXPECT().Get(gomock.Any(), message.ID).Return(&message, nil)
+				rr.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(fmt.Errorf("database error"))
+			},
+			want:    nil,
+			wantErr: fmt.Errorf("database error"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			mr := mock.NewMockMessageRepository(ctrl)
+			mcr := mock.NewMockMessageCacheRepository(ctrl)
+			rr := mock.NewMockReactionRepository(ctrl)
+			rcr := mock.NewMockReactionCacheRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(mcr, rr, rcr)
+			}
+
+			usecase := NewReactionUseCase(mr, mcr, rr, rcr)
+
+			got, err := usecase.RemoveReaction(context.Background(), message.ID, membershipID, "👍")
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("RemoveReaction() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("RemoveReaction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RemoveReaction() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+type SearchUseCase interface {
+	// SearchMessages は、ワークスペースのメンバーが参加しているチャンネルのメッセージを検索します。
+	SearchMessages(ctx context.Context, membershipID, workspaceID string, params SearchMessagesParams) (*SearchMessagesResult, error)
+}
+
+type searchUseCase struct {
+	mr  repository.MessageRepository
+	mcr repository.MessageCacheRepository
+	mrr repository.MembershipChannelRepository
+	ur  repository.MembershipRepository
+}
+
+func NewSearchUseCase(
+	mr repository.MessageRepository,
+	mcr repository.MessageCacheRepository,
+	mrr repository.MembershipChannelRepository,
+	ur repository.MembershipRepository,
+) SearchUseCase {
+	return &searchUseCase{
+		mr:  mr,
+		mcr: mcr,
+		mrr: mrr,
+		ur:  ur,
+	}
+}
+
+type SearchMessagesParams struct {
+	Text         string
+	ChannelID    string // 空の場合は参加している全てのチャンネルを検索します
+	MembershipID string // 作成者のメンバーシップ。空の場合は絞りません
+	Since        *time.Time
+	Until        *time.Time
+	HasLink      bool
+	Offset       int64
+	Limit        int64
+}
+
+type SearchMessagesResult struct {
+	Messages   []entity.Message
+	NextOffset int64 // 次のページがない場合は0
+}
+
+// SearchMessages は、永続化済みのメッセージを検索し、まだ永続化されていない編集と削除をキャッシュから反映して返します。
+// 検索の対象は書き戻しを終えたメッセージのため、作成や編集が検索に反映されるのは書き戻しの後です。
+func (suc *searchUseCase) SearchMessages(
+	ctx context.Context,
+	membershipID, workspaceID string,
+	params SearchMessagesParams,
+) (*SearchMessagesResult, error) {
+	membership, err := suc.ur.Get(ctx, membershipID)
+	if err != nil || membership.IsDeleted || membership.WorkspaceID != workspaceID {
+		log.Warn("Membership cannot search messages", log.Fstring("membershipID", membershipID), log.Fstring("workspaceID", workspaceID))
+		return nil, ErrPermissionDenied
+	}
+
+	channelIDs, err := suc.searchableChannelIDs(ctx, membershipID, params.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := repository.MessageSearchQuery{
+		Text:         params.Text,
+		ChannelIDs:   channelIDs,
+		MembershipID: params.MembershipID,
+		Since:        params.Since,
+		Until:        params.Until,
+		HasLink:      params.HasLink,
+		Offset:       params.Offset,
+		Limit:        params.Limit,
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+	if query.Limit <= 0 {
+		query.Limit = config.DefaultSearchPageSize
+	}
+	if query.Limit > config.MaxSearchPageSize {
+		query.Limit = config.MaxSearchPageSize
+	}
+	limit := query.Limit
+	// 次のページがあるかを判定するため1件多く取得します。
+	query.Limit++
+
+	messages, err := suc.mr.Search(ctx, query)
+	if err != nil {
+		log.Error("Failed to search messages", log.Fstring("membershipID", membershipID), log.Ferror(err))
+		return nil, err
+	}
+
+	result := &SearchMessagesResult{}
+	if int64(len(messages)) > limit {
+		messages = messages[:limit]
+		result.NextOffset = query.Offset + limit
+	}
+	result.Messages, err = suc.applyPendingChanges(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// searchableChannelIDs は、メンバーシップが参加しているチャンネルのIDを返します。
+// channelIDを指定した場合は、参加していることを確認してそのチャンネルだけを返します。
+func (suc *searchUseCase) searchableChannelIDs(ctx context.Context, membershipID, channelID string) ([]string, error) {
+	if channelID != "" {
+		if err := authorizeChannelMember(ctx, suc.mrr, membershipID, channelID); err != nil {
+			return nil, err
+		}
+		return []string{channelID}, nil
+	}
+
+	membershipChannels, err := suc.mrr.List(ctx, []repository.QueryCondition{{Field: "membership_id", Value: membershipID}})
+	if err != nil {
+		log.Error("Failed to list membership channels", log.Fstring("membershipID", membershipID))
+		return nil, err
+	}
+	channelIDs := make([]string, 0, len(membershipChannels))
+	for _, membershipChannel := range membershipChannels {
+		channelIDs = append(channelIDs, membershipChannel.ChannelID)
+	}
+	return channelIDs, nil
+}
+
+// applyPendingChanges は、削除がまだ永続化されていないメッセージを除き、キャッシュにあるメッセージは最新の内容に置き換えます。
+func (suc *searchUseCase) applyPendingChanges(ctx context.Context, messages []entity.Message) ([]entity.Message, error) {
+	if len(messages) == 0 {
+		return messages, nil
+	}
+
+	ids := make([]string, len(messages))
+	for i, message := range messages {
+		ids[i] = message.ID
+	}
+	deleted, err := suc.mcr.PendingDeletes(ctx, ids)
+	if err != nil {
+		log.Error("Failed to get pending deleted messages", log.Ferror(err))
+		return nil, err
+	}
+
+	results := make([]entity.Message, 0, len(messages))
+	for _, message := range messages {
+		if deleted[message.ID] {
+			continue
+		}
+		if cached, err := suc.mcr.Get(ctx, message.ID); err == nil {
+			message = *cached
+		}
+		results = append(results, message)
+	}
+	return results, nil
+}
@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/config"
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository"
+	"github.com/tusmasoma/connectHub-backend/repository/mock"
+)
+
+func TestSearchUseCase_SearchMessages(t *testing.T) {
+	t.Parallel()
+	workspaceID := uuid.New().String()
+	membershipID := uuid.New().String() + "_" + workspaceID
+	channelID := uuid.New().String()
+	otherChannelID := uuid.New().String()
+	membership := entity.Membership{ID: membershipID, WorkspaceID: workspaceID}
+	membershipChannels := []entity.MembershipChannel{
+		{MembershipID: membershipID, ChannelID: channelID},
+		{MembershipID: membershipID, ChannelID: otherChannelID},
+	}
+	found := []entity.Message{
+		{ID: "edited", ChannelID: channelID, Text: "hello"},
+		{ID: "deleted", ChannelID: channelID, Text: "hello"},
+		{ID: "persisted", ChannelID: otherChannelID, Text: "hello world"},
+	}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			mr *mock.MockMessageRepository,
+			mcr *mock.MockMessageCacheRepository,
+			mrr *mock.MockMembershipChannelRepository,
+			ur *mock.MockMembershipRepository,
+		)
+		params  SearchMessagesParams
+		want    *SearchMessagesResult
+		wantErr error
+	}{
+		{
+			name: "success: pending edits and deletes are applied",
+			setup: func(
+				mr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				ur *mock.MockMembershipRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				mrr.EXPECT().List(gomock.Any(), []repository.QueryCondition{{Field: "membership_id", Value: membershipID}}).Return(membershipChannels, nil)
+				mr.EXPECT().Search(gomock.Any(), repository.MessageSearchQuery{
+					Text:       "hello",
+					ChannelIDs: []string{channelID, otherChannelID},
+					HasLink:    true,
+					Limit:      config.DefaultSearchPageSize + 1,
+				}).Return(found, nil)
+				mcr.EXPECT().PendingDeletes(gomock.Any(), []string{"edited", "deleted", "persisted"}).Return(map[string]bool{"deleted": true}, nil)
+				mcr.EXPECT().Get(gomock.Any(), "edited").Return(&entity.Message{ID: "edited", ChannelID: channelID, Text: "hello again"}, nil)
+				mcr.EXPECT().Get(gomock.Any(), "persisted").Return(nil, fmt.Errorf("redis: nil"))
+			},
+			params: SearchMessagesParams{Text: "hello", HasLink: true},
+			want: &SearchMessagesResult{
+				Messages: []entity.Message{
+					{ID: "edited", ChannelID: channelID, Text: "hello again"},
+					{ID: "persisted", ChannelID: otherChannelID, Text: "hello world"},
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "success: next page",
+			setup: func(
+				mr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				ur *mock.MockMembershipRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				mrr.EXPECT().Get(gomock.Any(), membershipID, channelID).Return(&membershipChannels[0], nil)
+				mr.EXPECT().Search(gomock.Any(), repository.MessageSearchQuery{
+					Text:       "hello",
+					ChannelIDs: []string{channelID},
+					Offset:     2,
+					Limit:      3,
+				}).Return(found, nil)
+				mcr.EXPECT().PendingDeletes(gomock.Any(), []string{"edited", "deleted"}).Return(map[string]bool{}, nil)
+				mcr.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("redis: nil")).Times(2)
+			},
+			params: SearchMessagesParams{Text: "hello", ChannelID: channelID, Offset: 2, Limit: 2},
+			want: &SearchMessagesResult{
+				Messages:   found[:2],
+				NextOffset: 4,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: channel not joined",
+			setup: func(
+				mr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				ur *mock.MockMembershipRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&membership, nil)
+				mrr.EXPECT().Get(gomock.Any(), membershipID, channelID).Return(nil, repository.ErrMembershipChannelNotFound)
+			},
+			params:  SearchMessagesParams{Text: "hello", ChannelID: channelID},
+			want:    nil,
+			wantErr: ErrChannelAccessDenied,
+		},
+		{
+			name: "Fail: membership of another workspace",
+			setup: func(
+				mr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+				mrr *mock.MockMembershipChannelRepository,
+				ur *mock.MockMembershipRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), membershipID).Return(&entity.Membership{ID: membershipID, WorkspaceID: uuid.New().String()}, nil)
+			},
+			params:  SearchMessagesParams{Text: "hello"},
+			want:    nil,
+			wantErr: ErrPermissionDenied,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mr := mock.NewMockMessageRepository(ctrl)
+			mcr := mock.NewMockMessageCacheRepository(ctrl)
+			mrr := mock.NewMockMembershipChannelRepository(ctrl)
+			ur := mock.NewMockMembershipRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(mr, mcr, mrr, ur)
+			}
+
+			usecase := NewSearchUseCase(mr, mcr, mrr, ur)
+
+			got, err := usecase.SearchMessages(context.Background(), membershipID, workspaceID, tt.params)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SearchMessages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SearchMessages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
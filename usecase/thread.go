@@ -0,0 +1,179 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/internal/log"
+	"github.com/tusmasoma/connectHub-backend/repository"
+)
+
+type ThreadUseCase interface {
+	CreateReply(ctx context.Context, parentID string, reply entity.Message) (*ThreadReply, error)
+	ListThread(ctx context.Context, parentID string) ([]entity.Message, error)
+	FollowThread(ctx context.Context, parentID, membershipID string) error
+	UnfollowThread(ctx context.Context, parentID, membershipID string) error
+}
+
+// ThreadReply は、返信の作成結果です。Followersには返信の作成者も含まれます。
+type ThreadReply struct {
+	Reply     entity.Message
+	Parent    entity.Message
+	Followers []string
+}
+
+type threadUseCase struct {
+	mr  repository.MessageRepository
+	mcr repository.MessageCacheRepository
+	tfr repository.ThreadFollowerRepository
+	rr  repository.ReactionRepository
+	rcr repository.ReactionCacheRepository
+}
+
+func NewThreadUseCase(
+	mr repository.MessageRepository,
+	mcr repository.MessageCacheRepository,
+	tfr repository.ThreadFollowerRepository,
+	rr repository.ReactionRepository,
+	rcr repository.ReactionCacheRepository,
+) ThreadUseCase {
+	return &threadUseCase{
+		mr:  mr,
+		mcr: mcr,
+		tfr: tfr,
+		rr:  rr,
+		rcr: rcr,
+	}
+}
+
+// CreateReply は、返信をスレッドに追加します。返信した人と親メッセージの作成者は自動的にスレッドをフォローします。
+func (tuc *threadUseCase) CreateReply(ctx context.Context, parentID string, reply entity.Message) (*ThreadReply, error) {
+	parent, err := cacheMessage(ctx, tuc.mr, tuc.mcr, parentID)
+	if err != nil {
+		return nil, err
+	}
+	if parent.ParentID != "" {
+		log.Warn("Cannot reply to a reply", log.Fstring("msgID", parentID))
+		return nil, fmt.Errorf("cannot reply to a reply")
+	}
+
+	reply.ParentID = parent.ID
+	reply.ChannelID = parent.ChannelID
+	// 添付ファイルはチャンネルのメッセージにだけ付けられます。
+	reply.Attachments = nil
+	reply.ReplyCount = 0
+	reply.LastReplyAt = nil
+	updated, err := tuc.mcr.CreateReply(ctx, reply)
+	if err != nil {
+		log.Error("Failed to cache reply", log.Fstring("parentID", parentID), log.Ferror(err))
+		return nil, err
+	}
+
+	for _, membershipID := range []string{parent.MembershipID, reply.MembershipID} {
+		if err = tuc.FollowThread(ctx, parent.ID, membershipID); err != nil {
+			return nil, err
+		}
+	}
+
+	followers, err := tuc.tfr.List(ctx, []repository.QueryCondition{{Field: "message_id", Value: parent.ID}})
+	if err != nil {
+		log.Error("Failed to list thread followers", log.Fstring("msgID", parent.ID))
+		return nil, err
+	}
+	membershipIDs := make([]string, len(followers))
+	for i, follower := range followers {
+		membershipIDs[i] = follower.MembershipID
+	}
+
+	return &ThreadReply{
+		Reply:     reply,
+		Parent:    *updated,
+		Followers: membershipIDs,
+	}, nil
+}
+
+// ListThread は、キャッシュとMySQLの返信をID単位で重複排除して作成日時の昇順で返します。
+func (tuc *threadUseCase) ListThread(ctx context.Context, parentID string) ([]entity.Message, error) {
+	cached, err := tuc.mcr.ListThread(ctx, parentID)
+	cacheAvailable := err == nil
+	if !cacheAvailable {
+		log.Warn("Failed to get replies from cache, falling back to database", log.Ferror(err))
+	}
+
+	stored, err := tuc.mr.ListThread(ctx, parentID)
+	if err != nil {
+		log.Error("Failed to get replies", log.Fstring("parentID", parentID))
+		return nil, err
+	}
+
+	var channelID string
+	if len(stored) > 0 {
+		channelID = stored[0].ChannelID
+	}
+	replies, _, err := mergeMessages(ctx, tuc.mcr, channelID, cached, stored, cacheAvailable)
+	if err != nil {
+		return nil, err
+	}
+	sortMessages(replies)
+	if err = attachReactions(ctx, tuc.rr, tuc.rcr, replies); err != nil {
+		return nil, err
+	}
+	return replies, nil
+}
+
+func (tuc *threadUseCase) FollowThread(ctx context.Context, parentID, membershipID string) error {
+	follower, err := entity.NewThreadFollower(parentID, membershipID)
+	if err != nil {
+		log.Error("Failed to create thread follower", log.Ferror(err))
+		return err
+	}
+	if err = tuc.tfr.Create(ctx, *follower); err != nil {
+		log.Error("Failed to follow thread", log.Fstring("msgID", parentID), log.Fstring("membershipID", membershipID))
+		return err
+	}
+	return nil
+}
+
+func (tuc *threadUseCase) UnfollowThread(ctx context.Context, parentID, membershipID string) error {
+	if err := tuc.tfr.Delete(ctx, parentID, membershipID); err != nil {
+		log.Error("Failed to unfollow thread", log.Fstring("msgID", parentID), log.Fstring("membershipID", membershipID))
+		return err
+	}
+	return nil
+}
+
+// cacheMessage は、メッセージがキャッシュになければMySQLから読み込んでキャッシュに載せます。
+// 削除がまだMySQLに反映されていないメッセージは見つからなかったものとして扱います。
+func cacheMessage(
+	ctx context.Context,
+	mr repository.MessageRepository,
+	mcr repository.MessageCacheRepository,
+	id string,
+) (*entity.Message, error) {
+	if message, err := mcr.Get(ctx, id); err == nil {
+		return message, nil
+	}
+
+	message, err := mr.Get(ctx, id)
+	if err != nil {
+		log.Warn("Message not found", log.Fstring("msgID", id), log.Ferror(err))
+		return nil, ErrMessageNotFound
+	}
+	deleted, err := mcr.PendingDeletes(ctx, []string{id})
+	if err != nil {
+		log.Error("Failed to get pending deleted messages", log.Ferror(err))
+		return nil, err
+	}
+	if deleted[id] {
+		log.Warn("Message not found", log.Fstring("msgID", id))
+		return nil, ErrMessageNotFound
+	}
+
+	if err = mcr.Fill(ctx, message.ChannelID, []entity.Message{*message}); err != nil {
+		log.Error("Failed to fill message cache", log.Fstring("msgID", id), log.Ferror(err))
+		return nil, err
+	}
+	return message, nil
+}
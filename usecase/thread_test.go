@@ -0,0 +1,322 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository"
+	"github.com/tusmasoma/connectHub-backend/repository/mock"
+)
+
+func TestThreadUseCase_CreateReply(t *testing.T) {
+	t.Parallel()
+	channelID := uuid.New().String()
+	authorID := uuid.New().String()
+	replierID := uuid.New().String()
+	createdAt := time.Now().Truncate(time.Second)
+	parent := entity.Message{
+		ID:           uuid.New().String(),
+		MembershipID: authorID,
+		ChannelID:    channelID,
+		Text:         "parent message",
+		CreatedAt:    createdAt.Add(-1 * time.Minute),
+	}
+	reply := entity.Message{
+		ID:           uuid.New().String(),
+		MembershipID: replierID,
+		Text:         "reply message",
+		CreatedAt:    createdAt,
+	}
+	cachedReply := reply
+	cachedReply.ParentID = parent.ID
+	cachedReply.ChannelID = channelID
+	updatedParent := parent
+	updatedParent.ReplyCount = 1
+	updatedParent.LastReplyAt = &createdAt
+	followers := []entity.ThreadFollower{
+		{MessageID: parent.ID, MembershipID: authorID},
+		{MessageID: parent.ID, MembershipID: replierID},
+	}
+	nestedParent := parent
+	nestedParent.ParentID = uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			mmr *mock.MockMessageRepository,
+			mcr *mock.MockMessageCacheRepository,
+			tfr *mock.MockThreadFollowerRepository,
+		)
+		want    *ThreadReply
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+				tfr *mock.MockThreadFollowerRepository,
+			) {
+				mcr.EXPECT().Get(gomock.Any(), parent.ID).Return(&parent, nil)
+				mcr.EXPECT().CreateReply(gomock.Any(), cachedReply).Return(&updatedParent, nil)
+				tfr.EXPECT().Create(gomock.Any(), entity.ThreadFollower{MessageID: parent.ID, MembershipID: authorID}).Return(nil)
+				tfr.EXPECT().Create(gomock.Any(), entity.ThreadFollower{MessageID: parent.ID, MembershipID: replierID}).Return(nil)
+				tfr.EXPECT().List(
+					gomock.Any(),
+					[]repository.QueryCondition{{Field: "message_id", Value: parent.ID}},
+				).Return(followers, nil)
+			},
+			want: &ThreadReply{
+				Reply:     cachedReply,
+				Parent:    updatedParent,
+				Followers: []string{authorID, replierID},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "success: parent loaded from database",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+				tfr *mock.MockThreadFollowerRepository,
+			) {
+				mcr.EXPECT().Get(gomock.Any(), parent.ID).Return(nil, fmt.Errorf("redis: nil"))
+				mmr.EXPECT().Get(gomock.Any(), parent.ID).Return(&parent, nil)
+				mcr.EXPECT().PendingDeletes(gomock.Any(), []string{parent.ID}).Return(map[string]bool{}, nil)
+				mcr.EXPECT().Fill(gomock.Any(), channelID, []entity.Message{parent}).Return(nil)
+				mcr.EXPECT().CreateReply(gomock.Any(), cachedReply).Return(&updatedParent, nil)
+				tfr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+				tfr.EXPECT().List(gomock.Any(), gomock.Any()).Return(followers, nil)
+			},
+			want: &ThreadReply{
+				Reply:     cachedReply,
+				Parent:    updatedParent,
+				Followers: []string{authorID, replierID},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: parent is being deleted",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+				tfr *mock.MockThreadFollowerRepository,
+			) {
+				mcr.EXPECT().Get(gomock.Any(), parent.ID).Return(nil, fmt.Errorf("redis: nil"))
+				mmr.EXPECT().Get(gomock.Any(), parent.ID).Return(&parent, nil)
+				mcr.EXPECT().PendingDeletes(gomock.Any(), []string{parent.ID}).Return(map[string]bool{parent.ID: true}, nil)
+			},
+			want:    nil,
+			wantErr: fmt.Errorf("message not found"),
+		},
+		{
+			name: "Fail: cannot reply to a reply",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+				tfr *mock.MockThreadFollowerRepository,
+			) {
+				mcr.EXPECT().Get(gomock.Any(), parent.ID).Return(&nestedParent, nil)
+			},
+			want:    nil,
+			wantErr: fmt.Errorf("cannot reply to a reply"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			mr := mock.NewMockMessageRepository(ctrl)
+			mcr := mock.NewMockMessageCacheRepository(ctrl)
+			tfr := mock.NewMockThreadFollowerRepository(ctrl)
+			rr := mock.NewMockReactionRepository(ctrl)
+			rcr := mock.NewMockReactionCacheRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(mr, mcr, tfr)
+			}
+
+			usecase := NewThreadUseCase(mr, mcr, tfr, rr, rcr)
+
+			got, err := usecase.CreateReply(context.Background(), parent.ID, reply)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("CreateReply() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("CreateReply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CreateReply() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThreadUseCase_ListThread(t *testing.T) {
+	t.Parallel()
+	channelID := uuid.New().String()
+	parentID := uuid.New().String()
+	membershipID := uuid.New().String()
+	now := time.Now().Truncate(time.Second)
+	older := entity.Message{
+		ID:           "0a4a1bb4-6c2d-4a0e-9b8c-6f2b3c0a9c10",
+		MembershipID: membershipID,
+		ChannelID:    channelID,
+		ParentID:     parentID,
+		Text:         "older reply",
+		CreatedAt:    now.Add(-2 * time.Minute),
+	}
+	newer := entity.Message{
+		ID:           "1b5b2cc5-7d3e-4b1f-8c9d-7a3c4d1b0d21",
+		MembershipID: membershipID,
+		ChannelID:    channelID,
+		ParentID:     parentID,
+		Text:         "newer reply",
+		CreatedAt:    now.Add(-1 * time.Minute),
+	}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			mmr *mock.MockMessageRepository,
+			mcr *mock.MockMessageCacheRepository,
+		)
+		want    []entity.Message
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				mcr.EXPECT().ListThread(gomock.Any(), parentID).Return([]entity.Message{newer}, nil)
+				mmr.EXPECT().ListThread(gomock.Any(), parentID).Return([]entity.Message{older}, nil)
+				mcr.EXPECT().PendingDeletes(gomock.Any(), []string{older.ID}).Return(map[string]bool{}, nil)
+				mcr.EXPECT().Fill(gomock.Any(), channelID, []entity.Message{older}).Return(nil)
+			},
+			want:    []entity.Message{older, newer},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: database unavailable",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mcr *mock.MockMessageCacheRepository,
+			) {
+				mcr.EXPECT().ListThread(gomock.Any(), parentID).Return([]entity.Message{newer}, nil)
+				mmr.EXPECT().ListThread(gomock.Any(), parentID).Return(nil, fmt.Errorf("connection refused"))
+			},
+			want:    nil,
+			wantErr: fmt.Errorf("connection refused"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			mr := mock.NewMockMessageRepository(ctrl)
+			mcr := mock.NewMockMessageCacheRepository(ctrl)
+			tfr := mock.NewMockThreadFollowerRepository(ctrl)
+			rr := mock.NewMockReactionRepository(ctrl)
+			rcr := mock.NewMockReactionCacheRepository(ctrl)
+			cacheNoReactions(rcr)
+
+			if tt.setup != nil {
+				tt.setup(mr, mcr)
+			}
+
+			usecase := NewThreadUseCase(mr, mcr, tfr, rr, rcr)
+
+			got, err := usecase.ListThread(context.Background(), parentID)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("ListThread() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("ListThread() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListThread() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThreadUseCase_FollowThread(t *testing.T) {
+	t.Parallel()
+	parentID := uuid.New().String()
+	membershipID := uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			tfr *mock.MockThreadFollowerRepository,
+		)
+		arg struct {
+			parentID     string
+			membershipID string
+		}
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(tfr *mock.MockThreadFollowerRepository) {
+				tfr.EXPECT().Create(gomock.Any(), entity.ThreadFollower{MessageID: parentID, MembershipID: membershipID}).Return(nil)
+			},
+			arg: struct {
+				parentID     string
+				membershipID string
+			}{
+				parentID:     parentID,
+				membershipID: membershipID,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: messageID is required",
+			arg: struct {
+				parentID     string
+				membershipID string
+			}{
+				parentID:     "",
+				membershipID: membershipID,
+			},
+			wantErr: fmt.Errorf("messageID is required"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			mr := mock.NewMockMessageRepository(ctrl)
+			mcr := mock.NewMockMessageCacheRepository(ctrl)
+			tfr := mock.NewMockThreadFollowerRepository(ctrl)
+			rr := mock.NewMockReactionRepository(ctrl)
+			rcr := mock.NewMockReactionCacheRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(tfr)
+			}
+
+			usecase := NewThreadUseCase(mr, mcr, tfr, rr, rcr)
+
+			err := usecase.FollowThread(context.Background(), tt.arg.parentID, tt.arg.membershipID)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("FollowThread() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("FollowThread() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
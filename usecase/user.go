@@ -3,48 +3,88 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/tusmasoma/connectHub-backend/config"
 	"github.com/tusmasoma/connectHub-backend/entity"
 	"github.com/tusmasoma/connectHub-backend/internal/auth"
 	"github.com/tusmasoma/connectHub-backend/internal/log"
 	"github.com/tusmasoma/connectHub-backend/repository"
 )
 
+var (
+	// ErrInvalidRefreshToken は、リフレッシュトークンが存在しないか、期限切れや失効済みの場合に返されます。
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused は、使用済みのリフレッシュトークンが再び使われた場合に返されます。
+	// トークンが漏洩した可能性があるため、そのトークンを発行したセッションを失効させます。
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+	// ErrSessionNotFound は、対象のセッションが存在しないか、他のユーザーのセッションの場合に返されます。
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+// SessionMetadata は、ログインした端末の情報です。セッションの一覧で端末を見分けるために使います。
+type SessionMetadata struct {
+	Device    string
+	UserAgent string
+	IP        string
+}
+
+// AuthTokens は、サインアップやログイン、リフレッシュで発行したトークンの組です。
+type AuthTokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    time.Duration // アクセストークンの有効期間
+}
+
 type UserUseCase interface {
-	SignUpAndGenerateToken(ctx context.Context, email string, password string) (string, error)
-	LoginAndGenerateToken(ctx context.Context, email string, password string) (string, error)
-	LogoutUser(ctx context.Context, userID string) error
+	SignUpAndGenerateToken(ctx context.Context, email string, password string, meta SessionMetadata) (*AuthTokens, error)
+	LoginAndGenerateToken(ctx context.Context, email string, password string, meta SessionMetadata) (*AuthTokens, error)
+	// RefreshToken は、リフレッシュトークンを新しいものに交換し、アクセストークンを再発行します。
+	RefreshToken(ctx context.Context, refreshToken string) (*AuthTokens, error)
+	// LogoutUser は、sessionIDのセッションだけをログアウトします。他の端末のセッションは残ります。
+	LogoutUser(ctx context.Context, userID, sessionID string) error
+	ListSessions(ctx context.Context, userID string) ([]entity.Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	RevokeAllSessions(ctx context.Context, userID string) error
 }
 
 type userUseCase struct {
-	ur repository.UserRepository
-	cr repository.UserCacheRepository
-	tr repository.TransactionRepository
+	ur   repository.UserRepository
+	sr   repository.SessionRepository
+	rtr  repository.RefreshTokenRepository
+	tr   repository.TransactionRepository
+	km   *auth.KeyManager
+	conf *config.AuthConfig
 }
 
-func NewUserUseCase(ur repository.UserRepository, cr repository.UserCacheRepository, tr repository.TransactionRepository) UserUseCase {
+func NewUserUseCase(
+	ur repository.UserRepository,
+	sr repository.SessionRepository,
+	rtr repository.RefreshTokenRepository,
+	tr repository.TransactionRepository,
+	km *auth.KeyManager,
+	conf *config.AuthConfig,
+) UserUseCase {
 	return &userUseCase{
-		ur: ur,
-		cr: cr,
-		tr: tr,
+		ur:   ur,
+		sr:   sr,
+		rtr:  rtr,
+		tr:   tr,
+		km:   km,
+		conf: conf,
 	}
 }
 
-func (uuc *userUseCase) SignUpAndGenerateToken(ctx context.Context, email string, password string) (string, error) {
+func (uuc *userUseCase) SignUpAndGenerateToken(ctx context.Context, email string, password string, meta SessionMetadata) (*AuthTokens, error) {
 	user, err := uuc.CreateUser(ctx, email, password)
 	if err != nil {
 		log.Error("Failed to create user", log.Fstring("email", email))
-		return "", err
-	}
-
-	jwt, jti := auth.GenerateToken(user.ID, user.Email)
-	if err = uuc.cr.SetUserSession(ctx, user.ID, jti); err != nil {
-		log.Error("Failed to set access token in cache", log.Fstring("userID", user.ID), log.Fstring("jti", jti))
-		return "", err
+		return nil, err
 	}
 
-	return jwt, nil
+	return uuc.startSession(ctx, *user, meta)
 }
 
 func (uuc *userUseCase) CreateUser(ctx context.Context, email string, password string) (*entity.User, error) {
@@ -82,43 +122,165 @@ func (uuc *userUseCase) CreateUser(ctx context.Context, email string, password s
 	return user, nil
 }
 
-func (uuc *userUseCase) LoginAndGenerateToken(ctx context.Context, email string, password string) (string, error) {
+func (uuc *userUseCase) LoginAndGenerateToken(ctx context.Context, email string, password string, meta SessionMetadata) (*AuthTokens, error) {
 	var user entity.User
 	// emailでMySQLにユーザー情報問い合わせ
 	users, err := uuc.ur.List(ctx, []repository.QueryCondition{{Field: "Email", Value: email}})
 	if err != nil {
 		log.Error("Error retrieving user by email", log.Fstring("email", email))
-		return "", err
+		return nil, err
 	}
 	if len(users) > 0 {
 		user = users[0]
 	}
-	// 既にログイン済みかどうか確認する
-	session, _ := uuc.cr.GetUserSession(ctx, user.ID)
-	if session != "" {
-		log.Info("Already logged in", log.Fstring("userID", user.ID))
-		return "", fmt.Errorf("user id in cache")
-	}
-
 	// Clientから送られてきたpasswordをハッシュ化したものとMySQLから返されたハッシュ化されたpasswordを比較する
 	if err = auth.CompareHashAndPassword(user.Password, password); err != nil {
 		log.Info("Password does not match", log.Fstring("email", email))
-		return "", err
+		return nil, err
 	}
 
-	jwt, jti := auth.GenerateToken(user.ID, email)
-	if err = uuc.cr.SetUserSession(ctx, user.ID, jti); err != nil {
-		log.Error("Failed to set access token in cache", log.Fstring("userID", user.ID), log.Fstring("jti", jti))
-		return "", err
+	return uuc.startSession(ctx, user, meta)
+}
+
+// startSession は、ログインした端末の新しいセッションを作成し、最初のアクセストークンとリフレッシュトークンを発行します。
+func (uuc *userUseCase) startSession(ctx context.Context, user entity.User, meta SessionMetadata) (*AuthTokens, error) {
+	session, err := entity.NewSession(user.ID, meta.Device, meta.UserAgent, meta.IP)
+	if err != nil {
+		return nil, err
 	}
-	return jwt, nil
+	return uuc.issueTokens(ctx, user, session, uuc.sr.Save)
+}
+
+// issueTokens は、セッションのリフレッシュトークンとアクセストークンを発行し、saveでセッションの有効期限を延ばします。
+// セッションはリフレッシュトークンと同じ期間だけ残り、最後に発行したアクセストークンのjtiだけを有効とします。
+func (uuc *userUseCase) issueTokens(
+	ctx context.Context,
+	user entity.User,
+	session *entity.Session,
+	save func(ctx context.Context, session entity.Session) error,
+) (*AuthTokens, error) {
+	refreshToken, err := auth.GenerateRefreshToken()
+	if err != nil {
+		log.Error("Failed to generate refresh token", log.Fstring("userID", user.ID), log.Ferror(err))
+		return nil, err
+	}
+	token, err := entity.NewRefreshToken(auth.HashRefreshToken(refreshToken), user.ID, session.ID, uuc.conf.RefreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	if err = uuc.rtr.Create(ctx, *token); err != nil {
+		log.Error("Failed to save refresh token", log.Fstring("userID", user.ID))
+		return nil, err
+	}
+
+	jwt, jti, err := uuc.km.GenerateToken(user.ID, user.Email, session.ID, uuc.conf.AccessTokenTTL)
+	if err != nil {
+		log.Error("Failed to generate access token", log.Fstring("userID", user.ID), log.Ferror(err))
+		return nil, err
+	}
+	session.Renew(jti, uuc.conf.RefreshTokenTTL, time.Now())
+	if err = save(ctx, *session); err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			log.Info("Session revoked while refreshing", log.Fstring("userID", user.ID), log.Fstring("sessionID", session.ID))
+			return nil, ErrInvalidRefreshToken
+		}
+		log.Error("Failed to save session", log.Fstring("userID", user.ID), log.Fstring("sessionID", session.ID))
+		return nil, err
+	}
+
+	return &AuthTokens{
+		AccessToken:  jwt,
+		RefreshToken: refreshToken,
+		ExpiresIn:    uuc.conf.AccessTokenTTL,
+	}, nil
+}
+
+func (uuc *userUseCase) RefreshToken(ctx context.Context, refreshToken string) (*AuthTokens, error) {
+	token, err := uuc.rtr.Get(ctx, auth.HashRefreshToken(refreshToken))
+	if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+		log.Info("Refresh token not found")
+		return nil, ErrInvalidRefreshToken
+	} else if err != nil {
+		log.Error("Failed to get refresh token", log.Ferror(err))
+		return nil, err
+	}
+	if token.Expired(time.Now()) {
+		log.Info("Refresh token expired", log.Fstring("userID", token.UserID))
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// ログアウトや再利用の検知でセッションが失効していれば、残っているトークンも使えません
+	session, err := uuc.sr.Get(ctx, token.UserID, token.FamilyID)
+	if errors.Is(err, repository.ErrSessionNotFound) {
+		log.Info("Refresh token session revoked", log.Fstring("userID", token.UserID), log.Fstring("sessionID", token.FamilyID))
+		return nil, ErrInvalidRefreshToken
+	} else if err != nil {
+		log.Error("Failed to get session", log.Fstring("userID", token.UserID), log.Fstring("sessionID", token.FamilyID))
+		return nil, err
+	}
+
+	marked, err := uuc.rtr.MarkUsed(ctx, *token)
+	if err != nil {
+		log.Error("Failed to mark refresh token as used", log.Fstring("userID", token.UserID))
+		return nil, err
+	}
+	if !marked {
+		log.Warn("Refresh token reuse detected", log.Fstring("userID", token.UserID), log.Fstring("sessionID", session.ID))
+		if err = uuc.sr.Delete(ctx, session.UserID, session.ID); err != nil && !errors.Is(err, repository.ErrSessionNotFound) {
+			log.Error("Failed to revoke session", log.Fstring("userID", session.UserID), log.Fstring("sessionID", session.ID))
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	user, err := uuc.ur.Get(ctx, token.UserID)
+	if err != nil {
+		log.Error("Failed to get user", log.Fstring("userID", token.UserID))
+		return nil, err
+	}
+
+	log.Info("Successfully refreshed token", log.Fstring("userID", user.ID), log.Fstring("sessionID", session.ID))
+	// 再利用の検知やログアウトで、トークンを発行している間にセッションが失効することがあるため、存在する場合だけ更新します
+	return uuc.issueTokens(ctx, *user, session, uuc.sr.Renew)
+}
+
+func (uuc *userUseCase) LogoutUser(ctx context.Context, userID, sessionID string) error {
+	if err := uuc.sr.Delete(ctx, userID, sessionID); err != nil && !errors.Is(err, repository.ErrSessionNotFound) {
+		log.Error("Failed to delete session", log.Fstring("userID", userID), log.Fstring("sessionID", sessionID))
+		return err
+	}
+	log.Info("Successfully logged out", log.Fstring("userID", userID), log.Fstring("sessionID", sessionID))
+	return nil
+}
+
+func (uuc *userUseCase) ListSessions(ctx context.Context, userID string) ([]entity.Session, error) {
+	sessions, err := uuc.sr.List(ctx, userID)
+	if err != nil {
+		log.Error("Failed to list sessions", log.Fstring("userID", userID))
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (uuc *userUseCase) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	// セッションはユーザーごとに保存しているため、他のユーザーのセッションは見つかりません
+	err := uuc.sr.Delete(ctx, userID, sessionID)
+	if errors.Is(err, repository.ErrSessionNotFound) {
+		log.Info("Session not found", log.Fstring("userID", userID), log.Fstring("sessionID", sessionID))
+		return ErrSessionNotFound
+	} else if err != nil {
+		log.Error("Failed to revoke session", log.Fstring("userID", userID), log.Fstring("sessionID", sessionID))
+		return err
+	}
+	log.Info("Successfully revoked session", log.Fstring("userID", userID), log.Fstring("sessionID", sessionID))
+	return nil
 }
 
-func (uuc *userUseCase) LogoutUser(ctx context.Context, userID string) error {
-	if err := uuc.cr.Delete(ctx, userID); err != nil {
-		log.Error("Failed to delete userID from cache", log.Fstring("userID", userID))
+func (uuc *userUseCase) RevokeAllSessions(ctx context.Context, userID string) error {
+	if err := uuc.sr.DeleteAll(ctx, userID); err != nil {
+		log.Error("Failed to revoke sessions", log.Fstring("userID", userID))
 		return err
 	}
-	log.Info("Successfully logged out", log.Fstring("userID", userID))
+	log.Info("Successfully revoked all sessions", log.Fstring("userID", userID))
 	return nil
 }
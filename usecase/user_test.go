@@ -2,17 +2,34 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 
+	"github.com/tusmasoma/connectHub-backend/config"
 	"github.com/tusmasoma/connectHub-backend/entity"
 	"github.com/tusmasoma/connectHub-backend/internal/auth"
 	"github.com/tusmasoma/connectHub-backend/repository"
 	"github.com/tusmasoma/connectHub-backend/repository/mock"
 )
 
+var authConfig = &config.AuthConfig{AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: 720 * time.Hour}
+
+func newTestKeyManager(t *testing.T) *auth.KeyManager {
+	t.Helper()
+	t.Setenv("PRIVATE_KEY_PATH", "../.certificate/private_key.pem")
+	t.Setenv("PUBLIC_KEY_PATH", "../.certificate/public_key.pem")
+	km, err := auth.NewKeyManager("")
+	if err != nil {
+		t.Fatalf("Failed to NewKeyManager: %v", err)
+	}
+	return km
+}
+
 type SignUpAndGenerateTokenArg struct {
 	ctx      context.Context
 	email    string
@@ -20,20 +37,21 @@ type SignUpAndGenerateTokenArg struct {
 }
 
 func TestUserUseCase_SignUpAndGenerateToken(t *testing.T) {
+	km := newTestKeyManager(t)
 	patterns := []struct {
 		name  string
 		setup func(
 			m *mock.MockUserRepository,
-			m1 *mock.MockUserCacheRepository,
 			m2 *mock.MockTransactionRepository,
+			m3 *mock.MockRefreshTokenRepository,
+			m4 *mock.MockSessionRepository,
 		)
 		arg     SignUpAndGenerateTokenArg
 		wantErr error
 	}{
 		{
 			name: "success",
-			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository, m2 *mock.MockTransactionRepository) {
-				t.Setenv("PRIVATE_KEY_PATH", "../.certificate/private_key.pem")
+			setup: func(m *mock.MockUserRepository, m2 *mock.MockTransactionRepository, m3 *mock.MockRefreshTokenRepository, m4 *mock.MockSessionRepository) {
 				m2.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
 					return fn(ctx)
 				})
@@ -45,7 +63,8 @@ func TestUserUseCase_SignUpAndGenerateToken(t *testing.T) {
 					gomock.Any(),
 					gomock.Any(),
 				).Return(nil)
-				m1.EXPECT().SetUserSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				m3.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				m4.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil)
 			},
 			arg: SignUpAndGenerateTokenArg{
 				ctx:      context.Background(),
@@ -56,7 +75,7 @@ func TestUserUseCase_SignUpAndGenerateToken(t *testing.T) {
 		},
 		{
 			name: "Fail: Username already exists",
-			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository, m2 *mock.MockTransactionRepository) {
+			setup: func(m *mock.MockUserRepository, m2 *mock.MockTransactionRepository, m3 *mock.MockRefreshTokenRepository, m4 *mock.MockSessionRepository) {
 				m2.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
 					return fn(ctx)
 				})
@@ -78,15 +97,16 @@ func TestUserUseCase_SignUpAndGenerateToken(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			ur := mock.NewMockUserRepository(ctrl)
-			cr := mock.NewMockUserCacheRepository(ctrl)
 			tr := mock.NewMockTransactionRepository(ctrl)
+			rtr := mock.NewMockRefreshTokenRepository(ctrl)
+			sr := mock.NewMockSessionRepository(ctrl)
 
 			if tt.setup != nil {
-				tt.setup(ur, cr, tr)
+				tt.setup(ur, tr, rtr, sr)
 			}
 
-			usecase := NewUserUseCase(ur, cr, tr)
-			jwt, err := usecase.SignUpAndGenerateToken(tt.arg.ctx, tt.arg.email, tt.arg.passward)
+			usecase := NewUserUseCase(ur, sr, rtr, tr, km, authConfig)
+			tokens, err := usecase.SignUpAndGenerateToken(tt.arg.ctx, tt.arg.email, tt.arg.passward, SessionMetadata{})
 
 			if (err != nil) != (tt.wantErr != nil) {
 				t.Errorf("SignUpAndGenerateToken() error = %v, wantErr %v", err, tt.wantErr)
@@ -94,7 +114,7 @@ func TestUserUseCase_SignUpAndGenerateToken(t *testing.T) {
 				t.Errorf("SignUpAndGenerateToken() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
-			if tt.wantErr == nil && jwt == "" {
+			if tt.wantErr == nil && (tokens.AccessToken == "" || tokens.RefreshToken == "") {
 				t.Error("Failed to generate token")
 			}
 		})
@@ -108,19 +128,20 @@ type LoginAndGenerateTokenArg struct {
 }
 
 func TestUserUseCase_LoginAndGenerateToken(t *testing.T) {
+	km := newTestKeyManager(t)
 	patterns := []struct {
 		name  string
 		setup func(
 			m *mock.MockUserRepository,
-			m1 *mock.MockUserCacheRepository,
+			m1 *mock.MockSessionRepository,
+			m2 *mock.MockRefreshTokenRepository,
 		)
 		arg     LoginAndGenerateTokenArg
 		wantErr error
 	}{
 		{
 			name: "success",
-			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
-				t.Setenv("PRIVATE_KEY_PATH", "../.certificate/private_key.pem")
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockSessionRepository, m2 *mock.MockRefreshTokenRepository) {
 				passward, _ := auth.PasswordEncrypt("password123")
 				m.EXPECT().List(
 					gomock.Any(),
@@ -134,15 +155,13 @@ func TestUserUseCase_LoginAndGenerateToken(t *testing.T) {
 						},
 					}, nil,
 				)
-				m1.EXPECT().GetUserSession(
-					gomock.Any(),
-					"f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
-				).Return("", nil)
-				m1.EXPECT().SetUserSession(
-					gomock.Any(),
-					"f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
-					gomock.Any(),
-				).Return(nil)
+				m2.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				m1.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, session entity.Session) error {
+					if session.UserID != "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2" || session.Device != "laptop" || session.JTI == "" {
+						t.Errorf("Save() got unexpected session %v", session)
+					}
+					return nil
+				})
 			},
 			arg: LoginAndGenerateTokenArg{
 				ctx:      context.Background(),
@@ -151,37 +170,9 @@ func TestUserUseCase_LoginAndGenerateToken(t *testing.T) {
 			},
 			wantErr: nil,
 		},
-		{
-			name: "Fail: already logged in",
-			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
-				passward, _ := auth.PasswordEncrypt("password123")
-				m.EXPECT().List(
-					gomock.Any(),
-					[]repository.QueryCondition{{Field: "Email", Value: "test@gmail.com"}},
-				).Return(
-					[]entity.User{
-						{
-							ID:       "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
-							Email:    "test@gmail.com",
-							Password: passward,
-						},
-					}, nil,
-				)
-				m1.EXPECT().GetUserSession(
-					gomock.Any(),
-					"f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
-				).Return("session", nil)
-			},
-			arg: LoginAndGenerateTokenArg{
-				ctx:      context.Background(),
-				email:    "test@gmail.com",
-				passward: "password123",
-			},
-			wantErr: fmt.Errorf("user id in cache"),
-		},
 		{
 			name: "Fail: invalid passward",
-			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockSessionRepository, m2 *mock.MockRefreshTokenRepository) {
 				passward, _ := auth.PasswordEncrypt("password456")
 				m.EXPECT().List(
 					gomock.Any(),
@@ -195,10 +186,6 @@ func TestUserUseCase_LoginAndGenerateToken(t *testing.T) {
 						},
 					}, nil,
 				)
-				m1.EXPECT().GetUserSession(
-					gomock.Any(),
-					"f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
-				).Return("", nil)
 			},
 			arg: LoginAndGenerateTokenArg{
 				ctx:      context.Background(),
@@ -214,15 +201,16 @@ func TestUserUseCase_LoginAndGenerateToken(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			ur := mock.NewMockUserRepository(ctrl)
-			cr := mock.NewMockUserCacheRepository(ctrl)
+			sr := mock.NewMockSessionRepository(ctrl)
 			tr := mock.NewMockTransactionRepository(ctrl)
+			rtr := mock.NewMockRefreshTokenRepository(ctrl)
 
 			if tt.setup != nil {
-				tt.setup(ur, cr)
+				tt.setup(ur, sr, rtr)
 			}
 
-			usecase := NewUserUseCase(ur, cr, tr)
-			jwt, err := usecase.LoginAndGenerateToken(tt.arg.ctx, tt.arg.email, tt.arg.passward)
+			usecase := NewUserUseCase(ur, sr, rtr, tr, km, authConfig)
+			tokens, err := usecase.LoginAndGenerateToken(tt.arg.ctx, tt.arg.email, tt.arg.passward, SessionMetadata{Device: "laptop"})
 
 			if (err != nil) != (tt.wantErr != nil) {
 				t.Errorf("LoginAndGenerateToken() error = %v, wantErr %v", err, tt.wantErr)
@@ -230,9 +218,253 @@ func TestUserUseCase_LoginAndGenerateToken(t *testing.T) {
 				t.Errorf("LoginAndGenerateToken() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
-			if tt.wantErr == nil && jwt == "" {
+			if tt.wantErr == nil && (tokens.AccessToken == "" || tokens.RefreshToken == "") {
 				t.Error("Failed to generate token")
 			}
 		})
 	}
 }
+
+func TestUserUseCase_RefreshToken(t *testing.T) {
+	km := newTestKeyManager(t)
+	userID := "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"
+	sessionID := "0b2a8f4e-3c1d-4e5f-9a6b-7c8d9e0f1a2b"
+	refreshToken := "refresh-token"
+	stored := entity.RefreshToken{
+		ID:        auth.HashRefreshToken(refreshToken),
+		UserID:    userID,
+		FamilyID:  sessionID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	session := entity.Session{ID: sessionID, UserID: userID, JTI: "old-jti", Device: "laptop"}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockUserRepository,
+			m1 *mock.MockSessionRepository,
+			m2 *mock.MockRefreshTokenRepository,
+		)
+		wantErr error
+	}{
+		{
+			name: "success: token is rotated within the session",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockSessionRepository, m2 *mock.MockRefreshTokenRepository) {
+				m2.EXPECT().Get(gomock.Any(), stored.ID).Return(&stored, nil)
+				m1.EXPECT().Get(gomock.Any(), userID, sessionID).Return(&session, nil)
+				m2.EXPECT().MarkUsed(gomock.Any(), stored).Return(true, nil)
+				m.EXPECT().Get(gomock.Any(), userID).Return(&entity.User{ID: userID, Email: "test@gmail.com"}, nil)
+				m2.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, token entity.RefreshToken) error {
+					if token.ID == stored.ID || token.FamilyID != sessionID {
+						t.Errorf("Create() got unexpected token %v", token)
+					}
+					return nil
+				})
+				m1.EXPECT().Renew(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, got entity.Session) error {
+					if got.ID != sessionID || got.Device != "laptop" || got.JTI == "old-jti" {
+						t.Errorf("Save() got unexpected session %v", got)
+					}
+					return nil
+				})
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: unknown token",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockSessionRepository, m2 *mock.MockRefreshTokenRepository) {
+				m2.EXPECT().Get(gomock.Any(), stored.ID).Return(nil, repository.ErrRefreshTokenNotFound)
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "Fail: session revoked by logout",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockSessionRepository, m2 *mock.MockRefreshTokenRepository) {
+				m2.EXPECT().Get(gomock.Any(), stored.ID).Return(&stored, nil)
+				m1.EXPECT().Get(gomock.Any(), userID, sessionID).Return(nil, repository.ErrSessionNotFound)
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "Fail: reuse revokes the session",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockSessionRepository, m2 *mock.MockRefreshTokenRepository) {
+				m2.EXPECT().Get(gomock.Any(), stored.ID).Return(&stored, nil)
+				m1.EXPECT().Get(gomock.Any(), userID, sessionID).Return(&session, nil)
+				m2.EXPECT().MarkUsed(gomock.Any(), stored).Return(false, nil)
+				m1.EXPECT().Delete(gomock.Any(), userID, sessionID).Return(nil)
+			},
+			wantErr: ErrRefreshTokenReused,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+			sr := mock.NewMockSessionRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+			rtr := mock.NewMockRefreshTokenRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ur, sr, rtr)
+			}
+
+			usecase := NewUserUseCase(ur, sr, rtr, tr, km, authConfig)
+			tokens, err := usecase.RefreshToken(context.Background(), refreshToken)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RefreshToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (tokens.AccessToken == "" || tokens.RefreshToken == refreshToken) {
+				t.Errorf("RefreshToken() did not rotate tokens: %v", tokens)
+			}
+		})
+	}
+}
+
+// fakeSessionRepository は、リフレッシュの並行した処理を順に再現するため、セッションをメモリに保存します。
+type fakeSessionRepository struct {
+	mu       sync.Mutex
+	sessions map[string]entity.Session
+}
+
+func (fsr *fakeSessionRepository) Save(_ context.Context, session entity.Session) error {
+	fsr.mu.Lock()
+	defer fsr.mu.Unlock()
+	fsr.sessions[session.ID] = session
+	return nil
+}
+
+func (fsr *fakeSessionRepository) Renew(_ context.Context, session entity.Session) error {
+	fsr.mu.Lock()
+	defer fsr.mu.Unlock()
+	if _, ok := fsr.sessions[session.ID]; !ok {
+		return repository.ErrSessionNotFound
+	}
+	fsr.sessions[session.ID] = session
+	return nil
+}
+
+func (fsr *fakeSessionRepository) Get(_ context.Context, _, sessionID string) (*entity.Session, error) {
+	fsr.mu.Lock()
+	defer fsr.mu.Unlock()
+	session, ok := fsr.sessions[sessionID]
+	if !ok {
+		return nil, repository.ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (fsr *fakeSessionRepository) List(context.Context, string) ([]entity.Session, error) {
+	return nil, nil
+}
+
+func (fsr *fakeSessionRepository) Touch(context.Context, string, string, time.Time) error {
+	return nil
+}
+
+func (fsr *fakeSessionRepository) Delete(_ context.Context, _, sessionID string) error {
+	fsr.mu.Lock()
+	defer fsr.mu.Unlock()
+	if _, ok := fsr.sessions[sessionID]; !ok {
+		return repository.ErrSessionNotFound
+	}
+	delete(fsr.sessions, sessionID)
+	return nil
+}
+
+func (fsr *fakeSessionRepository) DeleteAll(context.Context, string) error {
+	return nil
+}
+
+// TestUserUseCase_RefreshToken_ConcurrentReuse は、正規のリフレッシュがトークンを使用済みにした直後に
+// 同じトークンが再利用されて失効したセッションを、正規のリフレッシュが作り直さないことを確認します。
+func TestUserUseCase_RefreshToken_ConcurrentReuse(t *testing.T) {
+	km := newTestKeyManager(t)
+	userID := "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"
+	sessionID := "0b2a8f4e-3c1d-4e5f-9a6b-7c8d9e0f1a2b"
+	refreshToken := "refresh-token"
+	stored := entity.RefreshToken{
+		ID:        auth.HashRefreshToken(refreshToken),
+		UserID:    userID,
+		FamilyID:  sessionID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	sr := &fakeSessionRepository{sessions: map[string]entity.Session{
+		sessionID: {ID: sessionID, UserID: userID, JTI: "old-jti", ExpiresAt: time.Now().Add(time.Hour)},
+	}}
+
+	ctrl := gomock.NewController(t)
+	ur := mock.NewMockUserRepository(ctrl)
+	rtr := mock.NewMockRefreshTokenRepository(ctrl)
+	tr := mock.NewMockTransactionRepository(ctrl)
+	usecase := NewUserUseCase(ur, sr, rtr, tr, km, authConfig)
+
+	rtr.EXPECT().Get(gomock.Any(), stored.ID).Return(&stored, nil).Times(2)
+	var reuseErr error
+	gomock.InOrder(
+		// Aがトークンを使用済みにした直後に、Bが同じトークンを再利用します
+		rtr.EXPECT().MarkUsed(gomock.Any(), stored).DoAndReturn(func(ctx context.Context, _ entity.RefreshToken) (bool, error) {
+			_, reuseErr = usecase.RefreshToken(ctx, refreshToken)
+			return true, nil
+		}),
+		rtr.EXPECT().MarkUsed(gomock.Any(), stored).Return(false, nil),
+	)
+	ur.EXPECT().Get(gomock.Any(), userID).Return(&entity.User{ID: userID, Email: "test@gmail.com"}, nil)
+	rtr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	_, err := usecase.RefreshToken(context.Background(), refreshToken)
+	if !errors.Is(reuseErr, ErrRefreshTokenReused) {
+		t.Errorf("RefreshToken() of the reused token error = %v, wantErr %v", reuseErr, ErrRefreshTokenReused)
+	}
+	if !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("RefreshToken() error = %v, wantErr %v", err, ErrInvalidRefreshToken)
+	}
+	if _, err = sr.Get(context.Background(), userID, sessionID); !errors.Is(err, repository.ErrSessionNotFound) {
+		t.Errorf("Session was recreated after reuse detection: %v", err)
+	}
+}
+
+func TestUserUseCase_RevokeSession(t *testing.T) {
+	t.Parallel()
+	userID := "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"
+	sessionID := "0b2a8f4e-3c1d-4e5f-9a6b-7c8d9e0f1a2b"
+
+	patterns := []struct {
+		name    string
+		setup   func(m *mock.MockSessionRepository)
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockSessionRepository) {
+				m.EXPECT().Delete(gomock.Any(), userID, sessionID).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: session of another user",
+			setup: func(m *mock.MockSessionRepository) {
+				m.EXPECT().Delete(gomock.Any(), userID, sessionID).Return(repository.ErrSessionNotFound)
+			},
+			wantErr: ErrSessionNotFound,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			sr := mock.NewMockSessionRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(sr)
+			}
+
+			usecase := NewUserUseCase(nil, sr, nil, nil, nil, authConfig)
+			if err := usecase.RevokeSession(context.Background(), userID, sessionID); !errors.Is(err, tt.wantErr) {
+				t.Errorf("RevokeSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,13 +3,18 @@ package usecase
 
 import (
 	"context"
+	"errors"
 
 	"github.com/tusmasoma/connectHub-backend/entity"
 	"github.com/tusmasoma/connectHub-backend/internal/log"
 	"github.com/tusmasoma/connectHub-backend/repository"
 )
 
+var ErrWorkspaceNotFound = errors.New("workspace not found")
+
 type WorkspaceUseCase interface {
+	ListWorkspaces(ctx context.Context) ([]entity.Workspace, error)
+	GetWorkspace(ctx context.Context, id string) (*entity.Workspace, error)
 	CreateWorkspace(ctx context.Context, id, name string) error
 }
 
@@ -23,6 +28,28 @@ func NewWorkspaceUseCase(wr repository.WorkspaceRepository) WorkspaceUseCase {
 	}
 }
 
+func (wuc *workspaceUseCase) ListWorkspaces(ctx context.Context) ([]entity.Workspace, error) {
+	workspaces, err := wuc.wr.List(ctx, nil)
+	if err != nil {
+		log.Error("Failed to list workspaces", log.Ferror(err))
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+func (wuc *workspaceUseCase) GetWorkspace(ctx context.Context, id string) (*entity.Workspace, error) {
+	workspaces, err := wuc.wr.List(ctx, []repository.QueryCondition{{Field: "id", Value: id}})
+	if err != nil {
+		log.Error("Failed to get workspace", log.Fstring("workspaceID", id))
+		return nil, err
+	}
+	if len(workspaces) == 0 {
+		log.Info("Workspace not found", log.Fstring("workspaceID", id))
+		return nil, ErrWorkspaceNotFound
+	}
+	return &workspaces[0], nil
+}
+
 func (wuc *workspaceUseCase) CreateWorkspace(ctx context.Context, id, name string) error {
 	workspace, err := entity.NewWorkspace(id, name)
 	if err != nil {
@@ -2,13 +2,16 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 
 	"github.com/tusmasoma/connectHub-backend/entity"
+	"github.com/tusmasoma/connectHub-backend/repository"
 	"github.com/tusmasoma/connectHub-backend/repository/mock"
 )
 
@@ -99,3 +102,143 @@ func TestWorkspaceUseCase_CreateWorkspace(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkspaceUseCase_GetWorkspace(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	workspace := entity.Workspace{ID: workspaceID, Name: "test", Description: "test"}
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockWorkspaceRepository,
+		)
+		arg struct {
+			ctx context.Context
+			id  string
+		}
+		want    *entity.Workspace
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockWorkspaceRepository) {
+				m.EXPECT().List(
+					gomock.Any(),
+					[]repository.QueryCondition{{Field: "id", Value: workspaceID}},
+				).Return([]entity.Workspace{workspace}, nil)
+			},
+			arg: struct {
+				ctx context.Context
+				id  string
+			}{
+				ctx: context.Background(),
+				id:  workspaceID,
+			},
+			want:    &workspace,
+			wantErr: nil,
+		},
+		{
+			name: "Fail: workspace not found",
+			setup: func(m *mock.MockWorkspaceRepository) {
+				m.EXPECT().List(
+					gomock.Any(),
+					[]repository.QueryCondition{{Field: "id", Value: workspaceID}},
+				).Return(nil, nil)
+			},
+			arg: struct {
+				ctx context.Context
+				id  string
+			}{
+				ctx: context.Background(),
+				id:  workspaceID,
+			},
+			want:    nil,
+			wantErr: ErrWorkspaceNotFound,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			wr := mock.NewMockWorkspaceRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(wr)
+			}
+
+			usecase := NewWorkspaceUseCase(wr)
+			got, err := usecase.GetWorkspace(tt.arg.ctx, tt.arg.id)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("GetWorkspace() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetWorkspace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetWorkspace() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkspaceUseCase_ListWorkspaces(t *testing.T) {
+	t.Parallel()
+
+	workspaces := []entity.Workspace{
+		{ID: uuid.New().String(), Name: "test1"},
+		{ID: uuid.New().String(), Name: "test2"},
+	}
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockWorkspaceRepository,
+		)
+		want    []entity.Workspace
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockWorkspaceRepository) {
+				m.EXPECT().List(gomock.Any(), nil).Return(workspaces, nil)
+			},
+			want:    workspaces,
+			wantErr: nil,
+		},
+		{
+			name: "Fail: failed to list workspaces",
+			setup: func(m *mock.MockWorkspaceRepository) {
+				m.EXPECT().List(gomock.Any(), nil).Return(nil, fmt.Errorf("connection refused"))
+			},
+			want:    nil,
+			wantErr: fmt.Errorf("connection refused"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			wr := mock.NewMockWorkspaceRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(wr)
+			}
+
+			usecase := NewWorkspaceUseCase(wr)
+			got, err := usecase.ListWorkspaces(context.Background())
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("ListWorkspaces() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("ListWorkspaces() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListWorkspaces() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}